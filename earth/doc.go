@@ -0,0 +1,25 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Package earth implements functions for working with the planet Earth modeled as
+a sphere.
+
+The s2 package works on the unit sphere, so distances are returned as angles
+and areas as steradians. This package converts between those and physical
+lengths and areas on the Earth's surface, using the mean radius of the Earth.
+
+See ../s2 for a more detailed overview.
+*/
+package earth
@@ -0,0 +1,140 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package earth
+
+import (
+	"math"
+
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+const (
+	// Radius is the Earth's mean radius, which is the radius of the
+	// equivalent sphere with the same surface area. According to NASA, this
+	// value is 6371.01 +/- 0.02 km. The equatorial radius is 6378.136 km,
+	// and the polar radius is 6356.752 km. They differ by one part in 298.257.
+	//
+	// Reference: http://ssd.jpl.nasa.gov/phys_props_earth.html, which quotes
+	// Yoder, C.F. 1995. "Astrometric and Geodetic Properties of Earth and the
+	// Solar System" in Global Earth Physics, A Handbook of Physical Constants,
+	// AGU Reference Shelf 1, American Geophysical Union, Table 2.
+	Radius = 6371.01 * Kilometer
+
+	// LowestAltitude is the altitude of the lowest known point on Earth. The
+	// lowest known point on Earth is the Challenger Deep with an altitude of
+	// -10898 meters above the surface of the spherical Earth.
+	LowestAltitude = -10898 * Meter
+
+	// HighestAltitude is the altitude of the highest known point on Earth.
+	// The highest known point on Earth is Mount Everest with an altitude of
+	// 8846 meters above the surface of the spherical Earth.
+	HighestAltitude = 8846 * Meter
+)
+
+// AngleFromLength returns the angle subtended at the center of the Earth by
+// an arc of the given length along the Earth's surface.
+func AngleFromLength(l Length) s1.Angle {
+	return s1.Angle(l/Radius) * s1.Radian
+}
+
+// ChordAngleFromLength returns the ChordAngle subtended at the center of the
+// Earth by an arc of the given length along the Earth's surface.
+func ChordAngleFromLength(l Length) s1.ChordAngle {
+	return s1.ChordAngleFromAngle(AngleFromLength(l))
+}
+
+// LengthFromAngle returns the length along the Earth's surface of an arc that
+// subtends the given angle at the center of the Earth.
+func LengthFromAngle(a s1.Angle) Length {
+	return Length(a.Radians()) * Radius
+}
+
+// LengthFromChordAngle returns the length along the Earth's surface of an arc
+// that subtends the given ChordAngle at the center of the Earth.
+func LengthFromChordAngle(c s1.ChordAngle) Length {
+	return LengthFromAngle(c.Angle())
+}
+
+// LengthFromPoints returns the distance along the Earth's surface between the
+// two given points.
+func LengthFromPoints(a, b s2.Point) Length {
+	return LengthFromAngle(a.Distance(b))
+}
+
+// LengthFromLatLngs returns the distance along the Earth's surface between
+// the two given LatLngs.
+func LengthFromLatLngs(a, b s2.LatLng) Length {
+	return LengthFromAngle(a.Distance(b))
+}
+
+// AngleFromLengthAtAltitude returns the angle subtended at the center of the
+// Earth by an arc of the given length at the given altitude above the
+// Earth's surface, such as the distance travelled by an aircraft.
+func AngleFromLengthAtAltitude(l, altitude Length) s1.Angle {
+	return s1.Angle(l/(Radius+altitude)) * s1.Radian
+}
+
+// LengthFromAngleAtAltitude returns the length of an arc at the given
+// altitude above the Earth's surface that subtends the given angle at the
+// center of the Earth.
+func LengthFromAngleAtAltitude(a s1.Angle, altitude Length) Length {
+	return Length(a.Radians()) * (Radius + altitude)
+}
+
+// LongitudeFromLength returns the range of longitudes spanned by an arc of
+// the given length along the parallel at the given latitude. The result is
+// capped at a full circle, which is also returned at the poles.
+func LongitudeFromLength(l Length, lat s1.Angle) s1.Angle {
+	scalar := math.Cos(lat.Radians())
+	if scalar == 0 {
+		return 2 * math.Pi * s1.Radian
+	}
+	return s1.Angle(math.Min(AngleFromLength(l).Radians()/scalar, 2*math.Pi)) * s1.Radian
+}
+
+// AreaFromSteradians returns the area on the Earth's surface of a region
+// that has the given area in steradians on the unit sphere.
+func AreaFromSteradians(steradians float64) Area {
+	return Area(steradians) * Area(Radius*Radius)
+}
+
+// SteradiansFromArea returns the area in steradians on the unit sphere of a
+// region that has the given area on the Earth's surface.
+func SteradiansFromArea(a Area) float64 {
+	return float64(a / Area(Radius*Radius))
+}
+
+// InitialBearingFromLatLngs returns the bearing at the first point of the
+// shortest path from a to b, measured clockwise from true north, so that 0
+// is north and 90 degrees is east. The result is in the range [-180, 180]
+// degrees. If a is at a pole or the points are the same or antipodal, the
+// bearing is undefined and the result may be any angle.
+func InitialBearingFromLatLngs(a, b s2.LatLng) s1.Angle {
+	lat1 := a.Lat.Radians()
+	cosLat2 := math.Cos(b.Lat.Radians())
+	latDiff := b.Lat.Radians() - a.Lat.Radians()
+	lngDiff := b.Lng.Radians() - a.Lng.Radians()
+
+	x := math.Sin(latDiff) + math.Sin(lat1)*cosLat2*2*haversine(lngDiff)
+	y := math.Sin(lngDiff) * cosLat2
+	return s1.Angle(math.Atan2(y, x)) * s1.Radian
+}
+
+// haversine returns the haversine of the given angle in radians.
+func haversine(radians float64) float64 {
+	sinHalf := math.Sin(radians / 2)
+	return sinHalf * sinHalf
+}
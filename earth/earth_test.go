@@ -0,0 +1,177 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package earth
+
+import (
+	"math"
+	"testing"
+
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+func float64Near(x, y, epsilon float64) bool {
+	return math.Abs(x-y) <= epsilon
+}
+
+func TestLengthUnits(t *testing.T) {
+	tests := []struct {
+		got, want, epsilon float64
+	}{
+		{Kilometer.Meters(), 1000, 0},
+		{(1500 * Meter).Kilometers(), 1.5, 0},
+		{Foot.Meters(), 0.3048, 0},
+		{(3 * Foot).Feet(), 3, 1e-15},
+		{Mile.Feet(), 5280, 1e-9},
+		{Mile.Miles(), 1, 0},
+		{SquareKilometer.SquareMeters(), 1e6, 0},
+		{SquareMile.SquareFeet(), 5280 * 5280, 1e-6},
+		{(2.5 * SquareMile).SquareMiles(), 2.5, 1e-15},
+		{(5e6 * SquareMeter).SquareKilometers(), 5, 0},
+	}
+	for i, test := range tests {
+		if !float64Near(test.got, test.want, test.epsilon) {
+			t.Errorf("%d. got %v, want %v", i, test.got, test.want)
+		}
+	}
+}
+
+func TestAngleLengthConversions(t *testing.T) {
+	if got, want := AngleFromLength(Radius), s1.Radian; !float64Near(got.Radians(), want.Radians(), 1e-15) {
+		t.Errorf("AngleFromLength(Radius) = %v, want %v", got, want)
+	}
+	if got, want := LengthFromAngle(s1.Radian).Kilometers(), 6371.01; !float64Near(got, want, 1e-12) {
+		t.Errorf("LengthFromAngle(1 radian) = %v km, want %v km", got, want)
+	}
+	if got, want := LengthFromAngle(2*s1.Radian), 2*Radius; got != want {
+		t.Errorf("LengthFromAngle(2 radians) = %v, want %v", got, want)
+	}
+	if got, want := ChordAngleFromLength(Radius).Angle(), s1.Radian; !float64Near(got.Radians(), want.Radians(), 1e-15) {
+		t.Errorf("ChordAngleFromLength(Radius) = %v, want %v", got, want)
+	}
+	c := s1.ChordAngleFromAngle(s1.Radian)
+	if got, want := LengthFromChordAngle(c), Radius; !float64Near(got.Meters(), want.Meters(), 1e-6) {
+		t.Errorf("LengthFromChordAngle(%v) = %v, want %v", c, got, want)
+	}
+
+	for _, l := range []Length{0, Meter, 1000 * Kilometer, LowestAltitude, HighestAltitude} {
+		if got := LengthFromAngle(AngleFromLength(l)); !float64Near(got.Meters(), l.Meters(), 1e-9) {
+			t.Errorf("LengthFromAngle(AngleFromLength(%v)) = %v, want %v", l, got, l)
+		}
+	}
+}
+
+func TestLengthAtAltitude(t *testing.T) {
+	if got := AngleFromLengthAtAltitude(Radius, 0); got != AngleFromLength(Radius) {
+		t.Errorf("AngleFromLengthAtAltitude(Radius, 0) = %v, want %v", got, AngleFromLength(Radius))
+	}
+	// Flying higher covers a smaller angle for the same distance.
+	if got, want := AngleFromLengthAtAltitude(Radius+HighestAltitude, HighestAltitude), s1.Radian; !float64Near(got.Radians(), want.Radians(), 1e-15) {
+		t.Errorf("AngleFromLengthAtAltitude(Radius+HighestAltitude, HighestAltitude) = %v, want %v", got, want)
+	}
+	if got, want := LengthFromAngleAtAltitude(s1.Radian, 10*Kilometer), Radius+10*Kilometer; got != want {
+		t.Errorf("LengthFromAngleAtAltitude(1 radian, 10 km) = %v, want %v", got, want)
+	}
+}
+
+func TestLengthFromPoints(t *testing.T) {
+	tests := []struct {
+		a, b s2.LatLng
+		want Length
+	}{
+		{s2.LatLngFromDegrees(0, 0), s2.LatLngFromDegrees(0, 0), 0},
+		{s2.LatLngFromDegrees(0, 0), s2.LatLngFromDegrees(0, 90), Radius * math.Pi / 2},
+		{s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0), Radius * math.Pi},
+		{s2.LatLngFromDegrees(0, 0), s2.LatLngFromDegrees(0, 180), Radius * math.Pi},
+	}
+	for _, test := range tests {
+		if got := LengthFromLatLngs(test.a, test.b); !float64Near(got.Meters(), test.want.Meters(), 1e-6) {
+			t.Errorf("LengthFromLatLngs(%v, %v) = %v, want %v", test.a, test.b, got, test.want)
+		}
+		a, b := s2.PointFromLatLng(test.a), s2.PointFromLatLng(test.b)
+		if got := LengthFromPoints(a, b); !float64Near(got.Meters(), test.want.Meters(), 1e-6) {
+			t.Errorf("LengthFromPoints(%v, %v) = %v, want %v", a, b, got, test.want)
+		}
+	}
+}
+
+func TestLongitudeFromLength(t *testing.T) {
+	tests := []struct {
+		length Length
+		lat    s1.Angle
+		want   s1.Angle
+	}{
+		// At the equator, longitude and distance scale the same way.
+		{Radius, 0, s1.Radian},
+		{Radius, 60 * s1.Degree, 2 * s1.Radian},
+		// The result is capped at a full circle.
+		{10 * Radius, 0, 2 * math.Pi * s1.Radian},
+		{Meter, 90 * s1.Degree, 2 * math.Pi * s1.Radian},
+		{Meter, -90 * s1.Degree, 2 * math.Pi * s1.Radian},
+	}
+	for _, test := range tests {
+		if got := LongitudeFromLength(test.length, test.lat); !float64Near(got.Radians(), test.want.Radians(), 1e-14) {
+			t.Errorf("LongitudeFromLength(%v, %v) = %v, want %v", test.length, test.lat, got, test.want)
+		}
+	}
+}
+
+func TestAreaConversions(t *testing.T) {
+	// The area of the whole Earth.
+	got, want := AreaFromSteradians(4*math.Pi), Area(4*math.Pi*Radius*Radius)
+	if !float64Near(got.SquareMeters(), want.SquareMeters(), 1) {
+		t.Errorf("AreaFromSteradians(4π) = %v, want %v", got, want)
+	}
+	if got, want := AreaFromSteradians(1).SquareKilometers(), 6371.01*6371.01; !float64Near(got, want, 1e-6) {
+		t.Errorf("AreaFromSteradians(1) = %v km², want %v km²", got, want)
+	}
+	if got := SteradiansFromArea(AreaFromSteradians(0.25)); !float64Near(got, 0.25, 1e-15) {
+		t.Errorf("SteradiansFromArea(AreaFromSteradians(0.25)) = %v, want 0.25", got)
+	}
+
+	// A small area computed by the s2 package.
+	loop := s2.LoopFromPoints([]s2.Point{
+		s2.PointFromLatLng(s2.LatLngFromDegrees(0, 0)),
+		s2.PointFromLatLng(s2.LatLngFromDegrees(0, 0.01)),
+		s2.PointFromLatLng(s2.LatLngFromDegrees(0.01, 0.01)),
+		s2.PointFromLatLng(s2.LatLngFromDegrees(0.01, 0)),
+	})
+	side := LengthFromAngle(0.01 * s1.Degree)
+	if got, want := AreaFromSteradians(loop.Area()), Area(side*side); !float64Near(got.SquareMeters(), want.SquareMeters(), 1) {
+		t.Errorf("AreaFromSteradians(%v) = %v, want %v", loop.Area(), got, want)
+	}
+}
+
+func TestInitialBearingFromLatLngs(t *testing.T) {
+	tests := []struct {
+		desc string
+		a, b s2.LatLng
+		want s1.Angle
+	}{
+		{"Westward on equator", s2.LatLngFromDegrees(0, 50), s2.LatLngFromDegrees(0, 100), 90 * s1.Degree},
+		{"Eastward on equator", s2.LatLngFromDegrees(0, 50), s2.LatLngFromDegrees(0, 0), -90 * s1.Degree},
+		{"Northward on meridian", s2.LatLngFromDegrees(16, 28), s2.LatLngFromDegrees(81, 28), 0},
+		{"Southward on meridian", s2.LatLngFromDegrees(24, 64), s2.LatLngFromDegrees(-27, 64), 180 * s1.Degree},
+		{"Towards north pole", s2.LatLngFromDegrees(12, 76), s2.LatLngFromDegrees(90, 50), 0},
+		{"Towards south pole", s2.LatLngFromDegrees(-35, 105), s2.LatLngFromDegrees(-90, -120), 180 * s1.Degree},
+		{"Spain to Japan", s2.LatLngFromDegrees(40.4379332, -3.749576), s2.LatLngFromDegrees(35.6733227, 139.6403486), 29.2 * s1.Degree},
+		{"Japan to Spain", s2.LatLngFromDegrees(35.6733227, 139.6403486), s2.LatLngFromDegrees(40.4379332, -3.749576), -27.2 * s1.Degree},
+	}
+	for _, test := range tests {
+		if got := InitialBearingFromLatLngs(test.a, test.b); !float64Near(got.Degrees(), test.want.Degrees(), 0.01) {
+			t.Errorf("%s: InitialBearingFromLatLngs(%v, %v) = %v, want %v", test.desc, test.a, test.b, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,1384 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package earth
+
+import (
+	"math"
+
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+// This file implements the solution of the direct and inverse geodesic
+// problems on an ellipsoid of revolution, following
+//
+//	C. F. F. Karney, "Algorithms for geodesics", J. Geodesy 87, 43-55 (2013),
+//	https://doi.org/10.1007/s00190-012-0578-z
+//
+// and the C implementation in GeographicLib (https://geographiclib.sourceforge.io).
+// The series expansions are carried out to 6th order in the flattening,
+// which gives results accurate to round-off for |f| < 0.01, which includes
+// all of the terrestrial ellipsoids.
+
+const (
+	geodesicOrder = 6
+	nA1           = geodesicOrder
+	nC1           = geodesicOrder
+	nC1p          = geodesicOrder
+	nA2           = geodesicOrder
+	nC2           = geodesicOrder
+	nA3           = geodesicOrder
+	nA3x          = nA3
+	nC3           = geodesicOrder
+	nC3x          = (nC3 * (nC3 - 1)) / 2
+	nC4           = geodesicOrder
+	nC4x          = (nC4 * (nC4 + 1)) / 2
+	nC            = geodesicOrder + 1
+
+	// The maximum number of Newton iterations and of total iterations
+	// (including bisections) used when solving the inverse problem.
+	maxit1 = 20
+	maxit2 = maxit1 + 53 + 10
+
+	degree = math.Pi / 180
+	tol0   = 0x1p-52
+	tol1   = 200 * tol0
+)
+
+var (
+	tiny    = math.Sqrt(0x1p-1022)
+	tol2    = math.Sqrt(tol0)
+	tolb    = tol0 * tol2
+	xthresh = 1000 * tol2
+)
+
+// Geodesic represents an ellipsoid of revolution on which geodesic (shortest
+// path) problems are solved. Points on the ellipsoid are given as LatLngs
+// holding geodetic latitudes and longitudes.
+//
+// Note that the s2 package interprets a LatLng as a point on the unit
+// sphere, so the geodesic between two points is not the same path as the
+// s2 edge between them, and its length is not proportional to the s2
+// distance between them. For WGS84, the two differ by up to about 0.5%.
+type Geodesic struct {
+	a     float64 // equatorial radius in meters
+	f     float64 // flattening
+	f1    float64 // 1 - f
+	e2    float64 // eccentricity squared
+	ep2   float64 // second eccentricity squared
+	n     float64 // third flattening
+	b     float64 // polar semi-axis in meters
+	c2    float64 // authalic radius squared
+	etol2 float64
+
+	a3x [nA3x]float64
+	c3x [nC3x]float64
+	c4x [nC4x]float64
+}
+
+// WGS84 is the ellipsoid of the World Geodetic System 1984, which is the
+// ellipsoid used by GPS.
+var WGS84 = NewGeodesic(6378137*Meter, 1/298.257223563)
+
+// NewGeodesic returns a Geodesic for the ellipsoid with the given equatorial
+// radius and flattening. A flattening of 0 gives a sphere, and negative
+// flattenings give prolate ellipsoids. The flattening must be less than 1.
+func NewGeodesic(equatorialRadius Length, flattening float64) *Geodesic {
+	g := &Geodesic{
+		a: equatorialRadius.Meters(),
+		f: flattening,
+	}
+	g.f1 = 1 - g.f
+	g.e2 = g.f * (2 - g.f)
+	g.ep2 = g.e2 / (g.f1 * g.f1)
+	g.n = g.f / (2 - g.f)
+	g.b = g.a * g.f1
+
+	var t float64
+	switch {
+	case g.e2 == 0:
+		t = 1
+	case g.e2 > 0:
+		t = math.Atanh(math.Sqrt(g.e2)) / math.Sqrt(g.e2)
+	default:
+		t = math.Atan(math.Sqrt(-g.e2)) / math.Sqrt(-g.e2)
+	}
+	g.c2 = (g.a*g.a + g.b*g.b*t) / 2
+
+	// The sig12 threshold for "really short". Using the auxiliary sphere
+	// solution with dnm computed at (bet1 + bet2) / 2, the relative error in
+	// the azimuth consistency check is sig12^2 * abs(f) * min(1, 1-f/2) / 2.
+	g.etol2 = 0.1 * tol2 / math.Sqrt(math.Max(0.001, math.Abs(g.f))*math.Min(1, 1-g.f/2)/2)
+
+	g.initA3()
+	g.initC3()
+	g.initC4()
+	return g
+}
+
+// EquatorialRadius returns the equatorial radius of the ellipsoid.
+func (g *Geodesic) EquatorialRadius() Length { return Length(g.a) }
+
+// Flattening returns the flattening of the ellipsoid.
+func (g *Geodesic) Flattening() float64 { return g.f }
+
+// EllipsoidArea returns the total area of the ellipsoid.
+func (g *Geodesic) EllipsoidArea() Area { return Area(4 * math.Pi * g.c2) }
+
+// minRadius returns the smallest radius of curvature of the ellipsoid, in
+// meters. The geodesic distance between two points is at least this value
+// times the angle between them when they are interpreted as points on the
+// unit sphere, since every line element on the ellipsoid is at least this
+// many times longer than its image on the sphere.
+func (g *Geodesic) minRadius() float64 {
+	// The meridional radius of curvature at the equator, a(1-e²), and the
+	// radius of curvature at the poles, a/sqrt(1-e²).
+	return math.Min(g.a*(1-g.e2), g.a/math.Sqrt(1-g.e2))
+}
+
+// Inverse solves the inverse geodesic problem. It returns the length of the
+// shortest geodesic between a and b, and the azimuths of the geodesic at a
+// and at b, measured clockwise from north. The azimuths are in the range
+// [-180, 180] degrees.
+//
+// The inverse problem always has a solution, but if a and b are antipodal
+// or at the poles there may be more than one shortest geodesic, in which
+// case the azimuths of one of them are returned.
+func (g *Geodesic) Inverse(a, b s2.LatLng) (length Length, azimuth1, azimuth2 s1.Angle) {
+	r := g.inverse(a.Lat.Degrees(), a.Lng.Degrees(), b.Lat.Degrees(), b.Lng.Degrees(), false)
+	return Length(r.s12), s1.Angle(atan2d(r.salp1, r.calp1)) * s1.Degree,
+		s1.Angle(atan2d(r.salp2, r.calp2)) * s1.Degree
+}
+
+// Distance returns the length of the shortest geodesic between a and b.
+func (g *Geodesic) Distance(a, b s2.LatLng) Length {
+	return Length(g.inverse(a.Lat.Degrees(), a.Lng.Degrees(), b.Lat.Degrees(), b.Lng.Degrees(), false).s12)
+}
+
+// Direct solves the direct geodesic problem. It returns the point reached by
+// following the geodesic that starts at a with the given azimuth, measured
+// clockwise from north, for the given length, along with the azimuth of the
+// geodesic at that point. Negative lengths follow the geodesic backwards.
+//
+// The longitude of the result is in the range [-180, 180] degrees, and the
+// azimuth is in the range [-180, 180] degrees.
+func (g *Geodesic) Direct(a s2.LatLng, azimuth s1.Angle, length Length) (b s2.LatLng, azimuth2 s1.Angle) {
+	lat2, lon2, azi2 := g.direct(a.Lat.Degrees(), a.Lng.Degrees(), azimuth.Degrees(), length.Meters())
+	return s2.LatLng{Lat: s1.Angle(lat2) * s1.Degree, Lng: s1.Angle(lon2) * s1.Degree}, s1.Angle(azi2) * s1.Degree
+}
+
+// PolylineLength returns the length of the polyline, where each edge of the
+// polyline is taken to be the geodesic between its endpoints.
+func (g *Geodesic) PolylineLength(p *s2.Polyline) Length {
+	var sum accumulator
+	for i := 1; i < len(*p); i++ {
+		sum.add(g.Distance(s2.LatLngFromPoint((*p)[i-1]), s2.LatLngFromPoint((*p)[i])).Meters())
+	}
+	return Length(sum.s)
+}
+
+// LoopArea returns the area of the region on the ellipsoid bounded by the
+// loop, where each edge of the loop is taken to be the geodesic between its
+// endpoints. As with Loop.Area, the interior of the loop is the region to
+// the left of its edges, and the result is in the range [0, EllipsoidArea()).
+// The empty loop has area 0, and the full loop has area EllipsoidArea().
+func (g *Geodesic) LoopArea(l *s2.Loop) Area {
+	if l.IsFull() {
+		return g.EllipsoidArea()
+	}
+	n := l.NumVertices()
+	if l.IsEmpty() || n < 3 {
+		return 0
+	}
+
+	var sum accumulator
+	crossings := 0
+	prev := s2.LatLngFromPoint(l.Vertex(n - 1))
+	for i := 0; i < n; i++ {
+		cur := s2.LatLngFromPoint(l.Vertex(i))
+		lat1, lon1 := prev.Lat.Degrees(), prev.Lng.Degrees()
+		lat2, lon2 := cur.Lat.Degrees(), cur.Lng.Degrees()
+		sum.add(g.inverse(lat1, lon1, lat2, lon2, true).S12)
+		crossings += transit(lon1, lon2)
+		prev = cur
+	}
+	return Area(g.reduceArea(sum, crossings))
+}
+
+// PolygonArea returns the area of the polygon on the ellipsoid, where each
+// edge of the polygon is taken to be the geodesic between its endpoints.
+func (g *Geodesic) PolygonArea(p *s2.Polygon) Area {
+	var area Area
+	for _, l := range p.Loops() {
+		area += Area(l.Sign()) * g.LoopArea(l)
+	}
+	return area
+}
+
+// reduceArea converts the sum of the areas between the edges of a loop and
+// the equator, and the number of times that the edges cross the prime
+// meridian, into the area to the left of the loop in [0, EllipsoidArea()).
+func (g *Geodesic) reduceArea(sum accumulator, crossings int) float64 {
+	area0 := 4 * math.Pi * g.c2
+	sum.remainder(area0)
+	if crossings&1 != 0 {
+		if sum.s < 0 {
+			sum.add(area0 / 2)
+		} else {
+			sum.add(-area0 / 2)
+		}
+	}
+	// The sum is with the clockwise sense, so negate it to get the area to
+	// the left of the edges.
+	area := -sum.s
+	if area >= area0 {
+		area -= area0
+	} else if area < 0 {
+		area += area0
+	}
+	return 0 + area
+}
+
+// transit returns 1 or -1 if the geodesic from lon1 to lon2 crosses the prime
+// meridian in the east or west direction, and 0 otherwise.
+func transit(lon1, lon2 float64) int {
+	// Compute lon12 the same way as inverse.
+	lon12, _ := angDiff(lon1, lon2)
+	lon1 = angNormalize(lon1)
+	lon2 = angNormalize(lon2)
+	switch {
+	case lon12 > 0 && ((lon1 < 0 && lon2 >= 0) || (lon1 > 0 && lon2 == 0)):
+		return 1
+	case lon12 < 0 && lon1 >= 0 && lon2 < 0:
+		return -1
+	}
+	return 0
+}
+
+// inverseResult holds the solution of the inverse problem. The azimuths are
+// given by their sines and cosines, which need not be normalized.
+type inverseResult struct {
+	s12          float64
+	salp1, calp1 float64
+	salp2, calp2 float64
+	// S12 is the area between the geodesic and the equator, and is only
+	// computed if requested.
+	S12 float64
+}
+
+func (g *Geodesic) inverse(lat1, lon1, lat2, lon2 float64, wantArea bool) inverseResult {
+	var (
+		s12x, m12x                 float64
+		sig12                      float64
+		salp1, calp1, salp2, calp2 float64
+		ca                         [nC]float64
+		// somg12 == 2 marks that it needs to be calculated.
+		omg12, somg12, comg12 = 0.0, 2.0, 0.0
+	)
+
+	// Compute the longitude difference carefully. The result is in
+	// [-180, 180], but -180 is only for west-going geodesics.
+	lon12, lon12s := angDiff(lon1, lon2)
+	// Make the longitude difference positive.
+	lonsign := 1.0
+	if math.Signbit(lon12) {
+		lonsign = -1
+	}
+	// If very close to being on the same half-meridian, then make it so.
+	lon12 = angRound(lon12 * lonsign)
+	lon12s = angRound((180 - lon12) - lonsign*lon12s)
+	lam12 := lon12 * degree
+	var slam12, clam12 float64
+	if lon12 > 90 {
+		slam12, clam12 = sincosd(lon12s)
+		clam12 = -clam12
+	} else {
+		slam12, clam12 = sincosd(lon12)
+	}
+
+	// If really close to the equator, treat as on the equator.
+	lat1 = angRound(latFix(lat1))
+	lat2 = angRound(latFix(lat2))
+	// Swap the points so that the point with the higher (absolute) latitude
+	// is point 1. If one latitude is a NaN, then it becomes lat1.
+	swapp := 1.0
+	if math.Abs(lat1) < math.Abs(lat2) || math.IsNaN(lat2) {
+		swapp = -1
+		lonsign = -lonsign
+		lat1, lat2 = lat2, lat1
+	}
+	// Make lat1 <= -0.
+	latsign := -1.0
+	if math.Signbit(lat1) {
+		latsign = 1
+	}
+	lat1 *= latsign
+	lat2 *= latsign
+	// Now we have
+	//
+	//	0 <= lon12 <= 180
+	//	-90 <= lat1 <= -0
+	//	lat1 <= lat2 <= -lat1
+	//
+	// lonsign, swapp and latsign record the transformation that brings the
+	// coordinates to this canonical form. These transformations leave few
+	// cases to check, and enforce some symmetries in the results.
+
+	sbet1, cbet1 := sincosd(lat1)
+	sbet1 *= g.f1
+	// Ensure cbet1 = +epsilon at poles.
+	sbet1, cbet1 = norm2(sbet1, cbet1)
+	cbet1 = math.Max(tiny, cbet1)
+
+	sbet2, cbet2 := sincosd(lat2)
+	sbet2 *= g.f1
+	// Ensure cbet2 = +epsilon at poles.
+	sbet2, cbet2 = norm2(sbet2, cbet2)
+	cbet2 = math.Max(tiny, cbet2)
+
+	// If cbet1 < -sbet1, then cbet2 - cbet1 is a sensitive measure of
+	// |bet1| - |bet2|. Otherwise |sbet2| + sbet1 is a better measure. This
+	// logic is used in assigning calp2 in lambda12. Sometimes these
+	// quantities vanish, and in that case we force bet2 = +/- bet1 exactly.
+	if cbet1 < -sbet1 {
+		if cbet2 == cbet1 {
+			sbet2 = math.Copysign(sbet1, sbet2)
+		}
+	} else if math.Abs(sbet2) == -sbet1 {
+		cbet2 = cbet1
+	}
+
+	dn1 := math.Sqrt(1 + g.ep2*sbet1*sbet1)
+	dn2 := math.Sqrt(1 + g.ep2*sbet2*sbet2)
+
+	meridian := lat1 == -90 || slam12 == 0
+	if meridian {
+		// The endpoints are on a single full meridian, so the geodesic
+		// might lie on a meridian.
+		calp1, salp1 = clam12, slam12 // Head to the target longitude.
+		calp2, salp2 = 1, 0           // At the target we're heading north.
+
+		// tan(bet) = tan(sig) * cos(alp)
+		ssig1, csig1 := sbet1, calp1*cbet1
+		ssig2, csig2 := sbet2, calp2*cbet2
+
+		// sig12 = sig2 - sig1
+		sig12 = math.Atan2(math.Max(0, csig1*ssig2-ssig1*csig2), csig1*csig2+ssig1*ssig2)
+		s12x, m12x, _ = lengths(g.n, sig12, ssig1, csig1, dn1, ssig2, csig2, dn2)
+
+		// Check sig12 too, since zero length geodesics might yield m12 < 0.
+		// Also sig12 > pi/2 for a meridional geodesic that is not a
+		// shortest path.
+		if sig12 < 1 || m12x >= 0 {
+			// Need at least 2, to handle 90 0 90 180.
+			if sig12 < 3*tiny || (sig12 < tol0 && (s12x < 0 || m12x < 0)) {
+				sig12, m12x, s12x = 0, 0, 0
+			}
+			m12x *= g.b
+			s12x *= g.b
+		} else {
+			// m12 < 0, i.e., prolate and too close to anti-podal.
+			meridian = false
+		}
+	}
+
+	if !meridian && sbet1 == 0 && (g.f <= 0 || lon12s >= g.f*180) {
+		// The geodesic runs along the equator (and sbet2 == 0). This mimics
+		// the way lambda12 works with calp1 = 0.
+		calp1, calp2 = 0, 0
+		salp1, salp2 = 1, 1
+		s12x = g.a * lam12
+		sig12 = lam12 / g.f1
+		omg12 = sig12
+		m12x = g.b * math.Sin(sig12)
+	} else if !meridian {
+		// The points lie within a hemisphere bounded by a meridian, and the
+		// geodesic is neither meridional nor equatorial.
+
+		// Find a starting point for Newton's method.
+		var dnm float64
+		sig12, salp1, calp1, salp2, calp2, dnm = g.inverseStart(sbet1, cbet1, dn1, sbet2, cbet2, dn2, lam12, slam12, clam12)
+
+		if sig12 >= 0 {
+			// A short line; inverseStart set salp2, calp2 and dnm.
+			s12x = sig12 * g.b * dnm
+			m12x = dnm * dnm * g.b * math.Sin(sig12/dnm)
+			omg12 = lam12 / (g.f1 * dnm)
+		} else {
+			// Newton's method. This is a straightforward solution of
+			// f(alp1) = lambda12(alp1) - lam12 = 0 with one wrinkle. f(alp)
+			// has exactly one root in the interval (0, pi) and its
+			// derivative is positive at the root. Thus f(alp) is positive
+			// for alp > alp1 and negative for alp < alp1. During the course
+			// of the iteration, a range (alp1a, alp1b) is maintained which
+			// brackets the root, and each evaluation of f(alp) shrinks the
+			// range if possible. Newton's method is restarted whenever the
+			// derivative of f is negative (because the new value of alp1 is
+			// then further from the solution) or if the new estimate of
+			// alp1 lies outside (0, pi); in this case, the new starting
+			// guess is taken to be (alp1a + alp1b) / 2.
+			var l lambda12Result
+			// The bracketing range.
+			salp1a, calp1a, salp1b, calp1b := tiny, 1.0, tiny, -1.0
+			tripn, tripb := false, false
+			for numit := 0; ; numit++ {
+				l = g.lambda12(sbet1, cbet1, dn1, sbet2, cbet2, dn2, salp1, calp1, slam12, clam12, numit < maxit1)
+				v := l.lam12
+				// The reversed test allows escape with NaNs.
+				tol := tol0
+				if tripn {
+					tol *= 8
+				}
+				if tripb || !(math.Abs(v) >= tol) || numit == maxit2 {
+					break
+				}
+				// Update the bracketing values.
+				if v > 0 && (numit > maxit1 || calp1/salp1 > calp1b/salp1b) {
+					salp1b, calp1b = salp1, calp1
+				} else if v < 0 && (numit > maxit1 || calp1/salp1 < calp1a/salp1a) {
+					salp1a, calp1a = salp1, calp1
+				}
+				if dv := l.dlam12; numit < maxit1 && dv > 0 {
+					if dalp1 := -v / dv; math.Abs(dalp1) < math.Pi {
+						sdalp1, cdalp1 := math.Sincos(dalp1)
+						if nsalp1 := salp1*cdalp1 + calp1*sdalp1; nsalp1 > 0 {
+							calp1 = calp1*cdalp1 - salp1*sdalp1
+							salp1 = nsalp1
+							salp1, calp1 = norm2(salp1, calp1)
+							// In some regimes we don't get quadratic
+							// convergence because the slope tends to 0, so
+							// use a convergence condition based on epsilon
+							// instead of sqrt(epsilon).
+							tripn = math.Abs(v) <= 16*tol0
+							continue
+						}
+					}
+				}
+				// Either dv was not positive or the updated value was
+				// outside the legal range. Use the midpoint of the bracket
+				// as the next estimate. This is not needed for the WGS84
+				// ellipsoid, but it catches problems with more eccentric
+				// ellipsoids.
+				salp1 = (salp1a + salp1b) / 2
+				calp1 = (calp1a + calp1b) / 2
+				salp1, calp1 = norm2(salp1, calp1)
+				tripn = false
+				tripb = math.Abs(salp1a-salp1)+(calp1a-calp1) < tolb ||
+					math.Abs(salp1-salp1b)+(calp1-calp1b) < tolb
+			}
+			salp2, calp2, sig12 = l.salp2, l.calp2, l.sig12
+			s12x, m12x, _ = lengths(l.eps, sig12, l.ssig1, l.csig1, dn1, l.ssig2, l.csig2, dn2)
+			m12x *= g.b
+			s12x *= g.b
+			if wantArea {
+				// omg12 = lam12 - domg12
+				sdomg12, cdomg12 := math.Sincos(l.domg12)
+				somg12 = slam12*cdomg12 - clam12*sdomg12
+				comg12 = clam12*cdomg12 + slam12*sdomg12
+			}
+		}
+	}
+
+	var r inverseResult
+	r.s12 = 0 + s12x // Convert -0 to 0.
+
+	if wantArea {
+		// From lambda12: sin(alp1) * cos(bet1) = sin(alp0)
+		salp0 := salp1 * cbet1
+		calp0 := math.Hypot(calp1, salp1*sbet1) // calp0 > 0
+		var S12 float64
+		if calp0 != 0 && salp0 != 0 {
+			// From lambda12: tan(bet) = tan(sig) * cos(alp)
+			ssig1, csig1 := norm2(sbet1, calp1*cbet1)
+			ssig2, csig2 := norm2(sbet2, calp2*cbet2)
+			k2 := calp0 * calp0 * g.ep2
+			eps := k2 / (2*(1+math.Sqrt(1+k2)) + k2)
+			// Multiplier = a^2 * e^2 * cos(alpha0) * sin(alpha0).
+			a4 := g.a * g.a * calp0 * salp0 * g.e2
+			g.c4f(eps, ca[:])
+			b41 := sinCosSeries(false, ssig1, csig1, ca[:], nC4)
+			b42 := sinCosSeries(false, ssig2, csig2, ca[:], nC4)
+			S12 = a4 * (b42 - b41)
+		}
+		// Otherwise avoid problems with indeterminate sig1, sig2 on the
+		// equator.
+
+		if !meridian && somg12 == 2 {
+			somg12, comg12 = math.Sincos(omg12)
+		}
+
+		var alp12 float64
+		if !meridian && comg12 > -0.7071 && sbet2-sbet1 < 1.75 {
+			// The longitude and latitude differences are not too big, so
+			// use tan(Gamma/2) = tan(omg12/2) *
+			// (tan(bet1/2)+tan(bet2/2))/(1+tan(bet1/2)*tan(bet2/2)) with
+			// tan(x/2) = sin(x)/(1+cos(x)).
+			domg12, dbet1, dbet2 := 1+comg12, 1+cbet1, 1+cbet2
+			alp12 = 2 * math.Atan2(somg12*(sbet1*dbet2+sbet2*dbet1), domg12*(sbet1*sbet2+dbet1*dbet2))
+		} else {
+			// alp12 = alp2 - alp1, used in atan2 so no need to normalize.
+			salp12 := salp2*calp1 - calp2*salp1
+			calp12 := calp2*calp1 + salp2*salp1
+			// If alp1 = +/-180 and alp2 = 0, this ensures that salp12 = -0
+			// and alp12 = -180.
+			if salp12 == 0 && calp12 < 0 {
+				salp12 = tiny * calp1
+				calp12 = -1
+			}
+			alp12 = math.Atan2(salp12, calp12)
+		}
+		S12 += g.c2 * alp12
+		S12 *= swapp * lonsign * latsign
+		r.S12 = 0 + S12 // Convert -0 to 0.
+	}
+
+	// Convert calp, salp to azimuths accounting for lonsign, swapp and
+	// latsign.
+	if swapp < 0 {
+		salp1, salp2 = salp2, salp1
+		calp1, calp2 = calp2, calp1
+	}
+	r.salp1, r.calp1 = salp1*swapp*lonsign, calp1*swapp*latsign
+	r.salp2, r.calp2 = salp2*swapp*lonsign, calp2*swapp*latsign
+	return r
+}
+
+// inverseStart returns a starting point for Newton's method in salp1 and
+// calp1. If the points are close enough that the solution on the auxiliary
+// sphere suffices, it also returns sig12 >= 0, along with salp2, calp2 and
+// dnm. Otherwise sig12 is negative.
+func (g *Geodesic) inverseStart(sbet1, cbet1, dn1, sbet2, cbet2, dn2, lam12, slam12, clam12 float64) (sig12, salp1, calp1, salp2, calp2, dnm float64) {
+	sig12 = -1
+	sbet12 := sbet2*cbet1 - cbet2*sbet1
+	cbet12 := cbet2*cbet1 + sbet2*sbet1
+	sbet12a := sbet2*cbet1 + cbet2*sbet1
+	shortline := cbet12 >= 0 && sbet12 < 0.5 && cbet2*lam12 < 0.5
+	var somg12, comg12 float64
+	if shortline {
+		sbetm2 := (sbet1 + sbet2) * (sbet1 + sbet2)
+		sbetm2 /= sbetm2 + (cbet1+cbet2)*(cbet1+cbet2)
+		dnm = math.Sqrt(1 + g.ep2*sbetm2)
+		omg12 := lam12 / (g.f1 * dnm)
+		somg12, comg12 = math.Sincos(omg12)
+	} else {
+		somg12, comg12 = slam12, clam12
+	}
+
+	salp1 = cbet2 * somg12
+	if comg12 >= 0 {
+		calp1 = sbet12 + cbet2*sbet1*somg12*somg12/(1+comg12)
+	} else {
+		calp1 = sbet12a - cbet2*sbet1*somg12*somg12/(1-comg12)
+	}
+
+	ssig12 := math.Hypot(salp1, calp1)
+	csig12 := sbet1*sbet2 + cbet1*cbet2*comg12
+
+	switch {
+	case shortline && ssig12 < g.etol2:
+		// Really short lines.
+		salp2 = cbet1 * somg12
+		if comg12 >= 0 {
+			calp2 = sbet12 - cbet1*sbet2*(somg12*somg12/(1+comg12))
+		} else {
+			calp2 = sbet12 - cbet1*sbet2*(1-comg12)
+		}
+		salp2, calp2 = norm2(salp2, calp2)
+		// Set the return value.
+		sig12 = math.Atan2(ssig12, csig12)
+	case math.Abs(g.n) > 0.1 || // No astroid calculation if too eccentric.
+		csig12 >= 0 ||
+		ssig12 >= 6*math.Abs(g.n)*math.Pi*cbet1*cbet1:
+		// Nothing to do, the zeroth order spherical approximation is OK.
+	default:
+		// Scale lam12 and bet2 to the x, y coordinate system where the
+		// antipodal point is at the origin and the singular point is at
+		// y = 0, x = -1.
+		var x, y, lamscale, betscale float64
+		lam12x := math.Atan2(-slam12, -clam12) // lam12 - pi
+		if g.f >= 0 {
+			// x = dlong, y = dlat
+			k2 := sbet1 * sbet1 * g.ep2
+			eps := k2 / (2*(1+math.Sqrt(1+k2)) + k2)
+			lamscale = g.f * cbet1 * g.a3f(eps) * math.Pi
+			betscale = lamscale * cbet1
+			x = lam12x / lamscale
+			y = sbet12a / betscale
+		} else {
+			// x = dlat, y = dlong
+			cbet12a := cbet2*cbet1 - sbet2*sbet1
+			bet12a := math.Atan2(sbet12a, cbet12a)
+			// In the case of lon12 = 180, this repeats a calculation made
+			// in inverse.
+			_, m12b, m0 := lengths(g.n, math.Pi+bet12a, sbet1, -cbet1, dn1, sbet2, cbet2, dn2)
+			x = -1 + m12b/(cbet1*cbet2*m0*math.Pi)
+			if x < -0.01 {
+				betscale = sbet12a / x
+			} else {
+				betscale = -g.f * cbet1 * cbet1 * math.Pi
+			}
+			lamscale = betscale / cbet1
+			y = lam12x / lamscale
+		}
+
+		if y > -tol1 && x > -1-xthresh {
+			// Strip near cut.
+			if g.f >= 0 {
+				salp1 = math.Min(1, -x)
+				calp1 = -math.Sqrt(1 - salp1*salp1)
+			} else {
+				lo := -1.0
+				if x > -tol1 {
+					lo = 0
+				}
+				calp1 = math.Max(lo, x)
+				salp1 = math.Sqrt(1 - calp1*calp1)
+			}
+		} else {
+			// Estimate alp1 by solving the astroid problem.
+			k := astroid(x, y)
+			var omg12a float64
+			if g.f >= 0 {
+				omg12a = lamscale * (-x * k / (1 + k))
+			} else {
+				omg12a = lamscale * (-y * (1 + k) / k)
+			}
+			somg12, comg12 = math.Sincos(omg12a)
+			comg12 = -comg12
+			// Update the spherical estimate of alp1 using omg12 instead of
+			// lam12.
+			salp1 = cbet2 * somg12
+			calp1 = sbet12a - cbet2*sbet1*somg12*somg12/(1-comg12)
+		}
+	}
+	// Sanity check on the starting guess. The backwards check allows NaN
+	// through.
+	if !(salp1 <= 0) {
+		salp1, calp1 = norm2(salp1, calp1)
+	} else {
+		salp1, calp1 = 1, 0
+	}
+	return sig12, salp1, calp1, salp2, calp2, dnm
+}
+
+// lambda12Result holds the outputs of lambda12.
+type lambda12Result struct {
+	lam12        float64
+	salp2, calp2 float64
+	sig12        float64
+	ssig1, csig1 float64
+	ssig2, csig2 float64
+	eps          float64
+	domg12       float64
+	dlam12       float64
+}
+
+// lambda12 returns the difference between the longitude difference of the
+// geodesic leaving point 1 at azimuth alp1 and the target longitude
+// difference lam120, along with the derivative with respect to alp1 if
+// diffp is true.
+func (g *Geodesic) lambda12(sbet1, cbet1, dn1, sbet2, cbet2, dn2, salp1, calp1, slam120, clam120 float64, diffp bool) lambda12Result {
+	var r lambda12Result
+	if sbet1 == 0 && calp1 == 0 {
+		// Break the degeneracy of the equatorial line. This case has
+		// already been handled.
+		calp1 = -tiny
+	}
+
+	// sin(alp1) * cos(bet1) = sin(alp0)
+	salp0 := salp1 * cbet1
+	calp0 := math.Hypot(calp1, salp1*sbet1) // calp0 > 0
+
+	// tan(bet1) = tan(sig1) * cos(alp1)
+	// tan(omg1) = sin(alp0) * tan(sig1) = tan(omg1)=tan(alp1)*sin(bet1)
+	ssig1, somg1 := sbet1, salp0*sbet1
+	csig1 := calp1 * cbet1
+	comg1 := csig1
+	ssig1, csig1 = norm2(ssig1, csig1)
+	// somg1, comg1 don't need to be normalized.
+
+	// Enforce symmetries in the case |bet2| = -bet1. This case needs care,
+	// since it can yield singularities in the Newton iteration.
+	// sin(alp2) * cos(bet2) = sin(alp0)
+	salp2 := salp1
+	if cbet2 != cbet1 {
+		salp2 = salp0 / cbet2
+	}
+	// calp2 = sqrt(1 - sq(salp2))
+	//       = sqrt(sq(calp0) - sq(sbet2)) / cbet2
+	// and substitute for calp0 and rearrange to give (choosing the positive
+	// sqrt to give alp2 in [0, pi/2]).
+	var calp2 float64
+	if cbet2 != cbet1 || math.Abs(sbet2) != -sbet1 {
+		var t float64
+		if cbet1 < -sbet1 {
+			t = (cbet2 - cbet1) * (cbet1 + cbet2)
+		} else {
+			t = (sbet1 - sbet2) * (sbet1 + sbet2)
+		}
+		calp2 = math.Sqrt((calp1*cbet1)*(calp1*cbet1)+t) / cbet2
+	} else {
+		calp2 = math.Abs(calp1)
+	}
+	// tan(bet2) = tan(sig2) * cos(alp2)
+	// tan(omg2) = sin(alp0) * tan(sig2).
+	ssig2, somg2 := sbet2, salp0*sbet2
+	csig2 := calp2 * cbet2
+	comg2 := csig2
+	ssig2, csig2 = norm2(ssig2, csig2)
+	// somg2, comg2 don't need to be normalized.
+
+	// sig12 = sig2 - sig1, limited to [0, pi]
+	sig12 := math.Atan2(math.Max(0, csig1*ssig2-ssig1*csig2), csig1*csig2+ssig1*ssig2)
+
+	// omg12 = omg2 - omg1, limited to [0, pi]
+	somg12 := math.Max(0, comg1*somg2-somg1*comg2)
+	comg12 := comg1*comg2 + somg1*somg2
+	// eta = omg12 - lam120
+	eta := math.Atan2(somg12*clam120-comg12*slam120, comg12*clam120+somg12*slam120)
+	k2 := calp0 * calp0 * g.ep2
+	eps := k2 / (2*(1+math.Sqrt(1+k2)) + k2)
+	var ca [nC]float64
+	g.c3f(eps, ca[:])
+	b312 := sinCosSeries(true, ssig2, csig2, ca[:], nC3-1) - sinCosSeries(true, ssig1, csig1, ca[:], nC3-1)
+	domg12 := -g.f * g.a3f(eps) * salp0 * (sig12 + b312)
+
+	r.lam12 = eta + domg12
+	if diffp {
+		if calp2 == 0 {
+			r.dlam12 = -2 * g.f1 * dn1 / sbet1
+		} else {
+			_, m12b, _ := lengths(eps, sig12, ssig1, csig1, dn1, ssig2, csig2, dn2)
+			r.dlam12 = m12b * g.f1 / (calp2 * cbet2)
+		}
+	}
+	r.salp2, r.calp2 = salp2, calp2
+	r.sig12 = sig12
+	r.ssig1, r.csig1 = ssig1, csig1
+	r.ssig2, r.csig2 = ssig2, csig2
+	r.eps = eps
+	r.domg12 = domg12
+	return r
+}
+
+// lengths returns the distance s12b and reduced length m12b of the
+// geodesic with the given parameters, both divided by b, along with m0.
+func lengths(eps, sig12, ssig1, csig1, dn1, ssig2, csig2, dn2 float64) (s12b, m12b, m0 float64) {
+	var ca, cb [nC]float64
+	a1 := a1m1f(eps)
+	c1f(eps, ca[:])
+	a2 := a2m1f(eps)
+	c2f(eps, cb[:])
+	m0 = a1 - a2
+	a1++
+	a2++
+
+	b1 := sinCosSeries(true, ssig2, csig2, ca[:], nC1) - sinCosSeries(true, ssig1, csig1, ca[:], nC1)
+	s12b = a1 * (sig12 + b1)
+	b2 := sinCosSeries(true, ssig2, csig2, cb[:], nC2) - sinCosSeries(true, ssig1, csig1, cb[:], nC2)
+	j12 := m0*sig12 + (a1*b1 - a2*b2)
+	// The parentheses around (csig1 * ssig2) and (ssig1 * csig2) ensure
+	// accurate cancellation in the case of coincident points.
+	m12b = dn2*(csig1*ssig2) - dn1*(ssig1*csig2) - csig1*csig2*j12
+	return s12b, m12b, m0
+}
+
+// direct solves the direct problem, with all angles in degrees.
+func (g *Geodesic) direct(lat1, lon1, azi1, s12 float64) (lat2, lon2, azi2 float64) {
+	azi1 = angNormalize(azi1)
+	// Guard against underflow in salp0.
+	salp1, calp1 := sincosd(angRound(azi1))
+
+	sbet1, cbet1 := sincosd(angRound(latFix(lat1)))
+	sbet1 *= g.f1
+	// Ensure cbet1 = +epsilon at poles.
+	sbet1, cbet1 = norm2(sbet1, cbet1)
+	cbet1 = math.Max(tiny, cbet1)
+
+	// Evaluate alp0 from sin(alp1) * cos(bet1) = sin(alp0).
+	salp0 := salp1 * cbet1 // alp0 in [0, pi/2 - |bet1|]
+	// Alternatively calp0 = hypot(sbet1, calp1 * cbet1). This is slightly
+	// better (consider the case salp1 = 0).
+	calp0 := math.Hypot(calp1, salp1*sbet1)
+	// Evaluate sig with tan(bet1) = tan(sig1) * cos(alp1), where sig = 0 is
+	// the nearest northward crossing of the equator, and evaluate omg1 with
+	// tan(omg1) = sin(alp0) * tan(sig1). With alp0 in (0, pi/2], the
+	// quadrants for sig and omg coincide. There is no atan2(0, 0) ambiguity
+	// at the poles since cbet1 = +epsilon.
+	ssig1, somg1 := sbet1, salp0*sbet1
+	csig1 := 1.0
+	if sbet1 != 0 || calp1 != 0 {
+		csig1 = cbet1 * calp1
+	}
+	comg1 := csig1
+	ssig1, csig1 = norm2(ssig1, csig1) // sig1 in (-pi, pi]
+	// somg1, comg1 don't need to be normalized.
+
+	k2 := calp0 * calp0 * g.ep2
+	eps := k2 / (2*(1+math.Sqrt(1+k2)) + k2)
+
+	var c1a, c1pa, c3a [nC]float64
+	a1m1 := a1m1f(eps)
+	c1f(eps, c1a[:])
+	b11 := sinCosSeries(true, ssig1, csig1, c1a[:], nC1)
+	s, c := math.Sincos(b11)
+	// tau1 = sig1 + B11
+	stau1 := ssig1*c + csig1*s
+	ctau1 := csig1*c - ssig1*s
+	c1pf(eps, c1pa[:])
+	g.c3f(eps, c3a[:])
+	a3c := -g.f * salp0 * g.a3f(eps)
+	b31 := sinCosSeries(true, ssig1, csig1, c3a[:], nC3-1)
+
+	tau12 := s12 / (g.b * (1 + a1m1))
+	s, c = math.Sincos(tau12)
+	// tau2 = tau1 + tau12
+	b12 := -sinCosSeries(true, stau1*c+ctau1*s, ctau1*c-stau1*s, c1pa[:], nC1p)
+	sig12 := tau12 - (b12 - b11)
+	ssig12, csig12 := math.Sincos(sig12)
+	if math.Abs(g.f) > 0.01 {
+		// The reverted distance series is inaccurate for |f| > 1/100, so
+		// correct sig12 with 1 Newton iteration.
+		ssig2 := ssig1*csig12 + csig1*ssig12
+		csig2 := csig1*csig12 - ssig1*ssig12
+		b12 = sinCosSeries(true, ssig2, csig2, c1a[:], nC1)
+		serr := (1+a1m1)*(sig12+(b12-b11)) - s12/g.b
+		sig12 = sig12 - serr/math.Sqrt(1+k2*ssig2*ssig2)
+		ssig12, csig12 = math.Sincos(sig12)
+	}
+
+	// sig2 = sig1 + sig12
+	ssig2 := ssig1*csig12 + csig1*ssig12
+	csig2 := csig1*csig12 - ssig1*ssig12
+	// sin(bet2) = cos(alp0) * sin(sig2)
+	sbet2 := calp0 * ssig2
+	// Alternatively cbet2 = hypot(csig2, salp0 * ssig2).
+	cbet2 := math.Hypot(salp0, calp0*csig2)
+	if cbet2 == 0 {
+		// I.e., salp0 = 0 and csig2 = 0. Break the degeneracy in this case.
+		cbet2 = tiny
+		csig2 = tiny
+	}
+	// tan(alp0) = cos(sig2) * tan(alp2)
+	salp2, calp2 := salp0, calp0*csig2 // No need to normalize.
+
+	// tan(omg2) = sin(alp0) * tan(sig2)
+	somg2, comg2 := salp0*ssig2, csig2 // No need to normalize.
+	// omg12 = omg2 - omg1
+	omg12 := math.Atan2(somg2*comg1-comg2*somg1, comg2*comg1+somg2*somg1)
+	lam12 := omg12 + a3c*(sig12+(sinCosSeries(true, ssig2, csig2, c3a[:], nC3-1)-b31))
+	lon12 := lam12 / degree
+	lon2 = angNormalize(angNormalize(lon1) + angNormalize(lon12))
+	lat2 = atan2d(sbet2, g.f1*cbet2)
+	azi2 = atan2d(salp2, calp2)
+	return lat2, lon2, azi2
+}
+
+// astroid solves k^4+2*k^3-(x^2+y^2-1)*k^2-2*y^2*k-y^2 = 0 for the positive
+// root k. This solution is adapted from Geocentric::Reverse.
+func astroid(x, y float64) float64 {
+	p := x * x
+	q := y * y
+	r := (p + q - 1) / 6
+	if q == 0 && r <= 0 {
+		// y = 0 with |x| <= 1. In this case k is undetermined and the
+		// caller handles it separately.
+		return 0
+	}
+	// Avoid possible division by zero when r = 0 by multiplying the
+	// equations for s and t by r^3 and r, respectively.
+	S := p * q / 4 // S = r^3 * s
+	r2 := r * r
+	r3 := r * r2
+	// The discriminant of the quadratic equation for T3. This is zero on
+	// the evolute curve p^(1/3)+q^(1/3) = 1.
+	disc := S * (S + 2*r3)
+	u := r
+	if disc >= 0 {
+		T3 := S + r3
+		// Pick the sign on the sqrt to maximize |T3|. This minimizes the
+		// loss of precision due to cancellation. The result is unchanged
+		// because of the way the T is used in the definition of u.
+		if T3 < 0 {
+			T3 -= math.Sqrt(disc)
+		} else {
+			T3 += math.Sqrt(disc)
+		}
+		// N.B. cbrt always returns the real root. cbrt(-8) = -2.
+		T := math.Cbrt(T3) // T = r * t
+		// T can be zero, but then r2 / T -> 0.
+		u += T
+		if T != 0 {
+			u += r2 / T
+		}
+	} else {
+		// T is complex, but the way u is defined the result is real.
+		ang := math.Atan2(math.Sqrt(-disc), -(S + r3))
+		// There are three possible cube roots. We choose the root which
+		// avoids cancellation. Note that disc < 0 implies that r < 0.
+		u += 2 * r * math.Cos(ang/3)
+	}
+	v := math.Sqrt(u*u + q) // guaranteed positive
+	// Avoid loss of accuracy when u < 0.
+	var uv float64
+	if u < 0 {
+		uv = q / (v - u)
+	} else {
+		uv = u + v // u+v, guaranteed positive
+	}
+	w := (uv - q) / (2 * v) // positive?
+	// Rearrange the expression for k to avoid loss of accuracy due to
+	// subtraction. The division by 0 is not possible because uv > 0, w >= 0.
+	return uv / (math.Sqrt(uv+w*w) + w) // guaranteed positive
+}
+
+// a1m1f returns the scale factor A1-1 = mean value of (d/dsigma)I1 - 1.
+func a1m1f(eps float64) float64 {
+	// (1-eps)*A1-1, polynomial in eps2 of order 3
+	coeff := [...]float64{1, 4, 64, 0, 256}
+	const m = nA1 / 2
+	t := polyval(m, coeff[:], eps*eps) / coeff[m+1]
+	return (t + eps) / (1 - eps)
+}
+
+// c1f sets c[1:nC1+1] to the coefficients C1[l] in the Fourier expansion of B1.
+func c1f(eps float64, c []float64) {
+	coeff := [...]float64{
+		// C1[1]/eps^1, polynomial in eps2 of order 2
+		-1, 6, -16, 32,
+		// C1[2]/eps^2, polynomial in eps2 of order 2
+		-9, 64, -128, 2048,
+		// C1[3]/eps^3, polynomial in eps2 of order 1
+		9, -16, 768,
+		// C1[4]/eps^4, polynomial in eps2 of order 1
+		3, -5, 512,
+		// C1[5]/eps^5, polynomial in eps2 of order 0
+		-7, 1280,
+		// C1[6]/eps^6, polynomial in eps2 of order 0
+		-7, 2048,
+	}
+	eps2 := eps * eps
+	d := eps
+	o := 0
+	for l := 1; l <= nC1; l++ {
+		m := (nC1 - l) / 2 // order of polynomial in eps^2
+		c[l] = d * polyval(m, coeff[o:], eps2) / coeff[o+m+1]
+		o += m + 2
+		d *= eps
+	}
+}
+
+// c1pf sets c[1:nC1p+1] to the coefficients C1p[l] in the Fourier expansion
+// of B1p.
+func c1pf(eps float64, c []float64) {
+	coeff := [...]float64{
+		// C1p[1]/eps^1, polynomial in eps2 of order 2
+		205, -432, 768, 1536,
+		// C1p[2]/eps^2, polynomial in eps2 of order 2
+		4005, -4736, 3840, 12288,
+		// C1p[3]/eps^3, polynomial in eps2 of order 1
+		-225, 116, 384,
+		// C1p[4]/eps^4, polynomial in eps2 of order 1
+		-7173, 2695, 7680,
+		// C1p[5]/eps^5, polynomial in eps2 of order 0
+		3467, 7680,
+		// C1p[6]/eps^6, polynomial in eps2 of order 0
+		38081, 61440,
+	}
+	eps2 := eps * eps
+	d := eps
+	o := 0
+	for l := 1; l <= nC1p; l++ {
+		m := (nC1p - l) / 2 // order of polynomial in eps^2
+		c[l] = d * polyval(m, coeff[o:], eps2) / coeff[o+m+1]
+		o += m + 2
+		d *= eps
+	}
+}
+
+// a2m1f returns the scale factor A2-1 = mean value of (d/dsigma)I2 - 1.
+func a2m1f(eps float64) float64 {
+	// (eps+1)*A2-1, polynomial in eps2 of order 3
+	coeff := [...]float64{-11, -28, -192, 0, 256}
+	const m = nA2 / 2
+	t := polyval(m, coeff[:], eps*eps) / coeff[m+1]
+	return (t - eps) / (1 + eps)
+}
+
+// c2f sets c[1:nC2+1] to the coefficients C2[l] in the Fourier expansion of B2.
+func c2f(eps float64, c []float64) {
+	coeff := [...]float64{
+		// C2[1]/eps^1, polynomial in eps2 of order 2
+		1, 2, 16, 32,
+		// C2[2]/eps^2, polynomial in eps2 of order 2
+		35, 64, 384, 2048,
+		// C2[3]/eps^3, polynomial in eps2 of order 1
+		15, 80, 768,
+		// C2[4]/eps^4, polynomial in eps2 of order 1
+		7, 35, 512,
+		// C2[5]/eps^5, polynomial in eps2 of order 0
+		63, 1280,
+		// C2[6]/eps^6, polynomial in eps2 of order 0
+		77, 2048,
+	}
+	eps2 := eps * eps
+	d := eps
+	o := 0
+	for l := 1; l <= nC2; l++ {
+		m := (nC2 - l) / 2 // order of polynomial in eps^2
+		c[l] = d * polyval(m, coeff[o:], eps2) / coeff[o+m+1]
+		o += m + 2
+		d *= eps
+	}
+}
+
+// initA3 sets the coefficients of the polynomial in eps for A3.
+func (g *Geodesic) initA3() {
+	coeff := [...]float64{
+		// A3, coeff of eps^5, polynomial in n of order 0
+		-3, 128,
+		// A3, coeff of eps^4, polynomial in n of order 1
+		-2, -3, 64,
+		// A3, coeff of eps^3, polynomial in n of order 2
+		-1, -3, -1, 16,
+		// A3, coeff of eps^2, polynomial in n of order 2
+		3, -1, -2, 8,
+		// A3, coeff of eps^1, polynomial in n of order 1
+		1, -1, 2,
+		// A3, coeff of eps^0, polynomial in n of order 0
+		1, 1,
+	}
+	o, k := 0, 0
+	for j := nA3 - 1; j >= 0; j-- { // coeff of eps^j
+		m := minInt(nA3-j-1, j) // order of polynomial in n
+		g.a3x[k] = polyval(m, coeff[o:], g.n) / coeff[o+m+1]
+		k++
+		o += m + 2
+	}
+}
+
+// initC3 sets the coefficients of the polynomials in eps for C3.
+func (g *Geodesic) initC3() {
+	coeff := [...]float64{
+		// C3[1], coeff of eps^5, polynomial in n of order 0
+		3, 128,
+		// C3[1], coeff of eps^4, polynomial in n of order 1
+		2, 5, 128,
+		// C3[1], coeff of eps^3, polynomial in n of order 2
+		-1, 3, 3, 64,
+		// C3[1], coeff of eps^2, polynomial in n of order 2
+		-1, 0, 1, 8,
+		// C3[1], coeff of eps^1, polynomial in n of order 1
+		-1, 1, 4,
+		// C3[2], coeff of eps^5, polynomial in n of order 0
+		5, 256,
+		// C3[2], coeff of eps^4, polynomial in n of order 1
+		1, 3, 128,
+		// C3[2], coeff of eps^3, polynomial in n of order 2
+		-3, -2, 3, 64,
+		// C3[2], coeff of eps^2, polynomial in n of order 2
+		1, -3, 2, 32,
+		// C3[3], coeff of eps^5, polynomial in n of order 0
+		7, 512,
+		// C3[3], coeff of eps^4, polynomial in n of order 1
+		-10, 9, 384,
+		// C3[3], coeff of eps^3, polynomial in n of order 2
+		5, -9, 5, 192,
+		// C3[4], coeff of eps^5, polynomial in n of order 0
+		7, 512,
+		// C3[4], coeff of eps^4, polynomial in n of order 1
+		-14, 7, 512,
+		// C3[5], coeff of eps^5, polynomial in n of order 0
+		21, 2560,
+	}
+	o, k := 0, 0
+	for l := 1; l < nC3; l++ { // l is index of C3[l]
+		for j := nC3 - 1; j >= l; j-- { // coeff of eps^j
+			m := minInt(nC3-j-1, j) // order of polynomial in n
+			g.c3x[k] = polyval(m, coeff[o:], g.n) / coeff[o+m+1]
+			k++
+			o += m + 2
+		}
+	}
+}
+
+// initC4 sets the coefficients of the polynomials in eps for C4.
+func (g *Geodesic) initC4() {
+	coeff := [...]float64{
+		// C4[0], coeff of eps^5, polynomial in n of order 0
+		97, 15015,
+		// C4[0], coeff of eps^4, polynomial in n of order 1
+		1088, 156, 45045,
+		// C4[0], coeff of eps^3, polynomial in n of order 2
+		-224, -4784, 1573, 45045,
+		// C4[0], coeff of eps^2, polynomial in n of order 3
+		-10656, 14144, -4576, -858, 45045,
+		// C4[0], coeff of eps^1, polynomial in n of order 4
+		64, 624, -4576, 6864, -3003, 15015,
+		// C4[0], coeff of eps^0, polynomial in n of order 5
+		100, 208, 572, 3432, -12012, 30030, 45045,
+		// C4[1], coeff of eps^5, polynomial in n of order 0
+		1, 9009,
+		// C4[1], coeff of eps^4, polynomial in n of order 1
+		-2944, 468, 135135,
+		// C4[1], coeff of eps^3, polynomial in n of order 2
+		5792, 1040, -1287, 135135,
+		// C4[1], coeff of eps^2, polynomial in n of order 3
+		5952, -11648, 9152, -2574, 135135,
+		// C4[1], coeff of eps^1, polynomial in n of order 4
+		-64, -624, 4576, -6864, 3003, 135135,
+		// C4[2], coeff of eps^5, polynomial in n of order 0
+		8, 10725,
+		// C4[2], coeff of eps^4, polynomial in n of order 1
+		1856, -936, 225225,
+		// C4[2], coeff of eps^3, polynomial in n of order 2
+		-8448, 4992, -1144, 225225,
+		// C4[2], coeff of eps^2, polynomial in n of order 3
+		-1440, 4160, -4576, 1716, 225225,
+		// C4[3], coeff of eps^5, polynomial in n of order 0
+		-136, 63063,
+		// C4[3], coeff of eps^4, polynomial in n of order 1
+		1024, -208, 105105,
+		// C4[3], coeff of eps^3, polynomial in n of order 2
+		3584, -3328, 1144, 315315,
+		// C4[4], coeff of eps^5, polynomial in n of order 0
+		-128, 135135,
+		// C4[4], coeff of eps^4, polynomial in n of order 1
+		-2560, 832, 405405,
+		// C4[5], coeff of eps^5, polynomial in n of order 0
+		128, 99099,
+	}
+	o, k := 0, 0
+	for l := 0; l < nC4; l++ { // l is index of C4[l]
+		for j := nC4 - 1; j >= l; j-- { // coeff of eps^j
+			m := nC4 - j - 1 // order of polynomial in n
+			g.c4x[k] = polyval(m, coeff[o:], g.n) / coeff[o+m+1]
+			k++
+			o += m + 2
+		}
+	}
+}
+
+// a3f returns the scale factor A3 = mean value of (d/dsigma)I3.
+func (g *Geodesic) a3f(eps float64) float64 {
+	return polyval(nA3-1, g.a3x[:], eps)
+}
+
+// c3f sets c[1:nC3] to the coefficients C3[l] in the Fourier expansion of B3.
+func (g *Geodesic) c3f(eps float64, c []float64) {
+	mult := 1.0
+	o := 0
+	for l := 1; l < nC3; l++ { // l is index of C3[l]
+		m := nC3 - l - 1 // order of polynomial in eps
+		mult *= eps
+		c[l] = mult * polyval(m, g.c3x[o:], eps)
+		o += m + 1
+	}
+}
+
+// c4f sets c[0:nC4] to the coefficients C4[l] in the Fourier expansion of I4.
+func (g *Geodesic) c4f(eps float64, c []float64) {
+	mult := 1.0
+	o := 0
+	for l := 0; l < nC4; l++ { // l is index of C4[l]
+		m := nC4 - l - 1 // order of polynomial in eps
+		c[l] = mult * polyval(m, g.c4x[o:], eps)
+		o += m + 1
+		mult *= eps
+	}
+}
+
+// sinCosSeries evaluates, using Clenshaw summation,
+//
+//	sum(c[i] * sin(2*i * x), i, 1, n)   if sinp is true
+//	sum(c[i] * cos((2*i+1) * x), i, 0, n-1)   otherwise
+func sinCosSeries(sinp bool, sinx, cosx float64, c []float64, n int) float64 {
+	// Start one beyond the last element.
+	i := n
+	if sinp {
+		i++
+	}
+	ar := 2 * (cosx - sinx) * (cosx + sinx) // 2 * cos(2 * x)
+	var y0, y1 float64
+	if n&1 != 0 {
+		i--
+		y0 = c[i]
+	}
+	for k := n / 2; k > 0; k-- {
+		// Unroll the loop by 2, so the accumulators need not be swapped.
+		i--
+		y1 = ar*y0 - y1 + c[i]
+		i--
+		y0 = ar*y1 - y0 + c[i]
+	}
+	if sinp {
+		return 2 * sinx * cosx * y0 // sin(2 * x) * y0
+	}
+	return cosx * (y0 - y1) // cos(x) * (y0 - y1)
+}
+
+// polyval evaluates the polynomial of degree n with coefficients p, in
+// decreasing order of degree, at x.
+func polyval(n int, p []float64, x float64) float64 {
+	if n < 0 {
+		return 0
+	}
+	y := p[0]
+	for i := 1; i <= n; i++ {
+		y = y*x + p[i]
+	}
+	return y
+}
+
+// sumx returns the error-free sum s = u + v and the error t, such that
+// s + t = u + v exactly.
+func sumx(u, v float64) (s, t float64) {
+	s = u + v
+	up := s - v
+	vpp := s - up
+	up -= u
+	vpp -= v
+	return s, -(up + vpp)
+}
+
+// accumulator sums values with about twice the precision of a float64.
+type accumulator struct {
+	s, t float64
+}
+
+// add adds y to the sum.
+func (a *accumulator) add(y float64) {
+	z, u := sumx(y, a.t)
+	a.s, a.t = sumx(z, a.s)
+	if a.s == 0 {
+		a.s = u
+	} else {
+		a.t += u
+	}
+}
+
+// remainder reduces the sum to the range [-y/2, y/2].
+func (a *accumulator) remainder(y float64) {
+	a.s = math.Remainder(a.s, y)
+	a.add(0)
+}
+
+// norm2 returns x and y scaled so that x^2 + y^2 = 1.
+func norm2(x, y float64) (float64, float64) {
+	r := math.Hypot(x, y)
+	return x / r, y / r
+}
+
+// angRound rounds tiny angles (in degrees) so that small differences are
+// not lost when they are added to larger angles.
+func angRound(x float64) float64 {
+	const z = 1.0 / 16
+	y := math.Abs(x)
+	// The compiler must not "simplify" z - (z - y) to y.
+	if w := z - y; w > 0 {
+		y = z - w
+	}
+	return math.Copysign(y, x)
+}
+
+// angNormalize reduces an angle in degrees to the range [-180, 180].
+func angNormalize(x float64) float64 {
+	y := math.Remainder(x, 360)
+	if math.Abs(y) == 180 {
+		return math.Copysign(180, x)
+	}
+	return y
+}
+
+// latFix returns NaN for latitudes outside [-90, 90] degrees.
+func latFix(x float64) float64 {
+	if math.Abs(x) > 90 {
+		return math.NaN()
+	}
+	return x
+}
+
+// angDiff returns the exact difference y - x of two angles in degrees,
+// reduced to [-180, 180], as the sum d + e.
+func angDiff(x, y float64) (d, e float64) {
+	d, t := sumx(math.Remainder(-x, 360), math.Remainder(y, 360))
+	d, t = sumx(math.Remainder(d, 360), t)
+	if d == 0 || math.Abs(d) == 180 {
+		// Use y - x to get the sign of the result right when t == 0.
+		if t == 0 {
+			d = math.Copysign(d, y-x)
+		} else {
+			d = math.Copysign(d, -t)
+		}
+	}
+	return d, t
+}
+
+// sincosd returns the sine and cosine of an angle in degrees, reducing the
+// angle exactly so that, for example, sincosd(90) is exactly (1, 0).
+func sincosd(x float64) (sinx, cosx float64) {
+	r := math.Mod(x, 360)
+	q := int(math.Floor(r/90 + 0.5))
+	r -= 90 * float64(q)
+	s, c := math.Sincos(r * degree)
+	switch uint(q) & 3 {
+	case 0:
+		sinx, cosx = s, c
+	case 1:
+		sinx, cosx = c, -s
+	case 2:
+		sinx, cosx = -s, -c
+	default:
+		sinx, cosx = -c, s
+	}
+	// Convert -0 to 0, but keep the sign of x for sin(+/-0).
+	cosx += 0
+	if sinx == 0 {
+		sinx = math.Copysign(0, x)
+	}
+	return sinx, cosx
+}
+
+// atan2d returns atan2(y, x) in degrees, reducing the arguments so that, for
+// example, atan2d(1, 1) is exactly 45.
+func atan2d(y, x float64) float64 {
+	// Reduce to the first octant.
+	q := 0
+	if math.Abs(y) > math.Abs(x) {
+		x, y = y, x
+		q = 2
+	}
+	if math.Signbit(x) {
+		x = -x
+		q++
+	}
+	ang := math.Atan2(y, x) / degree
+	switch q {
+	case 1:
+		ang = math.Copysign(180, y) - ang
+	case 2:
+		ang = 90 - ang
+	case 3:
+		ang = -90 + ang
+	}
+	return ang
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
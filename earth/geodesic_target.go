@@ -0,0 +1,226 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package earth
+
+import (
+	"math"
+
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+// geodesicDistance implements s2.Distance for the length of the shortest
+// geodesic on an ellipsoid, in meters.
+//
+// Distances are converted to and from ChordAngles using the mean radius of
+// the Earth (see ChordAngleFromLength and LengthFromChordAngle), so query
+// limits and results can be converted between ChordAngles and lengths in
+// the usual way regardless of the ellipsoid.
+type geodesicDistance struct {
+	length float64
+	// minRadius is the smallest radius of curvature of the ellipsoid, which
+	// relates geodesic lengths to angles on the unit sphere.
+	minRadius float64
+}
+
+func (d geodesicDistance) ChordAngle() s1.ChordAngle {
+	return ChordAngleFromLength(Length(d.length))
+}
+func (d geodesicDistance) FromChordAngle(o s1.ChordAngle) s2.Distance {
+	return geodesicDistance{LengthFromChordAngle(o).Meters(), d.minRadius}
+}
+func (d geodesicDistance) Zero() s2.Distance     { return geodesicDistance{0, d.minRadius} }
+func (d geodesicDistance) Negative() s2.Distance { return geodesicDistance{-1, d.minRadius} }
+func (d geodesicDistance) Infinity() s2.Distance {
+	return geodesicDistance{math.Inf(1), d.minRadius}
+}
+func (d geodesicDistance) Less(other s2.Distance) bool {
+	return d.length < other.(geodesicDistance).length
+}
+func (d geodesicDistance) Sub(other s2.Distance) s2.Distance {
+	o := other.(geodesicDistance).length
+	if o == 0 {
+		return d
+	}
+	if d.length <= o {
+		return d.Zero()
+	}
+	return geodesicDistance{d.length - o, d.minRadius}
+}
+
+// ChordAngleBound returns the largest angle on the unit sphere between two
+// points whose geodesic distance is d, which for WGS84 is about 0.56% larger
+// than the angle corresponding to d using the mean radius of the Earth.
+func (d geodesicDistance) ChordAngleBound() s1.ChordAngle {
+	c := s1.ChordAngleFromAngle(s1.Angle(d.length / d.minRadius))
+	return c.Expanded(c.MaxAngleError())
+}
+
+func (d geodesicDistance) UpdateDistance(other s2.Distance) (s2.Distance, bool) {
+	if other.Less(d) {
+		return other, true
+	}
+	return d, false
+}
+
+// GeodesicPointTarget is an s2.DistanceTarget for finding the geometry that
+// is closest to a point, as measured by the length of the shortest geodesic
+// on an ellipsoid, for use with s2.EdgeQuery.
+//
+// The points of the indexed geometry are interpreted as geodetic latitudes
+// and longitudes, and each edge is the s2 edge between its endpoints (rather
+// than the geodesic between them). The distances of query results can be
+// converted back to lengths using LengthFromChordAngle, and query distance
+// limits given with ChordAngleFromLength.
+//
+// For example:
+//
+//	target := earth.NewGeodesicPointTarget(earth.WGS84, p)
+//	opts := s2.NewClosestEdgeQueryOptions().MaxResults(5).
+//		DistanceLimit(earth.ChordAngleFromLength(10 * earth.Kilometer))
+//	for _, r := range s2.NewClosestEdgeQuery(index, opts).FindEdges(target) {
+//		length := earth.LengthFromChordAngle(r.Distance())
+//		...
+//	}
+type GeodesicPointTarget struct {
+	g      *Geodesic
+	point  s2.Point
+	latLng s2.LatLng
+	dist   geodesicDistance
+}
+
+// NewGeodesicPointTarget returns a target for the geodesic distance on the
+// given ellipsoid to the given point.
+func NewGeodesicPointTarget(g *Geodesic, point s2.Point) *GeodesicPointTarget {
+	return &GeodesicPointTarget{
+		g:      g,
+		point:  point,
+		latLng: s2.LatLngFromPoint(point),
+		dist:   geodesicDistance{0, g.minRadius()},
+	}
+}
+
+// CapBound returns a Cap that bounds the set of points whose distance to the
+// target is zero.
+func (t *GeodesicPointTarget) CapBound() s2.Cap {
+	return s2.CapFromCenterChordAngle(t.point, 0)
+}
+
+// UpdateDistanceToPoint updates the distance if the geodesic distance to the
+// point p is less than dist.
+func (t *GeodesicPointTarget) UpdateDistanceToPoint(p s2.Point, dist s2.Distance) (s2.Distance, bool) {
+	return dist.UpdateDistance(t.distanceTo(p))
+}
+
+// UpdateDistanceToEdge updates the distance if the geodesic distance to the
+// closest point of the edge e is less than dist.
+//
+// The closest point is found by a golden section search along the edge,
+// which assumes that the distance has a single local minimum along the
+// edge. This holds except for edges that pass close to the antipode of the
+// target.
+func (t *GeodesicPointTarget) UpdateDistanceToEdge(e s2.Edge, dist s2.Distance) (s2.Distance, bool) {
+	if !t.lowerBound(s2.DistanceFromSegment(t.point, e.V0, e.V1)).Less(dist) {
+		return dist, false
+	}
+	return dist.UpdateDistance(t.distanceToEdge(e))
+}
+
+// UpdateDistanceToCell updates the distance if a lower bound on the geodesic
+// distance to the cell (including its interior) is less than dist.
+func (t *GeodesicPointTarget) UpdateDistanceToCell(c s2.Cell, dist s2.Distance) (s2.Distance, bool) {
+	return dist.UpdateDistance(t.lowerBound(c.Distance(t.point).Angle()))
+}
+
+// SetMaxError reports that this target does not take advantage of maxErr.
+func (t *GeodesicPointTarget) SetMaxError(maxErr s1.ChordAngle) bool { return false }
+
+// MaxBruteForceIndexSize reports the maximum number of indexed objects for
+// which it is faster to compute the distance by brute force.
+func (t *GeodesicPointTarget) MaxBruteForceIndexSize() int {
+	// Geodesic distances are much more expensive to compute than
+	// ChordAngles, so the index pays off for smaller inputs than it does
+	// for s2.MinDistanceToPointTarget.
+	return 30
+}
+
+// Distance returns a zero geodesic distance.
+func (t *GeodesicPointTarget) Distance() s2.Distance { return t.dist }
+
+// VisitContainingShapes visits the polygons in the index that contain the
+// target point.
+func (t *GeodesicPointTarget) VisitContainingShapes(index *s2.ShapeIndex, v s2.ShapePointVisitorFunc) bool {
+	q := s2.NewContainsPointQuery(index, s2.VertexModelSemiOpen)
+	for _, shape := range q.ContainingShapes(t.point) {
+		if !v(shape, t.point) {
+			return false
+		}
+	}
+	return true
+}
+
+// distanceTo returns the geodesic distance from the target to p.
+func (t *GeodesicPointTarget) distanceTo(p s2.Point) geodesicDistance {
+	return geodesicDistance{t.g.Distance(t.latLng, s2.LatLngFromPoint(p)).Meters(), t.dist.minRadius}
+}
+
+// lowerBound returns a lower bound on the geodesic distance from the target
+// to any point that is the given angle away from it on the unit sphere.
+func (t *GeodesicPointTarget) lowerBound(a s1.Angle) geodesicDistance {
+	// Allow for the error in the angle, which is much less than a micrometer
+	// on the surface of the Earth.
+	const slack = 1e-6
+	return geodesicDistance{math.Max(0, a.Radians()*t.dist.minRadius-slack), t.dist.minRadius}
+}
+
+// distanceToEdge returns the geodesic distance from the target to the
+// closest point of the edge.
+func (t *GeodesicPointTarget) distanceToEdge(e s2.Edge) geodesicDistance {
+	best := t.distanceTo(e.V0)
+	if d := t.distanceTo(e.V1); d.length < best.length {
+		best = d
+	}
+
+	// Search for the minimum along the edge, parameterized by the angle from
+	// V0, until the bracketing interval is shorter than 0.1mm.
+	const (
+		invPhi    = 0.6180339887498949 // (sqrt(5) - 1) / 2
+		tolerance = 1e-4
+	)
+	edgeLength := e.V0.Distance(e.V1).Radians()
+	at := func(x float64) geodesicDistance {
+		return t.distanceTo(s2.InterpolateAtDistance(s1.Angle(x), e.V0, e.V1))
+	}
+	lo, hi := 0.0, edgeLength
+	x1, x2 := hi-invPhi*(hi-lo), lo+invPhi*(hi-lo)
+	d1, d2 := at(x1), at(x2)
+	for (hi-lo)*t.dist.minRadius > tolerance {
+		if d1.length < d2.length {
+			hi, x2, d2 = x2, x1, d1
+			x1 = hi - invPhi*(hi-lo)
+			d1 = at(x1)
+		} else {
+			lo, x1, d1 = x1, x2, d2
+			x2 = lo + invPhi*(hi-lo)
+			d2 = at(x2)
+		}
+	}
+	for _, d := range []geodesicDistance{d1, d2} {
+		if d.length < best.length {
+			best = d
+		}
+	}
+	return best
+}
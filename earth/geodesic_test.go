@@ -0,0 +1,317 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package earth
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+func latLngsToLoop(lls ...s2.LatLng) *s2.Loop {
+	var points []s2.Point
+	for _, ll := range lls {
+		points = append(points, s2.PointFromLatLng(ll))
+	}
+	return s2.LoopFromPoints(points)
+}
+
+func TestGeodesicInverse(t *testing.T) {
+	// Reference values are from GeographicLib.
+	tests := []struct {
+		a, b               s2.LatLng
+		length             Length
+		azimuth1, azimuth2 float64
+	}{
+		{
+			// JFK to LHR.
+			s2.LatLngFromDegrees(40.6, -73.8), s2.LatLngFromDegrees(51.6, -0.5),
+			5551759.400319, 51.198882845580, 107.821776735514,
+		},
+		{
+			// Wellington to Salamanca, which is nearly antipodal.
+			s2.LatLngFromDegrees(-41.32, 174.81), s2.LatLngFromDegrees(40.96, -5.50),
+			19959679.267353, 161.067669986160, 18.825195123247,
+		},
+		{
+			// Antipodal points on the equator are joined by meridians.
+			s2.LatLngFromDegrees(0, 0), s2.LatLngFromDegrees(0, 180),
+			20003931.458625, 0, 180,
+		},
+		{
+			s2.LatLngFromDegrees(10, 20), s2.LatLngFromDegrees(10, 20),
+			0, 180, 180,
+		},
+	}
+	for _, test := range tests {
+		length, azi1, azi2 := WGS84.Inverse(test.a, test.b)
+		if !float64Near(length.Meters(), test.length.Meters(), 1e-6) {
+			t.Errorf("Inverse(%v, %v) length = %.6f, want %.6f", test.a, test.b, length.Meters(), test.length.Meters())
+		}
+		if test.length == 0 {
+			continue
+		}
+		if !float64Near(azi1.Degrees(), test.azimuth1, 1e-9) || !float64Near(azi2.Degrees(), test.azimuth2, 1e-9) {
+			t.Errorf("Inverse(%v, %v) azimuths = %.12f, %.12f, want %.12f, %.12f",
+				test.a, test.b, azi1.Degrees(), azi2.Degrees(), test.azimuth1, test.azimuth2)
+		}
+		if got := WGS84.Distance(test.a, test.b); got != length {
+			t.Errorf("Distance(%v, %v) = %v, want %v", test.a, test.b, got, length)
+		}
+	}
+}
+
+func TestGeodesicDirect(t *testing.T) {
+	a := s2.LatLngFromDegrees(40.6, -73.8)
+	b, azi2 := WGS84.Direct(a, 51.198882845580*s1.Degree, 5551759.400319*Meter)
+	if !float64Near(b.Lat.Degrees(), 51.6, 1e-9) || !float64Near(b.Lng.Degrees(), -0.5, 1e-9) {
+		t.Errorf("Direct(%v, ...) = %v, want 51.6, -0.5", a, b)
+	}
+	if !float64Near(azi2.Degrees(), 107.821776735514, 1e-9) {
+		t.Errorf("Direct(%v, ...) azimuth = %.12f, want 107.821776735514", a, azi2.Degrees())
+	}
+
+	// Going north from the equator for a quarter meridian reaches the pole.
+	b, _ = WGS84.Direct(s2.LatLngFromDegrees(0, 30), 0, 10001965.729313*Meter)
+	if !float64Near(b.Lat.Degrees(), 90, 1e-9) {
+		t.Errorf("Direct quarter meridian = %v, want the north pole", b)
+	}
+}
+
+func TestGeodesicDirectInverseConsistency(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		a := s2.LatLngFromDegrees(r.Float64()*180-90, r.Float64()*360-180)
+		b := s2.LatLngFromDegrees(r.Float64()*180-90, r.Float64()*360-180)
+		if i%4 == 0 {
+			// Include some short geodesics.
+			b = s2.LatLngFromDegrees(a.Lat.Degrees()+r.Float64()*0.01, a.Lng.Degrees()+r.Float64()*0.01)
+		}
+		length, azi1, azi2 := WGS84.Inverse(a, b)
+		if length < 0 || length > 20003931.46*Meter {
+			t.Errorf("Inverse(%v, %v) length = %v, want in [0, half meridian]", a, b, length)
+		}
+		if back := WGS84.Distance(b, a); !float64Near(back.Meters(), length.Meters(), 1e-6) {
+			t.Errorf("Distance(%v, %v) = %v, want %v", b, a, back, length)
+		}
+		got, gotAzi2 := WGS84.Direct(a, azi1, length)
+		if d := WGS84.Distance(got, b); d > 1e-6*Meter {
+			t.Errorf("Direct(%v, %v, %v) = %v, which is %v from %v", a, azi1, length, got, d, b)
+		}
+		if diff, _ := angDiff(gotAzi2.Degrees(), azi2.Degrees()); math.Abs(diff) > 1e-7 && length > Meter {
+			t.Errorf("Direct(%v, %v, %v) azimuth = %v, want %v", a, azi1, length, gotAzi2, azi2)
+		}
+	}
+}
+
+func TestGeodesicSphere(t *testing.T) {
+	// With no flattening the geodesics are great circles.
+	g := NewGeodesic(Radius, 0)
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		a := s2.LatLngFromDegrees(r.Float64()*180-90, r.Float64()*360-180)
+		b := s2.LatLngFromDegrees(r.Float64()*180-90, r.Float64()*360-180)
+		if got, want := g.Distance(a, b), LengthFromLatLngs(a, b); !float64Near(got.Meters(), want.Meters(), 1e-6) {
+			t.Errorf("spherical Distance(%v, %v) = %v, want %v", a, b, got, want)
+		}
+	}
+	if got, want := g.EllipsoidArea(), AreaFromSteradians(4*math.Pi); !float64Near(got.SquareMeters(), want.SquareMeters(), 1) {
+		t.Errorf("spherical EllipsoidArea() = %v, want %v", got, want)
+	}
+}
+
+func TestGeodesicPolylineLength(t *testing.T) {
+	lls := []s2.LatLng{
+		s2.LatLngFromDegrees(40.6, -73.8),
+		s2.LatLngFromDegrees(51.6, -0.5),
+		s2.LatLngFromDegrees(-41.32, 174.81),
+	}
+	polyline := s2.PolylineFromLatLngs(lls)
+	want := WGS84.Distance(lls[0], lls[1]) + WGS84.Distance(lls[1], lls[2])
+	if got := WGS84.PolylineLength(polyline); !float64Near(got.Meters(), want.Meters(), 1e-6) {
+		t.Errorf("PolylineLength(%v) = %v, want %v", polyline, got, want)
+	}
+	if got := WGS84.PolylineLength(&s2.Polyline{}); got != 0 {
+		t.Errorf("PolylineLength(empty) = %v, want 0", got)
+	}
+}
+
+func TestGeodesicLoopArea(t *testing.T) {
+	// The perimeter of Antarctica, from the GeographicLib documentation.
+	antarctica := latLngsToLoop(
+		s2.LatLngFromDegrees(-63.1, -58), s2.LatLngFromDegrees(-72.9, -74),
+		s2.LatLngFromDegrees(-71.9, -102), s2.LatLngFromDegrees(-74.9, -102),
+		s2.LatLngFromDegrees(-74.3, -131), s2.LatLngFromDegrees(-77.5, -163),
+		s2.LatLngFromDegrees(-77.4, 163), s2.LatLngFromDegrees(-71.7, 172),
+		s2.LatLngFromDegrees(-65.9, 140), s2.LatLngFromDegrees(-65.7, 113),
+		s2.LatLngFromDegrees(-66.6, 88), s2.LatLngFromDegrees(-66.9, 59),
+		s2.LatLngFromDegrees(-69.8, 25), s2.LatLngFromDegrees(-70.0, -4),
+		s2.LatLngFromDegrees(-71.0, -14), s2.LatLngFromDegrees(-77.3, -33),
+		s2.LatLngFromDegrees(-77.9, -46), s2.LatLngFromDegrees(-74.7, -61),
+	)
+	tests := []struct {
+		desc string
+		loop *s2.Loop
+		want Area
+	}{
+		{"Antarctica", antarctica, 13662703680020.1},
+		{"Ring around the north pole", latLngsToLoop(
+			s2.LatLngFromDegrees(89, 0), s2.LatLngFromDegrees(89, 90),
+			s2.LatLngFromDegrees(89, 180), s2.LatLngFromDegrees(89, 270)), 24952305678.0},
+		{"Octant", latLngsToLoop(
+			s2.LatLngFromDegrees(0, 0), s2.LatLngFromDegrees(0, 90), s2.LatLngFromDegrees(90, 0)),
+			WGS84.EllipsoidArea() / 8},
+		{"Empty", s2.EmptyLoop(), 0},
+		{"Full", s2.FullLoop(), WGS84.EllipsoidArea()},
+	}
+	for _, test := range tests {
+		if got := WGS84.LoopArea(test.loop); !float64Near(got.SquareMeters(), test.want.SquareMeters(), 1) {
+			t.Errorf("%s: LoopArea() = %.1f, want %.1f", test.desc, got.SquareMeters(), test.want.SquareMeters())
+		}
+	}
+
+	// The complement of a loop covers the rest of the ellipsoid.
+	antarctica.Invert()
+	if got, want := WGS84.LoopArea(antarctica), WGS84.EllipsoidArea()-13662703680020.1; !float64Near(got.SquareMeters(), want.SquareMeters(), 1) {
+		t.Errorf("LoopArea(inverted Antarctica) = %.1f, want %.1f", got.SquareMeters(), want.SquareMeters())
+	}
+	if got, want := Area(WGS84.EllipsoidArea().SquareMeters()), AreaFromSteradians(4*math.Pi); !float64Near(got.SquareMeters()/want.SquareMeters(), 1, 0.01) {
+		t.Errorf("EllipsoidArea() = %v, want about %v", got, want)
+	}
+}
+
+func TestGeodesicPolygonArea(t *testing.T) {
+	shell := latLngsToLoop(
+		s2.LatLngFromDegrees(0, 0), s2.LatLngFromDegrees(0, 10),
+		s2.LatLngFromDegrees(10, 10), s2.LatLngFromDegrees(10, 0))
+	hole := latLngsToLoop(
+		s2.LatLngFromDegrees(4, 4), s2.LatLngFromDegrees(4, 6),
+		s2.LatLngFromDegrees(6, 6), s2.LatLngFromDegrees(6, 4))
+	polygon := s2.PolygonFromLoops([]*s2.Loop{shell, hole})
+
+	want := WGS84.LoopArea(shell) - WGS84.LoopArea(hole)
+	got := WGS84.PolygonArea(polygon)
+	if !float64Near(got.SquareMeters(), want.SquareMeters(), 1) {
+		t.Errorf("PolygonArea() = %.1f, want %.1f", got.SquareMeters(), want.SquareMeters())
+	}
+	// The ellipsoidal area is close to the spherical one.
+	if spherical := AreaFromSteradians(polygon.Area()); !float64Near(got.SquareMeters()/spherical.SquareMeters(), 1, 0.01) {
+		t.Errorf("PolygonArea() = %v, want about %v", got, spherical)
+	}
+}
+
+func TestGeodesicDistanceBounds(t *testing.T) {
+	// The angle between points on the unit sphere is never more than their
+	// geodesic distance divided by the minimum radius of curvature.
+	r := rand.New(rand.NewSource(1))
+	minRadius := WGS84.minRadius()
+	for i := 0; i < 1000; i++ {
+		a := s2.LatLngFromDegrees(r.Float64()*180-90, r.Float64()*360-180)
+		b := s2.LatLngFromDegrees(a.Lat.Degrees()+r.Float64()*10-5, a.Lng.Degrees()+r.Float64()*10-5)
+		if b.Lat.Degrees() > 90 || b.Lat.Degrees() < -90 {
+			continue
+		}
+		length := WGS84.Distance(a, b)
+		if angle := a.Distance(b); angle.Radians()*minRadius > length.Meters()+1e-6 {
+			t.Errorf("angle(%v, %v) * minRadius = %v, want <= geodesic distance %v", a, b, angle.Radians()*minRadius, length)
+		}
+	}
+}
+
+func TestGeodesicPointTarget(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	center := s2.LatLngFromDegrees(45, 10)
+	var points s2.PointVector
+	for i := 0; i < 200; i++ {
+		points = append(points, s2.PointFromLatLng(s2.LatLngFromDegrees(
+			center.Lat.Degrees()+r.Float64()*2-1, center.Lng.Degrees()+r.Float64()*2-1)))
+	}
+	index := s2.NewShapeIndex()
+	index.Add(&points)
+
+	for _, bruteForce := range []bool{true, false} {
+		for i := 0; i < 10; i++ {
+			q := s2.PointFromLatLng(s2.LatLngFromDegrees(
+				center.Lat.Degrees()+r.Float64()*3-1.5, center.Lng.Degrees()+r.Float64()*3-1.5))
+			target := NewGeodesicPointTarget(WGS84, q)
+
+			var want []float64
+			for _, p := range points {
+				want = append(want, WGS84.Distance(s2.LatLngFromPoint(q), s2.LatLngFromPoint(p)).Meters())
+			}
+			sort.Float64s(want)
+
+			opts := s2.NewClosestEdgeQueryOptions().MaxResults(5).UseBruteForce(bruteForce)
+			results := s2.NewClosestEdgeQuery(index, opts).FindEdges(target)
+			if len(results) != 5 {
+				t.Fatalf("FindEdges returned %d results, want 5", len(results))
+			}
+			for j, result := range results {
+				if got := LengthFromChordAngle(result.Distance()).Meters(); !float64Near(got, want[j], 1e-3) {
+					t.Errorf("bruteForce=%v: result %d distance = %v, want %v", bruteForce, j, got, want[j])
+				}
+			}
+
+			// A distance limit given as a length is respected.
+			limit := Length(want[2]+want[3]) / 2
+			opts = s2.NewClosestEdgeQueryOptions().UseBruteForce(bruteForce).DistanceLimit(ChordAngleFromLength(limit))
+			if got := len(s2.NewClosestEdgeQuery(index, opts).FindEdges(target)); got != 3 {
+				t.Errorf("bruteForce=%v: FindEdges with limit %v returned %d results, want 3", bruteForce, limit, got)
+			}
+		}
+	}
+}
+
+func TestGeodesicPointTargetEdges(t *testing.T) {
+	index := s2.NewShapeIndex()
+	polyline := s2.PolylineFromLatLngs([]s2.LatLng{
+		s2.LatLngFromDegrees(40, -10), s2.LatLngFromDegrees(42, 0), s2.LatLngFromDegrees(41, 10),
+	})
+	index.Add(polyline)
+
+	for _, ll := range []s2.LatLng{
+		s2.LatLngFromDegrees(45, -5),
+		s2.LatLngFromDegrees(38, 5),
+		s2.LatLngFromDegrees(42, 0),
+		s2.LatLngFromDegrees(41, -20),
+	} {
+		q := s2.PointFromLatLng(ll)
+		// Find the closest point on the polyline by dense sampling.
+		want := math.Inf(1)
+		for i := 0; i+1 < len(*polyline); i++ {
+			a, b := (*polyline)[i], (*polyline)[i+1]
+			for j := 0; j <= 10000; j++ {
+				p := s2.Interpolate(float64(j)/10000, a, b)
+				want = math.Min(want, WGS84.Distance(ll, s2.LatLngFromPoint(p)).Meters())
+			}
+		}
+
+		opts := s2.NewClosestEdgeQueryOptions().MaxResults(1)
+		result := s2.NewClosestEdgeQuery(index, opts).FindEdges(NewGeodesicPointTarget(WGS84, q))
+		if len(result) != 1 {
+			t.Fatalf("FindEdges(%v) returned %d results, want 1", ll, len(result))
+		}
+		got := LengthFromChordAngle(result[0].Distance()).Meters()
+		// The sampling is within about 100m of the true closest point, so
+		// the sampled distance can only be slightly too large.
+		if got > want+1e-3 || got < want-10 {
+			t.Errorf("distance from %v to polyline = %v, want about %v", ll, got, want)
+		}
+	}
+}
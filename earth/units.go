@@ -0,0 +1,64 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package earth
+
+// Length represents a physical distance. The internal representation is a
+// double precision value in meters.
+type Length float64
+
+// Length units.
+const (
+	Meter      Length = 1
+	Kilometer         = 1000 * Meter
+	Centimeter        = Meter / 100
+	Foot              = 0.3048 * Meter
+	Mile              = 1609.344 * Meter
+)
+
+// Meters returns the length in meters.
+func (l Length) Meters() float64 { return float64(l) }
+
+// Kilometers returns the length in kilometers.
+func (l Length) Kilometers() float64 { return float64(l / Kilometer) }
+
+// Feet returns the length in feet.
+func (l Length) Feet() float64 { return float64(l / Foot) }
+
+// Miles returns the length in miles.
+func (l Length) Miles() float64 { return float64(l / Mile) }
+
+// Area represents a physical area. The internal representation is a double
+// precision value in square meters.
+type Area float64
+
+// Area units.
+const (
+	SquareMeter     Area = 1
+	SquareKilometer      = 1e6 * SquareMeter
+	SquareFoot           = Area(Foot * Foot)
+	SquareMile           = Area(Mile * Mile)
+)
+
+// SquareMeters returns the area in square meters.
+func (a Area) SquareMeters() float64 { return float64(a) }
+
+// SquareKilometers returns the area in square kilometers.
+func (a Area) SquareKilometers() float64 { return float64(a / SquareKilometer) }
+
+// SquareFeet returns the area in square feet.
+func (a Area) SquareFeet() float64 { return float64(a / SquareFoot) }
+
+// SquareMiles returns the area in square miles.
+func (a Area) SquareMiles() float64 { return float64(a / SquareMile) }
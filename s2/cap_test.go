@@ -458,10 +458,10 @@ func TestCapContainsCell(t *testing.T) {
 		rootCell := CellFromCellID(CellIDFromFace(face))
 
 		// A leaf cell at the midpoint of the v=1 edge.
-		edgeCell := CellFromPoint(Point{faceUVToXYZ(face, 0, 1-epsilon)})
+		edgeCell := CellFromPoint(Point{FaceUVToXYZ(face, 0, 1-epsilon)})
 
 		// A leaf cell at the u=1, v=1 corner
-		cornerCell := CellFromPoint(Point{faceUVToXYZ(face, 1-epsilon, 1-epsilon)})
+		cornerCell := CellFromPoint(Point{FaceUVToXYZ(face, 1-epsilon, 1-epsilon)})
 
 		// Quick check for full and empty caps.
 		if !fullCap.ContainsCell(rootCell) {
@@ -482,7 +482,7 @@ func TestCapContainsCell(t *testing.T) {
 
 		for capFace := 0; capFace < 6; capFace++ {
 			// A cap that barely contains all of capFace.
-			center := unitNorm(capFace)
+			center := UnitNorm(capFace)
 			covering := CapFromCenterAngle(center, s1.Angle(faceRadius+epsilon))
 			if got, want := covering.ContainsCell(rootCell), capFace == face; got != want {
 				t.Errorf("Cap(%v).ContainsCell(%v) = %t; want = %t", covering, rootCell, got, want)
@@ -519,10 +519,10 @@ func TestCapIntersectsCell(t *testing.T) {
 		rootCell := CellFromCellID(CellIDFromFace(face))
 
 		// A leaf cell at the midpoint of the v=1 edge.
-		edgeCell := CellFromPoint(Point{faceUVToXYZ(face, 0, 1-epsilon)})
+		edgeCell := CellFromPoint(Point{FaceUVToXYZ(face, 0, 1-epsilon)})
 
 		// A leaf cell at the u=1, v=1 corner
-		cornerCell := CellFromPoint(Point{faceUVToXYZ(face, 1-epsilon, 1-epsilon)})
+		cornerCell := CellFromPoint(Point{FaceUVToXYZ(face, 1-epsilon, 1-epsilon)})
 
 		// Quick check for full and empty caps.
 		if emptyCap.IntersectsCell(rootCell) {
@@ -544,7 +544,7 @@ func TestCapIntersectsCell(t *testing.T) {
 		antiFace := (face + 3) % 6
 		for capFace := 0; capFace < 6; capFace++ {
 			// A cap that barely contains all of capFace.
-			center := unitNorm(capFace)
+			center := UnitNorm(capFace)
 			covering := CapFromCenterAngle(center, s1.Angle(faceRadius+epsilon))
 			if got, want := covering.IntersectsCell(rootCell), capFace != antiFace; got != want {
 				t.Errorf("Cap(%v).IntersectsCell(%v) = %t; want = %t", covering, rootCell, got, want)
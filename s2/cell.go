@@ -59,7 +59,7 @@ type Cell struct {
 func CellFromCellID(id CellID) Cell {
 	c := Cell{}
 	c.id = id
-	f, i, j, o := c.id.faceIJOrientation()
+	f, i, j, o := c.id.FaceIJOrientation()
 	c.face = int8(f)
 	c.level = int8(c.id.Level())
 	c.orientation = int8(o)
@@ -104,12 +104,12 @@ func (c Cell) IsLeaf() bool {
 
 // SizeIJ returns the edge length of this cell in (i,j)-space.
 func (c Cell) SizeIJ() int {
-	return sizeIJ(int(c.level))
+	return SizeIJ(int(c.level))
 }
 
 // SizeST returns the edge length of this cell in (s,t)-space.
 func (c Cell) SizeST() float64 {
-	return c.id.sizeST(int(c.level))
+	return SizeST(int(c.level))
 }
 
 // Vertex returns the normalized k-th vertex of the cell (k = 0,1,2,3) in CCW order
@@ -121,7 +121,7 @@ func (c Cell) Vertex(k int) Point {
 // VertexRaw returns the unnormalized k-th vertex of the cell (k = 0,1,2,3) in CCW order
 // (lower left, lower right, upper right, upper left in the UV plane).
 func (c Cell) VertexRaw(k int) Point {
-	return Point{faceUVToXYZ(int(c.face), c.uv.Vertices()[k].X, c.uv.Vertices()[k].Y)}
+	return Point{FaceUVToXYZ(int(c.face), c.uv.Vertices()[k].X, c.uv.Vertices()[k].Y)}
 }
 
 // Edge returns the normalized inward-facing normal of the great circle passing through
@@ -146,13 +146,13 @@ func (c Cell) Edge(k int) Point {
 func (c Cell) EdgeRaw(k int) Point {
 	switch k {
 	case 0:
-		return Point{vNorm(int(c.face), c.uv.Y.Lo)} // Bottom
+		return Point{VNorm(int(c.face), c.uv.Y.Lo)} // Bottom
 	case 1:
-		return Point{uNorm(int(c.face), c.uv.X.Hi)} // Right
+		return Point{UNorm(int(c.face), c.uv.X.Hi)} // Right
 	case 2:
-		return Point{vNorm(int(c.face), c.uv.Y.Hi).Mul(-1.0)} // Top
+		return Point{VNorm(int(c.face), c.uv.Y.Hi).Mul(-1.0)} // Top
 	default:
-		return Point{uNorm(int(c.face), c.uv.X.Lo).Mul(-1.0)} // Left
+		return Point{UNorm(int(c.face), c.uv.X.Lo).Mul(-1.0)} // Left
 	}
 }
 
@@ -196,7 +196,7 @@ func (c Cell) IJCoordOfEdge(k int) int {
 	// Intel CPUs that support SSE4.1 have the ROUNDSD instruction, and ARM CPUs
 	// with VFP have the VCVT instruction, both of which can implement correct
 	// rounding efficiently regardless of the current FPU rounding mode.
-	return int(math.Round(MaxSize * uvToST(c.UVCoordOfEdge(k))))
+	return int(math.Round(MaxSize * UVToST(c.UVCoordOfEdge(k))))
 }
 
 // Center returns the direction vector corresponding to the center in
@@ -219,7 +219,7 @@ func (c Cell) Children() ([4]Cell, bool) {
 	}
 
 	// Compute the cell midpoint in uv-space.
-	uvMid := c.id.centerUV()
+	uvMid := c.id.CenterUV()
 
 	// Create four children with the appropriate bounds.
 	cid := c.id.ChildBegin()
@@ -328,7 +328,7 @@ func (c Cell) latitude(i, j int) float64 {
 	default:
 		panic("i and/or j is out of bounds")
 	}
-	return latitude(Point{faceUVToXYZ(int(c.face), u, v)}).Radians()
+	return latitude(Point{FaceUVToXYZ(int(c.face), u, v)}).Radians()
 }
 
 // longitude returns the longitude of the cell vertex in radians given by (i,j),
@@ -351,7 +351,7 @@ func (c Cell) longitude(i, j int) float64 {
 	default:
 		panic("i and/or j is out of bounds")
 	}
-	return longitude(Point{faceUVToXYZ(int(c.face), u, v)}).Radians()
+	return longitude(Point{FaceUVToXYZ(int(c.face), u, v)}).Radians()
 }
 
 var (
@@ -375,14 +375,14 @@ func (c Cell) RectBound() Rect {
 		u := c.uv.X.Lo + c.uv.X.Hi
 		v := c.uv.Y.Lo + c.uv.Y.Hi
 		var i, j int
-		if uAxis(int(c.face)).Z == 0 {
+		if UAxis(int(c.face)).Z == 0 {
 			if u < 0 {
 				i = 1
 			}
 		} else if u > 0 {
 			i = 1
 		}
-		if vAxis(int(c.face)).Z == 0 {
+		if VAxis(int(c.face)).Z == 0 {
 			if v < 0 {
 				j = 1
 			}
@@ -445,7 +445,7 @@ func (c Cell) CapBound() Cap {
 	// We use the cell center in (u,v)-space as the cap axis.  This vector is very close
 	// to GetCenter() and faster to compute.  Neither one of these vectors yields the
 	// bounding cap with minimal surface area, but they are both pretty close.
-	cap := CapFromPoint(Point{faceUVToXYZ(int(c.face), c.uv.Center().X, c.uv.Center().Y).Normalize()})
+	cap := CapFromPoint(Point{FaceUVToXYZ(int(c.face), c.uv.Center().X, c.uv.Center().Y).Normalize()})
 	for k := 0; k < 4; k++ {
 		cap = cap.AddPoint(c.Vertex(k))
 	}
@@ -474,7 +474,7 @@ func (c Cell) ContainsPoint(p Point) bool {
 	// cross the origin.
 	var uv r2.Point
 	var ok bool
-	if uv.X, uv.Y, ok = faceXYZToUV(int(c.face), p); !ok {
+	if uv.X, uv.Y, ok = FaceXYZToUV(int(c.face), p); !ok {
 		return false
 	}
 
@@ -579,7 +579,7 @@ func edgeDistance(ij, uv float64) s1.ChordAngle {
 // the cell if toInterior is true or to the boundary of the cell otherwise.
 func (c Cell) distanceInternal(targetXYZ Point, toInterior bool) s1.ChordAngle {
 	// All calculations are done in the (u,v,w) coordinates of this cell's face.
-	target := faceXYZtoUVW(int(c.face), targetXYZ)
+	target := FaceXYZToUVW(int(c.face), targetXYZ)
 
 	// Compute dot products with all four upward or rightward-facing edge
 	// normals. dirIJ is the dot product for the edge corresponding to axis
@@ -650,7 +650,7 @@ func (c Cell) Distance(target Point) s1.ChordAngle {
 func (c Cell) MaxDistance(target Point) s1.ChordAngle {
 	// First check the 4 cell vertices.  If all are within the hemisphere
 	// centered around target, the max distance will be to one of these vertices.
-	targetUVW := faceXYZtoUVW(int(c.face), target)
+	targetUVW := FaceXYZToUVW(int(c.face), target)
 	maxDist := maxChordAngle(c.vertexChordDist2(targetUVW, false, false),
 		c.vertexChordDist2(targetUVW, true, false),
 		c.vertexChordDist2(targetUVW, false, true),
@@ -207,6 +207,134 @@ func (c *CellIndexRangeIterator) Seek(target CellID) {
 	}
 }
 
+// CellIndexCellIterator adapts a CellIndexRangeIterator to the CellIterator
+// interface, so that the ranges of a CellIndex can be used in a
+// CellIteratorJoin. Each leaf cell range is visited as the sequence of cells
+// that exactly covers it (the same cells as CellUnionFromRange), so every
+// visited cell is either contained by or disjoint from each indexed cell.
+type CellIndexCellIterator struct {
+	ranges *CellIndexRangeIterator
+	// The current cell, which lies within the current range of ranges.
+	id CellID
+}
+
+// NewCellIndexCellIterator returns a CellIterator over the cells that cover
+// the ranges visited by the given range iterator. For example, wrapping the
+// iterator returned by NewCellIndexNonEmptyRangeIterator visits exactly the
+// leaf cells covered by the indexed cells. Like the range iterator, it is
+// initially unpositioned.
+func NewCellIndexCellIterator(ranges *CellIndexRangeIterator) *CellIndexCellIterator {
+	return &CellIndexCellIterator{ranges: ranges}
+}
+
+// Ranges returns the underlying range iterator, which is positioned at the
+// range that contains the current cell. It can be passed to
+// CellIndexContentsIterator.StartUnion to visit the indexed cells that
+// contain the current cell.
+func (c *CellIndexCellIterator) Ranges() *CellIndexRangeIterator {
+	return c.ranges
+}
+
+// setCell sets the current cell to the largest cell that contains the given
+// leaf cell and lies within the current range. Positions outside of that
+// range are clamped to it.
+func (c *CellIndexCellIterator) setCell(leaf CellID) {
+	if c.ranges.Done() {
+		c.id = SentinelCellID
+		return
+	}
+	start, limit := c.ranges.StartID(), c.ranges.LimitID()
+	if leaf < start {
+		leaf = start
+	} else if leaf >= limit {
+		leaf = limit.Prev()
+	} else if !leaf.IsLeaf() {
+		// The range contains the position of a non-leaf cell, which lies
+		// between the two leaf cells at the center of that cell. The leaf
+		// cell just before it is in the range too, since ranges start at
+		// leaf cells.
+		leaf--
+	}
+	for leaf.Level() > 0 {
+		parent := leaf.immediateParent()
+		if parent.RangeMin() < start || parent.RangeMax() >= limit {
+			break
+		}
+		leaf = parent
+	}
+	c.id = leaf
+}
+
+// Begin positions the iterator at the first cell (if any).
+func (c *CellIndexCellIterator) Begin() {
+	c.ranges.Begin()
+	c.setCell(c.ranges.StartID())
+}
+
+// Next positions the iterator at the next cell.
+//
+// This assumes the iterator is not done.
+func (c *CellIndexCellIterator) Next() {
+	if next := c.id.RangeMax().Next(); next < c.ranges.LimitID() {
+		c.setCell(next)
+		return
+	}
+	c.ranges.Next()
+	c.setCell(c.ranges.StartID())
+}
+
+// Prev positions the iterator at the previous cell and reports whether it was
+// not already positioned at the first cell.
+func (c *CellIndexCellIterator) Prev() bool {
+	if !c.Done() && c.id.RangeMin() > c.ranges.StartID() {
+		// Step back to the previous cell within the same range.
+		c.setCell(c.id.RangeMin().Prev())
+		return true
+	}
+	if !c.ranges.Prev() {
+		return false
+	}
+	c.setCell(c.ranges.LimitID().Prev())
+	return true
+}
+
+// End positions the iterator so that Done is true.
+func (c *CellIndexCellIterator) End() {
+	c.ranges.Finish()
+	c.id = SentinelCellID
+}
+
+// Done reports if the iterator is positioned past the last cell.
+func (c *CellIndexCellIterator) Done() bool {
+	return c.ranges.Done()
+}
+
+// CellID returns the current cell, or SentinelCellID if Done is true.
+func (c *CellIndexCellIterator) CellID() CellID {
+	if c.Done() {
+		return SentinelCellID
+	}
+	return c.id
+}
+
+// Seek positions the iterator at the cell that contains target, or at the
+// first cell after it if target is not within any visited range.
+func (c *CellIndexCellIterator) Seek(target CellID) {
+	c.ranges.Seek(target)
+	c.setCell(target)
+}
+
+// LocatePoint positions the iterator at the cell that contains the given
+// Point and reports whether such a cell exists.
+func (c *CellIndexCellIterator) LocatePoint(p Point) bool { return locatePoint(c, p) }
+
+// LocateCellID positions the iterator at the first cell that has some
+// relation to the target and reports that relation. See CellIterator for
+// details.
+func (c *CellIndexCellIterator) LocateCellID(target CellID) CellRelation {
+	return locateCellID(c, target)
+}
+
 // CellIndexContentsIterator is an iterator that visits the (CellID, label) pairs
 // that cover a set of leaf cell ranges (see CellIndexRangeIterator). Note that
 // when multiple leaf cell ranges are visited, this iterator only guarantees that
@@ -492,7 +620,70 @@ func (c *CellIndex) Build() {
 	}
 }
 
+// CellVisitor is a function that is called by VisitIntersectingCells with each
+// (CellID, label) pair that it visits. If it returns false, the visiting stops.
+type CellVisitor func(id CellID, label int32) bool
+
+// VisitIntersectingCells visits all (CellID, label) pairs in the index that
+// intersect the given target CellUnion, which must be normalized. Each pair
+// is visited exactly once. It returns false if the visitor stopped early, and
+// true otherwise.
+func (c *CellIndex) VisitIntersectingCells(target CellUnion, visitor CellVisitor) bool {
+	if len(target) == 0 {
+		return true
+	}
+
+	contents := NewCellIndexContentsIterator(c)
+	rangeIter := NewCellIndexRangeIterator(c)
+	for i := 0; i < len(target); {
+		if i == 0 || (!rangeIter.Done() && rangeIter.LimitID() <= target[i].RangeMin()) {
+			// Only seek when necessary.
+			rangeIter.Seek(target[i].RangeMin())
+		}
+		for ; rangeIter.StartID() <= target[i].RangeMax(); rangeIter.Next() {
+			for contents.StartUnion(rangeIter); !contents.Done(); contents.Next() {
+				if !visitor(contents.CellID(), contents.Label()) {
+					return false
+				}
+			}
+		}
+
+		// Check whether the next target cell is also contained by the leaf
+		// cell range that we just processed. If so, we can skip over all
+		// such cells using binary search.
+		i++
+		if i < len(target) && target[i].RangeMax() < rangeIter.StartID() {
+			// Skip to the first target cell that extends past the previous range.
+			start := rangeIter.StartID()
+			i += sort.Search(len(target)-i, func(j int) bool { return target[i+j] >= start })
+			if target[i-1].RangeMax() >= start {
+				i--
+			}
+		}
+	}
+	return true
+}
+
+// IntersectingLabels returns the distinct sorted labels of all the cells in
+// the index that intersect the given target CellUnion, which must be
+// normalized.
+func (c *CellIndex) IntersectingLabels(target CellUnion) []int32 {
+	var labels []int32
+	c.VisitIntersectingCells(target, func(id CellID, label int32) bool {
+		labels = append(labels, label)
+		return true
+	})
+	sort.Slice(labels, func(i, j int) bool { return labels[i] < labels[j] })
+	// Remove duplicates.
+	n := 0
+	for i, label := range labels {
+		if i == 0 || label != labels[n-1] {
+			labels[n] = label
+			n++
+		}
+	}
+	return labels[:n]
+}
+
 // TODO(roberts): Differences from C++
-// IntersectingLabels
-// VisitIntersectingCells
 // CellIndexIterator
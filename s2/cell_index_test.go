@@ -28,6 +28,7 @@ func cellIndexQuadraticValidate(t *testing.T, desc string, index *CellIndex, con
 	verifyCellIndexCellIterator(t, desc, index)
 	verifyCellIndexRangeIterators(t, desc, index)
 	verifyCellIndexContents(t, desc, index)
+	verifyCellIndexIntersectingCells(t, desc, index)
 }
 
 // less reports whether this node is less than the other.
@@ -234,6 +235,41 @@ func verifyCellIndexContents(t *testing.T, desc string, index *CellIndex) {
 	}
 }
 
+// verifyCellIndexIntersectingCells checks VisitIntersectingCells and
+// IntersectingLabels against brute force for random targets.
+func verifyCellIndexIntersectingCells(t *testing.T, desc string, index *CellIndex) {
+	for i := 0; i < 20; i++ {
+		target := randomCellUnion(randomUniformInt(5))
+		target.Normalize()
+
+		var want []cellIndexNode
+		labelSet := make(map[int32]bool)
+		for _, node := range index.cellTree {
+			if target.IntersectsCellID(node.cellID) {
+				want = append(want, cellIndexNode{cellID: node.cellID, label: node.label})
+				labelSet[node.label] = true
+			}
+		}
+		var got []cellIndexNode
+		index.VisitIntersectingCells(target, func(id CellID, label int32) bool {
+			got = append(got, cellIndexNode{cellID: id, label: label})
+			return true
+		})
+		if !cellIndexNodesEqual(got, want) {
+			t.Errorf("%s: VisitIntersectingCells(%v) = %v, want %v", desc, target, got, want)
+		}
+
+		var wantLabels []int32
+		for label := range labelSet {
+			wantLabels = append(wantLabels, label)
+		}
+		sort.Slice(wantLabels, func(i, j int) bool { return wantLabels[i] < wantLabels[j] })
+		if got := index.IntersectingLabels(target); !reflect.DeepEqual(got, wantLabels) && len(got)+len(wantLabels) > 0 {
+			t.Errorf("%s: IntersectingLabels(%v) = %v, want %v", desc, target, got, wantLabels)
+		}
+	}
+}
+
 func TestCellIndex(t *testing.T) {
 	type cellIndexTestInput struct {
 		cellID string
@@ -0,0 +1,322 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package s2
+
+import (
+	"sort"
+)
+
+// CellIterator is the common interface of iterators over structures keyed by
+// a set of non-overlapping cells, such as the cells of a ShapeIndex. Cells are
+// visited in increasing order of CellID.
+//
+// A CellIterator is typically unpositioned when created; one of the
+// positioning methods (Begin, End, Seek, LocatePoint or LocateCellID) must be
+// called before accessing its contents.
+type CellIterator interface {
+	// Begin positions the iterator at the first cell (if any).
+	Begin()
+
+	// Next positions the iterator at the next cell. This requires that the
+	// iterator is not done.
+	Next()
+
+	// Prev positions the iterator at the previous cell and reports whether
+	// it was not already positioned at the first cell. If the iterator is
+	// done, it is positioned at the last cell (if any). If it is positioned
+	// at the first cell, the call does nothing and returns false.
+	Prev() bool
+
+	// End positions the iterator so that Done is true.
+	End()
+
+	// Done reports if the iterator is positioned past the last cell.
+	Done() bool
+
+	// CellID returns the CellID of the current cell. If Done is true, a
+	// value larger than any valid CellID is returned.
+	CellID() CellID
+
+	// Seek positions the iterator at the first cell whose CellID >= target,
+	// or at the end if no such cell exists. Implementations may instead
+	// position the iterator at a cell that contains target, if there is one.
+	Seek(target CellID)
+
+	// LocatePoint positions the iterator at the cell that contains the given
+	// Point and returns true. If no such cell exists, the iterator position is
+	// unspecified and false is returned.
+	LocatePoint(p Point) bool
+
+	// LocateCellID positions the iterator at the first cell that has some
+	// relation to the target and reports that relation. If the target is
+	// contained by (or equal to) some cell I, the iterator is positioned at I
+	// and Indexed is returned. Otherwise if the target contains one or more
+	// cells, the iterator is positioned at the first such cell and Subdivided
+	// is returned. Otherwise Disjoint is returned and the iterator position
+	// is unspecified.
+	LocateCellID(target CellID) CellRelation
+}
+
+var (
+	_ CellIterator = (*ShapeIndexIterator)(nil)
+	_ CellIterator = (*CellUnionIterator)(nil)
+	_ CellIterator = (*CellIndexCellIterator)(nil)
+)
+
+// locatePoint implements CellIterator.LocatePoint in terms of the other
+// methods of the iterator.
+func locatePoint(it CellIterator, p Point) bool {
+	// Let I = the first cell whose CellID >= T, where T is the leaf cell
+	// containing p. If T is contained by a cell, then the containing cell is
+	// either I or its predecessor.
+	target := cellIDFromPoint(p)
+	it.Seek(target)
+	if !it.Done() && it.CellID().RangeMin() <= target {
+		return true
+	}
+	return it.Prev() && it.CellID().RangeMax() >= target
+}
+
+// locateCellID implements CellIterator.LocateCellID in terms of the other
+// methods of the iterator.
+func locateCellID(it CellIterator, target CellID) CellRelation {
+	// Let T be the target, let I be the first cell whose CellID >= T.RangeMin(),
+	// and let I' be the predecessor of I. If T contains any cells, then T
+	// contains I. Similarly, if T is contained by a cell, then the containing
+	// cell is either I or I'. We test for containment by comparing the ranges
+	// of leaf cells spanned by T, I, and I'.
+	it.Seek(target.RangeMin())
+	if !it.Done() {
+		if it.CellID().Contains(target) {
+			return Indexed
+		}
+		if it.CellID().RangeMin() <= target.RangeMax() {
+			return Subdivided
+		}
+	}
+	if it.Prev() && it.CellID().RangeMax() >= target {
+		return Indexed
+	}
+	return Disjoint
+}
+
+// CellUnionIterator is a CellIterator over the cells of a normalized CellUnion.
+type CellUnionIterator struct {
+	cells CellUnion
+	pos   int
+}
+
+// NewCellUnionIterator returns an iterator over the cells of the given
+// CellUnion, which must be normalized (or at least valid, i.e. sorted and
+// non-overlapping). The iterator is initially positioned at the first cell.
+func NewCellUnionIterator(cu CellUnion) *CellUnionIterator {
+	return &CellUnionIterator{cells: cu}
+}
+
+// Begin positions the iterator at the first cell (if any).
+func (c *CellUnionIterator) Begin() { c.pos = 0 }
+
+// Next positions the iterator at the next cell.
+func (c *CellUnionIterator) Next() { c.pos++ }
+
+// Prev positions the iterator at the previous cell and reports whether it was
+// not already positioned at the first cell.
+func (c *CellUnionIterator) Prev() bool {
+	if c.pos == 0 {
+		return false
+	}
+	c.pos--
+	return true
+}
+
+// End positions the iterator so that Done is true.
+func (c *CellUnionIterator) End() { c.pos = len(c.cells) }
+
+// Done reports if the iterator is positioned past the last cell.
+func (c *CellUnionIterator) Done() bool { return c.pos >= len(c.cells) }
+
+// CellID returns the CellID of the current cell, or SentinelCellID if Done
+// is true.
+func (c *CellUnionIterator) CellID() CellID {
+	if c.Done() {
+		return SentinelCellID
+	}
+	return c.cells[c.pos]
+}
+
+// Seek positions the iterator at the first cell whose CellID >= target.
+func (c *CellUnionIterator) Seek(target CellID) {
+	c.pos = sort.Search(len(c.cells), func(i int) bool { return c.cells[i] >= target })
+}
+
+// LocatePoint positions the iterator at the cell that contains the given
+// Point and reports whether such a cell exists.
+func (c *CellUnionIterator) LocatePoint(p Point) bool { return locatePoint(c, p) }
+
+// LocateCellID positions the iterator at the first cell that has some
+// relation to the target and reports that relation.
+func (c *CellUnionIterator) LocateCellID(target CellID) CellRelation {
+	return locateCellID(c, target)
+}
+
+// CellRangeIterator wraps a CellIterator with methods that are useful for
+// merging the contents of two or more cell-keyed structures. In particular,
+// it exposes the range of leaf cells covered by the current cell.
+type CellRangeIterator struct {
+	it CellIterator
+	// The min and max leaf cell ids covered by the current cell. If the
+	// iterator is done, these are larger than any valid cell id.
+	rangeMin CellID
+	rangeMax CellID
+}
+
+// NewCellRangeIterator returns a CellRangeIterator wrapping the given
+// iterator. Like the underlying iterator, it must be positioned using Begin
+// or one of the seek methods before use.
+func NewCellRangeIterator(it CellIterator) *CellRangeIterator {
+	return &CellRangeIterator{it: it}
+}
+
+// Iterator returns the underlying CellIterator.
+func (r *CellRangeIterator) Iterator() CellIterator { return r.it }
+
+// CellID returns the CellID of the current cell.
+func (r *CellRangeIterator) CellID() CellID { return r.it.CellID() }
+
+// RangeMin returns the minimum leaf cell covered by the current cell.
+func (r *CellRangeIterator) RangeMin() CellID { return r.rangeMin }
+
+// RangeMax returns the maximum leaf cell covered by the current cell.
+func (r *CellRangeIterator) RangeMax() CellID { return r.rangeMax }
+
+// Done reports if the iterator is positioned past the last cell.
+func (r *CellRangeIterator) Done() bool { return r.it.Done() }
+
+// Begin positions the iterator at the first cell (if any).
+func (r *CellRangeIterator) Begin() { r.it.Begin(); r.refresh() }
+
+// Next positions the iterator at the next cell.
+func (r *CellRangeIterator) Next() { r.it.Next(); r.refresh() }
+
+// Prev positions the iterator at the previous cell and reports whether it was
+// not already positioned at the first cell.
+func (r *CellRangeIterator) Prev() bool {
+	ok := r.it.Prev()
+	r.refresh()
+	return ok
+}
+
+// SeekTo positions the iterator at the first cell that overlaps or follows
+// the current cell of the target iterator, i.e. the first cell whose
+// RangeMax >= target.RangeMin().
+func (r *CellRangeIterator) SeekTo(target *CellRangeIterator) {
+	r.it.Seek(target.rangeMin)
+	// If the current cell does not contain the start of the target, it is
+	// possible that the previous cell does. This can only happen when the
+	// previous cell has a smaller CellID but covers target.rangeMin.
+	if r.it.Done() || r.it.CellID().RangeMin() > target.rangeMin {
+		if r.it.Prev() && r.it.CellID().RangeMax() < target.rangeMin {
+			r.it.Next()
+		}
+	}
+	r.refresh()
+}
+
+// SeekBeyond positions the iterator at the first cell that follows the current
+// cell of the target iterator, i.e. the first cell whose
+// RangeMin > target.RangeMax().
+func (r *CellRangeIterator) SeekBeyond(target *CellRangeIterator) {
+	r.it.Seek(target.rangeMax.Next())
+	if !r.it.Done() && r.it.CellID().RangeMin() <= target.rangeMax {
+		r.it.Next()
+	}
+	r.refresh()
+}
+
+// refresh updates the cached range of the current cell.
+func (r *CellRangeIterator) refresh() {
+	r.rangeMin = r.it.CellID().RangeMin()
+	r.rangeMax = r.it.CellID().RangeMax()
+}
+
+// CellIteratorJoin joins two CellIterators, visiting every pair of cells
+// (one from each iterator) that intersect. Since the cells of each iterator
+// are non-overlapping, one cell of every such pair contains the other.
+//
+// For example, to find the shapes of a ShapeIndex that may intersect the
+// cells stored in a CellIndex:
+//
+//	a := index.Iterator()
+//	b := NewCellIndexCellIterator(NewCellIndexNonEmptyRangeIterator(cellIndex))
+//	contents := NewCellIndexContentsIterator(cellIndex)
+//	NewCellIteratorJoin(a, b).Join(func(x, y CellID) bool {
+//		// a.IndexCell() holds the clipped shapes of cell x, and the
+//		// contents iterator visits the stored cells that contain y.
+//		for contents.StartUnion(b.Ranges()); !contents.Done(); contents.Next() {
+//			...
+//		}
+//		return true
+//	})
+type CellIteratorJoin struct {
+	a, b *CellRangeIterator
+}
+
+// NewCellIteratorJoin returns a join of the two given iterators.
+func NewCellIteratorJoin(a, b CellIterator) *CellIteratorJoin {
+	return &CellIteratorJoin{
+		a: NewCellRangeIterator(a),
+		b: NewCellRangeIterator(b),
+	}
+}
+
+// Join calls visit for each pair of intersecting cells, in increasing order
+// of their leaf cell ranges. While visit is running, the two iterators are
+// positioned at the cells being visited, so it may inspect their contents.
+// If visit returns false, the join stops early and Join returns false;
+// otherwise Join returns true.
+//
+// The join positions both iterators from scratch; it does not matter where
+// they were positioned before the call.
+func (j *CellIteratorJoin) Join(visit func(a, b CellID) bool) bool {
+	a, b := j.a, j.b
+	a.Begin()
+	b.Begin()
+	for !a.Done() && !b.Done() {
+		switch {
+		case a.rangeMax < b.rangeMin:
+			// a is entirely before b; skip ahead in a.
+			a.SeekTo(b)
+		case b.rangeMax < a.rangeMin:
+			// b is entirely before a; skip ahead in b.
+			b.SeekTo(a)
+		default:
+			if !visit(a.CellID(), b.CellID()) {
+				return false
+			}
+			// Advance the iterator whose cell ends first, since the other
+			// cell may also intersect its successor.
+			switch {
+			case a.rangeMax < b.rangeMax:
+				a.Next()
+			case b.rangeMax < a.rangeMax:
+				b.Next()
+			default:
+				a.Next()
+				b.Next()
+			}
+		}
+	}
+	return true
+}
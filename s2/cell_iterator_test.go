@@ -0,0 +1,310 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package s2
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// bruteForceCellRelation returns the relation of target to the given
+// normalized CellUnion, as defined by CellIterator.LocateCellID.
+func bruteForceCellRelation(cu CellUnion, target CellID) (CellRelation, CellID) {
+	for _, id := range cu {
+		if id.Contains(target) {
+			return Indexed, id
+		}
+		if target.Contains(id) {
+			return Subdivided, id
+		}
+	}
+	return Disjoint, 0
+}
+
+func TestCellUnionIteratorLocate(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for iter := 0; iter < 100; iter++ {
+		cu := randomCellUnion(10, r)
+		cu.Normalize()
+		it := NewCellUnionIterator(cu)
+
+		for i := 0; i < 20; i++ {
+			target := randomCellID(r)
+			wantRel, wantID := bruteForceCellRelation(cu, target)
+			if got := it.LocateCellID(target); got != wantRel {
+				t.Errorf("%v.LocateCellID(%v) = %v, want %v", cu, target, got, wantRel)
+				continue
+			}
+			if wantRel != Disjoint && it.CellID() != wantID {
+				t.Errorf("after %v.LocateCellID(%v), CellID() = %v, want %v", cu, target, it.CellID(), wantID)
+			}
+
+			p := target.Point()
+			if got, want := it.LocatePoint(p), cu.ContainsPoint(p); got != want {
+				t.Errorf("%v.LocatePoint(%v) = %v, want %v", cu, p, got, want)
+			} else if got && !it.CellID().Contains(cellIDFromPoint(p)) {
+				t.Errorf("after %v.LocatePoint(%v), CellID() = %v does not contain it", cu, p, it.CellID())
+			}
+		}
+
+		// Walking backwards from the end visits every cell.
+		var got CellUnion
+		for it.End(); it.Prev(); {
+			got = append(CellUnion{it.CellID()}, got...)
+		}
+		if !reflect.DeepEqual(got, cu) && len(cu) > 0 {
+			t.Errorf("iterating %v backwards = %v", cu, got)
+		}
+	}
+}
+
+func TestCellIndexCellIterator(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for iter := 0; iter < 50; iter++ {
+		var index CellIndex
+		var all CellUnion
+		var added []CellID
+		var labels []int32
+		for i := 0; i < 10; i++ {
+			id := randomCellIDForLevel(randomUniformInt(10, r), r)
+			label := int32(randomUniformInt(3, r))
+			index.Add(id, label)
+			all = append(all, id)
+			added = append(added, id)
+			labels = append(labels, label)
+		}
+		index.Build()
+		all.Normalize()
+
+		// The plain iterator visits cells that exactly cover the sphere.
+		it := NewCellIndexCellIterator(NewCellIndexRangeIterator(&index))
+		next := CellIDFromFace(0).ChildBeginAtLevel(MaxLevel)
+		for it.Begin(); !it.Done(); it.Next() {
+			if got := it.CellID().RangeMin(); got != next {
+				t.Fatalf("cell %v starts at %v, want %v", it.CellID(), got, next)
+			}
+			if r := it.Ranges(); r.StartID() > it.CellID().RangeMin() || r.LimitID() <= it.CellID().RangeMax() {
+				t.Errorf("cell %v is not within the range [%v, %v)", it.CellID(), r.StartID(), r.LimitID())
+			}
+			next = it.CellID().RangeMax().Next()
+		}
+		if want := CellIDFromFace(5).ChildEndAtLevel(MaxLevel); next != want {
+			t.Errorf("cells end at %v, want %v", next, want)
+		}
+
+		// The non-empty iterator visits cells that exactly cover the indexed
+		// cells, each of which is contained by or disjoint from every indexed cell.
+		var cells CellUnion
+		nonEmpty := NewCellIndexCellIterator(NewCellIndexNonEmptyRangeIterator(&index))
+		contents := NewCellIndexContentsIterator(&index)
+		for nonEmpty.Begin(); !nonEmpty.Done(); nonEmpty.Next() {
+			id := nonEmpty.CellID()
+			if len(cells) > 0 && cells[len(cells)-1].RangeMax() >= id.RangeMin() {
+				t.Fatalf("cells out of order or overlapping: %v then %v", cells[len(cells)-1], id)
+			}
+			cells = append(cells, id)
+
+			var want []int32
+			for i, a := range added {
+				if a.Intersects(id) {
+					if !a.Contains(id) {
+						t.Errorf("indexed cell %v intersects but does not contain %v", a, id)
+					}
+					want = append(want, labels[i])
+				}
+			}
+			var got []int32
+			contents.Clear()
+			for contents.StartUnion(nonEmpty.Ranges()); !contents.Done(); contents.Next() {
+				got = append(got, contents.Label())
+			}
+			sort.Slice(got, func(i, j int) bool { return got[i] < got[j] })
+			sort.Slice(want, func(i, j int) bool { return want[i] < want[j] })
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("labels at %v = %v, want %v", id, got, want)
+			}
+
+			// Locating the cell or its center positions the iterator at it.
+			other := NewCellIndexCellIterator(NewCellIndexNonEmptyRangeIterator(&index))
+			if !other.LocatePoint(id.Point()) || other.CellID() != id {
+				t.Errorf("LocatePoint(%v) positioned at %v, want %v", id.Point(), other.CellID(), id)
+			}
+			if got := other.LocateCellID(id); got != Indexed || other.CellID() != id {
+				t.Errorf("LocateCellID(%v) = %v at %v, want %v at %v", id, got, other.CellID(), Indexed, id)
+			}
+		}
+		visited := append(CellUnion(nil), cells...)
+		cells.Normalize()
+		if !cells.Equal(all) {
+			t.Errorf("cells visited = %v, want %v", cells, all)
+		}
+
+		// Walking backwards visits the same cells.
+		var back CellUnion
+		for nonEmpty.End(); nonEmpty.Prev(); {
+			back = append(CellUnion{nonEmpty.CellID()}, back...)
+		}
+		if back.Normalize(); !back.Equal(all) {
+			t.Errorf("cells visited backwards = %v, want %v", back, all)
+		}
+
+		// LocateCellID agrees with a brute force check against the visited cells.
+		for i := 0; i < 20; i++ {
+			target := randomCellIDForLevel(randomUniformInt(12, r), r)
+			want := Disjoint
+			if visited.ContainsCellID(target) {
+				want = Indexed
+			} else if visited.IntersectsCellID(target) {
+				want = Subdivided
+			}
+			if got := nonEmpty.LocateCellID(target); got != want {
+				t.Errorf("LocateCellID(%v) = %v, want %v", target, got, want)
+			}
+		}
+	}
+}
+
+func TestCellIndexCellIteratorSingleLeaf(t *testing.T) {
+	leaf := cellIDFromPoint(parsePoint("5:5"))
+	var index CellIndex
+	index.Add(leaf, 1)
+	index.Build()
+
+	// The range iterator visits the ranges before, at, and after the leaf,
+	// while the cells that cover them are visited by the adapter.
+	ranges := NewCellIndexRangeIterator(&index)
+	numRanges := 0
+	for ranges.Begin(); !ranges.Done(); ranges.Next() {
+		numRanges++
+	}
+	if numRanges != 3 {
+		t.Errorf("CellIndexRangeIterator visited %d ranges, want 3", numRanges)
+	}
+	numCells := 0
+	it := NewCellIndexCellIterator(NewCellIndexRangeIterator(&index))
+	for it.Begin(); !it.Done(); it.Next() {
+		numCells++
+	}
+	if numCells <= numRanges {
+		t.Errorf("CellIndexCellIterator visited %d cells, want more than %d", numCells, numRanges)
+	}
+
+	it = NewCellIndexCellIterator(NewCellIndexNonEmptyRangeIterator(&index))
+	var got []CellID
+	for it.Begin(); !it.Done(); it.Next() {
+		got = append(got, it.CellID())
+	}
+	if want := []CellID{leaf}; !reflect.DeepEqual(got, want) {
+		t.Errorf("non-empty CellIndexCellIterator visited %v, want %v", got, want)
+	}
+}
+
+// cellPair is a pair of cells reported by a CellIteratorJoin.
+type cellPair struct {
+	a, b CellID
+}
+
+func TestCellIteratorJoinCellUnions(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for iter := 0; iter < 200; iter++ {
+		// Keep the cells on one face and fairly large so that many of them
+		// intersect.
+		var a, b CellUnion
+		for i := randomUniformInt(20, r); i > 0; i-- {
+			a = append(a, CellIDFromFacePosLevel(0, randomUint64(r), 1+randomUniformInt(6, r)))
+		}
+		for i := randomUniformInt(20, r); i > 0; i-- {
+			b = append(b, CellIDFromFacePosLevel(0, randomUint64(r), 1+randomUniformInt(6, r)))
+		}
+		a.Normalize()
+		b.Normalize()
+
+		var want []cellPair
+		for _, x := range a {
+			for _, y := range b {
+				if x.Intersects(y) {
+					want = append(want, cellPair{x, y})
+				}
+			}
+		}
+		var got []cellPair
+		ai, bi := NewCellUnionIterator(a), NewCellUnionIterator(b)
+		NewCellIteratorJoin(ai, bi).Join(func(x, y CellID) bool {
+			if ai.CellID() != x || bi.CellID() != y {
+				t.Errorf("iterators positioned at (%v, %v), want (%v, %v)", ai.CellID(), bi.CellID(), x, y)
+			}
+			got = append(got, cellPair{x, y})
+			return true
+		})
+		sort.Slice(want, func(i, j int) bool {
+			return want[i].a < want[j].a || (want[i].a == want[j].a && want[i].b < want[j].b)
+		})
+		sort.Slice(got, func(i, j int) bool {
+			return got[i].a < got[j].a || (got[i].a == got[j].a && got[i].b < got[j].b)
+		})
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Join(%v, %v) = %v, want %v", a, b, got, want)
+		}
+	}
+}
+
+func TestCellIteratorJoinShapeIndexCellIndex(t *testing.T) {
+	index := makeShapeIndex("# 0:0, 0:10, 10:10 | 20:20, 21:21 #")
+
+	var cellIndex CellIndex
+	cellIndex.Add(cellIDFromPoint(parsePoint("5:5")).Parent(8), 1)
+	cellIndex.Add(cellIDFromPoint(parsePoint("5:5")).Parent(3), 2)
+	cellIndex.Add(cellIDFromPoint(parsePoint("-40:-40")).Parent(10), 3)
+	cellIndex.Build()
+
+	a := index.Iterator()
+	b := NewCellIndexCellIterator(NewCellIndexNonEmptyRangeIterator(&cellIndex))
+	contents := NewCellIndexContentsIterator(&cellIndex)
+	gotLabels := make(map[int32]bool)
+	numPairs := 0
+	NewCellIteratorJoin(a, b).Join(func(x, y CellID) bool {
+		if !x.Intersects(y) {
+			t.Errorf("Join visited disjoint cells %v and %v", x, y)
+		}
+		if a.IndexCell() == nil {
+			t.Errorf("ShapeIndexIterator not positioned at %v", x)
+		}
+		for contents.StartUnion(b.Ranges()); !contents.Done(); contents.Next() {
+			gotLabels[contents.Label()] = true
+		}
+		numPairs++
+		return true
+	})
+	if numPairs == 0 {
+		t.Errorf("Join visited no pairs")
+	}
+	if want := map[int32]bool{1: true, 2: true}; !reflect.DeepEqual(gotLabels, want) {
+		t.Errorf("Join labels = %v, want %v", gotLabels, want)
+	}
+
+	// Stopping early.
+	numPairs = 0
+	if NewCellIteratorJoin(a, b).Join(func(x, y CellID) bool {
+		numPairs++
+		return false
+	}) {
+		t.Errorf("Join with a visitor returning false = true, want false")
+	}
+	if numPairs != 1 {
+		t.Errorf("Join with a visitor returning false visited %d pairs, want 1", numPairs)
+	}
+}
@@ -128,9 +128,9 @@ func Test2CellIJCoordOfEdge(t *testing.T) {
 
 		// Look up the canonical IJ coordinates of the cell boundary.
 		var ij [2]int
-		_, ij[0], ij[1], _ = id.faceIJOrientation()
+		_, ij[0], ij[1], _ = id.FaceIJOrientation()
 
-		ijSize := sizeIJ(id.Level())
+		ijSize := SizeIJ(id.Level())
 		var ijBounds r2.Rect
 		ijLo := ij[0] & -ijSize
 		ijBounds.X.Lo = float64(ijLo)
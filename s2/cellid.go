@@ -203,8 +203,8 @@ func (ci CellID) Children() [4]CellID {
 	return ch
 }
 
-// sizeIJ reports the edge length of cells at the given level in (i,j)-space.
-func sizeIJ(level int) int {
+// SizeIJ reports the edge length of cells at the given level in (i,j)-space.
+func SizeIJ(level int) int {
 	return 1 << uint(MaxLevel-level)
 }
 
@@ -213,8 +213,8 @@ func sizeIJ(level int) int {
 // All neighbors are guaranteed to be distinct.
 func (ci CellID) EdgeNeighbors() [4]CellID {
 	level := ci.Level()
-	size := sizeIJ(level)
-	f, i, j, _ := ci.faceIJOrientation()
+	size := SizeIJ(level)
+	f, i, j, _ := ci.FaceIJOrientation()
 	return [4]CellID{
 		cellIDFromFaceIJWrap(f, i, j-size).Parent(level),
 		cellIDFromFaceIJWrap(f, i+size, j).Parent(level),
@@ -227,9 +227,9 @@ func (ci CellID) EdgeNeighbors() [4]CellID {
 // this cell at the given level. Normally there are four neighbors, but the closest
 // vertex may only have three neighbors if it is one of the 8 cube vertices.
 func (ci CellID) VertexNeighbors(level int) []CellID {
-	halfSize := sizeIJ(level + 1)
+	halfSize := SizeIJ(level + 1)
 	size := halfSize << 1
-	f, i, j, _ := ci.faceIJOrientation()
+	f, i, j, _ := ci.FaceIJOrientation()
 
 	var isame, jsame bool
 	var ioffset, joffset int
@@ -277,16 +277,16 @@ func (ci CellID) AllNeighbors(level int) []CellID {
 
 	var neighbors []CellID
 
-	face, i, j, _ := ci.faceIJOrientation()
+	face, i, j, _ := ci.FaceIJOrientation()
 
 	// Find the coordinates of the lower left-hand leaf cell. We need to
 	// normalize (i,j) to a known position within the cell because level
 	// may be larger than this cell's level.
-	size := sizeIJ(ci.Level())
+	size := SizeIJ(ci.Level())
 	i &= -size
 	j &= -size
 
-	nbrSize := sizeIJ(level)
+	nbrSize := SizeIJ(level)
 
 	// We compute the top-bottom, left-right, and diagonal neighbors in one
 	// pass. The loop test is at the end of the loop to avoid 32-bit overflow.
@@ -501,28 +501,25 @@ func (ci *CellID) decode(d *decoder) {
 	*ci = CellID(d.readUint64())
 }
 
-// TODO: the methods below are not exported yet.  Settle on the entire API design
-// before doing this.  Do we want to mirror the C++ one as closely as possible?
-
-// distanceFromBegin returns the number of steps along the Hilbert curve that
+// DistanceFromBegin returns the number of steps along the Hilbert curve that
 // this cell is from the first node in the S2 hierarchy at our level. (i.e.,
 // FromFace(0).ChildBeginAtLevel(ci.Level())). This is analogous to Pos(), but
 // for this cell's level.
 // The return value is always non-negative.
-func (ci CellID) distanceFromBegin() int64 {
+func (ci CellID) DistanceFromBegin() int64 {
 	return int64(ci >> uint64(2*(MaxLevel-ci.Level())+1))
 }
 
 // rawPoint returns an unnormalized r3 vector from the origin through the center
 // of the s2 cell on the sphere.
 func (ci CellID) rawPoint() r3.Vector {
-	face, si, ti := ci.faceSiTi()
-	return faceUVToXYZ(face, stToUV((0.5/MaxSize)*float64(si)), stToUV((0.5/MaxSize)*float64(ti)))
+	face, si, ti := ci.FaceSiTi()
+	return FaceUVToXYZ(face, STToUV((0.5/MaxSize)*float64(si)), STToUV((0.5/MaxSize)*float64(ti)))
 }
 
-// faceSiTi returns the Face/Si/Ti coordinates of the center of the cell.
-func (ci CellID) faceSiTi() (face int, si, ti uint32) {
-	face, i, j, _ := ci.faceIJOrientation()
+// FaceSiTi returns the Face/Si/Ti coordinates of the center of the cell.
+func (ci CellID) FaceSiTi() (face int, si, ti uint32) {
+	face, i, j, _ := ci.FaceIJOrientation()
 	delta := 0
 	if ci.IsLeaf() {
 		delta = 1
@@ -534,8 +531,8 @@ func (ci CellID) faceSiTi() (face int, si, ti uint32) {
 	return face, uint32(2*i + delta), uint32(2*j + delta)
 }
 
-// faceIJOrientation uses the global lookupIJ table to unfiddle the bits of ci.
-func (ci CellID) faceIJOrientation() (f, i, j, orientation int) {
+// FaceIJOrientation uses the global lookupIJ table to unfiddle the bits of ci.
+func (ci CellID) FaceIJOrientation() (f, i, j, orientation int) {
 	f = ci.Face()
 	orientation = f & swapMask
 	nbits := MaxLevel - 7*lookupBits // first iteration
@@ -571,8 +568,8 @@ func (ci CellID) faceIJOrientation() (f, i, j, orientation int) {
 	return
 }
 
-// cellIDFromFaceIJ returns a leaf cell given its cube face (range 0..5) and IJ coordinates.
-func cellIDFromFaceIJ(f, i, j int) CellID {
+// CellIDFromFaceIJ returns a leaf cell given its cube face (range 0..5) and IJ coordinates.
+func CellIDFromFaceIJ(f, i, j int) CellID {
 	// Note that this value gets shifted one bit to the left at the end
 	// of the function.
 	n := uint64(f) << (PosBits - 1)
@@ -606,7 +603,7 @@ func cellIDFromFaceIJWrap(f, i, j int) CellID {
 	// We want to wrap these coordinates onto the appropriate adjacent face.
 	// The easiest way to do this is to convert the (i,j) coordinates to (x,y,z)
 	// (which yields a point outside the normal face boundary), and then call
-	// xyzToFaceUV to project back onto the correct face.
+	// XYZToFaceUV to project back onto the correct face.
 	//
 	// The code below converts (i,j) to (si,ti), and then (si,ti) to (u,v) using
 	// the linear projection (u=2*s-1 and v=2*t-1).  (The code further below
@@ -623,13 +620,13 @@ func cellIDFromFaceIJWrap(f, i, j int) CellID {
 
 	// Find the leaf cell coordinates on the adjacent face, and convert
 	// them to a cell id at the appropriate level.
-	f, u, v = xyzToFaceUV(faceUVToXYZ(f, u, v))
-	return cellIDFromFaceIJ(f, stToIJ(0.5*(u+1)), stToIJ(0.5*(v+1)))
+	f, u, v = XYZToFaceUV(FaceUVToXYZ(f, u, v))
+	return CellIDFromFaceIJ(f, STToIJ(0.5*(u+1)), STToIJ(0.5*(v+1)))
 }
 
 func cellIDFromFaceIJSame(f, i, j int, sameFace bool) CellID {
 	if sameFace {
-		return cellIDFromFaceIJ(f, i, j)
+		return CellIDFromFaceIJ(f, i, j)
 	}
 	return cellIDFromFaceIJWrap(f, i, j)
 }
@@ -644,27 +641,27 @@ func cellIDFromFaceIJSame(f, i, j int, sameFace bool) CellID {
 //
 // is always true.
 func cellIDFromPoint(p Point) CellID {
-	f, u, v := xyzToFaceUV(r3.Vector{X: p.X, Y: p.Y, Z: p.Z})
-	i := stToIJ(uvToST(u))
-	j := stToIJ(uvToST(v))
-	return cellIDFromFaceIJ(f, i, j)
+	f, u, v := XYZToFaceUV(r3.Vector{X: p.X, Y: p.Y, Z: p.Z})
+	i := STToIJ(UVToST(u))
+	j := STToIJ(UVToST(v))
+	return CellIDFromFaceIJ(f, i, j)
 }
 
 // ijLevelToBoundUV returns the bounds in (u,v)-space for the cell at the given
 // level containing the leaf cell with the given (i,j)-coordinates.
 func ijLevelToBoundUV(i, j, level int) r2.Rect {
-	cellSize := sizeIJ(level)
+	cellSize := SizeIJ(level)
 	xLo := i & -cellSize
 	yLo := j & -cellSize
 
 	return r2.Rect{
 		X: r1.Interval{
-			Lo: stToUV(ijToSTMin(xLo)),
-			Hi: stToUV(ijToSTMin(xLo + cellSize)),
+			Lo: STToUV(IJToSTMin(xLo)),
+			Hi: STToUV(IJToSTMin(xLo + cellSize)),
 		},
 		Y: r1.Interval{
-			Lo: stToUV(ijToSTMin(yLo)),
-			Hi: stToUV(ijToSTMin(yLo + cellSize)),
+			Lo: STToUV(IJToSTMin(yLo)),
+			Hi: STToUV(IJToSTMin(yLo + cellSize)),
 		},
 	}
 }
@@ -779,35 +776,35 @@ func (ci CellID) Advance(steps int64) CellID {
 	return ci + CellID(steps)<<stepShift
 }
 
-// centerST return the center of the CellID in (s,t)-space.
-func (ci CellID) centerST() r2.Point {
-	_, si, ti := ci.faceSiTi()
-	return r2.Point{X: siTiToST(si), Y: siTiToST(ti)}
+// CenterST returns the center of the CellID in (s,t)-space.
+func (ci CellID) CenterST() r2.Point {
+	_, si, ti := ci.FaceSiTi()
+	return r2.Point{X: SiTiToST(si), Y: SiTiToST(ti)}
 }
 
-// sizeST returns the edge length of this CellID in (s,t)-space at the given level.
-func (ci CellID) sizeST(level int) float64 {
-	return ijToSTMin(sizeIJ(level))
+// SizeST returns the edge length of cells at the given level in (s,t)-space.
+func SizeST(level int) float64 {
+	return IJToSTMin(SizeIJ(level))
 }
 
-// boundST returns the bound of this CellID in (s,t)-space.
-func (ci CellID) boundST() r2.Rect {
-	s := ci.sizeST(ci.Level())
-	return r2.RectFromCenterSize(ci.centerST(), r2.Point{X: s, Y: s})
+// BoundST returns the bound of this CellID in (s,t)-space.
+func (ci CellID) BoundST() r2.Rect {
+	s := SizeST(ci.Level())
+	return r2.RectFromCenterSize(ci.CenterST(), r2.Point{X: s, Y: s})
 }
 
-// centerUV returns the center of this CellID in (u,v)-space. Note that
+// CenterUV returns the center of this CellID in (u,v)-space. Note that
 // the center of the cell is defined as the point at which it is recursively
 // subdivided into four children; in general, it is not at the midpoint of
 // the (u,v) rectangle covered by the cell.
-func (ci CellID) centerUV() r2.Point {
-	_, si, ti := ci.faceSiTi()
-	return r2.Point{X: stToUV(siTiToST(si)), Y: stToUV(siTiToST(ti))}
+func (ci CellID) CenterUV() r2.Point {
+	_, si, ti := ci.FaceSiTi()
+	return r2.Point{X: STToUV(SiTiToST(si)), Y: STToUV(SiTiToST(ti))}
 }
 
-// boundUV returns the bound of this CellID in (u,v)-space.
-func (ci CellID) boundUV() r2.Rect {
-	_, i, j, _ := ci.faceIJOrientation()
+// BoundUV returns the bound of this CellID in (u,v)-space.
+func (ci CellID) BoundUV() r2.Rect {
+	_, i, j, _ := ci.FaceIJOrientation()
 	return ijLevelToBoundUV(i, j, ci.Level())
 }
 
@@ -824,7 +821,7 @@ func expandEndpoint(u, maxV, sinDist float64) float64 {
 	return (cosUShift*u + sinUShift) / (cosUShift - sinUShift*u)
 }
 
-// expandedByDistanceUV returns a rectangle expanded in (u,v)-space so that it
+// ExpandedByDistanceUV returns a rectangle expanded in (u,v)-space so that it
 // contains all points within the given distance of the boundary, and return the
 // smallest such rectangle. If the distance is negative, then instead shrink this
 // rectangle so that it excludes all points within the given absolute distance
@@ -835,7 +832,7 @@ func expandEndpoint(u, maxV, sinDist float64) float64 {
 // it contains all points within 5km of the original cell. You can then
 // test whether a point lies within the expanded bounds like this:
 //
-//	if u, v, ok := faceXYZtoUV(face, point); ok && bound.ContainsPoint(r2.Point{u,v}) { ... }
+//	if u, v, ok := FaceXYZToUV(face, point); ok && bound.ContainsPoint(r2.Point{u,v}) { ... }
 //
 // Limitations:
 //
@@ -848,7 +845,7 @@ func expandEndpoint(u, maxV, sinDist float64) float64 {
 //   - The implementation is not exact for negative distances. The resulting
 //     rectangle will exclude all points within the given distance of the
 //     boundary but may be slightly smaller than necessary.
-func expandedByDistanceUV(uv r2.Rect, distance s1.Angle) r2.Rect {
+func ExpandedByDistanceUV(uv r2.Rect, distance s1.Angle) r2.Rect {
 	// Expand each of the four sides of the rectangle just enough to include all
 	// points within the given distance of that side. (The rectangle may be
 	// expanded by a different amount in (u,v)-space on each side.)
@@ -913,13 +910,13 @@ func (ci CellID) centerFaceSiTi() (face, si, ti int) {
 	// First we compute the discrete (i,j) coordinates of a leaf cell contained
 	// within the given cell. Given that cells are represented by the Hilbert
 	// curve position corresponding at their center, it turns out that the cell
-	// returned by faceIJOrientation is always one of two leaf cells closest
+	// returned by FaceIJOrientation is always one of two leaf cells closest
 	// to the center of the cell (unless the given cell is a leaf cell itself,
 	// in which case there is only one possibility).
 	//
 	// Given a cell of size s >= 2 (i.e. not a leaf cell), and letting (imin,
 	// jmin) be the coordinates of its lower left-hand corner, the leaf cell
-	// returned by faceIJOrientation is either (imin + s/2, jmin + s/2)
+	// returned by FaceIJOrientation is either (imin + s/2, jmin + s/2)
 	// (imin + s/2 - 1, jmin + s/2 - 1). The first case is the one we want.
 	// We can distinguish these two cases by looking at the low bit of i or
 	// j. In the second case the low bit is one, unless s == 2 (i.e. the
@@ -927,7 +924,7 @@ func (ci CellID) centerFaceSiTi() (face, si, ti int) {
 	//
 	// In the code below, the expression ((i ^ (int(id) >> 2)) & 1) is true
 	// if we are in the second case described above.
-	face, i, j, _ := ci.faceIJOrientation()
+	face, i, j, _ := ci.FaceIJOrientation()
 	delta := 0
 	if ci.IsLeaf() {
 		delta = 1
@@ -205,7 +205,7 @@ func TestCellIDLatLng(t *testing.T) {
 func TestCellIDEdgeNeighbors(t *testing.T) {
 	// Check the edge neighbors of face 1.
 	faces := []int{5, 3, 2, 0}
-	for i, nbr := range cellIDFromFaceIJ(1, 0, 0).Parent(0).EdgeNeighbors() {
+	for i, nbr := range CellIDFromFaceIJ(1, 0, 0).Parent(0).EdgeNeighbors() {
 		if !nbr.isFace() {
 			t.Errorf("CellID(%d) is not a face", nbr)
 		}
@@ -217,15 +217,15 @@ func TestCellIDEdgeNeighbors(t *testing.T) {
 	// trickier because it requires projecting onto adjacent faces.
 	const maxIJ = MaxSize - 1
 	for level := 1; level <= MaxLevel; level++ {
-		id := cellIDFromFaceIJ(1, 0, 0).Parent(level)
+		id := CellIDFromFaceIJ(1, 0, 0).Parent(level)
 		// These neighbors were determined manually using the face and axis
 		// relationships.
-		levelSizeIJ := sizeIJ(level)
+		levelSizeIJ := SizeIJ(level)
 		want := []CellID{
-			cellIDFromFaceIJ(5, maxIJ, maxIJ).Parent(level),
-			cellIDFromFaceIJ(1, levelSizeIJ, 0).Parent(level),
-			cellIDFromFaceIJ(1, 0, levelSizeIJ).Parent(level),
-			cellIDFromFaceIJ(0, maxIJ, 0).Parent(level),
+			CellIDFromFaceIJ(5, maxIJ, maxIJ).Parent(level),
+			CellIDFromFaceIJ(1, levelSizeIJ, 0).Parent(level),
+			CellIDFromFaceIJ(1, 0, levelSizeIJ).Parent(level),
+			CellIDFromFaceIJ(0, maxIJ, 0).Parent(level),
 		}
 		for i, nbr := range id.EdgeNeighbors() {
 			if nbr != want[i] {
@@ -249,7 +249,7 @@ func TestCellIDVertexNeighbors(t *testing.T) {
 		if n == 0 || n == 3 {
 			j--
 		}
-		want := cellIDFromFaceIJ(2, i, j).Parent(5)
+		want := CellIDFromFaceIJ(2, i, j).Parent(5)
 
 		if nbr != want {
 			t.Errorf("CellID(%s).VertexNeighbors()[%d] = %v, want %v", id, n, nbr, want)
@@ -628,7 +628,7 @@ func TestCellIDDistanceToBegin(t *testing.T) {
 	}
 
 	for _, test := range tests {
-		if got := test.id.distanceFromBegin(); got != test.want {
+		if got := test.id.DistanceFromBegin(); got != test.want {
 			t.Errorf("%v.distanceToBegin() = %v, want %v", test.id, got, test.want)
 		}
 	}
@@ -636,7 +636,7 @@ func TestCellIDDistanceToBegin(t *testing.T) {
 	// Test that advancing from the beginning by the distance from a cell gets
 	// us back to that cell.
 	id := CellIDFromFacePosLevel(3, 0x12345678, MaxLevel-4)
-	if got := CellIDFromFace(0).ChildBeginAtLevel(id.Level()).Advance(id.distanceFromBegin()); got != id {
+	if got := CellIDFromFace(0).ChildBeginAtLevel(id.Level()).Advance(id.DistanceFromBegin()); got != id {
 		t.Errorf("advancing from the beginning by the distance of a cell should return us to that cell. got %v, want %v", got, id)
 	}
 }
@@ -793,12 +793,34 @@ func TestCellIDFaceSiTi(t *testing.T) {
 		want := uint32(1) << uint(level)
 		mask := uint32(1)<<(uint(level)+1) - 1
 
-		_, si, ti := id.Parent(l).faceSiTi()
+		_, si, ti := id.Parent(l).FaceSiTi()
 		if want != si&mask {
-			t.Errorf("CellID.Parent(%d).faceSiTi(), si = %b, want %b", l, si&mask, want)
+			t.Errorf("CellID.Parent(%d).FaceSiTi(), si = %b, want %b", l, si&mask, want)
 		}
 		if want != ti&mask {
-			t.Errorf("CellID.Parent(%d).faceSiTi(), ti = %b, want %b", l, ti&mask, want)
+			t.Errorf("CellID.Parent(%d).FaceSiTi(), ti = %b, want %b", l, ti&mask, want)
+		}
+	}
+}
+
+func TestCellIDFaceIJRoundTrip(t *testing.T) {
+	for iter := 0; iter < 1000; iter++ {
+		id := randomCellID()
+		face, i, j, _ := id.FaceIJOrientation()
+		if got := CellIDFromFaceIJ(face, i, j).Parent(id.Level()); got != id {
+			t.Errorf("CellIDFromFaceIJ(%d, %d, %d).Parent(%d) = %v, want %v", face, i, j, id.Level(), got, id)
+		}
+
+		// The cell's (s,t) bound has edge length SizeST and contains its center.
+		bound := id.BoundST()
+		if !float64Eq(bound.Size().X, SizeST(id.Level())) {
+			t.Errorf("%v.BoundST().Size().X = %v, want %v", id, bound.Size().X, SizeST(id.Level()))
+		}
+		if !bound.ContainsPoint(id.CenterST()) {
+			t.Errorf("%v.BoundST() = %v does not contain CenterST() = %v", id, bound, id.CenterST())
+		}
+		if !id.BoundUV().ContainsPoint(id.CenterUV()) {
+			t.Errorf("%v.BoundUV() = %v does not contain CenterUV() = %v", id, id.BoundUV(), id.CenterUV())
 		}
 	}
 }
@@ -829,12 +851,12 @@ func TestCellIDContinuity(t *testing.T) {
 
 		// Check that the rawPoint() returns the center of each cell
 		// in (s,t) coordinates.
-		_, u, v := xyzToFaceUV(id.rawPoint())
-		if !float64Eq(math.Remainder(uvToST(u), 0.5*cellSize), 0.0) {
-			t.Errorf("uvToST(%v) = %v, want %v", u, uvToST(u), 0.5*cellSize)
+		_, u, v := XYZToFaceUV(id.rawPoint())
+		if !float64Eq(math.Remainder(UVToST(u), 0.5*cellSize), 0.0) {
+			t.Errorf("UVToST(%v) = %v, want %v", u, UVToST(u), 0.5*cellSize)
 		}
-		if !float64Eq(math.Remainder(uvToST(v), 0.5*cellSize), 0.0) {
-			t.Errorf("uvToST(%v) = %v, want %v", v, uvToST(v), 0.5*cellSize)
+		if !float64Eq(math.Remainder(UVToST(v), 0.5*cellSize), 0.0) {
+			t.Errorf("UVToST(%v) = %v, want %v", v, UVToST(v), 0.5*cellSize)
 		}
 	}
 }
@@ -886,26 +908,26 @@ func TestCellIDExpandedByDistanceUV(t *testing.T) {
 		id := randomCellID()
 		distance := s1.Degree * s1.Angle(randomUniformFloat64(-maxDistDegrees, maxDistDegrees))
 
-		bound := id.boundUV()
-		expanded := expandedByDistanceUV(bound, distance)
+		bound := id.BoundUV()
+		expanded := ExpandedByDistanceUV(bound, distance)
 		for iter := 0; iter < 10; iter++ {
 			// Choose a point on the boundary of the rectangle.
 			face := randomUniformInt(6)
 			centerU, centerV := sampleBoundary(bound)
-			center := Point{faceUVToXYZ(face, centerU, centerV).Normalize()}
+			center := Point{FaceUVToXYZ(face, centerU, centerV).Normalize()}
 
 			// Now sample a point from a disc of radius (2 * distance).
 			p := samplePointFromCap(CapFromCenterHeight(center, 2*math.Abs(float64(distance))))
 
 			// Find the closest point on the boundary to the sampled point.
-			u, v, ok := faceXYZToUV(face, p)
+			u, v, ok := FaceXYZToUV(face, p)
 			if !ok {
 				continue
 			}
 
 			uv := r2.Point{X: u, Y: v}
 			closestUV := projectToBoundary(u, v, bound)
-			closest := faceUVToXYZ(face, closestUV.X, closestUV.Y).Normalize()
+			closest := FaceUVToXYZ(face, closestUV.X, closestUV.Y).Normalize()
 			actualDist := p.Distance(Point{closest})
 
 			if distance >= 0 {
@@ -913,7 +935,7 @@ func TestCellIDExpandedByDistanceUV(t *testing.T) {
 				// and also all points within distance of the boundary.
 				if bound.ContainsPoint(uv) || actualDist < distance {
 					if !expanded.ContainsPoint(uv) {
-						t.Errorf("expandedByDistanceUV(%v, %v).ContainsPoint(%v) = false, want true", bound, distance, uv)
+						t.Errorf("ExpandedByDistanceUV(%v, %v).ContainsPoint(%v) = false, want true", bound, distance, uv)
 					}
 				}
 			} else {
@@ -921,7 +943,7 @@ func TestCellIDExpandedByDistanceUV(t *testing.T) {
 				// of the original boundary.
 				if actualDist < -distance {
 					if expanded.ContainsPoint(uv) {
-						t.Errorf("negatively expandedByDistanceUV(%v, %v).ContainsPoint(%v) = true, want false", bound, distance, uv)
+						t.Errorf("negatively ExpandedByDistanceUV(%v, %v).ContainsPoint(%v) = true, want false", bound, distance, uv)
 					}
 				}
 			}
@@ -0,0 +1,215 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package s2
+
+import (
+	"sort"
+
+	"github.com/golang/geo/s1"
+)
+
+// ChainInterpolationResult is the result of interpolating a point along a
+// chain of edges.
+type ChainInterpolationResult struct {
+	// Point is the interpolated point on the chain.
+	Point Point
+	// EdgeID is the ID of the shape edge the point lies on. If the point
+	// falls exactly on a vertex shared by two edges, the earlier edge is
+	// returned.
+	EdgeID int
+	// Distance is the distance of the point from the start of the chain,
+	// clamped to the range [0, Length()].
+	Distance s1.Angle
+}
+
+// ChainInterpolationQuery is a helper for finding points on a Shape's edges
+// by the spherical distance along the edges from the start of the shape.
+//
+// The query operates either on a single chain of the shape, or on all of its
+// edges taken in order of their edge IDs. In the latter case the edges do not
+// need to be connected; the distance simply accumulates the lengths of all of
+// the edges in the shape, so gaps between chains are not counted.
+//
+// The typical use is with polyline shapes (Polyline, LaxPolyline), but loops
+// and polygons are also supported, in which case the distance is measured
+// along the loop boundary.
+//
+// Construction is O(n) in the number of edges, and each query is O(log n).
+//
+// For example:
+//
+//	q := NewChainInterpolationQuery(laxPolyline, 0)
+//	r, ok := q.AtFraction(0.25)
+//	// r.Point is a quarter of the way along the first chain.
+type ChainInterpolationQuery struct {
+	shape       Shape
+	firstEdgeID int
+
+	// cumulativeValues[i] is the distance from the start of the chain to the
+	// first vertex of edge firstEdgeID+i. The final element is the total length.
+	cumulativeValues []s1.Angle
+}
+
+// NewChainInterpolationQuery returns a query for the edges of the given
+// shape. If chainID is non-negative, only the edges of that chain are used,
+// otherwise all edges of the shape are used in order.
+func NewChainInterpolationQuery(shape Shape, chainID int) *ChainInterpolationQuery {
+	q := &ChainInterpolationQuery{shape: shape}
+	if shape == nil {
+		return q
+	}
+
+	start, end := 0, shape.NumEdges()
+	if chainID >= 0 {
+		chain := shape.Chain(chainID)
+		start, end = chain.Start, chain.Start+chain.Length
+	}
+	q.firstEdgeID = start
+
+	var cumulative s1.Angle
+	for i := start; i < end; i++ {
+		q.cumulativeValues = append(q.cumulativeValues, cumulative)
+		e := shape.Edge(i)
+		cumulative += e.V0.Distance(e.V1)
+	}
+	if len(q.cumulativeValues) > 0 {
+		q.cumulativeValues = append(q.cumulativeValues, cumulative)
+	}
+	return q
+}
+
+// Length returns the sum of the lengths of the edges used by this query.
+// The length is zero if there are no edges.
+func (q *ChainInterpolationQuery) Length() s1.Angle {
+	if len(q.cumulativeValues) == 0 {
+		return 0
+	}
+	return q.cumulativeValues[len(q.cumulativeValues)-1]
+}
+
+// LengthAtEdgeEnd returns the cumulative length along the chain up to the
+// end of the given edge. If the edge is not one of the edges used by this
+// query, an infinite angle is returned.
+func (q *ChainInterpolationQuery) LengthAtEdgeEnd(edgeID int) s1.Angle {
+	if len(q.cumulativeValues) == 0 {
+		return s1.InfAngle()
+	}
+	if edgeID < q.firstEdgeID || edgeID >= q.firstEdgeID+len(q.cumulativeValues)-1 {
+		return s1.InfAngle()
+	}
+	return q.cumulativeValues[edgeID-q.firstEdgeID+1]
+}
+
+// AtDistance returns the point located at the given distance along the
+// edges from the first vertex of the first edge. Distances less than zero
+// are snapped to the first vertex and distances greater than Length() are
+// snapped to the last vertex.
+//
+// The boolean is false if the query has no edges, in which case the result
+// is meaningless.
+func (q *ChainInterpolationQuery) AtDistance(distance s1.Angle) (ChainInterpolationResult, bool) {
+	n := len(q.cumulativeValues)
+	if n == 0 {
+		return ChainInterpolationResult{}, false
+	}
+
+	// Find the first cumulative value not less than distance.
+	i := sort.Search(n, func(i int) bool { return q.cumulativeValues[i] >= distance })
+
+	switch {
+	case i == 0:
+		// The first vertex of the chain at distance zero.
+		return ChainInterpolationResult{
+			Point:    q.shape.Edge(q.firstEdgeID).V0,
+			EdgeID:   q.firstEdgeID,
+			Distance: q.cumulativeValues[0],
+		}, true
+	case i == n:
+		// The distance is greater than the total length, so the result is
+		// snapped to the last vertex of the chain.
+		edgeID := q.firstEdgeID + n - 2
+		return ChainInterpolationResult{
+			Point:    q.shape.Edge(edgeID).V1,
+			EdgeID:   edgeID,
+			Distance: q.cumulativeValues[n-1],
+		}, true
+	}
+
+	edgeID := q.firstEdgeID + i - 1
+	e := q.shape.Edge(edgeID)
+	return ChainInterpolationResult{
+		Point:    PointOnLine(e.V0, e.V1, distance-q.cumulativeValues[i-1]),
+		EdgeID:   edgeID,
+		Distance: distance,
+	}, true
+}
+
+// AtFraction returns the point located at the given fraction of the total
+// length along the edges. Fractions outside [0, 1] are clamped to the
+// first and last vertices. See AtDistance for details.
+func (q *ChainInterpolationQuery) AtFraction(fraction float64) (ChainInterpolationResult, bool) {
+	return q.AtDistance(s1.Angle(fraction) * q.Length())
+}
+
+// Slice returns the vertices of the portion of the chain between the two
+// given fractions of its length. If beginFraction is greater than
+// endFraction, the points are returned in reverse order. The result is nil
+// if the query has no edges.
+//
+// The first and last points of the slice are the interpolated points at the
+// two fractions, and the remaining points are the chain vertices between
+// them. The result may contain consecutive duplicate points, for example
+// when the two fractions are equal or when a fraction falls exactly on a
+// vertex.
+func (q *ChainInterpolationQuery) Slice(beginFraction, endFraction float64) []Point {
+	var points []Point
+	q.AddSlice(beginFraction, endFraction, &points)
+	return points
+}
+
+// AddSlice appends the slice of the chain between the two fractions to the
+// given points. It reports false if the query has no edges, in which case
+// points is left unchanged. See Slice for details.
+func (q *ChainInterpolationQuery) AddSlice(beginFraction, endFraction float64, points *[]Point) bool {
+	if len(q.cumulativeValues) == 0 {
+		return false
+	}
+
+	reverse := beginFraction > endFraction
+	if reverse {
+		beginFraction, endFraction = endFraction, beginFraction
+	}
+
+	begin, _ := q.AtFraction(beginFraction)
+	end, _ := q.AtFraction(endFraction)
+
+	firstSize := len(*points)
+	*points = append(*points, begin.Point)
+	for edgeID := begin.EdgeID; edgeID < end.EdgeID; edgeID++ {
+		v := q.shape.Edge(edgeID).V1
+		if v != (*points)[len(*points)-1] {
+			*points = append(*points, v)
+		}
+	}
+	*points = append(*points, end.Point)
+
+	if reverse {
+		s := (*points)[firstSize:]
+		for i, j := 0, len(s)-1; i < j; i, j = i+1, j-1 {
+			s[i], s[j] = s[j], s[i]
+		}
+	}
+	return true
+}
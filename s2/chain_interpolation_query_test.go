@@ -0,0 +1,214 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package s2
+
+import (
+	"testing"
+
+	"github.com/golang/geo/s1"
+)
+
+func TestChainInterpolationQueryEmpty(t *testing.T) {
+	q := NewChainInterpolationQuery(LaxPolylineFromPoints(nil), -1)
+	if got := q.Length(); got != 0 {
+		t.Errorf("Length() = %v, want 0", got)
+	}
+	if _, ok := q.AtFraction(0.5); ok {
+		t.Errorf("AtFraction(0.5) on empty shape succeeded, want failure")
+	}
+	if got := q.Slice(0, 1); got != nil {
+		t.Errorf("Slice(0, 1) = %v, want nil", got)
+	}
+	if got := q.LengthAtEdgeEnd(0); got != s1.InfAngle() {
+		t.Errorf("LengthAtEdgeEnd(0) = %v, want %v", got, s1.InfAngle())
+	}
+
+	q = NewChainInterpolationQuery(nil, 0)
+	if _, ok := q.AtDistance(0); ok {
+		t.Errorf("AtDistance(0) on nil shape succeeded, want failure")
+	}
+}
+
+func TestChainInterpolationQuerySimplePolyline(t *testing.T) {
+	// A polyline along the equator with edges of 1, 2 and 1 degrees.
+	shape := makeLaxPolyline("0:0, 0:1, 0:3, 0:4")
+	q := NewChainInterpolationQuery(shape, -1)
+
+	if got, want := q.Length(), 4*s1.Degree; !float64Near(got.Radians(), want.Radians(), 1e-15) {
+		t.Errorf("Length() = %v, want %v", got, want)
+	}
+
+	tests := []struct {
+		fraction float64
+		point    Point
+		edgeID   int
+		distance s1.Angle
+	}{
+		{-0.5, parsePoint("0:0"), 0, 0},
+		{0, parsePoint("0:0"), 0, 0},
+		{0.125, parsePoint("0:0.5"), 0, 0.5 * s1.Degree},
+		{0.25, parsePoint("0:1"), 0, 1 * s1.Degree},
+		{0.5, parsePoint("0:2"), 1, 2 * s1.Degree},
+		{0.875, parsePoint("0:3.5"), 2, 3.5 * s1.Degree},
+		{1, parsePoint("0:4"), 2, 4 * s1.Degree},
+		{1.5, parsePoint("0:4"), 2, 4 * s1.Degree},
+	}
+	for _, test := range tests {
+		got, ok := q.AtFraction(test.fraction)
+		if !ok {
+			t.Errorf("AtFraction(%v) failed", test.fraction)
+			continue
+		}
+		if !pointsApproxEqual(got.Point, test.point, 1e-14) {
+			t.Errorf("AtFraction(%v).Point = %v, want %v", test.fraction, got.Point, test.point)
+		}
+		if got.EdgeID != test.edgeID {
+			t.Errorf("AtFraction(%v).EdgeID = %v, want %v", test.fraction, got.EdgeID, test.edgeID)
+		}
+		if !float64Near(got.Distance.Radians(), test.distance.Radians(), 1e-14) {
+			t.Errorf("AtFraction(%v).Distance = %v, want %v", test.fraction, got.Distance, test.distance)
+		}
+	}
+
+	for i, want := range []s1.Angle{1 * s1.Degree, 3 * s1.Degree, 4 * s1.Degree} {
+		if got := q.LengthAtEdgeEnd(i); !float64Near(got.Radians(), want.Radians(), 1e-15) {
+			t.Errorf("LengthAtEdgeEnd(%d) = %v, want %v", i, got, want)
+		}
+	}
+	if got := q.LengthAtEdgeEnd(3); got != s1.InfAngle() {
+		t.Errorf("LengthAtEdgeEnd(3) = %v, want %v", got, s1.InfAngle())
+	}
+}
+
+func TestChainInterpolationQueryAtDistance(t *testing.T) {
+	shape := makeLaxPolyline("0:0, 0:1, 0:3")
+	q := NewChainInterpolationQuery(shape, -1)
+
+	got, ok := q.AtDistance(1.5 * s1.Degree)
+	if !ok {
+		t.Fatalf("AtDistance(1.5°) failed")
+	}
+	if want := parsePoint("0:1.5"); !pointsApproxEqual(got.Point, want, 1e-14) {
+		t.Errorf("AtDistance(1.5°).Point = %v, want %v", got.Point, want)
+	}
+	if got.EdgeID != 1 {
+		t.Errorf("AtDistance(1.5°).EdgeID = %v, want 1", got.EdgeID)
+	}
+}
+
+func TestChainInterpolationQueryChains(t *testing.T) {
+	// Two loops of different sizes.
+	shape := LaxPolygonFromPoints([][]Point{
+		parsePoints("0:0, 0:1, 1:1"),
+		parsePoints("10:10, 10:12, 12:12"),
+	})
+
+	for chainID := 0; chainID < shape.NumChains(); chainID++ {
+		q := NewChainInterpolationQuery(shape, chainID)
+		chain := shape.Chain(chainID)
+
+		start, ok := q.AtFraction(0)
+		if !ok {
+			t.Fatalf("chain %d: AtFraction(0) failed", chainID)
+		}
+		if want := shape.ChainEdge(chainID, 0).V0; start.Point != want {
+			t.Errorf("chain %d: AtFraction(0).Point = %v, want %v", chainID, start.Point, want)
+		}
+		if start.EdgeID != chain.Start {
+			t.Errorf("chain %d: AtFraction(0).EdgeID = %v, want %v", chainID, start.EdgeID, chain.Start)
+		}
+
+		end, _ := q.AtFraction(1)
+		if want := shape.ChainEdge(chainID, chain.Length-1).V1; !pointsApproxEqual(end.Point, want, 1e-14) {
+			t.Errorf("chain %d: AtFraction(1).Point = %v, want %v", chainID, end.Point, want)
+		}
+		if want := chain.Start + chain.Length - 1; end.EdgeID != want {
+			t.Errorf("chain %d: AtFraction(1).EdgeID = %v, want %v", chainID, end.EdgeID, want)
+		}
+
+		var perimeter s1.Angle
+		for i := 0; i < chain.Length; i++ {
+			e := shape.ChainEdge(chainID, i)
+			perimeter += e.V0.Distance(e.V1)
+		}
+		if got := q.Length(); !float64Near(got.Radians(), perimeter.Radians(), 1e-15) {
+			t.Errorf("chain %d: Length() = %v, want %v", chainID, got, perimeter)
+		}
+	}
+
+	// Without a chain, the whole shape is used and the length is the sum.
+	all := NewChainInterpolationQuery(shape, -1)
+	want := NewChainInterpolationQuery(shape, 0).Length() + NewChainInterpolationQuery(shape, 1).Length()
+	if got := all.Length(); !float64Near(got.Radians(), want.Radians(), 1e-15) {
+		t.Errorf("Length() over all chains = %v, want %v", got, want)
+	}
+}
+
+func TestChainInterpolationQueryMatchesPolylineInterpolate(t *testing.T) {
+	polyline := makePolyline("0:0, 0:1, 1:2, 3:2, 4:-1")
+	q := NewChainInterpolationQuery(polyline, 0)
+
+	for _, f := range []float64{0, 0.1, 0.33, 0.5, 0.75, 0.99, 1} {
+		want, _ := polyline.Interpolate(f)
+		got, ok := q.AtFraction(f)
+		if !ok {
+			t.Errorf("AtFraction(%v) failed", f)
+			continue
+		}
+		if !pointsApproxEqual(got.Point, want, 1e-14) {
+			t.Errorf("AtFraction(%v).Point = %v, want %v", f, got.Point, want)
+		}
+	}
+}
+
+func TestChainInterpolationQuerySlice(t *testing.T) {
+	shape := makeLaxPolyline("0:0, 0:1, 0:2")
+	q := NewChainInterpolationQuery(shape, -1)
+
+	tests := []struct {
+		begin, end float64
+		want       string
+	}{
+		{0, 1, "0:0, 0:1, 0:2"},
+		{0, 0.5, "0:0, 0:1"},
+		{0.5, 1, "0:1, 0:2"},
+		{0.25, 0.75, "0:0.5, 0:1, 0:1.5"},
+		{1, 0, "0:2, 0:1, 0:0"},
+		{0.75, 0.25, "0:1.5, 0:1, 0:0.5"},
+		{0.25, 0.25, "0:0.5, 0:0.5"},
+	}
+	for _, test := range tests {
+		got := q.Slice(test.begin, test.end)
+		want := parsePoints(test.want)
+		if len(got) != len(want) {
+			t.Errorf("Slice(%v, %v) = %v, want %v", test.begin, test.end, got, want)
+			continue
+		}
+		for i := range got {
+			if !pointsApproxEqual(got[i], want[i], 1e-14) {
+				t.Errorf("Slice(%v, %v)[%d] = %v, want %v", test.begin, test.end, i, got[i], want[i])
+			}
+		}
+	}
+
+	// AddSlice appends to existing points.
+	points := parsePoints("10:10")
+	if !q.AddSlice(0, 0.5, &points) {
+		t.Fatalf("AddSlice(0, 0.5) failed")
+	}
+	if got, want := len(points), 3; got != want {
+		t.Errorf("len(points) after AddSlice = %d, want %d", got, want)
+	}
+}
@@ -14,6 +14,10 @@
 
 package s2
 
+import (
+	"sort"
+)
+
 // VertexModel defines whether shapes are considered to contain their vertices.
 // Note that these definitions differ from the ones used by BooleanOperation.
 //
@@ -175,6 +179,43 @@ func (q *ContainsPointQuery) visitContainingShapes(p Point, f shapeVisitorFunc)
 	return true
 }
 
+// visitContainingShapesOfPoints is like visitContainingShapes, but tests a
+// whole set of points. For every point that is contained by some shape, f
+// is called with the shape and the index of the point in the given slice;
+// a shape is visited once for each point it contains. Returns false if f
+// returned false, in which case the visiting stops early.
+//
+// Rather than locating each point from scratch, the points are sorted by
+// CellID and merge-joined with the cells of the index in a single forward
+// pass, so that consecutive points in the same index cell share one lookup.
+func (q *ContainsPointQuery) visitContainingShapesOfPoints(points []Point, f func(shape Shape, i int) bool) bool {
+	type idPoint struct {
+		id CellID
+		i  int
+	}
+	sorted := make([]idPoint, len(points))
+	for i, p := range points {
+		sorted[i] = idPoint{cellIDFromPoint(p), i}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].id < sorted[j].id })
+
+	q.iter.Begin()
+	for _, s := range sorted {
+		p := points[s.i]
+		if !q.iter.locatePointForward(p) {
+			continue
+		}
+		cell := q.iter.IndexCell()
+		for _, clipped := range cell.shapes {
+			if q.shapeContains(clipped, q.iter.Center(), p) &&
+				!f(q.index.Shape(clipped.shapeID), s.i) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
 // ContainingShapes returns a slice of all shapes that contain the given point.
 func (q *ContainsPointQuery) ContainingShapes(p Point) []Shape {
 	var shapes []Shape
@@ -20,9 +20,6 @@ import (
 	"github.com/golang/geo/r3"
 )
 
-// TODO(rsned): Rename this to coords.go to match the C++
-
-//
 // This file contains documentation of the various coordinate systems used
 // throughout the library. Most importantly, S2 defines a framework for
 // decomposing the unit sphere into a hierarchy of "cells". Each cell is a
@@ -153,55 +150,56 @@ const (
 	// magnitude less than two.
 	maxXYZtoUVError = 0.5 * dblEpsilon
 
-	// maxSiTi is the maximum value of an si- or ti-coordinate.
+	// MaxSiTi is the maximum value of an si- or ti-coordinate.
 	// It is one shift more than MaxSize. The range of valid (si,ti)
-	// values is [0..maxSiTi].
-	maxSiTi = MaxSize << 1
+	// values is [0..MaxSiTi].
+	MaxSiTi = MaxSize << 1
 )
 
-// siTiToST converts an si- or ti-value to the corresponding s- or t-value.
-// Value is capped at 1.0 because there is no ABSL_DCHECK in Go.
-func siTiToST(si uint32) float64 {
-	if si > maxSiTi {
+// SiTiToST converts an si- or ti-value to the corresponding s- or t-value.
+// Values greater than MaxSiTi are clamped to 1.0.
+func SiTiToST(si uint32) float64 {
+	if si > MaxSiTi {
 		return 1.0
 	}
-	return float64(si) / float64(maxSiTi)
+	return float64(si) / float64(MaxSiTi)
 }
 
-// stToSiTi converts the s- or t-value to the nearest si- or ti-coordinate.
+// STToSiTi converts the s- or t-value to the nearest si- or ti-coordinate.
 // The result may be outside the range of valid (si,ti)-values. Value of
 // 0.49999999999999994 (math.NextAfter(0.5, -1)), will be incorrectly rounded up.
-func stToSiTi(s float64) uint32 {
+func STToSiTi(s float64) uint32 {
 	if s < 0 {
-		return uint32(s*maxSiTi - 0.5)
+		return uint32(s*MaxSiTi - 0.5)
 	}
-	return uint32(s*maxSiTi + 0.5)
+	return uint32(s*MaxSiTi + 0.5)
 }
 
-// stToUV converts an s or t value to the corresponding u or v value.
+// STToUV converts an s or t value to the corresponding u or v value.
 // This is a non-linear transformation from [-1,1] to [-1,1] that
 // attempts to make the cell sizes more uniform.
 // This uses what the C++ version calls 'the quadratic transform'.
-func stToUV(s float64) float64 {
+func STToUV(s float64) float64 {
 	if s >= 0.5 {
 		return (1 / 3.) * (4*s*s - 1)
 	}
 	return (1 / 3.) * (1 - 4*(1-s)*(1-s))
 }
 
-// uvToST is the inverse of the stToUV transformation. Note that it
-// is not always true that uvToST(stToUV(x)) == x due to numerical
+// UVToST is the inverse of the STToUV transformation. Note that it
+// is not always true that UVToST(STToUV(x)) == x due to numerical
 // errors.
-func uvToST(u float64) float64 {
+func UVToST(u float64) float64 {
 	if u >= 0 {
 		return 0.5 * math.Sqrt(1+3*u)
 	}
 	return 1 - 0.5*math.Sqrt(1-3*u)
 }
 
-// face returns face ID from 0 to 5 containing the r. For points on the
-// boundary between faces, the result is arbitrary but deterministic.
-func face(r r3.Vector) int {
+// XYZToFace returns the face (from 0 to 5) containing the direction vector r,
+// i.e. the face whose normal is closest to r. For points on the boundary
+// between faces, the result is arbitrary but deterministic.
+func XYZToFace(r r3.Vector) int {
 	f := r.LargestComponent()
 	switch {
 	case f == r3.XAxis && r.X < 0:
@@ -214,23 +212,25 @@ func face(r r3.Vector) int {
 	return int(f)
 }
 
-// ijToSTMin converts the i- or j-index of a leaf cell to the minimum corresponding
+// IJToSTMin converts the i- or j-index of a leaf cell to the minimum corresponding
 // s- or t-value contained by that cell. The argument must be in the range
 // [0..2**30], i.e. up to one position beyond the normal range of valid leaf
 // cell indices.
-func ijToSTMin(i int) float64 {
+func IJToSTMin(i int) float64 {
 	return float64(i) / float64(MaxSize)
 }
 
-// stToIJ converts value in ST coordinates to a value in IJ coordinates.
-func stToIJ(s float64) int {
+// STToIJ converts an s- or t-value to the i- or j-index of the leaf cell
+// containing it, clamped to the range of valid leaf cell indices.
+func STToIJ(s float64) int {
 	return clampInt(int(math.Floor(MaxSize*s)), 0, MaxSize-1)
 }
 
-// validFaceXYZToUV given a valid face for the given point r (meaning that
-// dot product of r with the face normal is positive), returns
-// the corresponding u and v values, which may lie outside the range [-1,1].
-func validFaceXYZToUV(face int, r r3.Vector) (float64, float64) {
+// ValidFaceXYZToUV returns the u and v values, which may lie outside the
+// range [-1,1], of the direction vector r on the given face. The face must
+// be valid for r, meaning that the dot product of r with the face normal is
+// positive.
+func ValidFaceXYZToUV(face int, r r3.Vector) (float64, float64) {
 	switch face {
 	case 0:
 		return r.Y / r.X, r.Z / r.X
@@ -246,16 +246,17 @@ func validFaceXYZToUV(face int, r r3.Vector) (float64, float64) {
 	return -r.Y / r.Z, -r.X / r.Z
 }
 
-// xyzToFaceUV converts a direction vector (not necessarily unit length) to
+// XYZToFaceUV converts a direction vector (not necessarily unit length) to
 // (face, u, v) coordinates.
-func xyzToFaceUV(r r3.Vector) (f int, u, v float64) {
-	f = face(r)
-	u, v = validFaceXYZToUV(f, r)
+func XYZToFaceUV(r r3.Vector) (f int, u, v float64) {
+	f = XYZToFace(r)
+	u, v = ValidFaceXYZToUV(f, r)
 	return f, u, v
 }
 
-// faceUVToXYZ turns face and UV coordinates into an unnormalized 3 vector.
-func faceUVToXYZ(face int, u, v float64) r3.Vector {
+// FaceUVToXYZ converts (face, u, v) coordinates to a direction vector that
+// is not necessarily unit length.
+func FaceUVToXYZ(face int, u, v float64) r3.Vector {
 	switch face {
 	case 0:
 		return r3.Vector{X: 1, Y: u, Z: v}
@@ -272,9 +273,9 @@ func faceUVToXYZ(face int, u, v float64) r3.Vector {
 	}
 }
 
-// faceXYZToUV returns the u and v values (which may lie outside the range
+// FaceXYZToUV returns the u and v values (which may lie outside the range
 // [-1, 1]) if the dot product of the point p with the given face normal is positive.
-func faceXYZToUV(face int, p Point) (u, v float64, ok bool) {
+func FaceXYZToUV(face int, p Point) (u, v float64, ok bool) {
 	switch face {
 	case 0:
 		if p.X <= 0 {
@@ -302,13 +303,13 @@ func faceXYZToUV(face int, p Point) (u, v float64, ok bool) {
 		}
 	}
 
-	u, v = validFaceXYZToUV(face, p.Vector)
+	u, v = ValidFaceXYZToUV(face, p.Vector)
 	return u, v, true
 }
 
-// faceXYZtoUVW transforms the given point P to the (u,v,w) coordinate frame of the given
+// FaceXYZToUVW transforms the given point P to the (u,v,w) coordinate frame of the given
 // face where the w-axis represents the face normal.
-func faceXYZtoUVW(face int, p Point) Point {
+func FaceXYZToUVW(face int, p Point) Point {
 	// The result coordinates are simply the dot products of P with the (u,v,w)
 	// axes for the given face (see faceUVWAxes).
 	switch face {
@@ -327,45 +328,46 @@ func faceXYZtoUVW(face int, p Point) Point {
 	}
 }
 
-// faceSiTiToXYZ transforms the (si, ti) coordinates to a (not necessarily
+// FaceSiTiToXYZ transforms the (si, ti) coordinates to a (not necessarily
 // unit length) Point on the given face.
-func faceSiTiToXYZ(face int, si, ti uint32) Point {
-	return Point{faceUVToXYZ(face, stToUV(siTiToST(si)), stToUV(siTiToST(ti)))}
+func FaceSiTiToXYZ(face int, si, ti uint32) Point {
+	return Point{FaceUVToXYZ(face, STToUV(SiTiToST(si)), STToUV(SiTiToST(ti)))}
 }
 
-// xyzToFaceSiTi transforms the (not necessarily unit length) Point to
-// (face, si, ti) coordinates and the level the Point is at.
-func xyzToFaceSiTi(p Point) (face int, si, ti uint32, level int) {
-	face, u, v := xyzToFaceUV(p.Vector)
-	si = stToSiTi(uvToST(u))
-	ti = stToSiTi(uvToST(v))
+// XYZToFaceSiTi transforms the (not necessarily unit length) Point to
+// (face, si, ti) coordinates. It also returns the level of the cell whose
+// center is p, or -1 if p is not exactly the center of a cell.
+func XYZToFaceSiTi(p Point) (face int, si, ti uint32, level int) {
+	face, u, v := XYZToFaceUV(p.Vector)
+	si = STToSiTi(UVToST(u))
+	ti = STToSiTi(UVToST(v))
 
 	// If the levels corresponding to si,ti are not equal, then p is not a cell
-	// center. The si,ti values of 0 and maxSiTi need to be handled specially
+	// center. The si,ti values of 0 and MaxSiTi need to be handled specially
 	// because they do not correspond to cell centers at any valid level; they
 	// are mapped to level -1 by the code at the end.
-	level = MaxLevel - findLSBSetNonZero64(uint64(si|maxSiTi))
-	if level < 0 || level != MaxLevel-findLSBSetNonZero64(uint64(ti|maxSiTi)) {
+	level = MaxLevel - findLSBSetNonZero64(uint64(si|MaxSiTi))
+	if level < 0 || level != MaxLevel-findLSBSetNonZero64(uint64(ti|MaxSiTi)) {
 		return face, si, ti, -1
 	}
 
 	// In infinite precision, this test could be changed to ST == SiTi. However,
-	// due to rounding errors, uvToST(xyzToFaceUV(faceUVToXYZ(stToUV(...)))) is
+	// due to rounding errors, UVToST(XYZToFaceUV(FaceUVToXYZ(STToUV(...)))) is
 	// not idempotent. On the other hand, the center is computed exactly the same
 	// way p was originally computed (if it is indeed the center of a Cell);
 	// the comparison can be exact.
-	if p.Vector == faceSiTiToXYZ(face, si, ti).Normalize() {
+	if p.Vector == FaceSiTiToXYZ(face, si, ti).Normalize() {
 		return face, si, ti, level
 	}
 
 	return face, si, ti, -1
 }
 
-// uNorm returns the right-handed normal (not necessarily unit length) for an
+// UNorm returns the right-handed normal (not necessarily unit length) for an
 // edge in the direction of the positive v-axis at the given u-value on
 // the given face.  (This vector is perpendicular to the plane through
 // the sphere origin that contains the given edge.)
-func uNorm(face int, u float64) r3.Vector {
+func UNorm(face int, u float64) r3.Vector {
 	switch face {
 	case 0:
 		return r3.Vector{X: u, Y: -1, Z: 0}
@@ -382,10 +384,10 @@ func uNorm(face int, u float64) r3.Vector {
 	}
 }
 
-// vNorm returns the right-handed normal (not necessarily unit length) for an
+// VNorm returns the right-handed normal (not necessarily unit length) for an
 // edge in the direction of the positive u-axis at the given v-value on
 // the given face.
-func vNorm(face int, v float64) r3.Vector {
+func VNorm(face int, v float64) r3.Vector {
 	switch face {
 	case 0:
 		return r3.Vector{X: -v, Y: 0, Z: 1}
@@ -422,28 +424,28 @@ var faceUVWFaces = [6][3][2]int{
 	{{4, 1}, {3, 0}, {2, 5}},
 }
 
-// uvwAxis returns the given axis of the given face.
-func uvwAxis(face, axis int) Point {
+// UVWAxis returns the given axis of the given face.
+func UVWAxis(face, axis int) Point {
 	return faceUVWAxes[face][axis]
 }
 
-// uvwFace returns the face in the (u,v,w) coordinate system on the given axis
+// UVWFace returns the face in the (u,v,w) coordinate system on the given axis
 // in the given direction.
-func uvwFace(face, axis, direction int) int {
+func UVWFace(face, axis, direction int) int {
 	return faceUVWFaces[face][axis][direction]
 }
 
-// uAxis returns the u-axis for the given face.
-func uAxis(face int) Point {
-	return uvwAxis(face, 0)
+// UAxis returns the u-axis for the given face.
+func UAxis(face int) Point {
+	return UVWAxis(face, 0)
 }
 
-// vAxis returns the v-axis for the given face.
-func vAxis(face int) Point {
-	return uvwAxis(face, 1)
+// VAxis returns the v-axis for the given face.
+func VAxis(face int) Point {
+	return UVWAxis(face, 1)
 }
 
-// Return the unit-length normal for the given face.
-func unitNorm(face int) Point {
-	return uvwAxis(face, 2)
+// UnitNorm returns the unit-length normal for the given face.
+func UnitNorm(face int) Point {
+	return UVWAxis(face, 2)
 }
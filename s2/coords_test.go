@@ -70,25 +70,25 @@ func TestSTUVTraversalOrder(t *testing.T) {
 func TestSTUVConversions(t *testing.T) {
 	// Check boundary conditions.
 	for s := 0.0; s <= 1.0; s += 0.5 {
-		u := stToUV(s)
+		u := STToUV(s)
 		if want := 2*s - 1; !float64Eq(u, want) {
-			t.Errorf("stToUV(%f) = %f, want %f", s, u, want)
+			t.Errorf("STToUV(%f) = %f, want %f", s, u, want)
 		}
 	}
 	for u := -1.0; u <= 1.0; u++ {
-		s := uvToST(u)
+		s := UVToST(u)
 		if want := 0.5 * (u + 1); !float64Eq(s, want) {
-			t.Errorf("stToUV(%f) = %f, want %f", u, s, want)
+			t.Errorf("STToUV(%f) = %f, want %f", u, s, want)
 		}
 	}
 
-	// Check that uvToST and stToUV are inverses.
+	// Check that UVToST and STToUV are inverses.
 	for x := 0.0; x <= 1.0; x += 0.0001 {
-		if got := uvToST(stToUV(x)); !float64Near(got, x, 1e-15) {
-			t.Errorf("uvToST(stToUV(%f)) = %f, want %f", x, got, x)
+		if got := UVToST(STToUV(x)); !float64Near(got, x, 1e-15) {
+			t.Errorf("UVToST(STToUV(%f)) = %f, want %f", x, got, x)
 		}
-		if got, want := stToUV(uvToST(2*x-1)), 2*x-1; !float64Near(got, want, 1e-15) {
-			t.Errorf("stToUV(uvToST(%f)) = %f, want %f", x, got, want)
+		if got, want := STToUV(UVToST(2*x-1)), 2*x-1; !float64Near(got, want, 1e-15) {
+			t.Errorf("STToUV(UVToST(%f)) = %f, want %f", x, got, want)
 		}
 	}
 }
@@ -97,10 +97,10 @@ func TestUVNorms(t *testing.T) {
 	step := 1 / 1024.0
 	for face := 0; face < 6; face++ {
 		for x := -1.0; x <= 1; x += step {
-			if !float64Eq(float64(faceUVToXYZ(face, x, -1).Cross(faceUVToXYZ(face, x, 1)).Angle(uNorm(face, x))), 0.0) {
+			if !float64Eq(float64(FaceUVToXYZ(face, x, -1).Cross(FaceUVToXYZ(face, x, 1)).Angle(UNorm(face, x))), 0.0) {
 				t.Errorf("UNorm not orthogonal to the face(%d)", face)
 			}
-			if !float64Eq(float64(faceUVToXYZ(face, -1, x).Cross(faceUVToXYZ(face, 1, x)).Angle(vNorm(face, x))), 0.0) {
+			if !float64Eq(float64(FaceUVToXYZ(face, -1, x).Cross(FaceUVToXYZ(face, 1, x)).Angle(VNorm(face, x))), 0.0) {
 				t.Errorf("VNorm not orthogonal to the face(%d)", face)
 			}
 		}
@@ -111,9 +111,9 @@ func TestFaceUVToXYZ(t *testing.T) {
 	// Check that each face appears exactly once.
 	var sum r3.Vector
 	for face := 0; face < 6; face++ {
-		center := faceUVToXYZ(face, 0, 0)
-		if !center.ApproxEqual(unitNorm(face).Vector) {
-			t.Errorf("faceUVToXYZ(%d, 0, 0) != unitNorm(%d), should be equal", face, face)
+		center := FaceUVToXYZ(face, 0, 0)
+		if !center.ApproxEqual(UnitNorm(face).Vector) {
+			t.Errorf("FaceUVToXYZ(%d, 0, 0) != UnitNorm(%d), should be equal", face, face)
 		}
 		switch center.LargestComponent() {
 		case r3.XAxis:
@@ -132,8 +132,8 @@ func TestFaceUVToXYZ(t *testing.T) {
 		sum = sum.Add(center.Abs())
 
 		// Check that each face has a right-handed coordinate system.
-		if got := uAxis(face).Vector.Cross(vAxis(face).Vector).Dot(unitNorm(face).Vector); got != 1 {
-			t.Errorf("right-handed check failed. uAxis(%d).Cross(vAxis(%d)).Dot(unitNorm%v) = %f, want 1", face, face, face, got)
+		if got := UAxis(face).Vector.Cross(VAxis(face).Vector).Dot(UnitNorm(face).Vector); got != 1 {
+			t.Errorf("right-handed check failed. UAxis(%d).Cross(VAxis(%d)).Dot(UnitNorm%v) = %f, want 1", face, face, face, got)
 		}
 
 		// Check that the Hilbert curves on each face combine to form a
@@ -144,8 +144,8 @@ func TestFaceUVToXYZ(t *testing.T) {
 		if face&swapMask == 1 {
 			sign = -1
 		}
-		if faceUVToXYZ(face, sign, -sign) != faceUVToXYZ((face+1)%6, -1, -1) {
-			t.Errorf("faceUVToXYZ(%v, %v, %v) != faceUVToXYZ(%v, -1, -1)", face, sign, -sign, (face+1)%6)
+		if FaceUVToXYZ(face, sign, -sign) != FaceUVToXYZ((face+1)%6, -1, -1) {
+			t.Errorf("FaceUVToXYZ(%v, %v, %v) != FaceUVToXYZ(%v, -1, -1)", face, sign, -sign, (face+1)%6)
 		}
 	}
 
@@ -183,8 +183,8 @@ func TestFaceXYZToUV(t *testing.T) {
 	}
 
 	for _, test := range tests {
-		if u, v, ok := faceXYZToUV(test.face, test.point); !float64Eq(u, test.u) || !float64Eq(v, test.v) || ok != test.ok {
-			t.Errorf("faceXYZToUV(%d, %v) = %f, %f, %t, want %f, %f, %t", test.face, test.point, u, v, ok, test.u, test.v, test.ok)
+		if u, v, ok := FaceXYZToUV(test.face, test.point); !float64Eq(u, test.u) || !float64Eq(v, test.v) || ok != test.ok {
+			t.Errorf("FaceXYZToUV(%d, %v) = %f, %f, %t, want %f, %f, %t", test.face, test.point, u, v, ok, test.u, test.v, test.ok)
 		}
 	}
 }
@@ -201,32 +201,32 @@ func TestFaceXYZtoUVW(t *testing.T) {
 	)
 
 	for face := 0; face < 6; face++ {
-		if got := faceXYZtoUVW(face, origin); got != origin {
-			t.Errorf("faceXYZtoUVW(%d, %v) = %v, want %v", face, origin, got, origin)
+		if got := FaceXYZToUVW(face, origin); got != origin {
+			t.Errorf("FaceXYZToUVW(%d, %v) = %v, want %v", face, origin, got, origin)
 		}
 
-		if got := faceXYZtoUVW(face, uAxis(face)); got != posX {
-			t.Errorf("faceXYZtoUVW(%d, %v) = %v, want %v", face, uAxis(face), got, posX)
+		if got := FaceXYZToUVW(face, UAxis(face)); got != posX {
+			t.Errorf("FaceXYZToUVW(%d, %v) = %v, want %v", face, UAxis(face), got, posX)
 		}
 
-		if got := faceXYZtoUVW(face, Point{uAxis(face).Mul(-1)}); got != negX {
-			t.Errorf("faceXYZtoUVW(%d, %v) = %v, want %v", face, uAxis(face).Mul(-1), got, negX)
+		if got := FaceXYZToUVW(face, Point{UAxis(face).Mul(-1)}); got != negX {
+			t.Errorf("FaceXYZToUVW(%d, %v) = %v, want %v", face, UAxis(face).Mul(-1), got, negX)
 		}
 
-		if got := faceXYZtoUVW(face, vAxis(face)); got != posY {
-			t.Errorf("faceXYZtoUVW(%d, %v) = %v, want %v", face, vAxis(face), got, posY)
+		if got := FaceXYZToUVW(face, VAxis(face)); got != posY {
+			t.Errorf("FaceXYZToUVW(%d, %v) = %v, want %v", face, VAxis(face), got, posY)
 		}
 
-		if got := faceXYZtoUVW(face, Point{vAxis(face).Mul(-1)}); got != negY {
-			t.Errorf("faceXYZtoUVW(%d, %v) = %v, want %v", face, vAxis(face).Mul(-1), got, negY)
+		if got := FaceXYZToUVW(face, Point{VAxis(face).Mul(-1)}); got != negY {
+			t.Errorf("FaceXYZToUVW(%d, %v) = %v, want %v", face, VAxis(face).Mul(-1), got, negY)
 		}
 
-		if got := faceXYZtoUVW(face, unitNorm(face)); got != posZ {
-			t.Errorf("faceXYZtoUVW(%d, %v) = %v, want %v", face, unitNorm(face), got, posZ)
+		if got := FaceXYZToUVW(face, UnitNorm(face)); got != posZ {
+			t.Errorf("FaceXYZToUVW(%d, %v) = %v, want %v", face, UnitNorm(face), got, posZ)
 		}
 
-		if got := faceXYZtoUVW(face, Point{unitNorm(face).Mul(-1)}); got != negZ {
-			t.Errorf("faceXYZtoUVW(%d, %v) = %v, want %v", face, unitNorm(face).Mul(-1), got, negZ)
+		if got := FaceXYZToUVW(face, Point{UnitNorm(face).Mul(-1)}); got != negZ {
+			t.Errorf("FaceXYZToUVW(%d, %v) = %v, want %v", face, UnitNorm(face).Mul(-1), got, negZ)
 		}
 	}
 }
@@ -234,30 +234,30 @@ func TestFaceXYZtoUVW(t *testing.T) {
 func TestUVWAxis(t *testing.T) {
 	for face := 0; face < 6; face++ {
 		// Check that the axes are consistent with faceUVtoXYZ.
-		if faceUVToXYZ(face, 1, 0).Sub(faceUVToXYZ(face, 0, 0)) != uAxis(face).Vector {
-			t.Errorf("face 1,0 - face 0,0 should equal uAxis")
+		if FaceUVToXYZ(face, 1, 0).Sub(FaceUVToXYZ(face, 0, 0)) != UAxis(face).Vector {
+			t.Errorf("face 1,0 - face 0,0 should equal UAxis")
 		}
-		if faceUVToXYZ(face, 0, 1).Sub(faceUVToXYZ(face, 0, 0)) != vAxis(face).Vector {
-			t.Errorf("faceUVToXYZ(%d, 0, 1).Sub(faceUVToXYZ(%d, 0, 0)) != vAxis(%d), should be equal.", face, face, face)
+		if FaceUVToXYZ(face, 0, 1).Sub(FaceUVToXYZ(face, 0, 0)) != VAxis(face).Vector {
+			t.Errorf("FaceUVToXYZ(%d, 0, 1).Sub(FaceUVToXYZ(%d, 0, 0)) != VAxis(%d), should be equal.", face, face, face)
 		}
-		if faceUVToXYZ(face, 0, 0) != unitNorm(face).Vector {
-			t.Errorf("faceUVToXYZ(%d, 0, 0) != unitNorm(%d), should be equal", face, face)
+		if FaceUVToXYZ(face, 0, 0) != UnitNorm(face).Vector {
+			t.Errorf("FaceUVToXYZ(%d, 0, 0) != UnitNorm(%d), should be equal", face, face)
 		}
 
 		// Check that every face coordinate frame is right-handed.
-		if got := uAxis(face).Vector.Cross(vAxis(face).Vector).Dot(unitNorm(face).Vector); got != 1 {
+		if got := UAxis(face).Vector.Cross(VAxis(face).Vector).Dot(UnitNorm(face).Vector); got != 1 {
 			t.Errorf("right-handed check failed. got %f, want 1", got)
 		}
 
 		// Check that GetUVWAxis is consistent with GetUAxis, GetVAxis, GetNorm.
-		if uAxis(face) != uvwAxis(face, 0) {
-			t.Errorf("uAxis(%d) != uvwAxis(%d, 0), should be equal", face, face)
+		if UAxis(face) != UVWAxis(face, 0) {
+			t.Errorf("UAxis(%d) != UVWAxis(%d, 0), should be equal", face, face)
 		}
-		if vAxis(face) != uvwAxis(face, 1) {
-			t.Errorf("vAxis(%d) != uvwAxis(%d, 1), should be equal", face, face)
+		if VAxis(face) != UVWAxis(face, 1) {
+			t.Errorf("VAxis(%d) != UVWAxis(%d, 1), should be equal", face, face)
 		}
-		if unitNorm(face) != uvwAxis(face, 2) {
-			t.Errorf("unitNorm(%d) != uvwAxis(%d, 2), should be equal", face, face)
+		if UnitNorm(face) != UVWAxis(face, 2) {
+			t.Errorf("UnitNorm(%d) != UVWAxis(%d, 2), should be equal", face, face)
 		}
 	}
 }
@@ -265,9 +265,9 @@ func TestUVWAxis(t *testing.T) {
 func TestSiTiSTRoundtrip(t *testing.T) {
 	// test int -> float -> int direction.
 	for i := 0; i < 1000; i++ {
-		si := uint32(randomUniformInt(maxSiTi))
-		if got := stToSiTi(siTiToST(si)); got != si {
-			t.Errorf("stToSiTi(siTiToST(%v)) = %v, want %v", si, got, si)
+		si := uint32(randomUniformInt(MaxSiTi))
+		if got := STToSiTi(SiTiToST(si)); got != si {
+			t.Errorf("STToSiTi(SiTiToST(%v)) = %v, want %v", si, got, si)
 		}
 	}
 	// test float -> int -> float direction.
@@ -275,21 +275,21 @@ func TestSiTiSTRoundtrip(t *testing.T) {
 		st := randomUniformFloat64(0, 1.0)
 		// this uses near not exact because there is some loss in precision
 		// when scaling down to the nearest 1/MaxLevel and back.
-		if got := siTiToST(stToSiTi(st)); !float64Near(got, st, 1e-8) {
-			t.Errorf("siTiToST(stToSiTi(%v)) = %v, want %v", st, got, st)
+		if got := SiTiToST(STToSiTi(st)); !float64Near(got, st, 1e-8) {
+			t.Errorf("SiTiToST(STToSiTi(%v)) = %v, want %v", st, got, st)
 		}
 	}
 }
 
 func TestUVWFace(t *testing.T) {
-	// Check that uvwFace is consistent with uvwAxis.
+	// Check that UVWFace is consistent with UVWAxis.
 	for f := 0; f < 6; f++ {
 		for axis := 0; axis < 3; axis++ {
-			if got, want := face(uvwAxis(f, axis).Mul(-1)), uvwFace(f, axis, 0); got != want {
-				t.Errorf("face(%v) in positive direction = %v, want %v", uvwAxis(f, axis).Mul(-1), got, want)
+			if got, want := XYZToFace(UVWAxis(f, axis).Mul(-1)), UVWFace(f, axis, 0); got != want {
+				t.Errorf("XYZToFace(%v) in positive direction = %v, want %v", UVWAxis(f, axis).Mul(-1), got, want)
 			}
-			if got, want := face(uvwAxis(f, axis).Vector), uvwFace(f, axis, 1); got != want {
-				t.Errorf("face(%v) in negative direction = %v, want %v", uvwAxis(f, axis), got, want)
+			if got, want := XYZToFace(UVWAxis(f, axis).Vector), UVWFace(f, axis, 1); got != want {
+				t.Errorf("XYZToFace(%v) in negative direction = %v, want %v", UVWAxis(f, axis), got, want)
 			}
 		}
 	}
@@ -299,18 +299,18 @@ func TestXYZToFaceSiTi(t *testing.T) {
 	for level := 0; level < MaxLevel; level++ {
 		for i := 0; i < 1000; i++ {
 			ci := randomCellIDForLevel(level)
-			f, si, ti, gotLevel := xyzToFaceSiTi(ci.Point())
+			f, si, ti, gotLevel := XYZToFaceSiTi(ci.Point())
 			if gotLevel != level {
 				t.Errorf("level of CellID %v = %v, want %v", ci, gotLevel, level)
 			}
-			gotID := cellIDFromFaceIJ(f, int(si/2), int(ti/2)).Parent(level)
+			gotID := CellIDFromFaceIJ(f, int(si/2), int(ti/2)).Parent(level)
 			if gotID != ci {
 				t.Errorf("CellID = %b, want %b", gotID, ci)
 			}
 
 			// Test a point near the cell center but not equal to it.
 			pMoved := ci.Point().Add(r3.Vector{X: 1e-13, Y: 1e-13, Z: 1e-13})
-			fMoved, siMoved, tiMoved, gotLevel := xyzToFaceSiTi(Point{pMoved})
+			fMoved, siMoved, tiMoved, gotLevel := XYZToFaceSiTi(Point{pMoved})
 
 			if gotLevel != -1 {
 				t.Errorf("level of %v = %v, want %v", pMoved, gotLevel, -1)
@@ -334,34 +334,34 @@ func TestXYZToFaceSiTi(t *testing.T) {
 			mask := -1 << uint32(MaxLevel-level)
 			siRandom := randomUint32() & uint32(mask)
 			tiRandom := randomUint32() & uint32(mask)
-			for siRandom > maxSiTi || tiRandom > maxSiTi {
+			for siRandom > MaxSiTi || tiRandom > MaxSiTi {
 				siRandom = randomUint32() & uint32(mask)
 				tiRandom = randomUint32() & uint32(mask)
 			}
 
-			pRandom := faceSiTiToXYZ(faceRandom, siRandom, tiRandom)
-			f, si, ti, gotLevel = xyzToFaceSiTi(pRandom)
+			pRandom := FaceSiTiToXYZ(faceRandom, siRandom, tiRandom)
+			f, si, ti, gotLevel = XYZToFaceSiTi(pRandom)
 
 			// The chosen point is on the edge of a top-level face cell.
 			if f != faceRandom {
 				if gotLevel != -1 {
 					t.Errorf("level of random CellID = %v, want %v", gotLevel, -1)
 				}
-				if si != 0 && si != maxSiTi && ti != 0 && ti != maxSiTi {
-					t.Errorf("face %d, si = %v, ti = %v, want 0 or %v for both", f, si, ti, maxSiTi)
+				if si != 0 && si != MaxSiTi && ti != 0 && ti != MaxSiTi {
+					t.Errorf("face %d, si = %v, ti = %v, want 0 or %v for both", f, si, ti, MaxSiTi)
 				}
 				continue
 			}
 
 			if siRandom != si {
-				t.Errorf("xyzToFaceSiTi(%v).si = %v, want %v", pRandom, siRandom, si)
+				t.Errorf("XYZToFaceSiTi(%v).si = %v, want %v", pRandom, siRandom, si)
 			}
 			if tiRandom != ti {
-				t.Errorf("xyzToFaceSiTi(%v).ti = %v, want %v", pRandom, tiRandom, ti)
+				t.Errorf("XYZToFaceSiTi(%v).ti = %v, want %v", pRandom, tiRandom, ti)
 			}
 			if gotLevel >= 0 {
-				if got := cellIDFromFaceIJ(f, int(si/2), int(ti/2)).Parent(gotLevel).Point(); !pRandom.ApproxEqual(got) {
-					t.Errorf("cellIDFromFaceIJ(%d, %d, %d).Parent(%d) = %v, want %v", f, si/2, ti/2, gotLevel, got, pRandom)
+				if got := CellIDFromFaceIJ(f, int(si/2), int(ti/2)).Parent(gotLevel).Point(); !pRandom.ApproxEqual(got) {
+					t.Errorf("CellIDFromFaceIJ(%d, %d, %d).Parent(%d) = %v, want %v", f, si/2, ti/2, gotLevel, got, pRandom)
 				}
 			}
 		}
@@ -372,10 +372,10 @@ func TestXYZFaceSiTiRoundtrip(t *testing.T) {
 	for level := 0; level < MaxLevel; level++ {
 		for i := 0; i < 1000; i++ {
 			ci := randomCellIDForLevel(level)
-			f, si, ti, _ := xyzToFaceSiTi(ci.Point())
-			op := faceSiTiToXYZ(f, si, ti)
+			f, si, ti, _ := XYZToFaceSiTi(ci.Point())
+			op := FaceSiTiToXYZ(f, si, ti)
 			if !ci.Point().ApproxEqual(op) {
-				t.Errorf("faceSiTiToXYZ(xyzToFaceSiTi(%v)) = %v, want %v", ci.Point(), op, ci.Point())
+				t.Errorf("FaceSiTiToXYZ(XYZToFaceSiTi(%v)) = %v, want %v", ci.Point(), op, ci.Point())
 			}
 		}
 	}
@@ -416,8 +416,8 @@ func TestSTUVFace(t *testing.T) {
 	}
 
 	for _, test := range tests {
-		if got := face(test.v); got != test.want {
-			t.Errorf("face(%v) = %d, want %d", test.v, got, test.want)
+		if got := XYZToFace(test.v); got != test.want {
+			t.Errorf("XYZToFace(%v) = %d, want %d", test.v, got, test.want)
 		}
 	}
 }
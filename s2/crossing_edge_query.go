@@ -299,7 +299,7 @@ func (c *CrossingEdgeQuery) getCellsForEdge(a, b Point) {
 // edge that are descendants of pcell and adds them to this queries set of cells.
 func (c *CrossingEdgeQuery) computeCellsIntersected(pcell *PaddedCell, edgeBound r2.Rect) {
 
-	c.iter.seek(pcell.id.RangeMin())
+	c.iter.Seek(pcell.id.RangeMin())
 	if c.iter.Done() || c.iter.CellID() > pcell.id.RangeMax() {
 		// The index does not contain pcell or any of its descendants.
 		return
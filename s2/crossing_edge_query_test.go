@@ -237,8 +237,8 @@ func TestCrossingEdgeQueryCrossingCandidatesPerturbedCubeEdges(t *testing.T) {
 		u := scale*2*float64(randomUniformInt(2)) - 1
 		v := scale*2*float64(randomUniformInt(2)) - 1
 
-		a := Point{faceUVToXYZ(face, u, v)}
-		b := Point{a.Sub(unitNorm(face).Mul(2))}
+		a := Point{FaceUVToXYZ(face, u, v)}
+		b := Point{a.Sub(UnitNorm(face).Mul(2))}
 		// TODO(roberts): This test is currently slow because *every* crossing test
 		// needs to invoke ExpensiveSign.
 		edges := generatePerturbedSubEdges(a, b, 30)
@@ -253,9 +253,9 @@ func TestCrossingEdgeQueryCandidatesPerturbedCubeFaceAxes(t *testing.T) {
 	for iter := 0; iter < 5; iter++ {
 		face := randomUniformInt(6)
 		scale := math.Pow(1e-15, randomFloat64())
-		axis := uvwAxis(face, randomUniformInt(2))
-		a := Point{axis.Mul(scale).Add(unitNorm(face).Vector)}
-		b := Point{axis.Mul(scale).Sub(unitNorm(face).Vector)}
+		axis := UVWAxis(face, randomUniformInt(2))
+		a := Point{axis.Mul(scale).Add(UnitNorm(face).Vector)}
+		b := Point{axis.Mul(scale).Sub(UnitNorm(face).Vector)}
 		edges := generatePerturbedSubEdges(a, b, 30)
 		testCrossingEdgeQueryAllCrossings(t, edges)
 	}
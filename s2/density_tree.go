@@ -0,0 +1,646 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package s2
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+)
+
+const (
+	// densityTreeChildBits is the number of low bits of each encoded node
+	// that hold the mask of the node's children.
+	densityTreeChildBits = 4
+
+	// maxDensityTreeWeight is the largest weight a cell can have, so that
+	// the weight and the child mask fit in one varint.
+	maxDensityTreeWeight = math.MaxInt64 >> densityTreeChildBits
+)
+
+// DensityTree is a tree of cells, each with a non-negative weight, that
+// approximates the density of some collection of features over the sphere.
+// The weight of a cell is typically the total weight of the features that
+// intersect it, so the weights of a cell's children may add up to more than
+// the weight of the cell itself (see Normalize).
+//
+// The tree contains the face cells with non-zero weight, and a cell's
+// children with non-zero weight are either all present or all absent. A cell
+// without children is a leaf; the density within it is unknown beyond its
+// total weight.
+//
+// Density trees are typically used to divide the sphere into shards with a
+// roughly equal load:
+//
+//	tree, err := DensityTreeFromVertices(index, 10000, 16)
+//	...
+//	shards := tree.Normalize().Partition(tree.TotalWeight() / numShards)
+//
+// Trees are built breadth-first, one level at a time, until the next level
+// would make the encoded tree larger than a given approximate size in bytes,
+// or the given maximum level is reached.
+type DensityTree struct {
+	weights map[CellID]int64
+}
+
+// DensityWeightFunc returns the weight of the given cell, and whether the
+// cell should not be subdivided further (for example because the weights of
+// its children would all be the same as its own weight). A weight of zero
+// means that the cell is not part of the tree.
+type DensityWeightFunc func(cell Cell) (weight int64, terminate bool)
+
+// DensityTreeFromWeights builds a density tree with the weights returned by
+// the given function, which is called for every cell that is considered for
+// the tree. The encoded size of the tree is approximately at most
+// approxSizeBytes, and its cells are at most maxLevel. An error is returned
+// if maxLevel is invalid or the function returns an invalid weight.
+func DensityTreeFromWeights(weight DensityWeightFunc, approxSizeBytes int64, maxLevel int) (*DensityTree, error) {
+	if maxLevel < 0 || maxLevel > MaxLevel {
+		return nil, fmt.Errorf("density tree max level %d is not in [0, %d]", maxLevel, MaxLevel)
+	}
+
+	type densityCell struct {
+		id     CellID
+		weight int64
+	}
+
+	t := &DensityTree{weights: make(map[CellID]int64)}
+	var frontier []CellID
+	for face := 0; face < NumFaces; face++ {
+		frontier = append(frontier, CellIDFromFace(face))
+	}
+	for level := 0; len(frontier) > 0; level++ {
+		var cells []densityCell
+		var next []CellID
+		for _, id := range frontier {
+			w, terminate := weight(CellFromCellID(id))
+			if w < 0 || w > maxDensityTreeWeight {
+				return nil, fmt.Errorf("density tree weight %d of cell %v is not in [0, %d]", w, id, int64(maxDensityTreeWeight))
+			}
+			if w == 0 {
+				continue
+			}
+			cells = append(cells, densityCell{id, w})
+			if !terminate && level < maxLevel {
+				ch := id.Children()
+				next = append(next, ch[:]...)
+			}
+		}
+		for _, c := range cells {
+			t.weights[c.id] = c.weight
+		}
+		// The face cells are always kept, so that the tree is never empty
+		// unless all of the weights are zero.
+		if level > 0 && t.encodedSize() > approxSizeBytes {
+			for _, c := range cells {
+				delete(t.weights, c.id)
+			}
+			break
+		}
+		frontier = next
+	}
+	return t, nil
+}
+
+// DensityTreeFromVertices builds a density tree where the weight of each
+// cell is the number of vertices of the shapes in the index that it
+// contains. (For polygons, each loop vertex is counted once.)
+func DensityTreeFromVertices(index *ShapeIndex, approxSizeBytes int64, maxLevel int) (*DensityTree, error) {
+	var vertices []CellID
+	for _, shape := range index.shapes {
+		for i := 0; i < shape.NumChains(); i++ {
+			chain := shape.Chain(i)
+			for j := 0; j < chain.Length; j++ {
+				vertices = append(vertices, cellIDFromPoint(shape.ChainEdge(i, j).V0))
+			}
+			// Open polylines have one more vertex than edges.
+			if shape.Dimension() == 1 && chain.Length > 0 {
+				last := shape.ChainEdge(i, chain.Length-1).V1
+				if first := shape.ChainEdge(i, 0).V0; last != first {
+					vertices = append(vertices, cellIDFromPoint(last))
+				}
+			}
+		}
+	}
+	sort.Slice(vertices, func(i, j int) bool { return vertices[i] < vertices[j] })
+
+	return DensityTreeFromWeights(func(cell Cell) (int64, bool) {
+		id := cell.ID()
+		begin := sort.Search(len(vertices), func(i int) bool { return vertices[i] >= id.RangeMin() })
+		end := sort.Search(len(vertices), func(i int) bool { return vertices[i] > id.RangeMax() })
+		// There is nothing to gain by subdividing a cell with one vertex.
+		return int64(end - begin), end-begin <= 1
+	}, approxSizeBytes, maxLevel)
+}
+
+// DensityTreeFromShapes builds a density tree where the weight of each cell
+// is the total weight of the shapes in the index that intersect it, as given
+// by the weight function.
+func DensityTreeFromShapes(index *ShapeIndex, weight func(shape Shape) int64, approxSizeBytes int64, maxLevel int) (*DensityTree, error) {
+	index.maybeApplyUpdates()
+	it := index.Iterator()
+	query := NewContainsPointQuery(index, VertexModelSemiOpen)
+	return DensityTreeFromWeights(func(cell Cell) (int64, bool) {
+		sum := int64(0)
+		visitIntersectingShapes(it, query, cell, func(shapeID int32) {
+			sum += weight(index.Shape(shapeID))
+		})
+		return sum, false
+	}, approxSizeBytes, maxLevel)
+}
+
+// visitIntersectingShapes calls visit once with the ID of each shape in the
+// iterator's index that intersects the given cell. Shapes whose boundary
+// comes within the worst-case error tolerance of the cell may also be
+// visited.
+func visitIntersectingShapes(it *ShapeIndexIterator, query *ContainsPointQuery, cell Cell, visit func(shapeID int32)) {
+	target := cell.ID()
+	switch it.LocateCellID(target) {
+	case Disjoint:
+		return
+	case Subdivided:
+		// Every shape in an index cell contained by the target intersects it.
+		seen := make(map[int32]bool)
+		for ; !it.Done() && it.CellID() <= target.RangeMax(); it.Next() {
+			for _, clipped := range it.IndexCell().shapes {
+				if !seen[clipped.shapeID] {
+					seen[clipped.shapeID] = true
+					visit(clipped.shapeID)
+				}
+			}
+		}
+		return
+	}
+
+	// The target is contained by the index cell, so check whether each of
+	// its shapes has an edge that intersects the target, or else contains it.
+	maxError := faceClipErrorUVCoord + intersectsRectErrorUVDist
+	bound := cell.BoundUV().ExpandedByMargin(maxError)
+	center := it.Center()
+	for _, clipped := range it.IndexCell().shapes {
+		shape := it.index.Shape(clipped.shapeID)
+		intersects := false
+		for _, e := range clipped.edges {
+			edge := shape.Edge(e)
+			v0, v1, ok := ClipToPaddedFace(edge.V0, edge.V1, cell.Face(), maxError)
+			if ok && edgeIntersectsRect(v0, v1, bound) {
+				intersects = true
+				break
+			}
+		}
+		if intersects || query.shapeContains(clipped, center, cell.Center()) {
+			visit(clipped.shapeID)
+		}
+	}
+}
+
+// DensityTreeFromCellUnions builds a density tree where the weight of each
+// cell is the total weight of the given cell unions that intersect it, where
+// weights[i] is the weight of unions[i].
+func DensityTreeFromCellUnions(unions []CellUnion, weights []int64, approxSizeBytes int64, maxLevel int) (*DensityTree, error) {
+	if len(unions) != len(weights) {
+		return nil, fmt.Errorf("got %d cell unions but %d weights", len(unions), len(weights))
+	}
+	normalized := make([]CellUnion, len(unions))
+	var index CellIndex
+	for i, cu := range unions {
+		normalized[i] = append(CellUnion(nil), cu...)
+		normalized[i].Normalize()
+		index.AddCellUnion(normalized[i], int32(i))
+	}
+	index.Build()
+
+	return DensityTreeFromWeights(func(cell Cell) (int64, bool) {
+		sum := int64(0)
+		terminate := true
+		for _, label := range index.IntersectingLabels(CellUnion{cell.ID()}) {
+			sum += weights[label]
+			// Once every intersecting union contains the cell, all of its
+			// descendants have the same weight.
+			if !normalized[label].ContainsCellID(cell.ID()) {
+				terminate = false
+			}
+		}
+		return sum, terminate
+	}, approxSizeBytes, maxLevel)
+}
+
+// DensityTreeFromSum builds a density tree where the weight of each cell is
+// the sum of its weights in the given trees. A cell that lies within a leaf
+// of one of the trees is given the weight of that leaf.
+func DensityTreeFromSum(trees []*DensityTree, approxSizeBytes int64, maxLevel int) (*DensityTree, error) {
+	return DensityTreeFromWeights(func(cell Cell) (int64, bool) {
+		sum := int64(0)
+		terminate := true
+		for _, t := range trees {
+			w, leaf := t.estimatedWeight(cell.ID())
+			sum += w
+			if !leaf {
+				terminate = false
+			}
+		}
+		if sum > maxDensityTreeWeight {
+			sum = maxDensityTreeWeight
+		}
+		return sum, terminate
+	}, approxSizeBytes, maxLevel)
+}
+
+// estimatedWeight returns the weight of the given cell, which is the weight
+// of the leaf containing it if the cell is not in the tree, and reports
+// whether the cell lies within a leaf (or is itself a leaf or outside the
+// tree), in which case its descendants all have the same weight.
+func (t *DensityTree) estimatedWeight(id CellID) (int64, bool) {
+	for level := id.Level(); level >= 0; level-- {
+		ancestor := id.Parent(level)
+		if w, ok := t.weights[ancestor]; ok {
+			if level == id.Level() {
+				return w, !t.hasChildren(id)
+			}
+			if t.hasChildren(ancestor) {
+				// The cell is outside the tree.
+				return 0, true
+			}
+			return w, true
+		}
+	}
+	return 0, true
+}
+
+// children returns the children of the given cell that are in the tree, in
+// increasing CellID order.
+func (t *DensityTree) children(id CellID) []CellID {
+	if id.IsLeaf() {
+		return nil
+	}
+	var children []CellID
+	for _, child := range id.Children() {
+		if _, ok := t.weights[child]; ok {
+			children = append(children, child)
+		}
+	}
+	return children
+}
+
+// faces returns the face cells that are in the tree.
+func (t *DensityTree) faces() []CellID {
+	var faces []CellID
+	for face := 0; face < NumFaces; face++ {
+		if _, ok := t.weights[CellIDFromFace(face)]; ok {
+			faces = append(faces, CellIDFromFace(face))
+		}
+	}
+	return faces
+}
+
+// hasChildren reports whether the given cell of the tree has children.
+func (t *DensityTree) hasChildren(id CellID) bool {
+	if id.IsLeaf() {
+		return false
+	}
+	for _, child := range id.Children() {
+		if _, ok := t.weights[child]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Weight returns the weight of the given cell and reports whether the cell is
+// in the tree.
+func (t *DensityTree) Weight(id CellID) (int64, bool) {
+	w, ok := t.weights[id]
+	return w, ok
+}
+
+// TotalWeight returns the sum of the weights of the face cells.
+func (t *DensityTree) TotalWeight() int64 {
+	sum := int64(0)
+	for face := 0; face < NumFaces; face++ {
+		sum += t.weights[CellIDFromFace(face)]
+	}
+	return sum
+}
+
+// NumCells returns the number of cells in the tree.
+func (t *DensityTree) NumCells() int {
+	return len(t.weights)
+}
+
+// VisitCells calls visit for each cell of the tree in depth-first order,
+// visiting the children of each cell in increasing CellID order. If visit
+// returns false for a cell, the children of that cell are skipped.
+func (t *DensityTree) VisitCells(visit func(id CellID, weight int64) bool) {
+	var walk func(id CellID)
+	walk = func(id CellID) {
+		w, ok := t.weights[id]
+		if !ok || !visit(id, w) || id.IsLeaf() {
+			return
+		}
+		for _, child := range id.Children() {
+			walk(child)
+		}
+	}
+	for face := 0; face < NumFaces; face++ {
+		walk(CellIDFromFace(face))
+	}
+}
+
+// Normalize returns a copy of the tree in which the weights of the children
+// of each cell are scaled so that they add up to (approximately) the weight
+// of the cell. This removes the double counting of features that intersect
+// several children, so that the weights of a set of disjoint cells can be
+// added up.
+func (t *DensityTree) Normalize() *DensityTree {
+	n := &DensityTree{weights: make(map[CellID]int64, len(t.weights))}
+	var normalize func(id CellID, weight int64)
+	normalize = func(id CellID, weight int64) {
+		n.weights[id] = weight
+		if !t.hasChildren(id) {
+			return
+		}
+		children := t.children(id)
+		sum := int64(0)
+		for _, child := range children {
+			sum += t.weights[child]
+		}
+		for _, child := range children {
+			// The children of a decoded tree may all have zero weight, in
+			// which case the weight is shared equally among them.
+			share := float64(weight) / float64(len(children))
+			if sum > 0 {
+				share = float64(t.weights[child]) * float64(weight) / float64(sum)
+			}
+			// Keep every cell's weight positive so that the cell stays in
+			// the tree.
+			normalize(child, max(1, int64(math.Round(share))))
+		}
+	}
+	for face := 0; face < NumFaces; face++ {
+		if w, ok := t.weights[CellIDFromFace(face)]; ok {
+			normalize(CellIDFromFace(face), w)
+		}
+	}
+	return n
+}
+
+// Partition divides the sphere into disjoint cell unions, in increasing
+// CellID order, such that the total weight of the cells in each union is at
+// most maxWeight where possible. A leaf cell of the tree whose weight
+// exceeds maxWeight gets a union of its own. Cells that are not in the tree
+// have zero weight and are added to the adjacent unions, so that together
+// the unions cover the whole sphere.
+//
+// The tree should normally be normalized first, as otherwise the weights of
+// the cells of a union overestimate its total weight.
+func (t *DensityTree) Partition(maxWeight int64) []CellUnion {
+	var result []CellUnion
+	var current CellUnion
+	currentWeight := int64(0)
+	var walk func(id CellID)
+	walk = func(id CellID) {
+		w := t.weights[id]
+		if w > maxWeight && t.hasChildren(id) {
+			for _, child := range id.Children() {
+				walk(child)
+			}
+			return
+		}
+		if len(current) > 0 && currentWeight+w > maxWeight {
+			current.Normalize()
+			result = append(result, current)
+			current, currentWeight = nil, 0
+		}
+		current = append(current, id)
+		currentWeight += w
+	}
+	for face := 0; face < NumFaces; face++ {
+		walk(CellIDFromFace(face))
+	}
+	if len(current) > 0 {
+		current.Normalize()
+		result = append(result, current)
+	}
+	return result
+}
+
+// Encode encodes the DensityTree.
+//
+// The encoding consists of a version byte and a varint with the mask of the
+// face cells that are present, followed by the face cells in the layout
+// below. Each cell is encoded as a varint holding the weight of the cell
+// shifted left by 4 bits, with the mask of its children that are present in
+// the low bits, followed by the children in the same layout. Each list of
+// cells (the faces, or the children of a cell) is preceded by a varint for
+// every cell after the first, giving the offset in bytes of that cell from
+// the start of the first cell. The offsets allow a decoder to skip directly
+// to the subtree of any cell.
+//
+// TODO(roberts): Verify this against trees encoded by the C++ S2DensityTree,
+// which this encoding is meant to match.
+func (t *DensityTree) Encode(w io.Writer) error {
+	e := &encoder{w: w}
+	t.encode(e)
+	return e.err
+}
+
+func (t *DensityTree) encode(e *encoder) {
+	sizes := make(map[CellID]int64, len(t.weights))
+	faces := t.faces()
+	faceMask := uint64(0)
+	for _, id := range faces {
+		faceMask |= 1 << uint(id.Face())
+		t.subtreeSize(id, sizes)
+	}
+	e.writeInt8(encodingVersion)
+	e.writeUvarint(faceMask)
+	writeDensityTreeOffsets(e, faces, sizes)
+	for _, id := range faces {
+		t.encodeCell(e, id, sizes)
+	}
+}
+
+// encodeCell encodes the subtree rooted at the given cell, using the encoded
+// sizes of the subtrees computed by subtreeSize.
+func (t *DensityTree) encodeCell(e *encoder, id CellID, sizes map[CellID]int64) {
+	children := t.children(id)
+	e.writeUvarint(uint64(t.weights[id])<<densityTreeChildBits | densityTreeChildMask(id, children))
+	writeDensityTreeOffsets(e, children, sizes)
+	for _, child := range children {
+		t.encodeCell(e, child, sizes)
+	}
+}
+
+// writeDensityTreeOffsets writes the offset of each cell after the first from
+// the start of the first cell.
+func writeDensityTreeOffsets(e *encoder, ids []CellID, sizes map[CellID]int64) {
+	offset := int64(0)
+	for i := 1; i < len(ids); i++ {
+		offset += sizes[ids[i-1]]
+		e.writeUvarint(uint64(offset))
+	}
+}
+
+// densityTreeChildMask returns the mask of the given children of a cell.
+func densityTreeChildMask(id CellID, children []CellID) uint64 {
+	mask := uint64(0)
+	for _, child := range children {
+		mask |= 1 << uint(child.ChildPosition(id.Level()+1))
+	}
+	return mask
+}
+
+// subtreeSize returns the encoded size of the subtree rooted at the given
+// cell, and records it along with the sizes of all of its descendants.
+func (t *DensityTree) subtreeSize(id CellID, sizes map[CellID]int64) int64 {
+	children := t.children(id)
+	size := int64(uvarintLen(uint64(t.weights[id])<<densityTreeChildBits | densityTreeChildMask(id, children)))
+	offset := int64(0)
+	for i, child := range children {
+		if i > 0 {
+			size += int64(uvarintLen(uint64(offset)))
+		}
+		childSize := t.subtreeSize(child, sizes)
+		offset += childSize
+		size += childSize
+	}
+	sizes[id] = size
+	return size
+}
+
+// encodedSize returns the number of bytes written by Encode.
+func (t *DensityTree) encodedSize() int64 {
+	sizes := make(map[CellID]int64, len(t.weights))
+	faceMask := uint64(0)
+	size, offset := int64(1), int64(0)
+	for i, id := range t.faces() {
+		faceMask |= 1 << uint(id.Face())
+		if i > 0 {
+			size += int64(uvarintLen(uint64(offset)))
+		}
+		faceSize := t.subtreeSize(id, sizes)
+		offset += faceSize
+		size += faceSize
+	}
+	return size + int64(uvarintLen(faceMask))
+}
+
+// Decode decodes the DensityTree.
+func (t *DensityTree) Decode(r io.Reader) error {
+	cr := &countingByteReader{r: asByteReader(r)}
+	d := &decoder{r: cr}
+	t.decode(d, cr)
+	return d.err
+}
+
+// countingByteReader counts the bytes read through it, so that the offsets
+// in an encoded DensityTree can be checked.
+type countingByteReader struct {
+	r byteReader
+	n int64
+}
+
+func (c *countingByteReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func (c *countingByteReader) ReadByte() (byte, error) {
+	b, err := c.r.ReadByte()
+	if err == nil {
+		c.n++
+	}
+	return b, err
+}
+
+func (t *DensityTree) decode(d *decoder, cr *countingByteReader) {
+	version := d.readInt8()
+	if d.err != nil {
+		return
+	}
+	if version != encodingVersion {
+		d.err = fmt.Errorf("cannot decode version %d", version)
+		return
+	}
+	t.weights = make(map[CellID]int64)
+	faceMask := d.readUvarint()
+	if d.err == nil && faceMask >= 1<<NumFaces {
+		d.err = fmt.Errorf("invalid density tree face mask %#x", faceMask)
+	}
+
+	var readCell func(id CellID)
+	// readCells reads the offsets of the given cells followed by their
+	// subtrees, and checks that the offsets match.
+	readCells := func(ids []CellID) {
+		offsets := make([]uint64, len(ids))
+		for i := 1; i < len(ids); i++ {
+			offsets[i] = d.readUvarint()
+		}
+		start := cr.n
+		for i, id := range ids {
+			if d.err != nil {
+				return
+			}
+			if got := uint64(cr.n - start); got != offsets[i] {
+				d.err = fmt.Errorf("density tree cell %v is at offset %d, want %d", id, got, offsets[i])
+				return
+			}
+			readCell(id)
+		}
+	}
+	readCell = func(id CellID) {
+		x := d.readUvarint()
+		if d.err != nil {
+			return
+		}
+		childMask := x & (1<<densityTreeChildBits - 1)
+		t.weights[id] = int64(x >> densityTreeChildBits)
+		if childMask == 0 {
+			return
+		}
+		if id.IsLeaf() {
+			d.err = fmt.Errorf("density tree leaf cell %v has children", id)
+			return
+		}
+		var children []CellID
+		for i, child := range id.Children() {
+			if childMask&(1<<uint(i)) != 0 {
+				children = append(children, child)
+			}
+		}
+		readCells(children)
+	}
+	var faces []CellID
+	for face := 0; face < NumFaces; face++ {
+		if faceMask&(1<<uint(face)) != 0 {
+			faces = append(faces, CellIDFromFace(face))
+		}
+	}
+	if d.err == nil {
+		readCells(faces)
+	}
+}
+
+// uvarintLen returns the number of bytes needed to encode x as a uvarint.
+func uvarintLen(x uint64) int {
+	n := 1
+	for x >= 0x80 {
+		x >>= 7
+		n++
+	}
+	return n
+}
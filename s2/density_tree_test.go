@@ -0,0 +1,367 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package s2
+
+import (
+	"bytes"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/golang/geo/s1"
+)
+
+// checkDensityTreeStructure checks that every cell of the tree other than a
+// face cell has its parent in the tree.
+func checkDensityTreeStructure(t *testing.T, tree *DensityTree) {
+	t.Helper()
+	for id, w := range tree.weights {
+		if w <= 0 {
+			t.Errorf("cell %v has weight %d, want > 0", id, w)
+		}
+		if id.Level() > 0 {
+			if _, ok := tree.weights[id.immediateParent()]; !ok {
+				t.Errorf("cell %v is in the tree but its parent is not", id)
+			}
+		}
+	}
+}
+
+func TestDensityTreeFromVertices(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	center := randomPoint(r)
+	var points []Point
+	for i := 0; i < 500; i++ {
+		points = append(points, samplePointFromCap(CapFromCenterAngle(center, 5*s1.Degree), r))
+	}
+	index := NewShapeIndex()
+	pv := PointVector(points)
+	index.Add(&pv)
+	index.Add(PolylineFromLatLngs([]LatLng{LatLngFromDegrees(0, 0), LatLngFromDegrees(0, 10)}))
+
+	const sizeBytes = 400
+	tree, err := DensityTreeFromVertices(index, sizeBytes, 20)
+	if err != nil {
+		t.Fatalf("DensityTreeFromVertices() failed: %v", err)
+	}
+	checkDensityTreeStructure(t, tree)
+	if got, want := tree.TotalWeight(), int64(len(points)+2); got != want {
+		t.Errorf("TotalWeight() = %d, want %d", got, want)
+	}
+
+	vertices := append(points, PointFromLatLng(LatLngFromDegrees(0, 0)), PointFromLatLng(LatLngFromDegrees(0, 10)))
+	maxLevel := 0
+	tree.VisitCells(func(id CellID, weight int64) bool {
+		want := int64(0)
+		for _, p := range vertices {
+			if id.Contains(cellIDFromPoint(p)) {
+				want++
+			}
+		}
+		if weight != want {
+			t.Errorf("weight of %v = %d, want %d", id, weight, want)
+		}
+		maxLevel = max(maxLevel, id.Level())
+		return true
+	})
+	if maxLevel < 3 {
+		t.Errorf("deepest cell is at level %d, want a deeper tree", maxLevel)
+	}
+
+	var buf bytes.Buffer
+	if err := tree.Encode(&buf); err != nil {
+		t.Fatalf("Encode() failed: %v", err)
+	}
+	if buf.Len() > sizeBytes {
+		t.Errorf("encoded size = %d, want <= %d", buf.Len(), sizeBytes)
+	}
+}
+
+func TestDensityTreeMaxLevel(t *testing.T) {
+	tree, err := DensityTreeFromWeights(func(cell Cell) (int64, bool) { return 1, false }, 1<<20, 2)
+	if err != nil {
+		t.Fatalf("DensityTreeFromWeights() failed: %v", err)
+	}
+	if got, want := tree.NumCells(), 6*(1+4+16); got != want {
+		t.Errorf("NumCells() = %d, want %d", got, want)
+	}
+
+	if _, err := DensityTreeFromWeights(func(cell Cell) (int64, bool) { return 1, false }, 100, -1); err == nil {
+		t.Errorf("DensityTreeFromWeights with max level -1 succeeded, want error")
+	}
+	if _, err := DensityTreeFromWeights(func(cell Cell) (int64, bool) { return -1, false }, 100, 5); err == nil {
+		t.Errorf("DensityTreeFromWeights with a negative weight succeeded, want error")
+	}
+}
+
+func TestDensityTreeFromShapes(t *testing.T) {
+	polygon := PolygonFromLoops([]*Loop{RegularLoop(parsePoint("10:10"), 3*s1.Degree, 20)})
+	polyline := makePolyline("0.5:0.3, 1:20, 20:20.5")
+	index := NewShapeIndex()
+	index.Add(polygon)
+	index.Add(polyline)
+
+	tree, err := DensityTreeFromShapes(index, func(shape Shape) int64 {
+		if shape.Dimension() == 2 {
+			return 10
+		}
+		return 1
+	}, 2000, 10)
+	if err != nil {
+		t.Fatalf("DensityTreeFromShapes() failed: %v", err)
+	}
+	checkDensityTreeStructure(t, tree)
+	tree.VisitCells(func(id CellID, weight int64) bool {
+		cell := CellFromCellID(id)
+		want := int64(0)
+		if polygon.IntersectsCell(cell) {
+			want += 10
+		}
+		if polyline.IntersectsCell(cell) {
+			want++
+		}
+		if weight != want {
+			t.Errorf("weight of %v = %d, want %d", id, weight, want)
+		}
+		return true
+	})
+}
+
+func TestDensityTreeFromCellUnions(t *testing.T) {
+	a := CellUnion{CellIDFromFace(2).ChildBeginAtLevel(4)}
+	b := CellUnion{CellIDFromFace(2), CellIDFromFace(3)}
+	tree, err := DensityTreeFromCellUnions([]CellUnion{a, b}, []int64{5, 7}, 1000, 10)
+	if err != nil {
+		t.Fatalf("DensityTreeFromCellUnions() failed: %v", err)
+	}
+	checkDensityTreeStructure(t, tree)
+
+	tests := []struct {
+		id   CellID
+		want int64
+		ok   bool
+	}{
+		{CellIDFromFace(2), 12, true},
+		{CellIDFromFace(3), 7, true},
+		{CellIDFromFace(2).ChildBeginAtLevel(4), 12, true},
+		{CellIDFromFace(2).ChildBeginAtLevel(4).Next(), 7, true},
+		// Cells entirely within all intersecting unions are not subdivided.
+		{CellIDFromFace(2).ChildBeginAtLevel(5), 0, false},
+		{CellIDFromFace(3).ChildBeginAtLevel(1), 0, false},
+		{CellIDFromFace(4), 0, false},
+	}
+	for _, test := range tests {
+		if got, ok := tree.Weight(test.id); got != test.want || ok != test.ok {
+			t.Errorf("Weight(%v) = %d, %v, want %d, %v", test.id, got, ok, test.want, test.ok)
+		}
+	}
+
+	if _, err := DensityTreeFromCellUnions([]CellUnion{a}, nil, 1000, 10); err == nil {
+		t.Errorf("DensityTreeFromCellUnions with mismatched weights succeeded, want error")
+	}
+}
+
+func TestDensityTreeFromSum(t *testing.T) {
+	a, err := DensityTreeFromCellUnions([]CellUnion{{CellIDFromFace(1)}}, []int64{3}, 1000, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := DensityTreeFromCellUnions([]CellUnion{{CellIDFromFace(1).ChildBeginAtLevel(2)}}, []int64{4}, 1000, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum, err := DensityTreeFromSum([]*DensityTree{a, b}, 1000, 10)
+	if err != nil {
+		t.Fatalf("DensityTreeFromSum() failed: %v", err)
+	}
+	tests := []struct {
+		id   CellID
+		want int64
+	}{
+		{CellIDFromFace(1), 7},
+		// Face 1 is a leaf of a, so its weight applies to all of its descendants.
+		{CellIDFromFace(1).ChildBeginAtLevel(2), 7},
+		{CellIDFromFace(1).ChildBeginAtLevel(2).Next(), 3},
+	}
+	for _, test := range tests {
+		if got, _ := sum.Weight(test.id); got != test.want {
+			t.Errorf("Weight(%v) = %d, want %d", test.id, got, test.want)
+		}
+	}
+}
+
+func TestDensityTreeEncodeDecode(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	var unions []CellUnion
+	var weights []int64
+	for i := 0; i < 20; i++ {
+		unions = append(unions, randomCellUnion(5, r))
+		weights = append(weights, int64(randomUniformInt(1000, r)+1))
+	}
+	tree, err := DensityTreeFromCellUnions(unions, weights, 5000, 12)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := tree.Encode(&buf); err != nil {
+		t.Fatalf("Encode() failed: %v", err)
+	}
+	encoded := buf.Bytes()
+	var got DensityTree
+	if err := got.Decode(bytes.NewReader(encoded)); err != nil {
+		t.Fatalf("Decode() failed: %v", err)
+	}
+	if !reflect.DeepEqual(got.weights, tree.weights) {
+		t.Errorf("Decode(Encode(tree)) = %v, want %v", got.weights, tree.weights)
+	}
+
+	// Truncated encodings fail to decode.
+	for n := 0; n < len(encoded); n++ {
+		var d DensityTree
+		if err := d.Decode(bytes.NewReader(encoded[:n])); err == nil {
+			t.Errorf("Decode of %d of %d encoded bytes succeeded, want error", n, len(encoded))
+		}
+	}
+}
+
+func TestDensityTreeEncodingGolden(t *testing.T) {
+	// A tree with face 0 (weight 10) and its four children (weights 1 to 4),
+	// and face 2 (weight 5). The bytes are derived by hand from the layout
+	// described by Encode.
+	face0 := CellIDFromFace(0)
+	ch := face0.Children()
+	want := map[CellID]int64{
+		face0: 10, ch[0]: 1, ch[1]: 2, ch[2]: 3, ch[3]: 4,
+		CellIDFromFace(2): 5,
+	}
+	golden := []byte{
+		0x01,       // version
+		0x05,       // faces 0 and 2
+		0x09,       // offset of face 2
+		0xaf, 0x01, // face 0: weight 10, children 0-3
+		0x01, 0x02, 0x03, // offsets of children 1-3
+		0x10, 0x20, 0x30, 0x40, // children with weights 1-4
+		0x50, // face 2: weight 5
+	}
+
+	var tree DensityTree
+	if err := tree.Decode(bytes.NewReader(golden)); err != nil {
+		t.Fatalf("Decode() failed: %v", err)
+	}
+	if !reflect.DeepEqual(tree.weights, want) {
+		t.Errorf("Decode() = %v, want %v", tree.weights, want)
+	}
+	var buf bytes.Buffer
+	if err := tree.Encode(&buf); err != nil {
+		t.Fatalf("Encode() failed: %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), golden) {
+		t.Errorf("Encode() = %x, want %x", buf.Bytes(), golden)
+	}
+	if got := tree.encodedSize(); got != int64(len(golden)) {
+		t.Errorf("encodedSize() = %d, want %d", got, len(golden))
+	}
+
+	// Encodings with offsets that do not match the cells fail to decode.
+	for _, i := range []int{2, 5, 7} {
+		bad := append([]byte(nil), golden...)
+		bad[i]++
+		if err := tree.Decode(bytes.NewReader(bad)); err == nil {
+			t.Errorf("Decode with a bad offset at byte %d succeeded, want error", i)
+		}
+	}
+}
+
+func TestDensityTreeNormalizeZeroWeightChildren(t *testing.T) {
+	face0 := CellIDFromFace(0)
+	ch := face0.Children()
+	tree := &DensityTree{weights: map[CellID]int64{face0: 8, ch[0]: 0, ch[1]: 0}}
+	got := tree.Normalize().weights
+	want := map[CellID]int64{face0: 8, ch[0]: 4, ch[1]: 4}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Normalize() = %v, want %v", got, want)
+	}
+}
+
+func TestDensityTreeNormalizeAndPartition(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	center := randomPoint(r)
+	index := NewShapeIndex()
+	for i := 0; i < 50; i++ {
+		c := CapFromCenterAngle(samplePointFromCap(CapFromCenterAngle(center, 20*s1.Degree), r), 2*s1.Degree)
+		index.Add(PolygonFromLoops([]*Loop{RegularLoop(c.Center(), c.Radius(), 8)}))
+	}
+	tree, err := DensityTreeFromShapes(index, func(Shape) int64 { return 100 }, 5000, 12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	normalized := tree.Normalize()
+	if !reflect.DeepEqual(keysOf(normalized.weights), keysOf(tree.weights)) {
+		t.Errorf("Normalize() changed the cells of the tree")
+	}
+	normalized.VisitCells(func(id CellID, weight int64) bool {
+		if !normalized.hasChildren(id) {
+			return true
+		}
+		sum := int64(0)
+		for _, child := range id.Children() {
+			sum += normalized.weights[child]
+		}
+		// Each child's weight is rounded, and is at least 1.
+		if diff := sum - weight; diff < -2 || diff > 4 {
+			t.Errorf("children of %v have total weight %d, want about %d", id, sum, weight)
+		}
+		return true
+	})
+
+	total := normalized.TotalWeight()
+	const numShards = 8
+	maxWeight := total / numShards
+	shards := normalized.Partition(maxWeight)
+	if len(shards) < numShards {
+		t.Errorf("Partition(%d) returned %d shards, want at least %d", maxWeight, len(shards), numShards)
+	}
+	var all CellUnion
+	for i, shard := range shards {
+		weight := int64(0)
+		for _, id := range shard {
+			weight += normalized.weights[id]
+		}
+		if weight > maxWeight && len(shard) > 1 {
+			t.Errorf("shard %d = %v has weight %d, want <= %d", i, shard, weight, maxWeight)
+		}
+		if all.Intersects(shard) {
+			t.Errorf("shard %d = %v overlaps the previous shards", i, shard)
+		}
+		all = CellUnionFromUnion(all, shard)
+	}
+	var faces CellUnion
+	for face := 0; face < NumFaces; face++ {
+		faces = append(faces, CellIDFromFace(face))
+	}
+	if !all.Equal(faces) {
+		t.Errorf("union of shards = %v, want the whole sphere", all)
+	}
+}
+
+func keysOf(m map[CellID]int64) CellUnion {
+	var cu CellUnion
+	for id := range m {
+		cu = append(cu, id)
+	}
+	sortCellIDs(cu)
+	return cu
+}
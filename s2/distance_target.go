@@ -18,80 +18,102 @@ import (
 	"github.com/golang/geo/s1"
 )
 
-// The distance interface represents a set of common methods used by algorithms
-// that compute distances between various S2 types.
-type distance interface {
-	// chordAngle returns this type as a ChordAngle.
-	chordAngle() s1.ChordAngle
+// Distance represents a set of common methods used by algorithms that compute
+// distances between various S2 types. It allows the same search algorithms to
+// find either minimum or maximum distances, and allows distances other than
+// the spherical ChordAngle (e.g., distances on the WGS84 ellipsoid, or
+// distances weighted by some property of the target) to be used.
+//
+// The minimum and maximum distances used by the Min/MaxDistanceTo... targets
+// are implementations of this interface.
+//
+// Implementations must be comparable values (i.e., not slices, maps, or
+// funcs), since the queries compare distances using ==.
+type Distance interface {
+	// ChordAngle returns this type as a ChordAngle.
+	ChordAngle() s1.ChordAngle
 
-	// fromChordAngle is used to type convert a ChordAngle to this type.
+	// FromChordAngle is used to type convert a ChordAngle to this type.
 	// This is to work around needing to be clever in parts of the code
-	// where a distanceTarget interface method expects distances, but the
+	// where a DistanceTarget interface method expects distances, but the
 	// user only supplies a ChordAngle, and we need to dynamically cast it
 	// to an appropriate distance interface types.
-	fromChordAngle(o s1.ChordAngle) distance
+	FromChordAngle(o s1.ChordAngle) Distance
 
-	// zero returns a zero distance.
-	zero() distance
-	// negative returns a value smaller than any valid value.
-	negative() distance
-	// infinity returns a value larger than any valid value.
-	infinity() distance
+	// Zero returns a zero distance.
+	Zero() Distance
+	// Negative returns a value smaller than any valid value.
+	Negative() Distance
+	// Infinity returns a value larger than any valid value.
+	Infinity() Distance
 
-	// less is similar to the Less method in Sort. To get minimum values,
+	// Less is similar to the Less method in Sort. To get minimum values,
 	// this would be a less than type operation. For maximum, this would
 	// be a greater than type operation.
-	less(other distance) bool
+	Less(other Distance) bool
 
-	// sub subtracts the other value from this one and returns the new value.
+	// Sub subtracts the other value from this one and returns the new value.
 	// This is done as a method and not simple mathematical operation to
 	// allow closest and furthest to implement this in opposite ways.
-	sub(other distance) distance
+	Sub(other Distance) Distance
 
-	// chordAngleBound reports the upper bound on a ChordAngle corresponding
+	// ChordAngleBound reports the upper bound on a ChordAngle corresponding
 	// to this distance. For example, if distance measures WGS84 ellipsoid
 	// distance then the corresponding angle needs to be 0.56% larger.
-	chordAngleBound() s1.ChordAngle
+	ChordAngleBound() s1.ChordAngle
 
-	// updateDistance may update the value this distance represents
+	// UpdateDistance may update the value this distance represents
 	// based on the given input. The updated value and a boolean reporting
 	// if the value was changed are returned.
-	updateDistance(other distance) (distance, bool)
+	UpdateDistance(other Distance) (Distance, bool)
 }
 
-// distanceTarget is an interface that represents a geometric type to which distances
+// DistanceTarget is an interface that represents a geometric type to which distances
 // are measured.
 //
 // For example, there are implementations that measure distances to a Point,
 // an Edge, a Cell, a CellUnion, and even to an arbitrary collection of geometry
 // stored in ShapeIndex.
 //
-// The distanceTarget types are provided for the benefit of types that measure
+// The DistanceTarget types are provided for the benefit of types that measure
 // distances and/or find nearby geometry, such as ClosestEdgeQuery, FurthestEdgeQuery,
 // ClosestPointQuery, and ClosestCellQuery, etc.
-type distanceTarget interface {
-	// capBound returns a Cap that bounds the set of points whose distance to the
-	// target is distance.zero().
-	capBound() Cap
-
-	// updateDistanceToPoint updates the distance if the distance to
-	// the point P is within than the given dist.
+//
+// Users may implement this interface to measure distances in other ways (for
+// example to a geodesic on an ellipsoid, or weighted by some attribute) and
+// still use the optimized EdgeQuery search. The query relies on the
+// following properties of the target:
+//
+//   - The distance to a Cell must be a lower bound (in the sense of
+//     Distance.Less) on the distance to every point and edge in the cell.
+//   - CapBound must contain every point whose distance to the target is
+//     Distance().Zero().
+//
+// Implementations that cannot compute tight cell bounds may return
+// conservative ones at the expense of query speed.
+type DistanceTarget interface {
+	// CapBound returns a Cap that bounds the set of points whose distance to the
+	// target is Distance().Zero().
+	CapBound() Cap
+
+	// UpdateDistanceToPoint updates the distance if the distance to
+	// the point P is less than the given dist.
 	// The boolean reports if the value was updated.
-	updateDistanceToPoint(p Point, dist distance) (distance, bool)
+	UpdateDistanceToPoint(p Point, dist Distance) (Distance, bool)
 
-	// updateDistanceToEdge updates the distance if the distance to
-	// the edge E is within than the given dist.
+	// UpdateDistanceToEdge updates the distance if the distance to
+	// the edge E is less than the given dist.
 	// The boolean reports if the value was updated.
-	updateDistanceToEdge(e Edge, dist distance) (distance, bool)
+	UpdateDistanceToEdge(e Edge, dist Distance) (Distance, bool)
 
-	// updateDistanceToCell updates the distance if the distance to the cell C
-	// (including its interior) is within than the given dist.
+	// UpdateDistanceToCell updates the distance if the distance to the cell C
+	// (including its interior) is less than the given dist.
 	// The boolean reports if the value was updated.
-	updateDistanceToCell(c Cell, dist distance) (distance, bool)
+	UpdateDistanceToCell(c Cell, dist Distance) (Distance, bool)
 
-	// setMaxError potentially updates the value of MaxError, and reports if
+	// SetMaxError potentially updates the value of MaxError, and reports if
 	// the specific type supports altering it. Whenever one of the
-	// updateDistanceTo... methods above returns true, the returned distance
+	// UpdateDistanceTo... methods above returns true, the returned distance
 	// is allowed to be up to maxError larger than the true minimum distance.
 	// In other words, it gives this target object permission to terminate its
 	// distance calculation as soon as it has determined that (1) the minimum
@@ -101,9 +123,9 @@ type distanceTarget interface {
 	// If the target takes advantage of maxError to optimize its distance
 	// calculation, this method must return true. (Most target types will
 	// default to return false.)
-	setMaxError(maxErr s1.ChordAngle) bool
+	SetMaxError(maxErr s1.ChordAngle) bool
 
-	// maxBruteForceIndexSize reports the maximum number of indexed objects for
+	// MaxBruteForceIndexSize reports the maximum number of indexed objects for
 	// which it is faster to compute the distance by brute force (e.g., by testing
 	// every edge) rather than by using an index.
 	//
@@ -112,13 +134,13 @@ type distanceTarget interface {
 	// and ClosestPointQuery.
 	//
 	// Types that do not support this should return a -1.
-	maxBruteForceIndexSize() int
+	MaxBruteForceIndexSize() int
 
-	// distance returns an instance of the underlying distance type this
+	// Distance returns an instance of the underlying Distance type this
 	// target uses. This is to work around the use of Templates in the C++.
-	distance() distance
+	Distance() Distance
 
-	// visitContainingShapes finds all polygons in the given index that
+	// VisitContainingShapes finds all polygons in the given index that
 	// completely contain a connected component of the target geometry. (For
 	// example, if the target consists of 10 points, this method finds
 	// polygons that contain any of those 10 points.) For each such polygon,
@@ -141,9 +163,72 @@ type distanceTarget interface {
 	// returns false as well. Otherwise returns true.
 	//
 	// NOTE(roberts): This method exists only for the purpose of implementing
-	// edgeQuery IncludeInteriors efficiently.
-	visitContainingShapes(index *ShapeIndex, v shapePointVisitorFunc) bool
+	// EdgeQuery IncludeInteriors efficiently. Targets that have no interior
+	// containment semantics can simply return true without visiting anything.
+	VisitContainingShapes(index *ShapeIndex, v ShapePointVisitorFunc) bool
 }
 
-// shapePointVisitorFunc defines a type of function the visitContainingShapes can call.
-type shapePointVisitorFunc func(containingShape Shape, targetPoint Point) bool
+// ShapePointVisitorFunc defines a type of function the VisitContainingShapes can call.
+type ShapePointVisitorFunc func(containingShape Shape, targetPoint Point) bool
+
+// shapeIndexTarget is implemented by the targets that measure distances to
+// the geometry in a ShapeIndex. EdgeQuery uses it to merge-join the cells of
+// its index with those of the target index, rather than running a nested
+// query on the target index for every cell and edge that it visits.
+type shapeIndexTarget interface {
+	DistanceTarget
+
+	// targetQuery returns the query used to measure distances to the
+	// target index.
+	targetQuery() *EdgeQuery
+
+	// cellTarget and edgeTarget return targets of the same kind (i.e.,
+	// minimum or maximum distance) for a cell or an edge.
+	cellTarget(cell Cell) DistanceTarget
+	edgeTarget(edge Edge) DistanceTarget
+
+	// interiorTestPoint returns the point that must be contained by a
+	// polygon of the target index for p to be at distance Zero from the
+	// target when polygon interiors are included.
+	interiorTestPoint(p Point) Point
+}
+
+// shapeIndexComponentPoints returns one point for each connected component
+// of the geometry in the given index, namely the start of each non-empty
+// chain, plus the reference point of each full polygon (i.e., shapes that
+// contain points but have no edges). These are the points that the
+// ShapeIndex targets need to test for containment in VisitContainingShapes.
+func shapeIndexComponentPoints(index *ShapeIndex) []Point {
+	var points []Point
+	for _, shape := range index.shapes {
+		// Shapes that don't have any edges require a special case (below).
+		testedPoint := false
+		for c := 0; c < shape.NumChains(); c++ {
+			if shape.Chain(c).Length == 0 {
+				continue
+			}
+			testedPoint = true
+			points = append(points, shape.ChainEdge(c, 0).V0)
+		}
+		if !testedPoint {
+			// Special case to handle full polygons.
+			if ref := shape.ReferencePoint(); ref.Contained {
+				points = append(points, ref.Point)
+			}
+		}
+	}
+	return points
+}
+
+// cellUnionShapeIndex returns a ShapeIndex containing one polygon for each
+// cell of the given CellUnion, for use by the CellUnion distance targets.
+func cellUnionShapeIndex(cu CellUnion) *ShapeIndex {
+	index := NewShapeIndex()
+	for _, id := range cu {
+		cell := CellFromCellID(id)
+		index.Add(LaxPolygonFromPoints([][]Point{{
+			cell.Vertex(0), cell.Vertex(1), cell.Vertex(2), cell.Vertex(3),
+		}}))
+	}
+	return index
+}
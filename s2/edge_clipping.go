@@ -17,7 +17,7 @@ package s2
 // This file contains a collection of methods for:
 //
 //   (1) Robustly clipping geodesic edges to the faces of the S2 biunit cube
-//       (see s2stuv), and
+//       (see coords.go), and
 //
 //   (2) Robustly clipping 2D edges against 2D rectangles.
 //
@@ -91,9 +91,9 @@ func ClipToFace(a, b Point, face int) (aUV, bUV r2.Point, intersects bool) {
 // Padding must be non-negative.
 func ClipToPaddedFace(a, b Point, f int, padding float64) (aUV, bUV r2.Point, intersects bool) {
 	// Fast path: both endpoints are on the given face.
-	if face(a.Vector) == f && face(b.Vector) == f {
-		au, av := validFaceXYZToUV(f, a.Vector)
-		bu, bv := validFaceXYZToUV(f, b.Vector)
+	if XYZToFace(a.Vector) == f && XYZToFace(b.Vector) == f {
+		au, av := ValidFaceXYZToUV(f, a.Vector)
+		bu, bv := ValidFaceXYZToUV(f, b.Vector)
 		return r2.Point{X: au, Y: av}, r2.Point{X: bu, Y: bv}, true
 	}
 
@@ -103,9 +103,9 @@ func ClipToPaddedFace(a, b Point, f int, padding float64) (aUV, bUV r2.Point, in
 	// product) can produce different results in different coordinate systems
 	// when one argument is a linear multiple of the other, due to the use of
 	// symbolic perturbations.
-	normUVW := pointUVW(faceXYZtoUVW(f, a.PointCross(b)))
-	aUVW := pointUVW(faceXYZtoUVW(f, a))
-	bUVW := pointUVW(faceXYZtoUVW(f, b))
+	normUVW := pointUVW(FaceXYZToUVW(f, a.PointCross(b)))
+	aUVW := pointUVW(FaceXYZToUVW(f, a))
+	bUVW := pointUVW(FaceXYZToUVW(f, b))
 
 	// Padding is handled by scaling the u- and v-components of the normal.
 	// Letting R=1+padding, this means that when we compute the dot product of
@@ -533,8 +533,8 @@ func FaceSegments(a, b Point) []FaceSegment {
 
 	// Fast path: both endpoints are on the same face.
 	var aFace, bFace int
-	aFace, segment.a.X, segment.a.Y = xyzToFaceUV(a.Vector)
-	bFace, segment.b.X, segment.b.Y = xyzToFaceUV(b.Vector)
+	aFace, segment.a.X, segment.a.Y = XYZToFaceUV(a.Vector)
+	bFace, segment.b.X, segment.b.Y = XYZToFaceUV(b.Vector)
 	if aFace == bFace {
 		segment.face = aFace
 		return []FaceSegment{segment}
@@ -563,7 +563,7 @@ func FaceSegments(a, b Point) []FaceSegment {
 	for face := aFace; face != bFace; {
 		// Complete the current segment by finding the point where AB
 		// exits the current face.
-		z := faceXYZtoUVW(face, ab)
+		z := FaceXYZToUVW(face, ab)
 		n := pointUVW(z)
 
 		exitAxis := n.exitAxis()
@@ -573,9 +573,9 @@ func FaceSegments(a, b Point) []FaceSegment {
 		// Compute the next face intersected by AB, and translate the exit
 		// point of the current segment into the (u,v) coordinates of the
 		// next face. This becomes the first point of the next segment.
-		exitXyz := faceUVToXYZ(face, segment.b.X, segment.b.Y)
+		exitXyz := FaceUVToXYZ(face, segment.b.X, segment.b.Y)
 		face = nextFace(face, segment.b, exitAxis, n, bFace)
-		exitUvw := faceXYZtoUVW(face, Point{exitXyz})
+		exitUvw := FaceXYZToUVW(face, Point{exitXyz})
 		segment.face = face
 		segment.a = r2.Point{X: exitUvw.X, Y: exitUvw.Y}
 	}
@@ -604,13 +604,13 @@ func moveOriginToValidFace(face int, a, ab Point, aUV r2.Point) (int, r2.Point)
 	}
 
 	// Otherwise check whether the normal AB even intersects this face.
-	z := faceXYZtoUVW(face, ab)
+	z := FaceXYZToUVW(face, ab)
 	n := pointUVW(z)
 	if n.intersectsFace() {
 		// Check whether the point where the line AB exits this face is on the
 		// wrong side of A (by more than the acceptable error tolerance).
 		uv := n.exitPoint(n.exitAxis())
-		exit := faceUVToXYZ(face, uv.X, uv.Y)
+		exit := FaceUVToXYZ(face, uv.X, uv.Y)
 		aTangent := ab.Normalize().Cross(a.Vector)
 
 		// We can use the given face.
@@ -627,16 +627,16 @@ func moveOriginToValidFace(face int, a, ab Point, aUV r2.Point) (int, r2.Point)
 		if aUV.X > 0 {
 			dir = 1
 		}
-		face = uvwFace(face, 0, dir)
+		face = UVWFace(face, 0, dir)
 	} else {
 		// V-axis
 		if aUV.Y > 0 {
 			dir = 1
 		}
-		face = uvwFace(face, 1, dir)
+		face = UVWFace(face, 1, dir)
 	}
 
-	aUV.X, aUV.Y = validFaceXYZToUV(face, a.Vector)
+	aUV.X, aUV.Y = ValidFaceXYZToUV(face, a.Vector)
 	aUV.X = math.Max(-1.0, math.Min(1.0, aUV.X))
 	aUV.Y = math.Max(-1.0, math.Min(1.0, aUV.Y))
 
@@ -676,12 +676,12 @@ func nextFace(face int, exit r2.Point, axis axis, n pointUVW, targetFace int) in
 	// face, and (3) AB exits *exactly* through the corner. (The sumEqual
 	// code checks whether the dot product of (u,v,1) and n is exactly zero.)
 	if math.Abs(exit1MinusA) == 1 &&
-		uvwFace(face, int(1-axis), exit1MinusAPos) == targetFace &&
+		UVWFace(face, int(1-axis), exit1MinusAPos) == targetFace &&
 		sumEqual(exit.X*n.X, exit.Y*n.Y, -n.Z) {
 		return targetFace
 	}
 
 	// Otherwise return the face that is adjacent to the exit point in the
 	// direction of the exit axis.
-	return uvwFace(face, int(axis), exitAPos)
+	return UVWFace(face, int(axis), exitAPos)
 }
@@ -132,10 +132,10 @@ func testClipToPaddedFace(t *testing.T, a, b Point) {
 	const errorRadians = faceClipErrorRadians
 
 	// The first and last vertices should approximately equal A and B.
-	if aPrime := faceUVToXYZ(segments[0].face, segments[0].a.X, segments[0].a.Y); a.Angle(aPrime) > errorRadians {
+	if aPrime := FaceUVToXYZ(segments[0].face, segments[0].a.X, segments[0].a.Y); a.Angle(aPrime) > errorRadians {
 		t.Errorf("%v.Angle(%v) = %v, want < %v", a, aPrime, a.Angle(aPrime), errorRadians)
 	}
-	if bPrime := faceUVToXYZ(segments[n-1].face, segments[n-1].b.X, segments[n-1].b.Y); b.Angle(bPrime) > errorRadians {
+	if bPrime := FaceUVToXYZ(segments[n-1].face, segments[n-1].b.X, segments[n-1].b.Y); b.Angle(bPrime) > errorRadians {
 		t.Errorf("%v.Angle(%v) = %v, want < %v", b, bPrime, b.Angle(bPrime), errorRadians)
 	}
 
@@ -160,15 +160,15 @@ func testClipToPaddedFace(t *testing.T, a, b Point) {
 		if segments[i-1].face == segments[i].face {
 			t.Errorf("%v.face != %v.face", segments[i-1], segments[i])
 		}
-		if got, want := faceUVToXYZ(segments[i-1].face, segments[i-1].b.X, segments[i-1].b.Y),
-			faceUVToXYZ(segments[i].face, segments[i].a.X, segments[i].a.Y); !got.ApproxEqual(want) {
+		if got, want := FaceUVToXYZ(segments[i-1].face, segments[i-1].b.X, segments[i-1].b.Y),
+			FaceUVToXYZ(segments[i].face, segments[i].a.X, segments[i].a.Y); !got.ApproxEqual(want) {
 			t.Errorf("interior vertices on adjacent faces should be the same point. got %v != %v", got, want)
 		}
 
 		// Interior vertices should be in the plane containing A and B, and should
 		// be contained in the wedge of angles between A and B (i.e., the dot
 		// products with aTan and bTan should be non-negative).
-		p := faceUVToXYZ(segments[i].face, segments[i].a.X, segments[i].a.Y).Normalize()
+		p := FaceUVToXYZ(segments[i].face, segments[i].a.X, segments[i].a.Y).Normalize()
 		if got := math.Abs(p.Dot(norm.Vector)); got > errorRadians {
 			t.Errorf("%v.Dot(%v) = %v, want <= %v", p, norm, got, errorRadians)
 		}
@@ -203,8 +203,8 @@ func testClipToPaddedFace(t *testing.T, a, b Point) {
 			continue
 		}
 
-		aClip := Point{faceUVToXYZ(face, aUV.X, aUV.Y).Normalize()}
-		bClip := Point{faceUVToXYZ(face, bUV.X, bUV.Y).Normalize()}
+		aClip := Point{FaceUVToXYZ(face, aUV.X, aUV.Y).Normalize()}
+		bClip := Point{FaceUVToXYZ(face, bUV.X, bUV.Y).Normalize()}
 
 		desc := fmt.Sprintf("on face %d, a=%v, b=%v, aClip=%v, bClip=%v,", face, a, b, aClip, bClip)
 
@@ -273,8 +273,8 @@ func TestEdgeClippingClipToPaddedFace(t *testing.T) {
 		face := randomUniformInt(6)
 		i := randomUniformInt(4)
 		j := (i + 1) & 3
-		p := Point{faceUVToXYZ(face, biunit.Vertices()[i].X, biunit.Vertices()[i].Y)}
-		q := Point{faceUVToXYZ(face, biunit.Vertices()[j].X, biunit.Vertices()[j].Y)}
+		p := Point{FaceUVToXYZ(face, biunit.Vertices()[i].X, biunit.Vertices()[i].Y)}
+		q := Point{FaceUVToXYZ(face, biunit.Vertices()[j].X, biunit.Vertices()[j].Y)}
 
 		// Now choose two points that are nearly in the plane of PQ, preferring
 		// points that are near cube corners, face midpoints, or edge midpoints.
@@ -15,8 +15,10 @@
 package s2
 
 import (
+	"container/heap"
 	"sort"
 
+	"github.com/golang/geo/r2"
 	"github.com/golang/geo/s1"
 )
 
@@ -83,6 +85,20 @@ func (e *EdgeQueryOptions) MaxResults(n int) *EdgeQueryOptions {
 	return e
 }
 
+// Region specifies that only edges that intersect the given Region should
+// be returned, e.g. to find the closest road segments within a country's
+// boundary. Cells of the index that do not intersect the region are skipped
+// rather than filtering the results afterwards.
+//
+// Since a Region can only be tested against points and cells, an edge that
+// does not intersect the region may still be returned if it passes within a
+// small fraction of its own length of the region. Interior results (see
+// IncludeInteriors) are not affected by the region.
+func (e *EdgeQueryOptions) Region(r Region) *EdgeQueryOptions {
+	e.common = e.common.Region(r)
+	return e
+}
+
 // NewClosestEdgeQueryOptions returns a set of edge query options suitable
 // for performing closest edge queries.
 func NewClosestEdgeQueryOptions() *EdgeQueryOptions {
@@ -108,14 +124,14 @@ func NewFurthestEdgeQueryOptions() *EdgeQueryOptions {
 //   - ShapeID < 0 && EdgeID < 0 is returned to indicate that no edge
 //     satisfies the requested query options.
 type EdgeQueryResult struct {
-	distance distance
+	distance Distance
 	shapeID  int32
 	edgeID   int32
 }
 
 // Distance reports the distance between the edge in this shape that satisfied
 // the query's parameters.
-func (e EdgeQueryResult) Distance() s1.ChordAngle { return e.distance.chordAngle() }
+func (e EdgeQueryResult) Distance() s1.ChordAngle { return e.distance.ChordAngle() }
 
 // ShapeID reports the ID of the Shape this result is for.
 func (e EdgeQueryResult) ShapeID() int32 { return e.shapeID }
@@ -124,9 +140,9 @@ func (e EdgeQueryResult) ShapeID() int32 { return e.shapeID }
 func (e EdgeQueryResult) EdgeID() int32 { return e.edgeID }
 
 // newEdgeQueryResult returns a result instance with default values.
-func newEdgeQueryResult(target distanceTarget) EdgeQueryResult {
+func newEdgeQueryResult(target DistanceTarget) EdgeQueryResult {
 	return EdgeQueryResult{
-		distance: target.distance().infinity(),
+		distance: target.Distance().Infinity(),
 		shapeID:  -1,
 		edgeID:   -1,
 	}
@@ -147,8 +163,8 @@ func (e EdgeQueryResult) IsEmpty() bool {
 // Less reports if this results is less that the other first by distance,
 // then by (shapeID, edgeID). This is used for sorting.
 func (e EdgeQueryResult) Less(other EdgeQueryResult) bool {
-	if e.distance.chordAngle() != other.distance.chordAngle() {
-		return e.distance.less(other.distance)
+	if e.distance.ChordAngle() != other.distance.ChordAngle() {
+		return e.distance.Less(other.distance)
 	}
 	if e.shapeID != other.shapeID {
 		return e.shapeID < other.shapeID
@@ -177,7 +193,7 @@ func (e EdgeQueryResult) Less(other EdgeQueryResult) bool {
 type EdgeQuery struct {
 	index  *ShapeIndex
 	opts   *queryOptions
-	target distanceTarget
+	target DistanceTarget
 
 	// True if opts.maxError must be subtracted from ShapeIndex cell distances
 	// in order to ensure that such distances are measured conservatively. This
@@ -200,10 +216,14 @@ type EdgeQuery struct {
 	//
 	// Initially this is the same as the maximum distance specified by the user,
 	// but it can also be updated by the algorithm (see maybeAddResult).
-	distanceLimit distance
+	distanceLimit Distance
 
-	// The current set of results of the query.
-	results []EdgeQueryResult
+	// The current set of results of the query. When the number of results is
+	// limited (but is more than one), this is kept as a heap with the worst
+	// result first, and resultIDs maps each edge it contains to the position
+	// of its result in the heap.
+	results   []EdgeQueryResult
+	resultIDs map[ShapeEdgeID]int
 
 	// This field is true when duplicates must be avoided explicitly. This
 	// is achieved by maintaining a separate set keyed by (shapeID, edgeID)
@@ -219,16 +239,19 @@ type EdgeQuery struct {
 	// cells. Essentially this is just a covering of the indexed edges, except
 	// that we also store pointers to the corresponding ShapeIndexCells to
 	// reduce the number of index seeks required.
+	//
+	// The covering is reused by subsequent queries for as long as the index
+	// is unchanged, i.e. until its version differs from indexVersion.
 	indexCovering []CellID
 	indexCells    []*ShapeIndexCell
+	indexVersion  uint64
 
 	// The algorithm maintains a priority queue of unprocessed CellIDs, sorted
 	// in increasing order of distance from the target.
 	queue *queryQueue
 
-	iter                *ShapeIndexIterator
-	maxDistanceCovering []CellID
-	initialCells        []CellID
+	iter         *ShapeIndexIterator
+	initialCells []CellID
 }
 
 // NewClosestEdgeQuery returns an EdgeQuery that is used for finding the
@@ -300,7 +323,7 @@ func (e *EdgeQuery) Reset() {
 // Note that if opts.IncludeInteriors is true, the results may include some
 // entries with edge_id == -1. This indicates that the target intersects
 // the indexed polygon with the given ShapeID.
-func (e *EdgeQuery) FindEdges(target distanceTarget) []EdgeQueryResult {
+func (e *EdgeQuery) FindEdges(target DistanceTarget) []EdgeQueryResult {
 	return e.findEdges(target, e.opts)
 }
 
@@ -309,7 +332,7 @@ func (e *EdgeQuery) FindEdges(target distanceTarget) []EdgeQueryResult {
 //
 // Use IsDistanceLess()/IsDistanceGreater() if you only want to compare the
 // distance against a threshold value, since it is often much faster.
-func (e *EdgeQuery) Distance(target distanceTarget) s1.ChordAngle {
+func (e *EdgeQuery) Distance(target DistanceTarget) s1.ChordAngle {
 	return e.findEdge(target, e.opts).Distance()
 }
 
@@ -322,12 +345,14 @@ func (e *EdgeQuery) Distance(target distanceTarget) s1.ChordAngle {
 // If you wish to check if the distance is less than or equal to the limit, use:
 //
 //	query.IsDistanceLess(target, limit.Successor())
-func (e *EdgeQuery) IsDistanceLess(target distanceTarget, limit s1.ChordAngle) bool {
-	opts := e.opts
-	opts = opts.MaxResults(1).
+func (e *EdgeQuery) IsDistanceLess(target DistanceTarget, limit s1.ChordAngle) bool {
+	// Adjust a copy of the options, so that the query's own options are
+	// left unchanged.
+	opts := *e.opts
+	opts.MaxResults(1).
 		DistanceLimit(limit).
 		MaxError(s1.StraightChordAngle)
-	return !e.findEdge(target, opts).IsEmpty()
+	return !e.findEdge(target, &opts).IsEmpty()
 }
 
 // IsDistanceGreater reports if the distance to target is greater than limit.
@@ -338,7 +363,7 @@ func (e *EdgeQuery) IsDistanceLess(target distanceTarget, limit s1.ChordAngle) b
 // If you wish to check if the distance is less than or equal to the limit, use:
 //
 //	query.IsDistanceGreater(target, limit.Predecessor())
-func (e *EdgeQuery) IsDistanceGreater(target distanceTarget, limit s1.ChordAngle) bool {
+func (e *EdgeQuery) IsDistanceGreater(target DistanceTarget, limit s1.ChordAngle) bool {
 	return e.IsDistanceLess(target, limit)
 }
 
@@ -353,13 +378,13 @@ func (e *EdgeQuery) IsDistanceGreater(target distanceTarget, limit s1.ChordAngle
 // measure the distance between the two geometries conservatively.  If the
 // distance is definitely greater than "snap radius", then the geometries
 // are guaranteed to not intersect after snapping.
-func (e *EdgeQuery) IsConservativeDistanceLessOrEqual(target distanceTarget, limit s1.ChordAngle) bool {
+func (e *EdgeQuery) IsConservativeDistanceLessOrEqual(target DistanceTarget, limit s1.ChordAngle) bool {
 	return e.IsDistanceLess(target, limit.Expanded(minUpdateDistanceMaxError(limit)))
 }
 
 // IsConservativeDistanceGreaterOrEqual reports if the distance to the target is greater
 // than or equal to the given limit with some small tolerance.
-func (e *EdgeQuery) IsConservativeDistanceGreaterOrEqual(target distanceTarget, limit s1.ChordAngle) bool {
+func (e *EdgeQuery) IsConservativeDistanceGreaterOrEqual(target DistanceTarget, limit s1.ChordAngle) bool {
 	return e.IsDistanceGreater(target, limit.Expanded(-minUpdateDistanceMaxError(limit)))
 }
 
@@ -368,13 +393,13 @@ func (e *EdgeQuery) IsConservativeDistanceGreaterOrEqual(target distanceTarget,
 // Note that if opts.includeInteriors is true, the results may include some
 // entries with edgeID == -1. This indicates that the target intersects the
 // indexed polygon with the given shapeID.
-func (e *EdgeQuery) findEdges(target distanceTarget, opts *queryOptions) []EdgeQueryResult {
+func (e *EdgeQuery) findEdges(target DistanceTarget, opts *queryOptions) []EdgeQueryResult {
+	queryOpts := e.opts
 	e.findEdgesInternal(target, opts)
-	// TODO(roberts): Revisit this if there is a heap or other sorted and
-	// uniquing datastructure we can use instead of just a slice.
+	e.opts = queryOpts
 	e.results = sortAndUniqueResults(e.results)
-	if len(e.results) > e.opts.maxResults {
-		e.results = e.results[:e.opts.maxResults]
+	if len(e.results) > opts.maxResults {
+		e.results = e.results[:opts.maxResults]
 	}
 	return e.results
 }
@@ -400,9 +425,10 @@ func sortAndUniqueResults(results []EdgeQueryResult) []EdgeQueryResult {
 //
 // This is primarily to ease the usage of a number of the methods in the DistanceTargets
 // and in EdgeQuery.
-func (e *EdgeQuery) findEdge(target distanceTarget, opts *queryOptions) EdgeQueryResult {
-	opts.MaxResults(1)
-	e.findEdges(target, opts)
+func (e *EdgeQuery) findEdge(target DistanceTarget, opts *queryOptions) EdgeQueryResult {
+	o := *opts
+	o.MaxResults(1)
+	e.findEdges(target, &o)
 	if len(e.results) > 0 {
 		return e.results[0]
 	}
@@ -411,29 +437,30 @@ func (e *EdgeQuery) findEdge(target distanceTarget, opts *queryOptions) EdgeQuer
 }
 
 // findEdgesInternal does the actual work for find edges that match the given options.
-func (e *EdgeQuery) findEdgesInternal(target distanceTarget, opts *queryOptions) {
+func (e *EdgeQuery) findEdgesInternal(target DistanceTarget, opts *queryOptions) {
 	e.target = target
 	e.opts = opts
 
 	e.testedEdges = make(map[ShapeEdgeID]uint32)
-	e.distanceLimit = target.distance().fromChordAngle(opts.distanceLimit)
+	e.distanceLimit = target.Distance().FromChordAngle(opts.distanceLimit)
 	e.results = make([]EdgeQueryResult, 0)
+	e.resultIDs = make(map[ShapeEdgeID]int)
 
-	if e.distanceLimit == target.distance().zero() {
+	if e.distanceLimit == target.Distance().Zero() {
 		return
 	}
 
 	if opts.includeInteriors {
 		shapeIDs := map[int32]struct{}{}
-		e.target.visitContainingShapes(e.index, func(containingShape Shape, targetPoint Point) bool {
+		e.target.VisitContainingShapes(e.index, func(containingShape Shape, targetPoint Point) bool {
 			shapeIDs[e.index.idForShape(containingShape)] = struct{}{}
 			return len(shapeIDs) < opts.maxResults
 		})
 		for shapeID := range shapeIDs {
-			e.addResult(EdgeQueryResult{target.distance().zero(), shapeID, -1})
+			e.addResult(EdgeQueryResult{target.Distance().Zero(), shapeID, -1})
 		}
 
-		if e.distanceLimit == target.distance().zero() {
+		if e.distanceLimit == target.Distance().Zero() {
 			return
 		}
 	}
@@ -457,20 +484,28 @@ func (e *EdgeQuery) findEdgesInternal(target distanceTarget, opts *queryOptions)
 	// distanceLimit < maxError, this reduces the distance limit to 0,
 	// i.e. all remaining candidate cells and edges can safely be discarded.
 	// (This is how IsDistanceLess() and friends are implemented.)
-	targetUsesMaxError := opts.maxError != target.distance().zero().chordAngle() &&
-		e.target.setMaxError(opts.maxError)
+	targetUsesMaxError := opts.maxError != target.Distance().Zero().ChordAngle() &&
+		e.target.SetMaxError(opts.maxError)
 
 	// Note that we can't compare maxError and distanceLimit directly
 	// because one is a Delta and one is a Distance. Instead we subtract them.
 	e.useConservativeCellDistance = targetUsesMaxError &&
-		(e.distanceLimit == target.distance().infinity() ||
-			target.distance().zero().less(e.distanceLimit.sub(target.distance().fromChordAngle(opts.maxError))))
+		(e.distanceLimit == target.Distance().Infinity() ||
+			target.Distance().Zero().Less(e.distanceLimit.Sub(target.Distance().FromChordAngle(opts.maxError))))
+
+	// The index covering and the number of edges computed by earlier queries
+	// can be reused only if the index has not changed since.
+	e.index.maybeApplyUpdates()
+	if e.index.Version() != e.indexVersion {
+		e.Reset()
+		e.indexVersion = e.index.Version()
+	}
 
 	// Use the brute force algorithm if the index is small enough. To avoid
 	// spending too much time counting edges when there are many shapes, we stop
 	// counting once there are too many edges. We may need to recount the edges
 	// if we later see a target with a larger brute force edge threshold.
-	minOptimizedEdges := e.target.maxBruteForceIndexSize() + 1
+	minOptimizedEdges := e.target.MaxBruteForceIndexSize() + 1
 	if minOptimizedEdges > e.indexNumEdgesLimit && e.indexNumEdges >= e.indexNumEdgesLimit {
 		e.indexNumEdges = e.index.NumEdgesUpTo(minOptimizedEdges)
 		e.indexNumEdgesLimit = minOptimizedEdges
@@ -480,6 +515,11 @@ func (e *EdgeQuery) findEdgesInternal(target distanceTarget, opts *queryOptions)
 		// The brute force algorithm already considers each edge exactly once.
 		e.avoidDuplicates = false
 		e.findEdgesBruteForce()
+	} else if t, ok := target.(shapeIndexTarget); ok && !t.targetQuery().opts.useBruteForce {
+		// Distances to the target edges are computed directly, so maxError
+		// is only used to stop the search early.
+		e.avoidDuplicates = false
+		e.findEdgesMergeJoin(t)
 	} else {
 		// If the target takes advantage of maxError then we need to avoid
 		// duplicate edges explicitly. (Otherwise it happens automatically.)
@@ -489,27 +529,135 @@ func (e *EdgeQuery) findEdgesInternal(target distanceTarget, opts *queryOptions)
 }
 
 func (e *EdgeQuery) addResult(r EdgeQueryResult) {
-	e.results = append(e.results, r)
-	if e.opts.maxResults == 1 {
+	switch e.opts.maxResults {
+	case 1:
 		// Optimization for the common case where only the closest edge is wanted.
-		e.distanceLimit = r.distance.sub(e.target.distance().fromChordAngle(e.opts.maxError))
+		e.results = append(e.results, r)
+		e.distanceLimit = r.distance.Sub(e.target.Distance().FromChordAngle(e.opts.maxError))
+	case maxQueryResults:
+		// All results within the distance limit are wanted, so there is no
+		// need to keep them in order while the query runs.
+		e.results = append(e.results, r)
+	default:
+		// Keep the best maxResults results found so far, and once there are
+		// that many, only look for results that improve on the worst of them.
+		// The same edge may be found in several index cells, but it is only
+		// kept once.
+		id := ShapeEdgeID{r.shapeID, r.edgeID}
+		if _, ok := e.resultIDs[id]; ok {
+			return
+		}
+		h := e.resultHeap()
+		heap.Push(h, r)
+		if len(e.results) > e.opts.maxResults {
+			heap.Pop(h)
+		}
+		if len(e.results) == e.opts.maxResults {
+			e.distanceLimit = e.results[0].distance.Sub(e.target.Distance().FromChordAngle(e.opts.maxError))
+		}
 	}
-	// TODO(roberts): Add the other if/else cases when a different data structure
-	// is used for the results.
+}
+
+// edgeQueryResultHeap is a heap of results with the worst result first. It
+// keeps the position of each result in pos, so that a result can be improved
+// in place with heap.Fix.
+type edgeQueryResultHeap struct {
+	results *[]EdgeQueryResult
+	pos     map[ShapeEdgeID]int
+}
+
+// resultHeap returns the heap of the query's results.
+func (e *EdgeQuery) resultHeap() *edgeQueryResultHeap {
+	return &edgeQueryResultHeap{results: &e.results, pos: e.resultIDs}
+}
+
+func (h *edgeQueryResultHeap) Len() int           { return len(*h.results) }
+func (h *edgeQueryResultHeap) Less(i, j int) bool { return (*h.results)[j].Less((*h.results)[i]) }
+func (h *edgeQueryResultHeap) Swap(i, j int) {
+	r := *h.results
+	r[i], r[j] = r[j], r[i]
+	h.pos[ShapeEdgeID{r[i].shapeID, r[i].edgeID}] = i
+	h.pos[ShapeEdgeID{r[j].shapeID, r[j].edgeID}] = j
+}
+func (h *edgeQueryResultHeap) Push(x interface{}) {
+	r := x.(EdgeQueryResult)
+	h.pos[ShapeEdgeID{r.shapeID, r.edgeID}] = len(*h.results)
+	*h.results = append(*h.results, r)
+}
+func (h *edgeQueryResultHeap) Pop() interface{} {
+	old := *h.results
+	n := len(old)
+	x := old[n-1]
+	*h.results = old[:n-1]
+	delete(h.pos, ShapeEdgeID{x.shapeID, x.edgeID})
+	return x
 }
 
 func (e *EdgeQuery) maybeAddResult(shape Shape, shapeID, edgeID int32) {
-	if _, ok := e.testedEdges[ShapeEdgeID{shapeID, edgeID}]; e.avoidDuplicates && !ok {
-		return
+	if e.avoidDuplicates {
+		id := ShapeEdgeID{shapeID, edgeID}
+		if _, ok := e.testedEdges[id]; ok {
+			return
+		}
+		e.testedEdges[id] = 0
 	}
 	edge := shape.Edge(int(edgeID))
 	dist := e.distanceLimit
 
-	if dist, ok := e.target.updateDistanceToEdge(edge, dist); ok {
+	if dist, ok := e.target.UpdateDistanceToEdge(edge, dist); ok {
+		if e.opts.region != nil && !regionIntersectsEdge(e.opts.region, edge) {
+			return
+		}
 		e.addResult(EdgeQueryResult{dist, shapeID, edgeID})
 	}
 }
 
+// regionEdgeSubdivisionLevels is the number of levels below the level of
+// cells whose size is about the length of an edge that regionIntersectsEdge
+// subdivides to, i.e. the smallest cells tested are about 1/64 of the edge
+// length.
+const regionEdgeSubdivisionLevels = 6
+
+// regionIntersectsEdge reports whether the given edge may intersect the
+// region. Since a Region can only be tested against points and cells, the
+// edge is covered by successively smaller cells, and it is treated as
+// intersecting the region once one of these cells is contained by the
+// region, or when a cell that intersects the region is small enough compared
+// to the length of the edge.
+func regionIntersectsEdge(region Region, edge Edge) bool {
+	if region.ContainsPoint(edge.V0) || region.ContainsPoint(edge.V1) {
+		return true
+	}
+	maxLevel := minInt(MaxLevel,
+		MinWidthMetric.MaxLevel(edge.V0.Distance(edge.V1).Radians())+regionEdgeSubdivisionLevels)
+	for _, segment := range FaceSegments(edge.V0, edge.V1) {
+		if regionIntersectsEdgeInCell(region, segment.a, segment.b, CellIDFromFace(segment.face), maxLevel) {
+			return true
+		}
+	}
+	return false
+}
+
+// regionIntersectsEdgeInCell reports whether the edge AB, in the (u,v)
+// coordinates of the face of the given cell, may intersect the region within
+// the cell. The cell is subdivided down to at most maxLevel.
+func regionIntersectsEdgeInCell(region Region, a, b r2.Point, id CellID, maxLevel int) bool {
+	cell := CellFromCellID(id)
+	bound := cell.BoundUV().ExpandedByMargin(faceClipErrorUVCoord + intersectsRectErrorUVDist)
+	if !edgeIntersectsRect(a, b, bound) || !region.IntersectsCell(cell) {
+		return false
+	}
+	if id.Level() >= maxLevel || region.ContainsCell(cell) {
+		return true
+	}
+	for _, child := range id.Children() {
+		if regionIntersectsEdgeInCell(region, a, b, child, maxLevel) {
+			return true
+		}
+	}
+	return false
+}
+
 func (e *EdgeQuery) findEdgesBruteForce() {
 	// Range over all shapes in the index. Does order matter here? if so
 	// switch to for i = 0 .. n?
@@ -533,7 +681,7 @@ func (e *EdgeQuery) findEdgesOptimized() {
 		// remove it before adding any new entries to the queue.
 		entry := e.queue.pop()
 
-		if !entry.distance.less(e.distanceLimit) {
+		if !entry.distance.Less(e.distanceLimit) {
 			e.queue.reset() // Clear any remaining entries.
 			break
 		}
@@ -542,41 +690,47 @@ func (e *EdgeQuery) findEdgesOptimized() {
 			e.processEdges(entry)
 			continue
 		}
-		// Otherwise split the cell into its four children.  Before adding a
-		// child back to the queue, we first check whether it is empty.  We do
-		// this in two seek operations rather than four by seeking to the key
-		// between children 0 and 1 and to the key between children 2 and 3.
-		id := entry.id
-		ch := id.Children()
-		e.iter.seek(ch[1].RangeMin())
+		// Otherwise split the cell into its four children.
+		visitIndexChildren(e.iter, entry.id, e.processOrEnqueue)
+	}
+}
 
-		if !e.iter.Done() && e.iter.CellID() <= ch[1].RangeMax() {
-			e.processOrEnqueueCell(ch[1])
-		}
-		if e.iter.Prev() && e.iter.CellID() >= id.RangeMin() {
-			e.processOrEnqueueCell(ch[0])
+// visitIndexChildren calls visit for each child of the given cell that
+// contains at least one cell of the index, along with the corresponding
+// ShapeIndexCell if the child is itself an index cell (and nil otherwise).
+// The visit function must not move the iterator.
+func visitIndexChildren(it *ShapeIndexIterator, id CellID, visit func(child CellID, indexCell *ShapeIndexCell)) {
+	// Before visiting a child, we first check whether it is empty. We do this
+	// in two seek operations rather than four by seeking to the key between
+	// children 0 and 1 and to the key between children 2 and 3.
+	visitCurrent := func(child CellID) {
+		if it.CellID() == child {
+			visit(child, it.IndexCell())
+		} else {
+			visit(child, nil)
 		}
+	}
 
-		e.iter.seek(ch[3].RangeMin())
-		if !e.iter.Done() && e.iter.CellID() <= id.RangeMax() {
-			e.processOrEnqueueCell(ch[3])
-		}
-		if e.iter.Prev() && e.iter.CellID() >= ch[2].RangeMin() {
-			e.processOrEnqueueCell(ch[2])
-		}
+	ch := id.Children()
+	it.Seek(ch[1].RangeMin())
+	if !it.Done() && it.CellID() <= ch[1].RangeMax() {
+		visitCurrent(ch[1])
+	}
+	if it.Prev() && it.CellID() >= id.RangeMin() {
+		visitCurrent(ch[0])
 	}
-}
 
-func (e *EdgeQuery) processOrEnqueueCell(id CellID) {
-	if e.iter.CellID() == id {
-		e.processOrEnqueue(id, e.iter.IndexCell())
-	} else {
-		e.processOrEnqueue(id, nil)
+	it.Seek(ch[3].RangeMin())
+	if !it.Done() && it.CellID() <= id.RangeMax() {
+		visitCurrent(ch[3])
+	}
+	if it.Prev() && it.CellID() >= ch[2].RangeMin() {
+		visitCurrent(ch[2])
 	}
 }
 
 func (e *EdgeQuery) initQueue() {
-	if len(e.indexCovering) == 0 {
+	if e.iter == nil {
 		// We delay iterator initialization until now to make queries on very
 		// small indexes a bit faster (i.e., where brute force is used).
 		e.iter = NewShapeIndexIterator(e.index)
@@ -590,29 +744,33 @@ func (e *EdgeQuery) initQueue() {
 	// area.  This means that the cell containing "target" will be processed
 	// twice, but in general this is still faster.
 	//
-	// TODO(roberts): Even if the cap center is not contained, we could still
-	// process one or both of the adjacent index cells in CellID order,
-	// provided that those cells are closer than distanceLimit.
-	cb := e.target.capBound()
+	// Even if the cap center is not contained, we process the adjacent index
+	// cells in CellID order, provided that those cells are closer than
+	// distanceLimit, since they are often nearby.
+	cb := e.target.CapBound()
 	if cb.IsEmpty() {
 		return // Empty target.
 	}
 
-	if e.opts.maxResults == 1 && e.iter.LocatePoint(cb.Center()) {
-		e.processEdges(&queryQueueEntry{
-			distance:  e.target.distance().zero(),
-			id:        e.iter.CellID(),
-			indexCell: e.iter.IndexCell(),
-		})
+	if e.opts.maxResults == 1 {
+		if e.iter.LocatePoint(cb.Center()) {
+			e.processEdges(&queryQueueEntry{
+				distance:  e.target.Distance().Zero(),
+				id:        e.iter.CellID(),
+				indexCell: e.iter.IndexCell(),
+			})
+		} else {
+			e.processAdjacentCells(cellIDFromPoint(cb.Center()))
+		}
 		// Skip the rest of the algorithm if we found an intersecting edge.
-		if e.distanceLimit == e.target.distance().zero() {
+		if e.distanceLimit == e.target.Distance().Zero() {
 			return
 		}
 	}
 	if len(e.indexCovering) == 0 {
 		e.initCovering()
 	}
-	if e.distanceLimit == e.target.distance().infinity() {
+	if e.distanceLimit == e.target.Distance().Infinity() {
 		// Start with the precomputed index covering.
 		for i := range e.indexCovering {
 			e.processOrEnqueue(e.indexCovering[i], e.indexCells[i])
@@ -622,7 +780,7 @@ func (e *EdgeQuery) initQueue() {
 		// precomputed index covering.
 		coverer := &RegionCoverer{MaxCells: 4, LevelMod: 1, MaxLevel: MaxLevel}
 
-		radius := cb.Radius() + e.distanceLimit.chordAngleBound().Angle()
+		radius := cb.Radius() + e.distanceLimit.ChordAngleBound().Angle()
 		searchCB := CapFromCenterAngle(cb.Center(), radius)
 		maxDistCover := coverer.FastCovering(searchCB)
 		e.initialCells = CellUnionFromIntersection(e.indexCovering, maxDistCover)
@@ -670,6 +828,29 @@ func (e *EdgeQuery) initQueue() {
 	}
 }
 
+// processAdjacentCells processes the edges of the index cells just before
+// and just after the given leaf cell in CellID order, which is not contained
+// by any index cell, if they are closer than distanceLimit.
+func (e *EdgeQuery) processAdjacentCells(target CellID) {
+	type adjacentCell struct {
+		id   CellID
+		cell *ShapeIndexCell
+	}
+	var cells []adjacentCell
+	e.iter.Seek(target)
+	if !e.iter.Done() {
+		cells = append(cells, adjacentCell{e.iter.CellID(), e.iter.IndexCell()})
+	}
+	if e.iter.Prev() {
+		cells = append(cells, adjacentCell{e.iter.CellID(), e.iter.IndexCell()})
+	}
+	for _, c := range cells {
+		if dist, ok := e.target.UpdateDistanceToCell(CellFromCellID(c.id), e.distanceLimit); ok {
+			e.processEdges(&queryQueueEntry{distance: dist, id: c.id, indexCell: c.cell})
+		}
+	}
+}
+
 func (e *EdgeQuery) initCovering() {
 	// Find the range of Cells spanned by the index and choose a level such
 	// that the entire index can be covered with just a few cells. These are
@@ -688,17 +869,22 @@ func (e *EdgeQuery) initCovering() {
 	// split, except that we take the time to prune the children further since
 	// this will save work on every subsequent query.
 	e.indexCovering = make([]CellID, 0, 6)
-
-	// TODO(roberts): Use a single iterator below and save position
-	// information using pair {CellID, ShapeIndexCell}.
-	next := NewShapeIndexIterator(e.index, IteratorBegin)
-	last := NewShapeIndexIterator(e.index, IteratorEnd)
-	last.Prev()
-	if next.CellID() != last.CellID() {
+	e.indexCells = make([]*ShapeIndexCell, 0, 6)
+
+	// A single iterator is used, and the position of the last index cell is
+	// saved as its CellID.
+	it := e.iter
+	it.End()
+	if !it.Prev() {
+		return // The index is empty.
+	}
+	lastID := it.CellID()
+	it.Begin()
+	if it.CellID() != lastID {
 		// The index has at least two cells. Choose a level such that the entire
 		// index can be spanned with at most 6 cells (if the index spans multiple
 		// faces) or 4 cells (it the index spans a single face).
-		level, ok := next.CellID().CommonAncestorLevel(last.CellID())
+		level, ok := it.CellID().CommonAncestorLevel(lastID)
 		if !ok {
 			level = 0
 		} else {
@@ -706,40 +892,39 @@ func (e *EdgeQuery) initCovering() {
 		}
 
 		// Visit each potential top-level cell except the last (handled below).
-		lastID := last.CellID().Parent(level)
-		for id := next.CellID().Parent(level); id != lastID; id = id.Next() {
+		lastTop := lastID.Parent(level)
+		for id := it.CellID().Parent(level); id != lastTop; id = id.Next() {
 			// Skip any top-level cells that don't contain any index cells.
-			if id.RangeMax() < next.CellID() {
+			if id.RangeMax() < it.CellID() {
 				continue
 			}
 
 			// Find the range of index cells contained by this top-level cell and
 			// then shrink the cell if necessary so that it just covers them.
-			cellFirst := next.clone()
-			next.seek(id.RangeMax().Next())
-			cellLast := next.clone()
-			cellLast.Prev()
-			e.addInitialRange(cellFirst, cellLast)
-			break
+			firstID, firstCell := it.CellID(), it.IndexCell()
+			it.Seek(id.RangeMax().Next())
+			it.Prev()
+			e.addInitialRange(firstID, firstCell, it.CellID())
+			it.Next()
 		}
-
 	}
-	e.addInitialRange(next, last)
+	e.addInitialRange(it.CellID(), it.IndexCell(), lastID)
 }
 
-// addInitialRange adds an entry to the indexCovering and indexCells that covers the given
-// inclusive range of cells.
+// addInitialRange adds an entry to the indexCovering and indexCells that
+// covers the given inclusive range of index cells, where firstCell is the
+// index cell with the id first.
 //
 // This requires that first and last cells have a common ancestor.
-func (e *EdgeQuery) addInitialRange(first, last *ShapeIndexIterator) {
-	if first.CellID() == last.CellID() {
+func (e *EdgeQuery) addInitialRange(first CellID, firstCell *ShapeIndexCell, last CellID) {
+	if first == last {
 		// The range consists of a single index cell.
-		e.indexCovering = append(e.indexCovering, first.CellID())
-		e.indexCells = append(e.indexCells, first.IndexCell())
+		e.indexCovering = append(e.indexCovering, first)
+		e.indexCells = append(e.indexCells, firstCell)
 	} else {
 		// Add the lowest common ancestor of the given range.
-		level, _ := first.CellID().CommonAncestorLevel(last.CellID())
-		e.indexCovering = append(e.indexCovering, first.CellID().Parent(level))
+		level, _ := first.CommonAncestorLevel(last)
+		e.indexCovering = append(e.indexCovering, first.Parent(level))
 		e.indexCells = append(e.indexCells, nil)
 	}
 }
@@ -756,6 +941,10 @@ func (e *EdgeQuery) processEdges(entry *queryQueueEntry) {
 
 // processOrEnqueue the given cell id and indexCell.
 func (e *EdgeQuery) processOrEnqueue(id CellID, indexCell *ShapeIndexCell) {
+	// Cells that do not intersect the region cannot contain any results.
+	if e.opts.region != nil && !e.opts.region.IntersectsCell(CellFromCellID(id)) {
+		return
+	}
 	if indexCell != nil {
 		// If this index cell has only a few edges, then it is faster to check
 		// them directly rather than computing the minimum distance to the Cell
@@ -768,7 +957,7 @@ func (e *EdgeQuery) processOrEnqueue(id CellID, indexCell *ShapeIndexCell) {
 		if numEdges < minEdgesToEnqueue {
 			// Set "distance" to zero to avoid the expense of computing it.
 			e.processEdges(&queryQueueEntry{
-				distance:  e.target.distance().zero(),
+				distance:  e.target.Distance().Zero(),
 				id:        id,
 				indexCell: indexCell,
 			})
@@ -781,12 +970,12 @@ func (e *EdgeQuery) processOrEnqueue(id CellID, indexCell *ShapeIndexCell) {
 	cell := CellFromCellID(id)
 	dist := e.distanceLimit
 	var ok bool
-	if dist, ok = e.target.updateDistanceToCell(cell, dist); !ok {
+	if dist, ok = e.target.UpdateDistanceToCell(cell, dist); !ok {
 		return
 	}
 	if e.useConservativeCellDistance {
 		// Ensure that "distance" is a lower bound on the true distance to the cell.
-		dist = dist.sub(e.target.distance().fromChordAngle(e.opts.maxError))
+		dist = dist.Sub(e.target.Distance().FromChordAngle(e.opts.maxError))
 	}
 
 	e.queue.push(&queryQueueEntry{
@@ -796,6 +985,240 @@ func (e *EdgeQuery) processOrEnqueue(id CellID, indexCell *ShapeIndexCell) {
 	})
 }
 
-// TODO(roberts): Remaining pieces
-// GetEdge
-// Project
+// covering returns the top-level cells that cover the index and the
+// corresponding index cells (see initCovering), which are computed again
+// only if the index has changed since they were last computed.
+func (e *EdgeQuery) covering() ([]CellID, []*ShapeIndexCell) {
+	e.index.maybeApplyUpdates()
+	if e.index.Version() != e.indexVersion {
+		e.Reset()
+		e.indexVersion = e.index.Version()
+	}
+	if e.iter == nil {
+		e.iter = NewShapeIndexIterator(e.index)
+	}
+	if len(e.indexCovering) == 0 {
+		e.initCovering()
+	}
+	return e.indexCovering, e.indexCells
+}
+
+// shapeIndexJoin holds the state used by findEdgesMergeJoin.
+type shapeIndexJoin struct {
+	query  *EdgeQuery
+	target shapeIndexTarget
+
+	// The target index, and an iterator used to split its cells.
+	index *ShapeIndex
+	iter  *ShapeIndexIterator
+
+	// If includeInteriors is true, the edges of the query index are also at
+	// distance Zero from the polygons of the target index that contain them,
+	// which is tested using contains.
+	includeInteriors bool
+	contains         *ContainsPointQuery
+
+	// best holds the best distance found so far to each edge of the query
+	// index that has been visited, and eligible reports whether the edge may
+	// be returned at all (see EdgeQueryOptions.Region).
+	best     map[ShapeEdgeID]Distance
+	eligible map[ShapeEdgeID]bool
+
+	queue cellPairPQ
+}
+
+// findEdgesMergeJoin finds the edges that satisfy the query options when the
+// target is the geometry in another ShapeIndex.
+//
+// Rather than measuring the distance from each candidate cell and edge with
+// a nested query on the target index, which takes time proportional to the
+// product of the sizes of the two indexes for nearby geometry, the cells of
+// the two indexes are merge-joined. Pairs of cells, one from each index, are
+// processed in order of the distance between them. A pair is split into the
+// children of its larger cell that contain cells of the index, until both
+// are index cells, at which point the distances between their edges are
+// computed directly.
+func (e *EdgeQuery) findEdgesMergeJoin(target shapeIndexTarget) {
+	tq := target.targetQuery()
+	bCovering, bCells := tq.covering()
+	aCovering, aCells := e.covering()
+	j := &shapeIndexJoin{
+		query:            e,
+		target:           target,
+		index:            tq.index,
+		iter:             tq.iter,
+		includeInteriors: tq.opts.includeInteriors,
+		contains:         NewContainsPointQuery(tq.index, VertexModelSemiOpen),
+		best:             make(map[ShapeEdgeID]Distance),
+		eligible:         make(map[ShapeEdgeID]bool),
+	}
+
+	for i, a := range aCovering {
+		if e.opts.region != nil && !e.opts.region.IntersectsCell(CellFromCellID(a)) {
+			continue
+		}
+		for k, b := range bCovering {
+			j.enqueue(a, aCells[i], b, bCells[k])
+		}
+	}
+
+	for len(j.queue) > 0 {
+		entry := heap.Pop(&j.queue).(*cellPairQueueEntry)
+		if !entry.distance.Less(e.distanceLimit) {
+			break
+		}
+		switch {
+		case entry.aCell != nil && entry.bCell != nil:
+			j.processEdges(entry.aCell, entry.bCell)
+		case entry.bCell != nil || (entry.aCell == nil && entry.a.Level() <= entry.b.Level()):
+			// Split the cell of the query index.
+			visitIndexChildren(e.iter, entry.a, func(child CellID, indexCell *ShapeIndexCell) {
+				if e.opts.region != nil && !e.opts.region.IntersectsCell(CellFromCellID(child)) {
+					return
+				}
+				j.enqueue(child, indexCell, entry.b, entry.bCell)
+			})
+		default:
+			// Split the cell of the target index.
+			visitIndexChildren(j.iter, entry.b, func(child CellID, indexCell *ShapeIndexCell) {
+				j.enqueue(entry.a, entry.aCell, child, indexCell)
+			})
+		}
+	}
+
+	if e.opts.maxResults == maxQueryResults {
+		// The results were not added while the query ran, since the distance
+		// to an edge may be improved by later pairs of cells.
+		for id, dist := range j.best {
+			e.results = append(e.results, EdgeQueryResult{dist, id.ShapeID, id.EdgeID})
+		}
+	}
+}
+
+// enqueue adds the given pair of cells to the queue if they may contain a
+// result, i.e. if the distance between them is less than the distance limit.
+func (j *shapeIndexJoin) enqueue(a CellID, aCell *ShapeIndexCell, b CellID, bCell *ShapeIndexCell) {
+	if aCell != nil && aCell.numEdges() == 0 {
+		return
+	}
+	// Index cells without edges are in the interior of some polygon.
+	if bCell != nil && bCell.numEdges() == 0 && !j.includeInteriors {
+		return
+	}
+	dist, ok := j.target.cellTarget(CellFromCellID(b)).UpdateDistanceToCell(CellFromCellID(a), j.query.distanceLimit)
+	if !ok {
+		return
+	}
+	heap.Push(&j.queue, &cellPairQueueEntry{
+		distance: dist,
+		a:        a,
+		b:        b,
+		aCell:    aCell,
+		bCell:    bCell,
+	})
+}
+
+// processEdges computes the distances from the edges of the query index cell
+// to the edges of the target index cell.
+func (j *shapeIndexJoin) processEdges(aCell, bCell *ShapeIndexCell) {
+	for _, aClipped := range aCell.shapes {
+		aShape := j.query.index.Shape(aClipped.shapeID)
+		for _, edgeID := range aClipped.edges {
+			id := ShapeEdgeID{aClipped.shapeID, int32(edgeID)}
+			edge := aShape.Edge(edgeID)
+			if !j.visitEdge(id, edge) {
+				continue
+			}
+
+			dist := j.query.distanceLimit
+			if best, ok := j.best[id]; ok && best.Less(dist) {
+				dist = best
+			}
+			target := j.target.edgeTarget(edge)
+			updated := false
+			for _, bClipped := range bCell.shapes {
+				bShape := j.index.Shape(bClipped.shapeID)
+				for _, bEdgeID := range bClipped.edges {
+					var ok bool
+					if dist, ok = target.UpdateDistanceToEdge(bShape.Edge(bEdgeID), dist); ok {
+						updated = true
+					}
+				}
+			}
+			if updated {
+				j.addResult(id, dist)
+			}
+		}
+	}
+}
+
+// visitEdge reports whether the given edge of the query index may be returned.
+// The first time an edge is visited, it is also tested for containment by the
+// polygons of the target index if interiors are included.
+func (j *shapeIndexJoin) visitEdge(id ShapeEdgeID, edge Edge) bool {
+	if eligible, ok := j.eligible[id]; ok {
+		return eligible
+	}
+	eligible := j.query.opts.region == nil || regionIntersectsEdge(j.query.opts.region, edge)
+	j.eligible[id] = eligible
+	if eligible && j.includeInteriors {
+		// As for the edge targets, the center of the edge is tested so that
+		// edges AB and BA give identical results.
+		p := j.target.interiorTestPoint(Point{edge.V0.Add(edge.V1.Vector).Normalize()})
+		if j.contains.Contains(p) {
+			j.addResult(id, j.target.Distance().Zero())
+		}
+	}
+	return eligible
+}
+
+// addResult records that the distance to the given edge is dist, which is
+// better than any distance found for it before.
+func (j *shapeIndexJoin) addResult(id ShapeEdgeID, dist Distance) {
+	e := j.query
+	j.best[id] = dist
+	switch e.opts.maxResults {
+	case maxQueryResults:
+		// All the results are added once the query is done.
+	case 1:
+		e.addResult(EdgeQueryResult{dist, id.ShapeID, id.EdgeID})
+	default:
+		i, ok := e.resultIDs[id]
+		if !ok {
+			e.addResult(EdgeQueryResult{dist, id.ShapeID, id.EdgeID})
+			return
+		}
+		// The edge is already one of the results, so just improve it.
+		e.results[i].distance = dist
+		heap.Fix(e.resultHeap(), i)
+		if len(e.results) == e.opts.maxResults {
+			e.distanceLimit = e.results[0].distance.Sub(e.target.Distance().FromChordAngle(e.opts.maxError))
+		}
+	}
+}
+
+// Edge returns the edge in the index corresponding to the given result. The
+// result must refer to an edge, i.e. it must not be an interior or empty
+// result.
+func (e *EdgeQuery) Edge(result EdgeQueryResult) Edge {
+	return e.index.Shape(result.shapeID).Edge(int(result.edgeID))
+}
+
+// Project returns the point on the edge of the given result that is closest
+// to point. If the result represents an interior (or is empty), point itself
+// is returned, since for interior results the point is contained by the
+// shape.
+//
+// This is a convenience method for projecting the target point of a
+// closest edge query onto the edges that were found, e.g.
+//
+//	target := NewMinDistanceToPointTarget(p)
+//	results := query.FindEdges(target)
+//	closest := query.Project(p, results[0])
+func (e *EdgeQuery) Project(point Point, result EdgeQueryResult) Point {
+	if result.edgeID < 0 {
+		return point
+	}
+	edge := e.Edge(result)
+	return Project(point, edge.V0, edge.V1)
+}
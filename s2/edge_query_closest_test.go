@@ -16,6 +16,7 @@ package s2
 
 import (
 	"fmt"
+	"reflect"
 	"testing"
 
 	"github.com/golang/geo/r3"
@@ -213,6 +214,58 @@ func TestClosestEdgeQueryTargetPolygonContainingIndexedPoints(t *testing.T) {
 	}
 }
 
+func TestClosestEdgeQueryRegion(t *testing.T) {
+	// Shape 3 crosses the rectangle below without having a vertex in it.
+	index := makeShapeIndex("# 0:0, 0:1 | 0:3, 0:4 | 0:6, 0:7 | -1:5, 1:5 #")
+	target := NewMinDistanceToPointTarget(parsePoint("0:-1"))
+
+	tests := []struct {
+		region Region
+		want   []int32
+	}{
+		{nil, []int32{0, 1, 3, 2}},
+		{rectFromDegrees(-1, 2.5, 1, 10), []int32{1, 3, 2}},
+		{CapFromCenterAngle(parsePoint("0:6.5"), 0.2*s1.Degree), []int32{2}},
+		{rectFromDegrees(-0.5, 4.5, 0.5, 5.5), []int32{3}},
+		{rectFromDegrees(10, 10, 20, 20), nil},
+	}
+	for _, test := range tests {
+		for _, bruteForce := range []bool{false, true} {
+			query := NewClosestEdgeQuery(index, NewClosestEdgeQueryOptions().
+				Region(test.region).UseBruteForce(bruteForce))
+			var got []int32
+			for _, result := range query.FindEdges(target) {
+				got = append(got, result.ShapeID())
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("FindEdges with Region(%v), UseBruteForce(%v) returned shapes %v, want %v",
+					test.region, bruteForce, got, test.want)
+			}
+		}
+	}
+}
+
+func TestRegionIntersectsEdge(t *testing.T) {
+	region := CapFromCenterAngle(parsePoint("0:0"), s1.Degree)
+	tests := []struct {
+		edge string
+		want bool
+	}{
+		{"0:0, 0:10", true},     // One vertex inside.
+		{"-5:0.5, 5:0.5", true}, // Crosses without a vertex inside.
+		{"0:-10, 0:10", true},   // Crosses through the center.
+		{"-5:2, 5:2", false},    // Passes by.
+		{"10:10, 20:20", false}, // Far away.
+	}
+	for _, test := range tests {
+		pts := parsePoints(test.edge)
+		edge := Edge{pts[0], pts[1]}
+		if got := regionIntersectsEdge(region, edge); got != test.want {
+			t.Errorf("regionIntersectsEdge(%v, %s) = %v, want %v", region, test.edge, got, test.want)
+		}
+	}
+}
+
 // BenchmarkEdgeQueryFindEdges encapulates the benchmarks into a more standard
 // form to cut down on repeated copy and paste with all the combinations of
 // benchmarks for EdgeQuery.
@@ -323,6 +376,21 @@ func BenchmarkEdgeQueryFindEdges(b *testing.B) {
 				centerSeparationFraction: -2.0,
 			},
 		},
+		{
+			// Test against a small target index abutting the query index.
+			benchCase: "ClosestToAbuttingIndex",
+			opts: &edgeQueryBenchmarkOptions{
+				includeInteriors:         false,
+				targetType:               queryTypeIndex,
+				numTargetEdges:           64,
+				chooseTargetFromIndex:    false,
+				radiusKm:                 1000,
+				maxDistanceFraction:      -1,
+				maxErrorFraction:         -1,
+				targetRadiusFraction:     1.0,
+				centerSeparationFraction: 2.0,
+			},
+		},
 	}
 
 	for _, bench := range benchmarks {
@@ -335,6 +403,33 @@ func BenchmarkEdgeQueryFindEdges(b *testing.B) {
 	}
 }
 
+func TestClosestEdgeQueryCircleEdges(t *testing.T) {
+	testEdgeQueryWithGenerator(t,
+		NewClosestEdgeQuery,
+		NewClosestEdgeQueryOptions,
+		closestEdgeQueryTargets,
+		loopShapeIndexGenerator, edgeQueryTestNumIndexes,
+		edgeQueryTestNumEdges, edgeQueryTestNumQueries)
+}
+
+func TestClosestEdgeQueryFractalEdges(t *testing.T) {
+	testEdgeQueryWithGenerator(t,
+		NewClosestEdgeQuery,
+		NewClosestEdgeQueryOptions,
+		closestEdgeQueryTargets,
+		fractalLoopShapeIndexGenerator, edgeQueryTestNumIndexes,
+		edgeQueryTestNumEdges, edgeQueryTestNumQueries)
+}
+
+func TestClosestEdgeQueryPointCloudEdges(t *testing.T) {
+	testEdgeQueryWithGenerator(t,
+		NewClosestEdgeQuery,
+		NewClosestEdgeQueryOptions,
+		closestEdgeQueryTargets,
+		pointCloudShapeIndexGenerator, edgeQueryTestNumIndexes,
+		edgeQueryTestNumEdges, edgeQueryTestNumQueries)
+}
+
 // TODO(roberts): Remaining tests to implement.
 //
 // TestClosestEdgeQueryTestReuseOfQuery) {
@@ -346,9 +441,6 @@ func BenchmarkEdgeQueryFindEdges(b *testing.B) {
 // TestClosestEdgeQueryFullLaxPolygonTarget) {
 // TestClosestEdgeQueryFullS2PolygonTarget) {
 // TestClosestEdgeQueryIsConservativeDistanceLessOrEqual) {
-// TestClosestEdgeQueryCircleEdges) {
-// TestClosestEdgeQueryFractalEdges) {
-// TestClosestEdgeQueryPointCloudEdges) {
 // TestClosestEdgeQueryConservativeCellDistanceIsUsed) {
 //
 // Add the remaining Benchmarking cases for each generator type.
@@ -263,29 +263,32 @@ func TestFurthestEdgeQueryFullPolygonTarget(t *testing.T) {
 	}
 }
 
-/*
 func TestFurthestEdgeQueryCircleEdges(t *testing.T) {
 	testEdgeQueryWithGenerator(t,
 		NewFurthestEdgeQuery,
 		NewFurthestEdgeQueryOptions,
+		furthestEdgeQueryTargets,
 		loopShapeIndexGenerator, edgeQueryTestNumIndexes,
 		edgeQueryTestNumEdges, edgeQueryTestNumQueries)
 }
+
 func TestFurthestEdgeQueryFractalEdges(t *testing.T) {
 	testEdgeQueryWithGenerator(t,
 		NewFurthestEdgeQuery,
 		NewFurthestEdgeQueryOptions,
+		furthestEdgeQueryTargets,
 		fractalLoopShapeIndexGenerator, edgeQueryTestNumIndexes,
 		edgeQueryTestNumEdges, edgeQueryTestNumQueries)
 }
+
 func TestFurthestEdgeQueryPointCloudEdges(t *testing.T) {
 	testEdgeQueryWithGenerator(t,
 		NewFurthestEdgeQuery,
 		NewFurthestEdgeQueryOptions,
+		furthestEdgeQueryTargets,
 		pointCloudShapeIndexGenerator, edgeQueryTestNumIndexes,
 		edgeQueryTestNumEdges, edgeQueryTestNumQueries)
 }
-*/
 
 // TODO(roberts): Remaining tests to implement.
 //
@@ -18,6 +18,7 @@ import (
 	"flag"
 
 	"fmt"
+	"math"
 	"math/rand"
 	"reflect"
 	"testing"
@@ -177,6 +178,208 @@ func TestEdgeQuerySortAndUnique(t *testing.T) {
 	}
 }
 
+func TestEdgeQueryEdgeAndProject(t *testing.T) {
+	// A polyline along the equator and a polygon north of it.
+	index := makeShapeIndex("# 0:0, 0:10 # 2:0, 2:5, 5:5, 5:0")
+	query := NewClosestEdgeQuery(index, NewClosestEdgeQueryOptions().MaxResults(1))
+
+	tests := []struct {
+		point    Point
+		shapeID  int32
+		want     Point
+		interior bool
+	}{
+		// Projects onto the interior of the polyline.
+		{parsePoint("-1:3"), 0, parsePoint("0:3"), false},
+		// Projects onto a polyline vertex.
+		{parsePoint("-1:12"), 0, parsePoint("0:10"), false},
+		// Contained by the polygon, so the point is its own projection.
+		{parsePoint("3:3"), 1, parsePoint("3:3"), true},
+	}
+
+	for _, test := range tests {
+		results := query.FindEdges(NewMinDistanceToPointTarget(test.point))
+		if len(results) != 1 {
+			t.Fatalf("FindEdges(%v) returned %d results, want 1", test.point, len(results))
+		}
+		r := results[0]
+		if r.ShapeID() != test.shapeID {
+			t.Errorf("FindEdges(%v).ShapeID() = %v, want %v", test.point, r.ShapeID(), test.shapeID)
+		}
+		if r.IsInterior() != test.interior {
+			t.Errorf("FindEdges(%v).IsInterior() = %v, want %v", test.point, r.IsInterior(), test.interior)
+		}
+		if !r.IsInterior() {
+			if got, want := query.Edge(r), index.Shape(r.ShapeID()).Edge(int(r.EdgeID())); got != want {
+				t.Errorf("Edge(%v) = %v, want %v", r, got, want)
+			}
+		}
+		if got := query.Project(test.point, r); !got.ApproxEqual(test.want) {
+			t.Errorf("Project(%v, %v) = %v, want %v", test.point, r, got, test.want)
+		}
+	}
+
+	// Empty results return the point unchanged.
+	p := parsePoint("1:1")
+	if got := query.Project(p, newEdgeQueryResult(NewMinDistanceToPointTarget(p))); got != p {
+		t.Errorf("Project(%v, empty result) = %v, want %v", p, got, p)
+	}
+}
+
+// customDistance is a Distance implemented entirely outside of the library
+// provided distance types, to verify that users can plug their own distance
+// measures into EdgeQuery.
+type customDistance s1.ChordAngle
+
+func (c customDistance) ChordAngle() s1.ChordAngle { return s1.ChordAngle(c) }
+func (c customDistance) FromChordAngle(o s1.ChordAngle) Distance {
+	return customDistance(o)
+}
+func (c customDistance) Zero() Distance     { return customDistance(0) }
+func (c customDistance) Negative() Distance { return customDistance(s1.NegativeChordAngle) }
+func (c customDistance) Infinity() Distance { return customDistance(s1.InfChordAngle()) }
+func (c customDistance) Less(other Distance) bool {
+	return c.ChordAngle() < other.ChordAngle()
+}
+func (c customDistance) Sub(other Distance) Distance {
+	return customDistance(c.ChordAngle() - other.ChordAngle())
+}
+func (c customDistance) ChordAngleBound() s1.ChordAngle {
+	return c.ChordAngle().Expanded(c.ChordAngle().MaxAngleError())
+}
+func (c customDistance) UpdateDistance(other Distance) (Distance, bool) {
+	if other.Less(c) {
+		return customDistance(other.ChordAngle()), true
+	}
+	return c, false
+}
+
+// customPointTarget measures the minimum distance to a point using
+// customDistance.
+type customPointTarget struct {
+	point Point
+}
+
+func (c *customPointTarget) CapBound() Cap {
+	return CapFromCenterChordAngle(c.point, 0)
+}
+func (c *customPointTarget) UpdateDistanceToPoint(p Point, dist Distance) (Distance, bool) {
+	return dist.UpdateDistance(customDistance(ChordAngleBetweenPoints(p, c.point)))
+}
+func (c *customPointTarget) UpdateDistanceToEdge(e Edge, dist Distance) (Distance, bool) {
+	if d, ok := UpdateMinDistance(c.point, e.V0, e.V1, dist.ChordAngle()); ok {
+		return customDistance(d), true
+	}
+	return dist, false
+}
+func (c *customPointTarget) UpdateDistanceToCell(cell Cell, dist Distance) (Distance, bool) {
+	return dist.UpdateDistance(customDistance(cell.Distance(c.point)))
+}
+func (c *customPointTarget) SetMaxError(maxErr s1.ChordAngle) bool { return false }
+func (c *customPointTarget) MaxBruteForceIndexSize() int           { return 120 }
+func (c *customPointTarget) Distance() Distance                    { return customDistance(0) }
+func (c *customPointTarget) VisitContainingShapes(index *ShapeIndex, v ShapePointVisitorFunc) bool {
+	return true
+}
+
+func TestEdgeQueryCustomDistanceTarget(t *testing.T) {
+	index := NewShapeIndex()
+	loopShapeIndexGenerator(CapFromCenterAngle(parsePoint("0:0"), s1.Degree), 1000, index)
+
+	for _, bruteForce := range []bool{true, false} {
+		opts := NewClosestEdgeQueryOptions().MaxResults(5).IncludeInteriors(false).UseBruteForce(bruteForce)
+		for _, p := range parsePoints("0:0, 0:1.5, 2:2, -0.5:0.5") {
+			want := NewClosestEdgeQuery(index, opts).FindEdges(NewMinDistanceToPointTarget(p))
+			got := NewClosestEdgeQuery(index, opts).FindEdges(&customPointTarget{p})
+			if len(got) != len(want) {
+				t.Errorf("bruteForce=%v: FindEdges(customPointTarget(%v)) returned %d results, want %d",
+					bruteForce, p, len(got), len(want))
+				continue
+			}
+			for i := range got {
+				if got[i].ShapeID() != want[i].ShapeID() || got[i].EdgeID() != want[i].EdgeID() ||
+					got[i].Distance() != want[i].Distance() {
+					t.Errorf("bruteForce=%v: FindEdges(customPointTarget(%v))[%d] = %+v, want %+v",
+						bruteForce, p, i, got[i], want[i])
+				}
+			}
+		}
+	}
+}
+
+func TestEdgeQueryShapeIndexTargetMergeJoin(t *testing.T) {
+	// The query and target indexes each hold a fractal polygon and a point
+	// cloud, and overlap partially so that some edges of each are inside the
+	// polygon of the other.
+	r := rand.New(rand.NewSource(1))
+	center := randomPoint(r)
+	radius := kmToAngle(100)
+	queryIndex := NewShapeIndex()
+	fractalLoopShapeIndexGenerator(CapFromCenterAngle(center, radius), 1000, queryIndex)
+	pointCloudShapeIndexGenerator(CapFromCenterAngle(center, 2*radius), 200, queryIndex)
+
+	targetCenter := InterpolateAtDistance(radius, center, randomPoint(r))
+	targetIndex := NewShapeIndex()
+	fractalLoopShapeIndexGenerator(CapFromCenterAngle(targetCenter, radius), 1000, targetIndex)
+	pointCloudShapeIndexGenerator(CapFromCenterAngle(targetCenter, 2*radius), 200, targetIndex)
+
+	tests := []struct {
+		name     string
+		newQuery func(*ShapeIndex, *EdgeQueryOptions) *EdgeQuery
+		newOpts  func() *EdgeQueryOptions
+		targets  edgeQueryTargets
+		limit    s1.ChordAngle
+	}{
+		{"Closest", NewClosestEdgeQuery, NewClosestEdgeQueryOptions, closestEdgeQueryTargets,
+			s1.ChordAngleFromAngle(radius / 10)},
+		{"Furthest", NewFurthestEdgeQuery, NewFurthestEdgeQueryOptions, furthestEdgeQueryTargets,
+			s1.ChordAngleFromAngle(3 * radius)},
+	}
+	for _, test := range tests {
+		for _, includeInteriors := range []bool{false, true} {
+			for _, maxResults := range []int{1, 10, maxQueryResults} {
+				opts := test.newOpts().IncludeInteriors(includeInteriors).MaxResults(maxResults)
+				if maxResults == maxQueryResults {
+					opts.DistanceLimit(test.limit)
+				}
+				query := test.newQuery(queryIndex, opts)
+
+				// A target that uses brute force measures the distance to
+				// each cell and edge of the query index with a nested query.
+				nested := test.targets.index(targetIndex, includeInteriors).(shapeIndexTarget)
+				nested.(interface{ setUseBruteForce(bool) }).setUseBruteForce(true)
+				want := append([]EdgeQueryResult(nil), query.FindEdges(nested)...)
+				got := query.FindEdges(test.targets.index(targetIndex, includeInteriors))
+
+				if len(want) == 0 {
+					t.Errorf("%s: FindEdges(includeInteriors=%v, maxResults=%d) returned no results",
+						test.name, includeInteriors, maxResults)
+				}
+				if maxResults == maxQueryResults {
+					if !reflect.DeepEqual(got, want) {
+						t.Errorf("%s: merge-joined FindEdges(includeInteriors=%v) = %v, want %v",
+							test.name, includeInteriors, got, want)
+					}
+					continue
+				}
+				// Edges at the same distance may be returned in place of
+				// each other, so only the distances are compared.
+				if len(got) != len(want) {
+					t.Errorf("%s: len(merge-joined FindEdges(includeInteriors=%v, maxResults=%d)) = %d, want %d",
+						test.name, includeInteriors, maxResults, len(got), len(want))
+					continue
+				}
+				for i := range got {
+					if got[i].Distance() != want[i].Distance() {
+						t.Errorf("%s: merge-joined FindEdges(includeInteriors=%v, maxResults=%d)[%d] = %v, want %v",
+							test.name, includeInteriors, maxResults, i, got[i], want[i])
+					}
+				}
+			}
+		}
+	}
+}
+
 // For various tests and benchmarks on the edge query code, there are a number of
 // ShapeIndex generators that can be used.
 type shapeIndexGeneratorFunc func(c Cap, numEdges int, index *ShapeIndex)
@@ -225,32 +428,144 @@ const edgeQueryTestNumQueries = 200
 // The approximate radius of Cap from which query edges are chosen.
 var testCapRadius = kmToAngle(10)
 
-/*
-// testEdgeQueryWithGenerator is used to perform high volume random testing on EdqeQuery
+// edgeQueryTargets creates the targets of the given types for a closest or
+// furthest edge query.
+type edgeQueryTargets struct {
+	point func(p Point) DistanceTarget
+	edge  func(e Edge) DistanceTarget
+	cell  func(c Cell) DistanceTarget
+	index func(index *ShapeIndex, includeInteriors bool) DistanceTarget
+}
+
+var closestEdgeQueryTargets = edgeQueryTargets{
+	point: func(p Point) DistanceTarget { return NewMinDistanceToPointTarget(p) },
+	edge:  func(e Edge) DistanceTarget { return NewMinDistanceToEdgeTarget(e) },
+	cell:  func(c Cell) DistanceTarget { return NewMinDistanceToCellTarget(c) },
+	index: func(index *ShapeIndex, includeInteriors bool) DistanceTarget {
+		target := NewMinDistanceToShapeIndexTarget(index)
+		target.setIncludeInteriors(includeInteriors)
+		return target
+	},
+}
+
+var furthestEdgeQueryTargets = edgeQueryTargets{
+	point: func(p Point) DistanceTarget { return NewMaxDistanceToPointTarget(p) },
+	edge:  func(e Edge) DistanceTarget { return NewMaxDistanceToEdgeTarget(e) },
+	cell:  func(c Cell) DistanceTarget { return NewMaxDistanceToCellTarget(c) },
+	index: func(index *ShapeIndex, includeInteriors bool) DistanceTarget {
+		target := NewMaxDistanceToShapeIndexTarget(index)
+		target.setIncludeInteriors(includeInteriors)
+		return target
+	},
+}
+
+// edgeQueryMaxPruningError is the amount by which results near the distance
+// limit may be missed, since the distances used to prune cells are not
+// computed conservatively.
+const edgeQueryMaxPruningError = s1.ChordAngle(1e-15)
+
+// checkEdgeQueryResultSet reports whether the results x contain every result
+// of y that they should: all results of y up to the distance limit if x was
+// not limited by maxResults, and otherwise all results of y that are closer
+// than the worst result of x by more than maxError.
+func checkEdgeQueryResultSet(t *testing.T, x, y []EdgeQueryResult, maxResults int, distanceLimit, maxError, maxPruningError Distance, label string) bool {
+	t.Helper()
+	limit := distanceLimit.Zero()
+	if len(x) < maxResults {
+		// The results were not limited by maxResults, so they should contain
+		// all the results up to the distance limit, except that a few
+		// results right near the limit may be missed.
+		limit = distanceLimit
+		if distanceLimit != distanceLimit.Infinity() {
+			limit = distanceLimit.Sub(maxPruningError)
+		}
+	} else if len(x) > 0 {
+		// The results contain only the closest maxResults results, to within
+		// a tolerance of maxError + maxPruningError.
+		limit = x[len(x)-1].distance.Sub(maxError).Sub(maxPruningError)
+	}
+
+	ok := true
+	for _, yr := range y {
+		// This also catches duplicate results.
+		count := 0
+		for _, xr := range x {
+			if xr.shapeID == yr.shapeID && xr.edgeID == yr.edgeID {
+				count++
+			}
+		}
+		if yr.distance.Less(limit) && count != 1 {
+			t.Errorf("%s (%d copies): %+v", label, count, yr)
+			ok = false
+		}
+	}
+	return ok
+}
+
+// testFindEdges checks that the optimized and brute force algorithms return
+// consistent results for the given target, and that the distance methods of
+// the query are consistent with them.
+func testFindEdges(t *testing.T, target DistanceTarget, query *EdgeQuery) {
+	t.Helper()
+	query.opts.useBruteForce = true
+	expected := append([]EdgeQueryResult(nil), query.FindEdges(target)...)
+	query.opts.useBruteForce = false
+	actual := append([]EdgeQueryResult(nil), query.FindEdges(target)...)
+
+	dist := target.Distance()
+	distanceLimit := dist.FromChordAngle(query.opts.distanceLimit)
+	maxError := dist.FromChordAngle(query.opts.maxError)
+	checkEdgeQueryResultSet(t, actual, expected, query.opts.maxResults, distanceLimit,
+		maxError, dist.FromChordAngle(edgeQueryMaxPruningError), "Missing")
+	checkEdgeQueryResultSet(t, expected, actual, query.opts.maxResults, distanceLimit,
+		maxError, dist.Zero(), "Extra")
+
+	if len(expected) == 0 {
+		return
+	}
+	// Note that when maxError > 0, expected[0].distance may not be the
+	// minimum distance. It is never worse by more than maxError, but the
+	// actual value also depends on maxResults.
+	//
+	// Here we verify that Distance and IsDistanceLess return results that
+	// are consistent with the maxError setting.
+	best := expected[0].distance
+	if got := dist.FromChordAngle(query.Distance(target)); best.Less(got.Sub(maxError)) {
+		t.Errorf("query.Distance(%v) = %v, want no worse than %v with max error %v", target, got, best, maxError)
+	}
+	if query.IsDistanceLess(target, best.Sub(maxError).ChordAngle()) {
+		t.Errorf("query.IsDistanceLess(%v, %v) = true, want false", target, best.Sub(maxError))
+	}
+	if query.opts.maxResults == 1 && len(actual) != 1 {
+		t.Errorf("query with MaxResults(1) returned %d results", len(actual))
+	}
+}
+
+// testEdgeQueryWithGenerator is used to perform high volume random testing on EdgeQuery
 // using a variety of index generation methods and varying sizes.
 //
 // The running time of this test is proportional to
-//    (numIndexes + numQueries) * numEdges.
+// (numIndexes + numQueries) * numEdges.
 // Every query is checked using the brute force algorithm.
 func testEdgeQueryWithGenerator(t *testing.T,
 	newQueryFunc func(si *ShapeIndex, opts *EdgeQueryOptions) *EdgeQuery,
 	newOptsFunc func() *EdgeQueryOptions,
+	targets edgeQueryTargets,
 	gen shapeIndexGeneratorFunc,
 	numIndexes, numEdges, numQueries int) {
+	r := rand.New(rand.NewSource(1))
 
 	// Build a set of ShapeIndexes containing the desired geometry.
 	var indexCaps []Cap
 	var indexes []*ShapeIndex
 	for i := 0; i < numIndexes; i++ {
-	        r := rand.New(rand.NewSource(i))
 		indexCaps = append(indexCaps, CapFromCenterAngle(randomPoint(r), testCapRadius))
 		indexes = append(indexes, NewShapeIndex())
 		gen(indexCaps[i], numEdges, indexes[i])
 	}
 
 	for i := 0; i < numQueries; i++ {
-	        r := rand.New(rand.NewSource(i))
-		iIndex := randomUniformInt(numIndexes)
+		iIndex := randomUniformInt(numIndexes, r)
 		indexCap := indexCaps[iIndex]
 
 		// Choose query points from an area approximately 4x larger than the
@@ -258,68 +573,61 @@ func testEdgeQueryWithGenerator(t *testing.T,
 		queryRadius := 2 * indexCap.Radius()
 		// Exercise the opposite-hemisphere code 1/5 of the time.
 		antipodal := 1.0
-		if oneIn(5) {
+		if oneIn(5, r) {
 			antipodal = -1
 		}
-		//queryCap := CapFromCenterAngle(indexCap.Center(), queryRadius)
 		queryCap := CapFromCenterAngle(Point{indexCap.Center().Mul(antipodal)}, queryRadius)
 
 		opts := newOptsFunc()
 
 		// Occasionally we don't set any limit on the number of result edges.
 		// (This may return all edges if we also don't set a distance limit.)
-		if oneIn(5) {
+		if !oneIn(5, r) {
 			opts.MaxResults(1 + randomUniformInt(10, r))
 		}
 
 		// We set a distance limit 1/3 of the time.
-		if oneIn(3) {
+		if oneIn(3, r) {
 			opts.DistanceLimit(s1.ChordAngleFromAngle(s1.Angle(randomFloat64(r)) * queryRadius))
 		}
-		if oneIn(2) {
+		if oneIn(2, r) {
 			// Choose a maximum error whose logarithm is uniformly distributed over
 			// a reasonable range, except that it is sometimes zero.
 			opts.MaxError(s1.ChordAngleFromAngle(s1.Angle(math.Pow(1e-4, randomFloat64(r)) * queryRadius.Radians())))
 		}
-		opts.IncludeInteriors(oneIn(2))
+		opts.IncludeInteriors(oneIn(2, r))
+		if oneIn(5, r) {
+			// Restrict the results to a region overlapping the geometry.
+			opts.Region(CapFromCenterAngle(samplePointFromCap(indexCap, r), s1.Angle(randomFloat64(r))*indexCap.Radius()))
+		}
 
 		query := newQueryFunc(indexes[iIndex], opts)
 
 		switch randomUniformInt(4, r) {
 		case 0:
-			// Find the edges furthest from a given point.
-			point := samplePointFromCap(queryCap)
-			target := NewMaxDistanceToPointTarget(point)
-			testFindEdges(target, query)
+			// Find the edges closest to or furthest from a given point.
+			testFindEdges(t, targets.point(samplePointFromCap(queryCap, r)), query)
 		case 1:
-			// Find the edges furthest from a given edge.
-			a := samplePointFromCap(queryCap)
+			// Find the edges closest to or furthest from a given edge.
+			a := samplePointFromCap(queryCap, r)
 			b := samplePointFromCap(
-				CapFromCenterAngle(a, s1.Angle(math.Pow(1e-4, randomFloat64(r)))*queryRadius))
-			target := NewMaxDistanceToEdgeTarget(Edge{a, b})
-			testFindEdges(target, query)
-
+				CapFromCenterAngle(a, s1.Angle(math.Pow(1e-4, randomFloat64(r)))*queryRadius), r)
+			testFindEdges(t, targets.edge(Edge{a, b}), query)
 		case 2:
-			// Find the edges furthest from a given cell.
+			// Find the edges closest to or furthest from a given cell.
 			minLevel := MaxDiagMetric.MinLevel(queryRadius.Radians())
 			level := minLevel + randomUniformInt(MaxLevel-minLevel+1, r)
-			a := samplePointFromCap(queryCap)
+			a := samplePointFromCap(queryCap, r)
 			cell := CellFromCellID(cellIDFromPoint(a).Parent(level))
-			target := NewMaxDistanceToCellTarget(cell)
-			testFindEdges(target, query)
-
+			testFindEdges(t, targets.cell(cell), query)
 		case 3:
 			// Use another one of the pre-built indexes as the target.
 			jIndex := randomUniformInt(numIndexes, r)
-			target := NewMaxDistanceToShapeIndexTarget(indexes[jIndex])
-			target.setIncludeInteriors(oneIn(2))
-			testFindEdges(target, query)
+			testFindEdges(t, targets.index(indexes[jIndex], oneIn(2, r)), query)
 		}
 	}
 }
 
-*/
-
 // benchmarkEdgeQueryFindClosest calls FindEdges the given number of times on
 // a ShapeIndex with approximately numIndexEdges edges generated by the given
 // generator. The geometry is generated within a Cap of the radius given.
@@ -351,7 +659,7 @@ func benchmarkEdgeQueryFindClosest(b *testing.B, bmOpts *edgeQueryBenchmarkOptio
 	opts.UseBruteForce(*benchmarkBruteForce)
 	query := NewClosestEdgeQuery(index, opts)
 
-	var targets []distanceTarget
+	var targets []DistanceTarget
 
 	// To follow the sizing on the C++ tests to ease comparisons, the number of
 	// edges in the index range on 3 * 4^n (up to ~48k by default).
@@ -364,6 +672,8 @@ func benchmarkEdgeQueryFindClosest(b *testing.B, bmOpts *edgeQueryBenchmarkOptio
 				// ShapeIndexes. Incorporate it once ShapeIndexTargets
 				// are able to be used in tests.
 				targets, _ = generateEdgeQueryWithTargets(bmOpts, query, index)
+				index.Build()
+				b.ResetTimer()
 				for i := 0; i < b.N; i++ {
 					// TODO(rsned): In the reference C++ benchmark
 					// they use the tooling to split the benchmark
@@ -427,7 +737,7 @@ type edgeQueryBenchmarkOptions struct {
 //	- The randomSeed is used to initialize an internal seed, which is
 //	  incremented at the start of each call to generateEdgeQueryWithTargets.
 //	  This is for debugging purposes.
-func generateEdgeQueryWithTargets(opts *edgeQueryBenchmarkOptions, query *EdgeQuery, queryIndex *ShapeIndex) (targets []distanceTarget, targetIndexes []*ShapeIndex) {
+func generateEdgeQueryWithTargets(opts *edgeQueryBenchmarkOptions, query *EdgeQuery, queryIndex *ShapeIndex) (targets []DistanceTarget, targetIndexes []*ShapeIndex) {
 
 	// To save time, we generate at most this many distinct targets per index.
 	const maxTargetsPerIndex = 100
@@ -436,13 +746,13 @@ func generateEdgeQueryWithTargets(opts *edgeQueryBenchmarkOptions, query *EdgeQu
 	// Replace with r := rand.New(rand.NewSource(opts.randomSeed)) and pass through.
 	r := rand.New(rand.NewSource(opts.randomSeed))
 	opts.randomSeed++
-	indexCap := CapFromCenterAngle(randomPoint(r), opts.radiusKm)
+	indexCap := CapFromCenterAngle(randomPoint(r), kmToAngle(opts.radiusKm.Radians()))
 
 	query.Reset()
 	queryIndex.Reset()
 	opts.indexGenerator(indexCap, opts.numIndexEdges, queryIndex)
 
-	targets = make([]distanceTarget, 0)
+	targets = make([]DistanceTarget, 0)
 	targetIndexes = make([]*ShapeIndex, 0)
 
 	numTargets := maxTargetsPerIndex
@@ -528,7 +838,7 @@ func sampleEdgeFromIndex(index *ShapeIndex) Edge {
 
 func sampleCellFromIndex(index *ShapeIndex) CellID {
 	iter := index.Iterator()
-	for i := randomUniformInt(len(index.cells)); i >= 0; i-- {
+	for i := randomUniformInt(index.cells.len); i >= 0; i-- {
 		iter.Next()
 		continue
 	}
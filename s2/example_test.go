@@ -16,7 +16,9 @@ package s2_test
 
 import (
 	"fmt"
+	"math"
 
+	"github.com/golang/geo/r2"
 	"github.com/golang/geo/s1"
 	"github.com/golang/geo/s2"
 )
@@ -303,3 +305,57 @@ func ExampleEdgeQuery_FindEdges_findFurthestEdges() {
 	// Polyline 0, Edge 33 is 26.115 degrees from Point (-0.425124, -0.667311, 0.611527)
 
 }
+
+// kmProjection is a Projection that scales the coordinates of another
+// projection from units of the Earth's radius to kilometers.
+type kmProjection struct {
+	proj s2.Projection
+}
+
+const earthRadiusKm = 6371.01
+
+func (p kmProjection) Project(pt s2.Point) r2.Point {
+	return p.proj.Project(pt).Mul(earthRadiusKm)
+}
+
+func (p kmProjection) Unproject(pt r2.Point) s2.Point {
+	return p.proj.Unproject(pt.Mul(1 / earthRadiusKm))
+}
+
+func (p kmProjection) FromLatLng(ll s2.LatLng) r2.Point {
+	return p.proj.FromLatLng(ll).Mul(earthRadiusKm)
+}
+
+func (p kmProjection) ToLatLng(pt r2.Point) s2.LatLng {
+	return p.proj.ToLatLng(pt.Mul(1 / earthRadiusKm))
+}
+
+func (p kmProjection) Interpolate(f float64, a, b r2.Point) r2.Point {
+	return a.Mul(1 - f).Add(b.Mul(f))
+}
+
+func (p kmProjection) WrapDistance() r2.Point                 { return r2.Point{} }
+func (p kmProjection) WrapDestination(a, b r2.Point) r2.Point { return b }
+
+func ExampleNewEdgeTessellator_customProjection() {
+	// Draw the edge between two points at 70 degrees north on a polar
+	// stereographic map in kilometers, with an error of at most 1km.
+	proj := kmProjection{s2.NewStereographicProjection(s2.PointFromCoords(0, 0, 1))}
+	tess := s2.NewEdgeTessellator(proj, s1.Angle(1/earthRadiusKm))
+
+	a := s2.PointFromLatLng(s2.LatLngFromDegrees(70, 0))
+	b := s2.PointFromLatLng(s2.LatLngFromDegrees(70, 90))
+	for _, v := range tess.AppendProjected(a, b, nil) {
+		fmt.Printf("%d, %d\n", int(math.Round(v.X)), int(math.Round(v.Y)))
+	}
+	// Output:
+	// 0, -2247
+	// 290, -1972
+	// 577, -1696
+	// 860, -1419
+	// 1141, -1141
+	// 1419, -860
+	// 1696, -577
+	// 1972, -290
+	// 2247, 0
+}
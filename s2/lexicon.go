@@ -16,137 +16,331 @@ package s2
 
 import (
 	"encoding/binary"
+	"fmt"
 	"hash/adler32"
+	"io"
 	"math"
 	"sort"
 )
 
-// TODO(roberts): If any of these are worth making public, change the
-// method signatures and type names.
-
-// emptySetID represents the last ID that will ever be generated.
+// EmptySetID represents the last ID that will ever be generated.
 // (Non-negative IDs are reserved for singleton sets.)
-var emptySetID = int32(math.MinInt32)
+const EmptySetID = int32(math.MinInt32)
 
-// idSetLexicon compactly represents a set of non-negative
+// IDSetLexicon compactly represents a set of non-negative
 // integers such as array indices ("ID sets"). It is especially suitable when
 // either (1) there are many duplicate sets, or (2) there are many singleton
-// or empty sets. See also sequenceLexicon.
+// or empty sets. See also SequenceLexicon and ValueSetLexicon.
 //
 // Each distinct ID set is mapped to a 32-bit integer. Empty and singleton
 // sets take up no additional space; the set itself is represented
 // by the unique ID assigned to the set. Duplicate sets are automatically
 // eliminated. Note also that ID sets are referred to using 32-bit integers
 // rather than pointers.
-type idSetLexicon struct {
-	idSets *sequenceLexicon
+//
+// The zero value for an IDSetLexicon is an empty lexicon ready to use.
+type IDSetLexicon struct {
+	idSets *SequenceLexicon
 }
 
-func newIDSetLexicon() *idSetLexicon {
-	return &idSetLexicon{
-		idSets: newSequenceLexicon(),
+// NewIDSetLexicon returns a new, empty IDSetLexicon.
+func NewIDSetLexicon() *IDSetLexicon {
+	return &IDSetLexicon{
+		idSets: NewSequenceLexicon(),
 	}
 }
 
-// add adds the given set of integers to the lexicon if it is not already
+// Add adds the given set of integers to the lexicon if it is not already
 // present, and return the unique ID for this set. The values are automatically
 // sorted and duplicates are removed.
 //
-// The primary difference between this and sequenceLexicon are:
-// 1. Empty and singleton sets are represented implicitly; they use no space.
-// 2. Sets are represented rather than sequences; the ordering of values is
-//
-//	not important and duplicates are removed.
-//
-// 3. The values must be 32-bit non-negative integers only.
-func (l *idSetLexicon) add(ids ...int32) int32 {
+// The primary difference between this and SequenceLexicon are:
+//  1. Empty and singleton sets are represented implicitly; they use no space.
+//  2. Sets are represented rather than sequences; the ordering of values is
+//     not important and duplicates are removed.
+//  3. The values must be 32-bit non-negative integers only.
+func (l *IDSetLexicon) Add(ids ...int32) int32 {
+	// Canonicalize the set by sorting and removing duplicates.
+	//
+	// Creates a new slice in order to not alter the supplied values.
+	set := uniqueInt32s(ids)
+
 	// Empty sets have a special ID chosen not to conflict with other IDs.
-	if len(ids) == 0 {
-		return emptySetID
+	if len(set) == 0 {
+		return EmptySetID
 	}
 
 	// Singleton sets are represented by their element.
-	if len(ids) == 1 {
-		return ids[0]
+	if len(set) == 1 {
+		return set[0]
 	}
 
-	// Canonicalize the set by sorting and removing duplicates.
-	//
-	// Creates a new slice in order to not alter the supplied values.
-	set := uniqueInt32s(ids)
-
 	// Non-singleton sets are represented by the bitwise complement of the ID
-	// returned by the sequenceLexicon
-	return ^l.idSets.add(set)
+	// returned by the SequenceLexicon
+	return ^l.sequences().Add(set)
+}
+
+// sequences returns the lexicon of the non-singleton sets, creating it if
+// this is the zero IDSetLexicon.
+func (l *IDSetLexicon) sequences() *SequenceLexicon {
+	if l.idSets == nil {
+		l.idSets = NewSequenceLexicon()
+	}
+	return l.idSets
 }
 
-// idSet returns the set of integers corresponding to an ID returned by add.
-func (l *idSetLexicon) idSet(setID int32) []int32 {
+// IDSet returns the set of integers corresponding to an ID returned by Add.
+func (l *IDSetLexicon) IDSet(setID int32) []int32 {
 	if setID >= 0 {
 		return []int32{setID}
 	}
-	if setID == emptySetID {
+	if setID == EmptySetID {
 		return []int32{}
 	}
 
-	return l.idSets.sequence(^setID)
+	return l.sequences().Sequence(^setID)
 }
 
-func (l *idSetLexicon) clear() {
-	l.idSets.clear()
+// Clear clears all data from the lexicon.
+func (l *IDSetLexicon) Clear() {
+	l.sequences().Clear()
 }
 
-// sequenceLexicon compactly represents a sequence of values (e.g., tuples).
+// Encode encodes the IDSetLexicon. Only the non-singleton sets are stored,
+// using the encoding of SequenceLexicon.
+func (l *IDSetLexicon) Encode(w io.Writer) error {
+	return l.sequences().Encode(w)
+}
+
+// Decode decodes the IDSetLexicon.
+func (l *IDSetLexicon) Decode(r io.Reader) error {
+	return l.sequences().Decode(r)
+}
+
+// ValueSetLexicon is like IDSetLexicon, but for sets of arbitrary comparable
+// values rather than non-negative integers. Each distinct value is stored
+// once and assigned an integer ID, and each distinct set of values is then
+// mapped to a 32-bit integer using an IDSetLexicon.
+//
+// This is useful, for example, for compactly storing labels or attributes
+// when there are many edges or features that share the same set of them.
+//
+// The zero value for a ValueSetLexicon is an empty lexicon ready to use.
+type ValueSetLexicon[T comparable] struct {
+	values   []T
+	valueIDs map[T]int32
+	sets     *IDSetLexicon
+}
+
+// NewValueSetLexicon returns a new, empty ValueSetLexicon.
+func NewValueSetLexicon[T comparable]() *ValueSetLexicon[T] {
+	return &ValueSetLexicon[T]{
+		valueIDs: make(map[T]int32),
+		sets:     NewIDSetLexicon(),
+	}
+}
+
+// Add adds the given set of values to the lexicon if it is not already
+// present, and returns the unique ID for this set. The ordering of the
+// values is not important and duplicates are removed.
+func (l *ValueSetLexicon[T]) Add(values ...T) int32 {
+	ids := make([]int32, 0, len(values))
+	for _, v := range values {
+		id, ok := l.valueIDs[v]
+		if !ok {
+			if l.valueIDs == nil {
+				l.valueIDs = make(map[T]int32)
+			}
+			id = int32(len(l.values))
+			l.values = append(l.values, v)
+			l.valueIDs[v] = id
+		}
+		ids = append(ids, id)
+	}
+	return l.idSets().Add(ids...)
+}
+
+// idSets returns the lexicon of the sets of value IDs, creating it if this
+// is the zero ValueSetLexicon.
+func (l *ValueSetLexicon[T]) idSets() *IDSetLexicon {
+	if l.sets == nil {
+		l.sets = NewIDSetLexicon()
+	}
+	return l.sets
+}
+
+// ValueSet returns the set of values corresponding to an ID returned by Add.
+// The values are returned in the order in which they were first added to
+// the lexicon.
+func (l *ValueSetLexicon[T]) ValueSet(setID int32) []T {
+	ids := l.idSets().IDSet(setID)
+	values := make([]T, 0, len(ids))
+	for _, id := range ids {
+		values = append(values, l.values[id])
+	}
+	return values
+}
+
+// NumValues reports the number of distinct values in the lexicon.
+func (l *ValueSetLexicon[T]) NumValues() int {
+	return len(l.values)
+}
+
+// Clear clears all data from the lexicon.
+func (l *ValueSetLexicon[T]) Clear() {
+	l.values = nil
+	l.valueIDs = make(map[T]int32)
+	l.idSets().Clear()
+}
+
+// SequenceLexicon compactly represents a sequence of values (e.g., tuples).
 // It automatically eliminates duplicates slices, and maps the remaining
-// sequences to sequentially increasing integer IDs. See also idSetLexicon.
+// sequences to sequentially increasing integer IDs. See also IDSetLexicon.
 //
 // Each distinct sequence is mapped to a 32-bit integer.
-type sequenceLexicon struct {
+//
+// The zero value for a SequenceLexicon is an empty lexicon ready to use.
+type SequenceLexicon struct {
 	values []int32
 	begins []uint32
 
-	// idSet is a mapping of a sequence hash to sequence index in the lexicon.
-	idSet map[uint32]int32
+	// idSet is a mapping of a sequence hash to the IDs of the sequences in
+	// the lexicon with that hash.
+	idSet map[uint32][]int32
 }
 
-func newSequenceLexicon() *sequenceLexicon {
-	return &sequenceLexicon{
+// NewSequenceLexicon returns a new, empty SequenceLexicon.
+func NewSequenceLexicon() *SequenceLexicon {
+	return &SequenceLexicon{
 		begins: []uint32{0},
-		idSet:  make(map[uint32]int32),
+		idSet:  make(map[uint32][]int32),
 	}
 }
 
-// clears all data from the lexicon.
-func (l *sequenceLexicon) clear() {
+// Clear clears all data from the lexicon.
+func (l *SequenceLexicon) Clear() {
 	l.values = nil
 	l.begins = []uint32{0}
-	l.idSet = make(map[uint32]int32)
+	l.idSet = make(map[uint32][]int32)
 }
 
-// add adds the given value to the lexicon if it is not already present, and
+// Add adds the given value to the lexicon if it is not already present, and
 // returns its ID. IDs are assigned sequentially starting from zero.
-func (l *sequenceLexicon) add(ids []int32) int32 {
-	if id, ok := l.idSet[hashSet(ids)]; ok {
+func (l *SequenceLexicon) Add(ids []int32) int32 {
+	if l.idSet == nil {
+		// This is the zero SequenceLexicon.
+		l.Clear()
+	}
+	h := hashSet(ids)
+	if id, ok := l.find(h, ids); ok {
 		return id
 	}
 	l.values = append(l.values, ids...)
 	l.begins = append(l.begins, uint32(len(l.values)))
 
 	id := int32(len(l.begins)) - 2
-	l.idSet[hashSet(ids)] = id
+	l.idSet[h] = append(l.idSet[h], id)
 
 	return id
 }
 
-// sequence returns the original sequence of values for the given ID.
-func (l *sequenceLexicon) sequence(id int32) []int32 {
+// find returns the ID of the given sequence with the given hash if it is
+// present in the lexicon.
+func (l *SequenceLexicon) find(h uint32, ids []int32) (int32, bool) {
+	for _, id := range l.idSet[h] {
+		if equalInt32s(l.Sequence(id), ids) {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
+// Sequence returns the original sequence of values for the given ID.
+func (l *SequenceLexicon) Sequence(id int32) []int32 {
 	return l.values[l.begins[id]:l.begins[id+1]]
 }
 
-// size reports the number of value sequences in the lexicon.
-func (l *sequenceLexicon) size() int {
+// Size reports the number of value sequences in the lexicon.
+func (l *SequenceLexicon) Size() int {
 	// Subtract one because the list of begins starts out with the first element set to 0.
-	return len(l.begins) - 1
+	return max(0, len(l.begins)-1)
+}
+
+// Encode encodes the SequenceLexicon.
+//
+// The encoding consists of a version byte, a varint with the number of
+// sequences, a varint with the length of each sequence, and then all of
+// the values in order as 32-bit little-endian integers. Decoding the result
+// assigns every sequence the same ID that it has in this lexicon.
+//
+// The C++ SequenceLexicon has no encoding, so this format is defined by this
+// package.
+func (l *SequenceLexicon) Encode(w io.Writer) error {
+	e := &encoder{w: w}
+	l.encode(e)
+	return e.err
+}
+
+func (l *SequenceLexicon) encode(e *encoder) {
+	e.writeInt8(encodingVersion)
+	e.writeUvarint(uint64(l.Size()))
+	for i := 1; i < len(l.begins); i++ {
+		e.writeUvarint(uint64(l.begins[i] - l.begins[i-1]))
+	}
+	for _, v := range l.values {
+		e.writeUint32(uint32(v))
+	}
+}
+
+// Decode decodes the SequenceLexicon.
+func (l *SequenceLexicon) Decode(r io.Reader) error {
+	d := &decoder{r: asByteReader(r)}
+	l.decode(d)
+	return d.err
+}
+
+func (l *SequenceLexicon) decode(d *decoder) {
+	version := d.readInt8()
+	if d.err != nil {
+		return
+	}
+	if version != encodingVersion {
+		d.err = fmt.Errorf("cannot decode version %d", version)
+		return
+	}
+
+	n := d.readUvarint()
+	if d.err != nil {
+		return
+	}
+	if n > math.MaxInt32 {
+		d.err = fmt.Errorf("too many sequences (%d; max is %d)", n, math.MaxInt32)
+		return
+	}
+	begins := []uint32{0}
+	for i := uint64(0); i < n && d.err == nil; i++ {
+		length := d.readUvarint()
+		end := uint64(begins[len(begins)-1]) + length
+		if d.err == nil && end > math.MaxUint32 {
+			d.err = fmt.Errorf("too many sequence values (%d; max is %d)", end, uint32(math.MaxUint32))
+		}
+		begins = append(begins, uint32(end))
+	}
+	var values []int32
+	for i := uint32(0); i < begins[len(begins)-1] && d.err == nil; i++ {
+		values = append(values, int32(d.readUint32()))
+	}
+	if d.err != nil {
+		return
+	}
+
+	l.Clear()
+	l.values = values
+	l.begins = begins
+	for id := int32(0); int(id) < l.Size(); id++ {
+		h := hashSet(l.Sequence(id))
+		l.idSet[h] = append(l.idSet[h], id)
+	}
 }
 
 // hashSet returns a hash of this sequence of int32s.
@@ -159,6 +353,19 @@ func hashSet(s []int32) uint32 {
 	return a.Sum32()
 }
 
+// equalInt32s reports whether the two slices contain the same values.
+func equalInt32s(a, b []int32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
 // uniqueInt32s returns the sorted and uniqued set of int32s from the input.
 func uniqueInt32s(in []int32) []int32 {
 	var vals []int32
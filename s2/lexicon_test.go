@@ -15,6 +15,7 @@
 package s2
 
 import (
+	"bytes"
 	"math"
 	"reflect"
 	"testing"
@@ -36,40 +37,40 @@ func TestSequenceLexiconAdd(t *testing.T) {
 		{have: []int32{}, want: 0},
 	}
 
-	lex := newSequenceLexicon()
+	lex := NewSequenceLexicon()
 	for _, test := range tests {
-		if got := lex.add(test.have); got != test.want {
-			t.Errorf("lexicon.add(%v) = %v, want %v", test.have, got, test.want)
+		if got := lex.Add(test.have); got != test.want {
+			t.Errorf("lexicon.Add(%v) = %v, want %v", test.have, got, test.want)
 		}
 
 	}
 
-	if lex.size() != 5 {
-		t.Errorf("lexicon.size() = %v, want 5", lex.size())
+	if lex.Size() != 5 {
+		t.Errorf("lexicon.Size() = %v, want 5", lex.Size())
 	}
 
 	for _, test := range tests {
-		if got := lex.sequence(test.want); !reflect.DeepEqual(got, test.have) {
-			t.Errorf("lexicon.sequence(%v) = %v, want %v", test.want, got, test.have)
+		if got := lex.Sequence(test.want); !reflect.DeepEqual(got, test.have) {
+			t.Errorf("lexicon.Sequence(%v) = %v, want %v", test.want, got, test.have)
 		}
 	}
 }
 
 func TestSequenceLexiconClear(t *testing.T) {
-	lex := newSequenceLexicon()
+	lex := NewSequenceLexicon()
 
-	if got, want := lex.add([]int32{1}), int32(0); got != want {
-		t.Errorf("lex.add([]int32{1}) = %v, want %v", got, want)
+	if got, want := lex.Add([]int32{1}), int32(0); got != want {
+		t.Errorf("lex.Add([]int32{1}) = %v, want %v", got, want)
 	}
-	if got, want := lex.add([]int32{2}), int32(1); got != want {
-		t.Errorf("lex.add(sequence{2}) = %v, want %v", got, want)
+	if got, want := lex.Add([]int32{2}), int32(1); got != want {
+		t.Errorf("lex.Add(sequence{2}) = %v, want %v", got, want)
 	}
-	lex.clear()
-	if got, want := lex.add([]int32{2}), int32(0); got != want {
-		t.Errorf("lex.add([]int32{2}) = %v, want %v", got, want)
+	lex.Clear()
+	if got, want := lex.Add([]int32{2}), int32(0); got != want {
+		t.Errorf("lex.Add([]int32{2}) = %v, want %v", got, want)
 	}
-	if got, want := lex.add([]int32{1}), int32(1); got != want {
-		t.Errorf("lex.add([]int32{1}) = %v, want %v", got, want)
+	if got, want := lex.Add([]int32{1}), int32(1); got != want {
+		t.Errorf("lex.Add([]int32{1}) = %v, want %v", got, want)
 	}
 }
 
@@ -85,18 +86,18 @@ func TestIDSetLexiconSingletonSets(t *testing.T) {
 		{m, m},
 	}
 
-	lex := newIDSetLexicon()
+	lex := NewIDSetLexicon()
 	// Test adding
 	for _, test := range tests {
-		if got := lex.add(test.have); got != test.want {
-			t.Errorf("lexicon.add(%v) = %v, want %v", test.have, got, test.want)
+		if got := lex.Add(test.have); got != test.want {
+			t.Errorf("lexicon.Add(%v) = %v, want %v", test.have, got, test.want)
 		}
 	}
 
 	// Test recall
 	for _, test := range tests {
-		if got := lex.idSet(test.want); !reflect.DeepEqual(got, []int32{test.have}) {
-			t.Errorf("lexicon.idSet(%v) = %v, want %v", test.want, got, test.have)
+		if got := lex.IDSet(test.want); !reflect.DeepEqual(got, []int32{test.have}) {
+			t.Errorf("lexicon.IDSet(%v) = %v, want %v", test.want, got, test.have)
 		}
 	}
 }
@@ -129,10 +130,10 @@ func TestIDSetLexiconSetsAreSorted(t *testing.T) {
 		},
 	}
 
-	lexicon := newIDSetLexicon()
+	lexicon := NewIDSetLexicon()
 	for _, test := range tests {
-		if got := lexicon.add(test.have...); got != test.want {
-			t.Errorf("lexicon.addSet(%v) = %v, want %v", test.have, got, test.want)
+		if got := lexicon.Add(test.have...); got != test.want {
+			t.Errorf("lexicon.Add(%v) = %v, want %v", test.have, got, test.want)
 		}
 	}
 
@@ -151,27 +152,215 @@ func TestIDSetLexiconSetsAreSorted(t *testing.T) {
 	}
 
 	for _, test := range recallTests {
-		if got := lexicon.idSet(test.have); !reflect.DeepEqual(got, test.want) {
-			t.Errorf("lexicon.idSet(%v) = %+v, want %+v", test.have, got, test.want)
+		if got := lexicon.IDSet(test.have); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("lexicon.IDSet(%v) = %+v, want %+v", test.have, got, test.want)
 		}
 	}
 }
 
 func TestIDSetLexiconClear(t *testing.T) {
-	lex := newIDSetLexicon()
+	lex := NewIDSetLexicon()
 
-	if got, want := lex.add(1, 2), int32(^0); got != want {
-		t.Errorf("lex.add([]int32{1, 2}) = %v, want %v", got, want)
+	if got, want := lex.Add(1, 2), int32(^0); got != want {
+		t.Errorf("lex.Add([]int32{1, 2}) = %v, want %v", got, want)
 	}
-	if got, want := lex.add(3, 4), int32(^1); got != want {
-		t.Errorf("lex.add(sequence{3, 4}) = %v, want %v", got, want)
+	if got, want := lex.Add(3, 4), int32(^1); got != want {
+		t.Errorf("lex.Add(sequence{3, 4}) = %v, want %v", got, want)
 	}
-	lex.clear()
-	if got, want := lex.add(3, 4), int32(^0); got != want {
-		t.Errorf("lex.add([]int32{3, 4}) = %v, want %v", got, want)
+	lex.Clear()
+	if got, want := lex.Add(3, 4), int32(^0); got != want {
+		t.Errorf("lex.Add([]int32{3, 4}) = %v, want %v", got, want)
 	}
-	if got, want := lex.add(1, 2), int32(^1); got != want {
-		t.Errorf("lex.add([]int32{1, 2}) = %v, want %v", got, want)
+	if got, want := lex.Add(1, 2), int32(^1); got != want {
+		t.Errorf("lex.Add([]int32{1, 2}) = %v, want %v", got, want)
+	}
+}
+
+func TestIDSetLexiconZeroValue(t *testing.T) {
+	var lex IDSetLexicon
+	if got, want := lex.Add(1, 2), int32(^0); got != want {
+		t.Errorf("lex.Add(1, 2) = %v, want %v", got, want)
+	}
+	if got, want := lex.Add(), EmptySetID; got != want {
+		t.Errorf("lex.Add() = %v, want %v", got, want)
+	}
+	if got, want := lex.IDSet(^0), []int32{1, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("lex.IDSet(%v) = %v, want %v", ^0, got, want)
+	}
+
+	var empty IDSetLexicon
+	empty.Clear()
+	var buf bytes.Buffer
+	if err := empty.Encode(&buf); err != nil {
+		t.Errorf("Encode of the zero IDSetLexicon failed: %v", err)
+	}
+}
+
+func TestSequenceLexiconZeroValue(t *testing.T) {
+	var lex SequenceLexicon
+	if got := lex.Size(); got != 0 {
+		t.Errorf("lex.Size() = %v, want 0", got)
+	}
+	if got, want := lex.Add([]int32{1, 2}), int32(0); got != want {
+		t.Errorf("lex.Add([]int32{1, 2}) = %v, want %v", got, want)
+	}
+	if got, want := lex.Add([]int32{1, 2}), int32(0); got != want {
+		t.Errorf("lex.Add([]int32{1, 2}) again = %v, want %v", got, want)
+	}
+	if got, want := lex.Sequence(0), []int32{1, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("lex.Sequence(0) = %v, want %v", got, want)
+	}
+
+	var empty SequenceLexicon
+	var buf bytes.Buffer
+	if err := empty.Encode(&buf); err != nil {
+		t.Errorf("Encode of the zero SequenceLexicon failed: %v", err)
+	}
+}
+
+func TestSequenceLexiconHashCollision(t *testing.T) {
+	// These sequences have the same adler32 hash.
+	a, b := []int32{1, 0, 1}, []int32{0, 2, 0}
+	if hashSet(a) != hashSet(b) {
+		t.Fatalf("hashSet(%v) = %v, hashSet(%v) = %v, want equal hashes", a, hashSet(a), b, hashSet(b))
+	}
+
+	lex := NewSequenceLexicon()
+	idA, idB := lex.Add(a), lex.Add(b)
+	if idA == idB {
+		t.Errorf("lex.Add(%v) = lex.Add(%v) = %v, want distinct IDs", a, b, idA)
+	}
+	if got := lex.Add(b); got != idB {
+		t.Errorf("lex.Add(%v) = %v, want %v", b, got, idB)
+	}
+	if got := lex.Sequence(idB); !reflect.DeepEqual(got, b) {
+		t.Errorf("lex.Sequence(%v) = %v, want %v", idB, got, b)
+	}
+}
+
+func TestSequenceLexiconEncodeDecode(t *testing.T) {
+	sequences := [][]int32{{}, {5}, {5, 0, -3}, {math.MaxInt32, math.MinInt32}, {1, 0, 1}, {0, 2, 0}}
+	lex := NewSequenceLexicon()
+	for _, seq := range sequences {
+		lex.Add(seq)
+	}
+
+	var buf bytes.Buffer
+	if err := lex.Encode(&buf); err != nil {
+		t.Fatalf("Encode() failed: %v", err)
+	}
+	encoded := buf.Bytes()
+
+	got := NewSequenceLexicon()
+	if err := got.Decode(bytes.NewReader(encoded)); err != nil {
+		t.Fatalf("Decode() failed: %v", err)
+	}
+	if got.Size() != lex.Size() {
+		t.Errorf("decoded Size() = %v, want %v", got.Size(), lex.Size())
+	}
+	for i, seq := range sequences {
+		if s := got.Sequence(int32(i)); !reflect.DeepEqual(s, seq) {
+			t.Errorf("decoded Sequence(%v) = %v, want %v", i, s, seq)
+		}
+		// Existing sequences keep their IDs in the decoded lexicon.
+		if id := got.Add(seq); id != int32(i) {
+			t.Errorf("decoded Add(%v) = %v, want %v", seq, id, i)
+		}
+	}
+
+	for n := 0; n < len(encoded); n++ {
+		var d SequenceLexicon
+		if err := d.Decode(bytes.NewReader(encoded[:n])); err == nil {
+			t.Errorf("Decode of %d of %d encoded bytes succeeded, want error", n, len(encoded))
+		}
+	}
+}
+
+func TestIDSetLexiconDuplicateSingleton(t *testing.T) {
+	lex := NewIDSetLexicon()
+	if got, want := lex.Add(5, 5, 5), int32(5); got != want {
+		t.Errorf("lex.Add(5, 5, 5) = %v, want %v", got, want)
+	}
+}
+
+func TestIDSetLexiconEncodeDecode(t *testing.T) {
+	lex := NewIDSetLexicon()
+	a := lex.Add(7, 3, 5)
+	b := lex.Add(1, 2)
+
+	var buf bytes.Buffer
+	if err := lex.Encode(&buf); err != nil {
+		t.Fatalf("Encode() failed: %v", err)
+	}
+	var got IDSetLexicon
+	if err := got.Decode(&buf); err != nil {
+		t.Fatalf("Decode() failed: %v", err)
+	}
+	if s := got.IDSet(a); !reflect.DeepEqual(s, []int32{3, 5, 7}) {
+		t.Errorf("decoded IDSet(%v) = %v, want [3 5 7]", a, s)
+	}
+	if s := got.IDSet(b); !reflect.DeepEqual(s, []int32{1, 2}) {
+		t.Errorf("decoded IDSet(%v) = %v, want [1 2]", b, s)
+	}
+}
+
+func TestValueSetLexicon(t *testing.T) {
+	lex := NewValueSetLexicon[string]()
+
+	empty := lex.Add()
+	if empty != EmptySetID {
+		t.Errorf("lex.Add() = %v, want %v", empty, EmptySetID)
+	}
+	road := lex.Add("road")
+	roadBridge := lex.Add("road", "bridge")
+	if got := lex.Add("bridge", "road", "road"); got != roadBridge {
+		t.Errorf("lex.Add(bridge, road, road) = %v, want %v", got, roadBridge)
+	}
+	if road == roadBridge {
+		t.Errorf("lex.Add(road) = lex.Add(road, bridge) = %v, want distinct IDs", road)
+	}
+	if got, want := lex.NumValues(), 2; got != want {
+		t.Errorf("lex.NumValues() = %v, want %v", got, want)
+	}
+
+	tests := []struct {
+		id   int32
+		want []string
+	}{
+		{empty, []string{}},
+		{road, []string{"road"}},
+		// Values are returned in the order in which they were first added.
+		{roadBridge, []string{"road", "bridge"}},
+	}
+	for _, test := range tests {
+		if got := lex.ValueSet(test.id); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("lex.ValueSet(%v) = %v, want %v", test.id, got, test.want)
+		}
+	}
+
+	lex.Clear()
+	if got := lex.NumValues(); got != 0 {
+		t.Errorf("after Clear, lex.NumValues() = %v, want 0", got)
+	}
+	if got := lex.Add("bridge"); got != 0 {
+		t.Errorf("after Clear, lex.Add(bridge) = %v, want 0", got)
+	}
+}
+
+func TestValueSetLexiconZeroValue(t *testing.T) {
+	var lex ValueSetLexicon[string]
+	if got, want := lex.ValueSet(EmptySetID), []string{}; !reflect.DeepEqual(got, want) {
+		t.Errorf("lex.ValueSet(%v) = %v, want %v", EmptySetID, got, want)
+	}
+	id := lex.Add("road", "bridge")
+	if got, want := lex.ValueSet(id), []string{"road", "bridge"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("lex.ValueSet(%v) = %v, want %v", id, got, want)
+	}
+
+	var empty ValueSetLexicon[int]
+	empty.Clear()
+	if got := empty.Add(7); got != 0 {
+		t.Errorf("after Clear, empty.Add(7) = %v, want 0", got)
 	}
 }
 
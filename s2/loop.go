@@ -1319,7 +1319,7 @@ func (l *Loop) xyzFaceSiTiVertices() []xyzFaceSiTi {
 	ret := make([]xyzFaceSiTi, len(l.vertices))
 	for i, v := range l.vertices {
 		ret[i].xyz = v
-		ret[i].face, ret[i].si, ret[i].ti, ret[i].level = xyzToFaceSiTi(v)
+		ret[i].face, ret[i].si, ret[i].ti, ret[i].level = XYZToFaceSiTi(v)
 	}
 	return ret
 }
@@ -1571,9 +1571,9 @@ func (l *loopCrosser) cellCrossesAnySubcell(aClipped *clippedShape, bID CellID)
 }
 
 // hasCrossing reports whether given two iterators positioned such that
-// ai.cellID().ContainsCellID(bi.cellID()), there is an edge or wedge crossing
-// anywhere within ai.cellID(). This function advances bi only past ai.cellID().
-func (l *loopCrosser) hasCrossing(ai, bi *rangeIterator) bool {
+// ai.CellID().ContainsCellID(bi.CellID()), there is an edge or wedge crossing
+// anywhere within ai.CellID(). This function advances bi only past ai.CellID().
+func (l *loopCrosser) hasCrossing(ai, bi *CellRangeIterator) bool {
 	// If ai.CellID() intersects many edges of B, then it is faster to use
 	// CrossingEdgeQuery to narrow down the candidates. But if it intersects
 	// only a few edges, it is faster to check all the crossings directly.
@@ -1584,27 +1584,27 @@ func (l *loopCrosser) hasCrossing(ai, bi *rangeIterator) bool {
 	l.bCells = nil
 
 	for {
-		if n := bi.clipped().numEdges(); n > 0 {
+		if n := rangeClipped(bi).numEdges(); n > 0 {
 			totalEdges += n
 			if totalEdges >= edgeQueryMinEdges {
 				// There are too many edges to test them directly, so use CrossingEdgeQuery.
-				if l.cellCrossesAnySubcell(ai.clipped(), ai.cellID()) {
+				if l.cellCrossesAnySubcell(rangeClipped(ai), ai.CellID()) {
 					return true
 				}
-				bi.seekBeyond(ai)
+				bi.SeekBeyond(ai)
 				return false
 			}
-			l.bCells = append(l.bCells, bi.indexCell())
+			l.bCells = append(l.bCells, rangeIndexCell(bi))
 		}
-		bi.next()
-		if bi.cellID() > ai.rangeMax {
+		bi.Next()
+		if bi.CellID() > ai.rangeMax {
 			break
 		}
 	}
 
 	// Test all the edge crossings directly.
 	for _, c := range l.bCells {
-		if l.cellCrossesCell(ai.clipped(), c.shapes[0]) {
+		if l.cellCrossesCell(rangeClipped(ai), c.shapes[0]) {
 			return true
 		}
 	}
@@ -1621,40 +1621,40 @@ func containsCenterMatches(containsCenter bool, target crossingTarget) bool {
 }
 
 // hasCrossingRelation reports whether given two iterators positioned such that
-// ai.cellID().ContainsCellID(bi.cellID()), there is a crossing relationship
-// anywhere within ai.cellID(). Specifically, this method returns true if there
+// ai.CellID().ContainsCellID(bi.CellID()), there is a crossing relationship
+// anywhere within ai.CellID(). Specifically, this method returns true if there
 // is an edge crossing, a wedge crossing, or a point P that matches both relations
-// crossing targets. This function advances both iterators past ai.cellID.
-func (l *loopCrosser) hasCrossingRelation(ai, bi *rangeIterator) bool {
+// crossing targets. This function advances both iterators past ai.CellID().
+func (l *loopCrosser) hasCrossingRelation(ai, bi *CellRangeIterator) bool {
 	// ABSL_DCHECK(ai->id().contains(bi->id()));
-	aClipped := ai.clipped()
+	aClipped := rangeClipped(ai)
 	if aClipped.numEdges() != 0 {
 		// The current cell of A has at least one edge, so check for crossings.
 		if l.hasCrossing(ai, bi) {
 			return true
 		}
-		ai.next()
+		ai.Next()
 		return false
 	}
 
-	if !containsCenterMatches(ai.containsCenter(), l.aCrossingTarget) {
+	if !containsCenterMatches(rangeClipped(ai).containsCenter, l.aCrossingTarget) {
 		// The crossing target for A is not satisfied, so we skip over
 		// these cells of B.
-		bi.seekBeyond(ai)
-		ai.next()
+		bi.SeekBeyond(ai)
+		ai.Next()
 		return false
 	}
 
-	// All points within ai.cellID() satisfy the crossing target for A, so it's
+	// All points within ai.CellID() satisfy the crossing target for A, so it's
 	// worth iterating through the cells of B to see whether any cell
 	// centers also satisfy the crossing target for B.
-	for bi.cellID() <= ai.rangeMax {
-		if containsCenterMatches(bi.containsCenter(), l.bCrossingTarget) {
+	for bi.CellID() <= ai.rangeMax {
+		if containsCenterMatches(rangeClipped(bi).containsCenter, l.bCrossingTarget) {
 			return true
 		}
-		bi.next()
+		bi.Next()
 	}
-	ai.next()
+	ai.Next()
 	return false
 }
 
@@ -1681,16 +1681,16 @@ func hasCrossingRelation(a, b *Loop, relation loopRelation) bool {
 	ab := newLoopCrosser(a, b, relation, false) // Tests edges of A against B
 	ba := newLoopCrosser(b, a, relation, true)  // Tests edges of B against A
 
-	for !ai.done() || !bi.done() {
+	for !ai.Done() || !bi.Done() {
 		if ai.rangeMax < bi.rangeMin {
 			// The A and B cells don't overlap, and A precedes B.
-			ai.seekTo(bi)
+			ai.SeekTo(bi)
 		} else if bi.rangeMax < ai.rangeMin {
 			// The A and B cells don't overlap, and B precedes A.
-			bi.seekTo(ai)
+			bi.SeekTo(ai)
 		} else {
 			// One cell contains the other. Determine which cell is larger.
-			abRelation := int64(ai.it.CellID().lsb() - bi.it.CellID().lsb())
+			abRelation := int64(ai.CellID().lsb() - bi.CellID().lsb())
 			if abRelation > 0 {
 				// A's index cell is larger.
 				if ab.hasCrossingRelation(ai, bi) {
@@ -1705,18 +1705,18 @@ func hasCrossingRelation(a, b *Loop, relation loopRelation) bool {
 				// The A and B cells are the same. Since the two
 				// cells have the same center point P, check
 				// whether P satisfies the crossing targets.
-				if containsCenterMatches(ai.containsCenter(), ab.aCrossingTarget) &&
-					containsCenterMatches(bi.containsCenter(), ab.bCrossingTarget) {
+				if containsCenterMatches(rangeClipped(ai).containsCenter, ab.aCrossingTarget) &&
+					containsCenterMatches(rangeClipped(bi).containsCenter, ab.bCrossingTarget) {
 					return true
 				}
 				// Otherwise test all the edge crossings directly.
-				aClipped := ai.clipped()
-				bClipped := bi.clipped()
+				aClipped := rangeClipped(ai)
+				bClipped := rangeClipped(bi)
 				if aClipped.numEdges() > 0 && bClipped.numEdges() > 0 && ab.cellCrossesCell(aClipped, bClipped) {
 					return true
 				}
-				ai.next()
-				bi.next()
+				ai.Next()
+				bi.Next()
 			}
 		}
 	}
@@ -1835,10 +1835,54 @@ func (l *Loop) containsNonCrossingBoundary(other *Loop, reverseOther bool) bool
 		other.Vertex(1), reverseOther)
 }
 
+// DistanceToPoint returns the distance from the given point to the loop
+// interior. If the loop contains the point, the distance is zero. If the
+// loop is empty, the distance is infinite.
+func (l *Loop) DistanceToPoint(x Point) s1.Angle {
+	// Loop.ContainsPoint is slightly more efficient than the generic
+	// version used by EdgeQuery.
+	if l.ContainsPoint(x) {
+		return 0
+	}
+	return l.DistanceToBoundary(x)
+}
+
+// DistanceToBoundary returns the distance from the given point to the loop
+// boundary. If the loop is empty or full, the distance is infinite (since
+// such loops have no boundary).
+func (l *Loop) DistanceToBoundary(x Point) s1.Angle {
+	if l.isEmptyOrFull() {
+		return s1.InfAngle()
+	}
+	query := NewClosestEdgeQuery(l.index, NewClosestEdgeQueryOptions().IncludeInteriors(false))
+	return query.Distance(NewMinDistanceToPointTarget(x)).Angle()
+}
+
+// Project returns the closest point in the loop to the given point. If the
+// loop contains the point, the point itself is returned. If the loop is
+// empty, the point itself is also returned.
+func (l *Loop) Project(x Point) Point {
+	if l.ContainsPoint(x) {
+		return x
+	}
+	return l.ProjectToBoundary(x)
+}
+
+// ProjectToBoundary returns the closest point on the loop boundary to the
+// given point. If the loop is empty or full (and so has no boundary), the
+// point itself is returned.
+func (l *Loop) ProjectToBoundary(x Point) Point {
+	if l.isEmptyOrFull() {
+		return x
+	}
+	query := NewClosestEdgeQuery(l.index, NewClosestEdgeQueryOptions().IncludeInteriors(false).MaxResults(1))
+	results := query.FindEdges(NewMinDistanceToPointTarget(x))
+	if len(results) == 0 {
+		return x
+	}
+	return query.Project(x, results[0])
+}
+
 // TODO(roberts): Differences from the C++ version:
-// DistanceToPoint
-// DistanceToBoundary
-// Project
-// ProjectToBoundary
 // BoundaryApproxEqual
 // BoundaryNear
@@ -1820,6 +1820,98 @@ const (
 	numQueriesPerLoop = 100
 )
 
+func TestLoopDistanceMethods(t *testing.T) {
+	// EdgeQuery is already tested, so just do a bit of sanity checking.
+
+	// A CCW square around the LatLng point (0,0). Note that because lines of
+	// latitude are curved on the sphere, it is not straightforward to project
+	// points onto any edge except along the equator. (The equator is the only
+	// line of latitude that is also a geodesic.)
+	square := makeLoop("-1:-1, -1:1, 1:1, 1:-1")
+	if !square.IsNormalized() {
+		t.Fatalf("%v.IsNormalized() = false, want true", square)
+	}
+
+	tests := []struct {
+		desc string
+		x, y Point
+	}{
+		{
+			desc: "a vertex",
+			x:    parsePoint("1:-1"),
+			y:    parsePoint("1:-1"),
+		},
+		{
+			desc: "a point on one of the edges",
+			x:    parsePoint("0.5:1"),
+			y:    parsePoint("0.5:1"),
+		},
+		{
+			desc: "a point inside the square",
+			x:    parsePoint("0:0.5"),
+			y:    parsePoint("0:1"),
+		},
+		{
+			desc: "a point outside the square that projects onto an edge",
+			x:    parsePoint("0:-2"),
+			y:    parsePoint("0:-1"),
+		},
+		{
+			desc: "a point outside the square that projects onto a vertex",
+			x:    parsePoint("3:4"),
+			y:    parsePoint("1:1"),
+		},
+	}
+
+	for _, test := range tests {
+		want := test.x.Distance(test.y)
+		if got := square.DistanceToBoundary(test.x); !float64Near(got.Radians(), want.Radians(), epsilon) {
+			t.Errorf("%s: DistanceToBoundary(%v) = %v, want %v", test.desc, test.x, got, want)
+		}
+		if got := square.ProjectToBoundary(test.x); !got.ApproxEqual(test.y) {
+			t.Errorf("%s: ProjectToBoundary(%v) = %v, want %v", test.desc, test.x, got, test.y)
+		}
+
+		if square.ContainsPoint(test.x) {
+			if got := square.DistanceToPoint(test.x); got != 0 {
+				t.Errorf("%s: DistanceToPoint(%v) = %v, want 0", test.desc, test.x, got)
+			}
+			if got := square.Project(test.x); got != test.x {
+				t.Errorf("%s: Project(%v) = %v, want %v", test.desc, test.x, got, test.x)
+			}
+		} else {
+			if got := square.DistanceToPoint(test.x); !float64Near(got.Radians(), want.Radians(), epsilon) {
+				t.Errorf("%s: DistanceToPoint(%v) = %v, want %v", test.desc, test.x, got, want)
+			}
+			if got := square.Project(test.x); !got.ApproxEqual(test.y) {
+				t.Errorf("%s: Project(%v) = %v, want %v", test.desc, test.x, got, test.y)
+			}
+		}
+	}
+}
+
+func TestLoopDistanceMethodsEmptyAndFull(t *testing.T) {
+	// The empty and full loops don't have boundaries.
+	x := PointFromCoords(0, 1, 0)
+	for _, l := range []*Loop{EmptyLoop(), FullLoop()} {
+		if got := l.DistanceToBoundary(x); got != s1.InfAngle() {
+			t.Errorf("%v.DistanceToBoundary(%v) = %v, want %v", l, x, got, s1.InfAngle())
+		}
+		if got := l.ProjectToBoundary(x); got != x {
+			t.Errorf("%v.ProjectToBoundary(%v) = %v, want %v", l, x, got, x)
+		}
+		if got := l.Project(x); got != x {
+			t.Errorf("%v.Project(%v) = %v, want %v", l, x, got, x)
+		}
+	}
+	if got := EmptyLoop().DistanceToPoint(x); got != s1.InfAngle() {
+		t.Errorf("EmptyLoop().DistanceToPoint(%v) = %v, want %v", x, got, s1.InfAngle())
+	}
+	if got := FullLoop().DistanceToPoint(x); got != 0 {
+		t.Errorf("FullLoop().DistanceToPoint(%v) = %v, want 0", x, got)
+	}
+}
+
 func BenchmarkLoopContainsPoint(b *testing.B) {
 	// Benchmark ContainsPoint() on regular loops. The query points for a loop are
 	// chosen so that they all lie in the loop's bounding rectangle (to avoid the
@@ -24,33 +24,33 @@ import (
 // results that are the furthest using the distance related algorithms.
 type maxDistance s1.ChordAngle
 
-func (m maxDistance) chordAngle() s1.ChordAngle { return s1.ChordAngle(m) }
-func (m maxDistance) zero() distance            { return maxDistance(s1.StraightChordAngle) }
-func (m maxDistance) negative() distance        { return maxDistance(s1.InfChordAngle()) }
-func (m maxDistance) infinity() distance        { return maxDistance(s1.NegativeChordAngle) }
-func (m maxDistance) less(other distance) bool  { return m.chordAngle() > other.chordAngle() }
-func (m maxDistance) sub(other distance) distance {
-	return maxDistance(m.chordAngle() + other.chordAngle())
-}
-func (m maxDistance) chordAngleBound() s1.ChordAngle {
-	return s1.StraightChordAngle - m.chordAngle()
-}
-func (m maxDistance) updateDistance(dist distance) (distance, bool) {
-	if dist.less(m) {
-		m = maxDistance(dist.chordAngle())
+func (m maxDistance) ChordAngle() s1.ChordAngle { return s1.ChordAngle(m) }
+func (m maxDistance) Zero() Distance            { return maxDistance(s1.StraightChordAngle) }
+func (m maxDistance) Negative() Distance        { return maxDistance(s1.InfChordAngle()) }
+func (m maxDistance) Infinity() Distance        { return maxDistance(s1.NegativeChordAngle) }
+func (m maxDistance) Less(other Distance) bool  { return m.ChordAngle() > other.ChordAngle() }
+func (m maxDistance) Sub(other Distance) Distance {
+	return maxDistance(m.ChordAngle().Add(other.ChordAngle()))
+}
+func (m maxDistance) ChordAngleBound() s1.ChordAngle {
+	return s1.StraightChordAngle - m.ChordAngle()
+}
+func (m maxDistance) UpdateDistance(dist Distance) (Distance, bool) {
+	if dist.Less(m) {
+		m = maxDistance(dist.ChordAngle())
 		return m, true
 	}
 	return m, false
 }
 
-func (m maxDistance) fromChordAngle(o s1.ChordAngle) distance {
+func (m maxDistance) FromChordAngle(o s1.ChordAngle) Distance {
 	return maxDistance(o)
 }
 
 // MaxDistanceToPointTarget is used for computing the maximum distance to a Point.
 type MaxDistanceToPointTarget struct {
 	point Point
-	dist  distance
+	dist  Distance
 }
 
 // NewMaxDistanceToPointTarget returns a new target for the given Point.
@@ -59,44 +59,49 @@ func NewMaxDistanceToPointTarget(point Point) *MaxDistanceToPointTarget {
 	return &MaxDistanceToPointTarget{point: point, dist: &m}
 }
 
-func (m *MaxDistanceToPointTarget) capBound() Cap {
+func (m *MaxDistanceToPointTarget) CapBound() Cap {
 	return CapFromCenterChordAngle(Point{m.point.Mul(-1)}, (s1.ChordAngle(0)))
 }
 
-func (m *MaxDistanceToPointTarget) updateDistanceToPoint(p Point, dist distance) (distance, bool) {
-	return dist.updateDistance(maxDistance(ChordAngleBetweenPoints(p, m.point)))
+func (m *MaxDistanceToPointTarget) UpdateDistanceToPoint(p Point, dist Distance) (Distance, bool) {
+	return dist.UpdateDistance(maxDistance(ChordAngleBetweenPoints(p, m.point)))
 }
 
-func (m *MaxDistanceToPointTarget) updateDistanceToEdge(edge Edge, dist distance) (distance, bool) {
-	if d, ok := UpdateMaxDistance(m.point, edge.V0, edge.V1, dist.chordAngle()); ok {
-		dist, _ = dist.updateDistance(maxDistance(d))
+func (m *MaxDistanceToPointTarget) UpdateDistanceToEdge(edge Edge, dist Distance) (Distance, bool) {
+	if d, ok := UpdateMaxDistance(m.point, edge.V0, edge.V1, dist.ChordAngle()); ok {
+		dist, _ = dist.UpdateDistance(maxDistance(d))
 		return dist, true
 	}
 	return dist, false
 }
 
-func (m *MaxDistanceToPointTarget) updateDistanceToCell(cell Cell, dist distance) (distance, bool) {
-	return dist.updateDistance(maxDistance(cell.MaxDistance(m.point)))
+func (m *MaxDistanceToPointTarget) UpdateDistanceToCell(cell Cell, dist Distance) (Distance, bool) {
+	return dist.UpdateDistance(maxDistance(cell.MaxDistance(m.point)))
 }
 
-func (m *MaxDistanceToPointTarget) visitContainingShapes(index *ShapeIndex, v shapePointVisitorFunc) bool {
+func (m *MaxDistanceToPointTarget) VisitContainingShapes(index *ShapeIndex, v ShapePointVisitorFunc) bool {
 	// For furthest points, we visit the polygons whose interior contains
 	// the antipode of the target point. These are the polygons whose
-	// distance to the target is maxDistance.zero()
+	// distance to the target is maxDistance.Zero()
 	q := NewContainsPointQuery(index, VertexModelSemiOpen)
 	return q.visitContainingShapes(Point{m.point.Mul(-1)}, func(shape Shape) bool {
 		return v(shape, m.point)
 	})
 }
 
-func (m *MaxDistanceToPointTarget) setMaxError(maxErr s1.ChordAngle) bool { return false }
-func (m *MaxDistanceToPointTarget) maxBruteForceIndexSize() int           { return 30 }
-func (m *MaxDistanceToPointTarget) distance() distance                    { return m.dist }
+func (m *MaxDistanceToPointTarget) SetMaxError(maxErr s1.ChordAngle) bool { return false }
+func (m *MaxDistanceToPointTarget) MaxBruteForceIndexSize() int {
+	// Using BM_FindFurthest (which finds the single furthest edge), the
+	// break-even points are approximately 100, 400, and 600 edges for point
+	// cloud, fractal, and regular loop geometry respectively.
+	return 300
+}
+func (m *MaxDistanceToPointTarget) Distance() Distance { return m.dist }
 
 // MaxDistanceToEdgeTarget is used for computing the maximum distance to an Edge.
 type MaxDistanceToEdgeTarget struct {
 	e    Edge
-	dist distance
+	dist Distance
 }
 
 // NewMaxDistanceToEdgeTarget returns a new target for the given Edge.
@@ -105,9 +110,9 @@ func NewMaxDistanceToEdgeTarget(e Edge) *MaxDistanceToEdgeTarget {
 	return &MaxDistanceToEdgeTarget{e: e, dist: m}
 }
 
-// capBound returns a Cap that bounds the antipode of the target. (This
+// CapBound returns a Cap that bounds the antipode of the target. (This
 // is the set of points whose maxDistance to the target is maxDistance.zero)
-func (m *MaxDistanceToEdgeTarget) capBound() Cap {
+func (m *MaxDistanceToEdgeTarget) CapBound() Cap {
 	// The following computes a radius equal to half the edge length in an
 	// efficient and numerically stable way.
 	d2 := float64(ChordAngleBetweenPoints(m.e.V0, m.e.V1))
@@ -115,27 +120,27 @@ func (m *MaxDistanceToEdgeTarget) capBound() Cap {
 	return CapFromCenterChordAngle(Point{m.e.V0.Add(m.e.V1.Vector).Mul(-1).Normalize()}, s1.ChordAngleFromSquaredLength(r2))
 }
 
-func (m *MaxDistanceToEdgeTarget) updateDistanceToPoint(p Point, dist distance) (distance, bool) {
-	if d, ok := UpdateMaxDistance(p, m.e.V0, m.e.V1, dist.chordAngle()); ok {
-		dist, _ = dist.updateDistance(maxDistance(d))
+func (m *MaxDistanceToEdgeTarget) UpdateDistanceToPoint(p Point, dist Distance) (Distance, bool) {
+	if d, ok := UpdateMaxDistance(p, m.e.V0, m.e.V1, dist.ChordAngle()); ok {
+		dist, _ = dist.UpdateDistance(maxDistance(d))
 		return dist, true
 	}
 	return dist, false
 }
 
-func (m *MaxDistanceToEdgeTarget) updateDistanceToEdge(edge Edge, dist distance) (distance, bool) {
-	if d, ok := updateEdgePairMaxDistance(m.e.V0, m.e.V1, edge.V0, edge.V1, dist.chordAngle()); ok {
-		dist, _ = dist.updateDistance(maxDistance(d))
+func (m *MaxDistanceToEdgeTarget) UpdateDistanceToEdge(edge Edge, dist Distance) (Distance, bool) {
+	if d, ok := updateEdgePairMaxDistance(m.e.V0, m.e.V1, edge.V0, edge.V1, dist.ChordAngle()); ok {
+		dist, _ = dist.UpdateDistance(maxDistance(d))
 		return dist, true
 	}
 	return dist, false
 }
 
-func (m *MaxDistanceToEdgeTarget) updateDistanceToCell(cell Cell, dist distance) (distance, bool) {
-	return dist.updateDistance(maxDistance(cell.MaxDistanceToEdge(m.e.V0, m.e.V1)))
+func (m *MaxDistanceToEdgeTarget) UpdateDistanceToCell(cell Cell, dist Distance) (Distance, bool) {
+	return dist.UpdateDistance(maxDistance(cell.MaxDistanceToEdge(m.e.V0, m.e.V1)))
 }
 
-func (m *MaxDistanceToEdgeTarget) visitContainingShapes(index *ShapeIndex, v shapePointVisitorFunc) bool {
+func (m *MaxDistanceToEdgeTarget) VisitContainingShapes(index *ShapeIndex, v ShapePointVisitorFunc) bool {
 	// We only need to test one edge point. That is because the method *must*
 	// visit a polygon if it fully contains the target, and *is allowed* to
 	// visit a polygon if it intersects the target. If the tested vertex is not
@@ -144,17 +149,22 @@ func (m *MaxDistanceToEdgeTarget) visitContainingShapes(index *ShapeIndex, v sha
 	// intersects (is allowed to be visited). We visit the center of the edge so
 	// that edge AB gives identical results to BA.
 	target := NewMaxDistanceToPointTarget(Point{m.e.V0.Add(m.e.V1.Vector).Normalize()})
-	return target.visitContainingShapes(index, v)
+	return target.VisitContainingShapes(index, v)
 }
 
-func (m *MaxDistanceToEdgeTarget) setMaxError(maxErr s1.ChordAngle) bool { return false }
-func (m *MaxDistanceToEdgeTarget) maxBruteForceIndexSize() int           { return 30 }
-func (m *MaxDistanceToEdgeTarget) distance() distance                    { return m.dist }
+func (m *MaxDistanceToEdgeTarget) SetMaxError(maxErr s1.ChordAngle) bool { return false }
+func (m *MaxDistanceToEdgeTarget) MaxBruteForceIndexSize() int {
+	// Using BM_FindFurthest (which finds the single furthest edge), the
+	// break-even points are approximately 80, 100, and 230 edges for point
+	// cloud, fractal, and regular loop geometry respectively.
+	return 110
+}
+func (m *MaxDistanceToEdgeTarget) Distance() Distance { return m.dist }
 
 // MaxDistanceToCellTarget is used for computing the maximum distance to a Cell.
 type MaxDistanceToCellTarget struct {
 	cell Cell
-	dist distance
+	dist Distance
 }
 
 // NewMaxDistanceToCellTarget returns a new target for the given Cell.
@@ -163,39 +173,47 @@ func NewMaxDistanceToCellTarget(cell Cell) *MaxDistanceToCellTarget {
 	return &MaxDistanceToCellTarget{cell: cell, dist: m}
 }
 
-func (m *MaxDistanceToCellTarget) capBound() Cap {
+func (m *MaxDistanceToCellTarget) CapBound() Cap {
 	c := m.cell.CapBound()
 	return CapFromCenterAngle(Point{c.Center().Mul(-1)}, c.Radius())
 }
 
-func (m *MaxDistanceToCellTarget) updateDistanceToPoint(p Point, dist distance) (distance, bool) {
-	return dist.updateDistance(maxDistance(m.cell.MaxDistance(p)))
+func (m *MaxDistanceToCellTarget) UpdateDistanceToPoint(p Point, dist Distance) (Distance, bool) {
+	return dist.UpdateDistance(maxDistance(m.cell.MaxDistance(p)))
 }
 
-func (m *MaxDistanceToCellTarget) updateDistanceToEdge(edge Edge, dist distance) (distance, bool) {
-	return dist.updateDistance(maxDistance(m.cell.MaxDistanceToEdge(edge.V0, edge.V1)))
+func (m *MaxDistanceToCellTarget) UpdateDistanceToEdge(edge Edge, dist Distance) (Distance, bool) {
+	return dist.UpdateDistance(maxDistance(m.cell.MaxDistanceToEdge(edge.V0, edge.V1)))
 }
 
-func (m *MaxDistanceToCellTarget) updateDistanceToCell(cell Cell, dist distance) (distance, bool) {
-	return dist.updateDistance(maxDistance(m.cell.MaxDistanceToCell(cell)))
+func (m *MaxDistanceToCellTarget) UpdateDistanceToCell(cell Cell, dist Distance) (Distance, bool) {
+	return dist.UpdateDistance(maxDistance(m.cell.MaxDistanceToCell(cell)))
 }
 
-func (m *MaxDistanceToCellTarget) visitContainingShapes(index *ShapeIndex, v shapePointVisitorFunc) bool {
+func (m *MaxDistanceToCellTarget) VisitContainingShapes(index *ShapeIndex, v ShapePointVisitorFunc) bool {
 	// We only need to check one point here - cell center is simplest.
 	// See comment at MaxDistanceToEdgeTarget's visitContainingShapes.
 	target := NewMaxDistanceToPointTarget(m.cell.Center())
-	return target.visitContainingShapes(index, v)
+	return target.VisitContainingShapes(index, v)
 }
 
-func (m *MaxDistanceToCellTarget) setMaxError(maxErr s1.ChordAngle) bool { return false }
-func (m *MaxDistanceToCellTarget) maxBruteForceIndexSize() int           { return 30 }
-func (m *MaxDistanceToCellTarget) distance() distance                    { return m.dist }
+func (m *MaxDistanceToCellTarget) SetMaxError(maxErr s1.ChordAngle) bool { return false }
+func (m *MaxDistanceToCellTarget) MaxBruteForceIndexSize() int {
+	// Using BM_FindFurthest (which finds the single furthest edge), the
+	// break-even points are approximately 70, 100, and 170 edges for point
+	// cloud, fractal, and regular loop geometry respectively.
+	return 100
+}
+func (m *MaxDistanceToCellTarget) Distance() Distance { return m.dist }
 
 // MaxDistanceToShapeIndexTarget is used for computing the maximum distance to a ShapeIndex.
+//
+// As with MinDistanceToShapeIndexTarget, the cells of the two indexes are
+// merge-joined when this is the target of an EdgeQuery.
 type MaxDistanceToShapeIndexTarget struct {
 	index *ShapeIndex
 	query *EdgeQuery
-	dist  distance
+	dist  Distance
 }
 
 // NewMaxDistanceToShapeIndexTarget returns a new target for the given ShapeIndex.
@@ -208,15 +226,15 @@ func NewMaxDistanceToShapeIndexTarget(index *ShapeIndex) *MaxDistanceToShapeInde
 	}
 }
 
-// capBound returns a Cap that bounds the antipode of the target. This
-// is the set of points whose maxDistance to the target is maxDistance.zero()
-func (m *MaxDistanceToShapeIndexTarget) capBound() Cap {
+// CapBound returns a Cap that bounds the antipode of the target. This
+// is the set of points whose maxDistance to the target is maxDistance.Zero()
+func (m *MaxDistanceToShapeIndexTarget) CapBound() Cap {
 	c := m.index.Region().CapBound()
 	return CapFromCenterAngle(Point{c.Center().Mul(-1)}, c.Radius())
 }
 
-func (m *MaxDistanceToShapeIndexTarget) updateDistanceToPoint(p Point, dist distance) (distance, bool) {
-	m.query.opts.distanceLimit = dist.chordAngle()
+func (m *MaxDistanceToShapeIndexTarget) UpdateDistanceToPoint(p Point, dist Distance) (Distance, bool) {
+	m.query.opts.distanceLimit = dist.ChordAngle()
 	target := NewMaxDistanceToPointTarget(p)
 	r := m.query.findEdge(target, m.query.opts)
 	if r.shapeID < 0 {
@@ -225,8 +243,8 @@ func (m *MaxDistanceToShapeIndexTarget) updateDistanceToPoint(p Point, dist dist
 	return r.distance, true
 }
 
-func (m *MaxDistanceToShapeIndexTarget) updateDistanceToEdge(edge Edge, dist distance) (distance, bool) {
-	m.query.opts.distanceLimit = dist.chordAngle()
+func (m *MaxDistanceToShapeIndexTarget) UpdateDistanceToEdge(edge Edge, dist Distance) (Distance, bool) {
+	m.query.opts.distanceLimit = dist.ChordAngle()
 	target := NewMaxDistanceToEdgeTarget(edge)
 	r := m.query.findEdge(target, m.query.opts)
 	if r.shapeID < 0 {
@@ -235,8 +253,8 @@ func (m *MaxDistanceToShapeIndexTarget) updateDistanceToEdge(edge Edge, dist dis
 	return r.distance, true
 }
 
-func (m *MaxDistanceToShapeIndexTarget) updateDistanceToCell(cell Cell, dist distance) (distance, bool) {
-	m.query.opts.distanceLimit = dist.chordAngle()
+func (m *MaxDistanceToShapeIndexTarget) UpdateDistanceToCell(cell Cell, dist Distance) (Distance, bool) {
+	m.query.opts.distanceLimit = dist.ChordAngle()
 	target := NewMaxDistanceToCellTarget(cell)
 	r := m.query.findEdge(target, m.query.opts)
 	if r.shapeID < 0 {
@@ -245,59 +263,113 @@ func (m *MaxDistanceToShapeIndexTarget) updateDistanceToCell(cell Cell, dist dis
 	return r.distance, true
 }
 
-// visitContainingShapes returns the polygons containing the antipodal
+// VisitContainingShapes returns the polygons containing the antipodal
 // reflection of *any* connected component for target types consisting of
 // multiple connected components. It is sufficient to test containment of
 // one vertex per connected component, since this allows us to also return
-// any polygon whose boundary has distance.zero() to the target.
-func (m *MaxDistanceToShapeIndexTarget) visitContainingShapes(index *ShapeIndex, v shapePointVisitorFunc) bool {
+// any polygon whose boundary has Distance.Zero() to the target.
+func (m *MaxDistanceToShapeIndexTarget) VisitContainingShapes(index *ShapeIndex, v ShapePointVisitorFunc) bool {
 	// It is sufficient to find the set of chain starts in the target index
-	// (i.e., one vertex per connected component of edges) that are contained by
-	// the query index, except for one special case to handle full polygons.
-	//
-	// TODO(roberts): Do this by merge-joining the two ShapeIndexes and share
-	// the code with BooleanOperation.
-	for _, shape := range m.index.shapes {
-		numChains := shape.NumChains()
-		// Shapes that don't have any edges require a special case (below).
-		testedPoint := false
-		for c := 0; c < numChains; c++ {
-			chain := shape.Chain(c)
-			if chain.Length == 0 {
-				continue
-			}
-			testedPoint = true
-			target := NewMaxDistanceToPointTarget(shape.ChainEdge(c, 0).V0)
-			if !target.visitContainingShapes(index, v) {
-				return false
-			}
-		}
-		if !testedPoint {
-			// Special case to handle full polygons.
-			ref := shape.ReferencePoint()
-			if !ref.Contained {
-				continue
-			}
-			target := NewMaxDistanceToPointTarget(ref.Point)
-			if !target.visitContainingShapes(index, v) {
-				return false
-			}
-		}
+	// (i.e., one vertex per connected component of edges) whose antipodes are
+	// contained by the query index, except for one special case to handle full
+	// polygons. These are merge-joined with the query index in a single pass.
+	points := shapeIndexComponentPoints(m.index)
+	antipodes := make([]Point, len(points))
+	for i, p := range points {
+		antipodes[i] = Point{p.Mul(-1)}
 	}
-	return true
+	q := NewContainsPointQuery(index, VertexModelSemiOpen)
+	return q.visitContainingShapesOfPoints(antipodes, func(shape Shape, i int) bool {
+		return v(shape, points[i])
+	})
 }
 
-func (m *MaxDistanceToShapeIndexTarget) setMaxError(maxErr s1.ChordAngle) bool {
+func (m *MaxDistanceToShapeIndexTarget) SetMaxError(maxErr s1.ChordAngle) bool {
 	m.query.opts.maxError = maxErr
 	return true
 }
-func (m *MaxDistanceToShapeIndexTarget) maxBruteForceIndexSize() int { return 30 }
-func (m *MaxDistanceToShapeIndexTarget) distance() distance          { return m.dist }
+func (m *MaxDistanceToShapeIndexTarget) MaxBruteForceIndexSize() int {
+	// Using BM_FindFurthest (which finds the single furthest edge), the
+	// break-even points are approximately 30, 100, and 130 edges for point
+	// cloud, fractal, and regular loop geometry respectively.
+	return 70
+}
+func (m *MaxDistanceToShapeIndexTarget) Distance() Distance      { return m.dist }
+func (m *MaxDistanceToShapeIndexTarget) targetQuery() *EdgeQuery { return m.query }
+func (m *MaxDistanceToShapeIndexTarget) cellTarget(cell Cell) DistanceTarget {
+	return NewMaxDistanceToCellTarget(cell)
+}
+func (m *MaxDistanceToShapeIndexTarget) edgeTarget(edge Edge) DistanceTarget {
+	return NewMaxDistanceToEdgeTarget(edge)
+}
+
+// interiorTestPoint returns the antipode of p, since p is at the maximum
+// possible distance from any polygon that contains its antipode.
+func (m *MaxDistanceToShapeIndexTarget) interiorTestPoint(p Point) Point { return Point{p.Mul(-1)} }
 func (m *MaxDistanceToShapeIndexTarget) setIncludeInteriors(b bool) {
 	m.query.opts.includeInteriors = b
 }
 func (m *MaxDistanceToShapeIndexTarget) setUseBruteForce(b bool) { m.query.opts.useBruteForce = b }
 
-// TODO(roberts): Remaining methods
-//
-// CellUnionTarget
+// ----------------------------------------------------------
+
+// MaxDistanceToCellUnionTarget is used for computing the maximum distance to
+// a CellUnion. The cells are treated as solid regions.
+type MaxDistanceToCellUnionTarget struct {
+	cu     CellUnion
+	target *MaxDistanceToShapeIndexTarget
+	dist   Distance
+}
+
+// NewMaxDistanceToCellUnionTarget returns a new target for the given CellUnion.
+func NewMaxDistanceToCellUnionTarget(cu CellUnion) *MaxDistanceToCellUnionTarget {
+	m := maxDistance(0)
+	target := NewMaxDistanceToShapeIndexTarget(cellUnionShapeIndex(cu))
+	target.setIncludeInteriors(true)
+	return &MaxDistanceToCellUnionTarget{cu: cu, target: target, dist: m}
+}
+
+// CapBound returns a Cap that bounds the antipode of the target. This
+// is the set of points whose maxDistance to the target is maxDistance.Zero().
+func (m *MaxDistanceToCellUnionTarget) CapBound() Cap {
+	c := m.cu.CapBound()
+	return CapFromCenterAngle(Point{c.Center().Mul(-1)}, c.Radius())
+}
+
+func (m *MaxDistanceToCellUnionTarget) UpdateDistanceToPoint(p Point, dist Distance) (Distance, bool) {
+	return m.target.UpdateDistanceToPoint(p, dist)
+}
+
+func (m *MaxDistanceToCellUnionTarget) UpdateDistanceToEdge(edge Edge, dist Distance) (Distance, bool) {
+	return m.target.UpdateDistanceToEdge(edge, dist)
+}
+
+func (m *MaxDistanceToCellUnionTarget) UpdateDistanceToCell(cell Cell, dist Distance) (Distance, bool) {
+	return m.target.UpdateDistanceToCell(cell, dist)
+}
+
+func (m *MaxDistanceToCellUnionTarget) VisitContainingShapes(index *ShapeIndex, v ShapePointVisitorFunc) bool {
+	// For furthest points, we visit the polygons whose interior contains the
+	// antipode of some cell of the target. As with the minimum distance, it is
+	// sufficient to test the center of each cell.
+	points := make([]Point, len(m.cu))
+	antipodes := make([]Point, len(m.cu))
+	for i, id := range m.cu {
+		points[i] = id.Point()
+		antipodes[i] = Point{points[i].Mul(-1)}
+	}
+	q := NewContainsPointQuery(index, VertexModelSemiOpen)
+	return q.visitContainingShapesOfPoints(antipodes, func(shape Shape, i int) bool {
+		return v(shape, points[i])
+	})
+}
+
+func (m *MaxDistanceToCellUnionTarget) SetMaxError(maxErr s1.ChordAngle) bool {
+	return m.target.SetMaxError(maxErr)
+}
+func (m *MaxDistanceToCellUnionTarget) MaxBruteForceIndexSize() int {
+	// Same as for Cells.
+	return 100
+}
+func (m *MaxDistanceToCellUnionTarget) Distance() Distance      { return m.dist }
+func (m *MaxDistanceToCellUnionTarget) setUseBruteForce(b bool) { m.target.setUseBruteForce(b) }
@@ -15,6 +15,7 @@
 package s2
 
 import (
+	"math/rand"
 	"reflect"
 	"sort"
 	"testing"
@@ -24,18 +25,18 @@ import (
 
 func TestDistanceTargetMaxCellTargetCapBound(t *testing.T) {
 	var md maxDistance
-	zero := md.zero()
+	zero := md.Zero()
 
 	for i := 0; i < 100; i++ {
 		cell := CellFromCellID(randomCellID())
 		target := NewMaxDistanceToCellTarget(cell)
-		c := target.capBound()
+		c := target.CapBound()
 
 		for j := 0; j < 100; j++ {
 			pTest := randomPoint()
 			// Check points outside of cap to be away from maxDistance's zero().
 			if !c.ContainsPoint(pTest) {
-				if got := cell.MaxDistance(pTest); !zero.less(maxDistance(got)) {
+				if got := cell.MaxDistance(pTest); !zero.Less(maxDistance(got)) {
 					t.Errorf("%v.MaxDistance(%v) = %v, want < %v", cell, pTest, got, zero)
 				}
 			}
@@ -54,10 +55,10 @@ func TestDistanceTargetMaxCellTargetUpdateDistance(t *testing.T) {
 
 	// Update max distance target to point.
 	p := parsePoint("0:0")
-	if _, ok = target.updateDistanceToPoint(p, dist0); !ok {
+	if _, ok = target.UpdateDistanceToPoint(p, dist0); !ok {
 		t.Errorf("target.updateDistanceToPoint(%v, %v) should have succeeded", p, dist0)
 	}
-	if _, ok = target.updateDistanceToPoint(p, dist10); ok {
+	if _, ok = target.UpdateDistanceToPoint(p, dist10); ok {
 		t.Errorf("target.updateDistanceToPoint(%v, %v) should have failed", p, dist10)
 	}
 
@@ -66,11 +67,11 @@ func TestDistanceTargetMaxCellTargetUpdateDistance(t *testing.T) {
 	// Test for edges.
 	pts := parsePoints("0:2, 0:3")
 	edge := Edge{pts[0], pts[1]}
-	if _, ok := target.updateDistanceToEdge(edge, dist0); !ok {
+	if _, ok := target.UpdateDistanceToEdge(edge, dist0); !ok {
 		t.Errorf("target.updateDistanceToEdge(%v, %v) should have succeeded", edge, dist0)
 	}
 	// Second call should fail.
-	if _, ok := target.updateDistanceToEdge(edge, dist10); ok {
+	if _, ok := target.UpdateDistanceToEdge(edge, dist10); ok {
 		t.Errorf("target.updateDistanceToEdge(%v, %v) should have failed", edge, dist10)
 	}
 
@@ -78,11 +79,11 @@ func TestDistanceTargetMaxCellTargetUpdateDistance(t *testing.T) {
 	dist0 = maxDistance(0)
 	// Test for cell.
 	cell := CellFromCellID(cellIDFromPoint(parsePoint("0:0")))
-	if _, ok = target.updateDistanceToCell(cell, dist0); !ok {
+	if _, ok = target.UpdateDistanceToCell(cell, dist0); !ok {
 		t.Errorf("target.updateDistanceToCell(%v, %v) should have succeeded", cell, dist0)
 	}
 	// Leaf cell will be tiny compared to 10 degrees - expect no update.
-	if _, ok = target.updateDistanceToCell(cell, dist10); ok {
+	if _, ok = target.UpdateDistanceToCell(cell, dist10); ok {
 		t.Errorf("target.updateDistanceToCell(%v, %v) should have failed", cell, dist10)
 	}
 }
@@ -93,19 +94,19 @@ func TestDistanceTargetMaxCellTargetUpdateDistanceToCellAntipodal(t *testing.T)
 	p := parsePoint("0:0")
 	targetCell := CellFromCellID(cellIDFromPoint(p))
 	target := NewMaxDistanceToCellTarget(targetCell)
-	dist := maxDist.infinity()
+	dist := maxDist.Infinity()
 	cell := CellFromCellID(cellIDFromPoint(Point{p.Mul(-1)}))
 
 	// First call should pass.
-	dist0, ok := target.updateDistanceToCell(cell, dist)
+	dist0, ok := target.UpdateDistanceToCell(cell, dist)
 	if !ok {
 		t.Errorf("target.updateDistanceToCell(%v, %v) should have succeeded", cell, dist)
 	}
-	if dist0.chordAngle() != s1.StraightChordAngle {
-		t.Errorf("target.updateDistanceToCell() = %v, want %v", dist0.chordAngle(), s1.StraightChordAngle)
+	if dist0.ChordAngle() != s1.StraightChordAngle {
+		t.Errorf("target.updateDistanceToCell() = %v, want %v", dist0.ChordAngle(), s1.StraightChordAngle)
 	}
 	// Second call should fail.
-	if _, ok := target.updateDistanceToCell(cell, dist0); ok {
+	if _, ok := target.UpdateDistanceToCell(cell, dist0); ok {
 		t.Errorf("target.updateDistanceToCell(%v, %v) should have failed", cell, dist0)
 	}
 }
@@ -115,16 +116,16 @@ func TestDistanceTargetMaxCellTargetUpdateDistanceToCellWhenEqual(t *testing.T)
 
 	targetCell := CellFromCellID(cellIDFromPoint(parsePoint("0:1")))
 	target := NewMaxDistanceToCellTarget(targetCell)
-	dist := maxDist.infinity()
+	dist := maxDist.Infinity()
 	cell := CellFromCellID(cellIDFromPoint(parsePoint("0:0")))
 
 	// First call should pass.
-	dist0, ok := target.updateDistanceToCell(cell, dist)
+	dist0, ok := target.UpdateDistanceToCell(cell, dist)
 	if !ok {
 		t.Errorf("target.updateDistanceToCell(%v, %v) should have succeeded", cell, dist)
 	}
 	// Second call should fail.
-	if _, ok := target.updateDistanceToCell(cell, dist0); ok {
+	if _, ok := target.UpdateDistanceToCell(cell, dist0); ok {
 		t.Errorf("target.updateDistanceToCell(%v, %v) should have failed", cell, dist0)
 	}
 }
@@ -134,17 +135,17 @@ func TestDistanceTargetMaxCellTargetUpdateDistanceToEdgeWhenEqual(t *testing.T)
 
 	targetCell := CellFromCellID(cellIDFromPoint(parsePoint("0:1")))
 	target := NewMaxDistanceToCellTarget(targetCell)
-	dist := maxDist.infinity()
+	dist := maxDist.Infinity()
 	pts := parsePoints("0:-1, 0:1")
 	edge := Edge{pts[0], pts[1]}
 
 	// First call should pass.
-	dist0, ok := target.updateDistanceToEdge(edge, dist)
+	dist0, ok := target.UpdateDistanceToEdge(edge, dist)
 	if !ok {
 		t.Errorf("target.updateDistanceToEdge(%v, %v) should have succeeded", edge, dist)
 	}
 	// Second call should fail.
-	if _, ok := target.updateDistanceToEdge(edge, dist0); ok {
+	if _, ok := target.UpdateDistanceToEdge(edge, dist0); ok {
 		t.Errorf("target.updateDistanceToEdge(%v, %v) should have failed", edge, dist0)
 	}
 }
@@ -175,20 +176,20 @@ func TestDistanceTargetMaxCellTargetVisitContainingShapes(t *testing.T) {
 
 func TestDistanceTargetMaxPointTargetUpdateDistance(t *testing.T) {
 	var ok bool
-	var dist0, dist10 distance
+	var dist0, dist10 Distance
 	target := NewMaxDistanceToPointTarget(parsePoint("0:0"))
 	dist0 = maxDistance(0)
 	dist10 = maxDistance(s1.ChordAngleFromAngle(s1.Angle(10) * s1.Degree))
 
 	// Update max distance target to point.
 	p := parsePoint("1:0")
-	if dist0, ok = target.updateDistanceToPoint(p, dist0); !ok {
+	if dist0, ok = target.UpdateDistanceToPoint(p, dist0); !ok {
 		t.Errorf("target.updateDistanceToPoint(%v, %v) should have succeeded", p, dist0)
 	}
-	if got, want := dist0.chordAngle().Angle().Degrees(), 1.0; !float64Near(got, want, epsilon) {
-		t.Errorf("target.updateDistanceToPoint(%v, %v) = %v, want ~%v", p, dist0.chordAngle(), got, want)
+	if got, want := dist0.ChordAngle().Angle().Degrees(), 1.0; !float64Near(got, want, epsilon) {
+		t.Errorf("target.updateDistanceToPoint(%v, %v) = %v, want ~%v", p, dist0.ChordAngle(), got, want)
 	}
-	if _, ok = target.updateDistanceToPoint(p, dist10); ok {
+	if _, ok = target.UpdateDistanceToPoint(p, dist10); ok {
 		t.Errorf("target.updateDistanceToPoint(%v, %v) should have failed", p, dist0)
 
 	}
@@ -198,13 +199,13 @@ func TestDistanceTargetMaxPointTargetUpdateDistance(t *testing.T) {
 	// Test for edges.
 	pts := parsePoints("0:-1, 0:1")
 	edge := Edge{pts[0], pts[1]}
-	if dist0, ok = target.updateDistanceToEdge(edge, dist0); !ok {
+	if dist0, ok = target.UpdateDistanceToEdge(edge, dist0); !ok {
 		t.Errorf("target.updateDistanceToEdge(%v, %v) should have succeeded", edge, dist0)
 	}
-	if got, want := dist0.chordAngle().Angle().Degrees(), 1.0; !float64Near(got, want, epsilon) {
-		t.Errorf("target.updateDistanceToEdge(%v, %v) = %v, want ~%v", edge, dist0.chordAngle(), got, want)
+	if got, want := dist0.ChordAngle().Angle().Degrees(), 1.0; !float64Near(got, want, epsilon) {
+		t.Errorf("target.updateDistanceToEdge(%v, %v) = %v, want ~%v", edge, dist0.ChordAngle(), got, want)
 	}
-	if _, ok = target.updateDistanceToEdge(edge, dist10); ok {
+	if _, ok = target.UpdateDistanceToEdge(edge, dist10); ok {
 		t.Errorf("target.updateDistanceToEdge(%v, %v) should have failed", edge, dist10)
 	}
 
@@ -212,11 +213,11 @@ func TestDistanceTargetMaxPointTargetUpdateDistance(t *testing.T) {
 	dist0 = maxDistance(0)
 	// Test for cell.
 	cell := CellFromCellID(cellIDFromPoint(parsePoint("0:0")))
-	if _, ok = target.updateDistanceToCell(cell, dist0); !ok {
+	if _, ok = target.UpdateDistanceToCell(cell, dist0); !ok {
 		t.Errorf("target.updateDistanceToCell(%v, %v) should have succeeded", cell, dist0)
 	}
 	// Leaf cell will be tiny compared to 10 degrees - expect no update.
-	if _, ok = target.updateDistanceToCell(cell, dist10); ok {
+	if _, ok = target.UpdateDistanceToCell(cell, dist10); ok {
 		t.Errorf("target.updateDistanceToCell(%v, %v) should have failed", cell, dist10)
 	}
 }
@@ -225,16 +226,16 @@ func TestDistanceTargetMaxPointTargetUpdateDistanceToCellWhenEqual(t *testing.T)
 	var maxDist maxDistance
 
 	target := NewMaxDistanceToPointTarget(parsePoint("1:0"))
-	dist := maxDist.infinity()
+	dist := maxDist.Infinity()
 	cell := CellFromCellID(cellIDFromPoint(parsePoint("0:0")))
 
 	// First call should pass.
-	dist0, ok := target.updateDistanceToCell(cell, dist)
+	dist0, ok := target.UpdateDistanceToCell(cell, dist)
 	if !ok {
 		t.Errorf("target.updateDistanceToCell(%v, %v) should have succeeded", cell, dist)
 	}
 	// Second call should fail.
-	if _, ok := target.updateDistanceToCell(cell, dist0); ok {
+	if _, ok := target.UpdateDistanceToCell(cell, dist0); ok {
 		t.Errorf("target.updateDistanceToCell(%v, %v) should have failed", cell, dist0)
 	}
 }
@@ -245,24 +246,24 @@ func TestDistanceTargetMaxPointTargetUpdateDistanceToEdgeWhenEqual(t *testing.T)
 	// Verifies that UpdateDistance only returns true when the new distance
 	// is less than the old distance (not less than or equal to).
 	target := NewMaxDistanceToPointTarget(parsePoint("1:0"))
-	dist := maxDist.infinity()
+	dist := maxDist.Infinity()
 	pts := parsePoints("0:-1, 0:1")
 	edge := Edge{pts[0], pts[1]}
 
 	// First call should pass.
-	dist0, ok := target.updateDistanceToEdge(edge, dist)
+	dist0, ok := target.UpdateDistanceToEdge(edge, dist)
 	if !ok {
 		t.Errorf("target.updateDistanceToEdge(%v, %v) should have succeeded", edge, dist)
 	}
 	// Second call should fail.
-	if _, ok := target.updateDistanceToEdge(edge, dist0); ok {
+	if _, ok := target.UpdateDistanceToEdge(edge, dist0); ok {
 		t.Errorf("target.updateDistanceToEdge(%v, %v) should have failed", edge, dist0)
 	}
 }
 
-func containingShapesForTarget(target distanceTarget, index *ShapeIndex, maxShapes int) []int {
+func containingShapesForTarget(target DistanceTarget, index *ShapeIndex, maxShapes int) []int {
 	shapeIDs := map[int32]bool{}
-	target.visitContainingShapes(index,
+	target.VisitContainingShapes(index,
 		func(containingShape Shape, targetPoint Point) bool {
 			// TODO(roberts): Update this if Shapes get an ID.
 			shapeIDs[index.idForShape(containingShape)] = true
@@ -294,7 +295,7 @@ func TestDistanceTargetMaxPointTargetVisitContainingShapes(t *testing.T) {
 
 func TestDistanceTargetMaxEdgeTargetUpdateDistance(t *testing.T) {
 	var ok bool
-	var dist0, dist10 distance
+	var dist0, dist10 Distance
 
 	targetPts := parsePoints("0:-1, 0:1")
 	targetEdge := Edge{targetPts[0], targetPts[1]}
@@ -305,13 +306,13 @@ func TestDistanceTargetMaxEdgeTargetUpdateDistance(t *testing.T) {
 
 	// Update max distance target to point.
 	p := parsePoint("0:2")
-	if dist0, ok = target.updateDistanceToPoint(p, dist0); !ok {
+	if dist0, ok = target.UpdateDistanceToPoint(p, dist0); !ok {
 		t.Errorf("target.updateDistanceToPoint(%v, %v) should have succeeded", p, dist0)
 	}
-	if got, want := dist0.chordAngle().Angle().Degrees(), 3.0; !float64Near(got, want, epsilon) {
-		t.Errorf("target.updateDistanceToPoint(%v, %v) = %v, want ~%v", p, dist0.chordAngle(), got, want)
+	if got, want := dist0.ChordAngle().Angle().Degrees(), 3.0; !float64Near(got, want, epsilon) {
+		t.Errorf("target.updateDistanceToPoint(%v, %v) = %v, want ~%v", p, dist0.ChordAngle(), got, want)
 	}
-	if _, ok = target.updateDistanceToPoint(p, dist10); ok {
+	if _, ok = target.UpdateDistanceToPoint(p, dist10); ok {
 		t.Errorf("target.updateDistanceToPoint(%v, %v) should have failed", p, dist10)
 	}
 
@@ -320,13 +321,13 @@ func TestDistanceTargetMaxEdgeTargetUpdateDistance(t *testing.T) {
 	// Test for edges.
 	pts := parsePoints("0:2, 0:3")
 	edge := Edge{pts[0], pts[1]}
-	if dist0, ok = target.updateDistanceToEdge(edge, dist0); !ok {
+	if dist0, ok = target.UpdateDistanceToEdge(edge, dist0); !ok {
 		t.Errorf("target.updateDistanceToEdge(%v, %v) should have succeeded", edge, dist0)
 	}
-	if got, want := dist0.chordAngle().Angle().Degrees(), 4.0; !float64Near(got, want, epsilon) {
-		t.Errorf("target.updateDistanceToEdge(%v, %v) = %v, want ~%v", p, dist0.chordAngle(), got, want)
+	if got, want := dist0.ChordAngle().Angle().Degrees(), 4.0; !float64Near(got, want, epsilon) {
+		t.Errorf("target.updateDistanceToEdge(%v, %v) = %v, want ~%v", p, dist0.ChordAngle(), got, want)
 	}
-	if _, ok = target.updateDistanceToEdge(edge, dist10); ok {
+	if _, ok = target.UpdateDistanceToEdge(edge, dist10); ok {
 		t.Errorf("target.updateDistanceToEdge(%v, %v) should have failed", edge, dist10)
 	}
 
@@ -334,11 +335,11 @@ func TestDistanceTargetMaxEdgeTargetUpdateDistance(t *testing.T) {
 	dist0 = maxDistance(0)
 	// Test for cell.
 	cell := CellFromCellID(cellIDFromPoint(parsePoint("0:0")))
-	if _, ok = target.updateDistanceToCell(cell, dist0); !ok {
+	if _, ok = target.UpdateDistanceToCell(cell, dist0); !ok {
 		t.Errorf("target.updateDistanceToCell(%v, %v) should have succeeded", cell, dist0)
 	}
 	// Leaf cell will be tiny compared to 10 degrees - expect no update.
-	if _, ok = target.updateDistanceToCell(cell, dist10); ok {
+	if _, ok = target.UpdateDistanceToCell(cell, dist10); ok {
 		t.Errorf("target.updateDistanceToCell(%v, %v) should have failed", cell, dist10)
 	}
 }
@@ -348,16 +349,16 @@ func TestDistanceTargetMaxEdgeTargetUpdateDistanceToCellWhenEqual(t *testing.T)
 
 	targetEdge := parsePoints("1:0, 1:1")
 	target := NewMaxDistanceToEdgeTarget(Edge{targetEdge[0], targetEdge[1]})
-	dist := maxDist.infinity()
+	dist := maxDist.Infinity()
 	cell := CellFromCellID(cellIDFromPoint(parsePoint("0:0")))
 
 	// First call should pass.
-	dist0, ok := target.updateDistanceToCell(cell, dist)
+	dist0, ok := target.UpdateDistanceToCell(cell, dist)
 	if !ok {
 		t.Errorf("target.updateDistanceToCell(%v, %v) should have succeeded", cell, dist)
 	}
 	// Second call should fail.
-	if _, ok := target.updateDistanceToCell(cell, dist0); ok {
+	if _, ok := target.UpdateDistanceToCell(cell, dist0); ok {
 		t.Errorf("target.updateDistanceToCell(%v, %v) should have failed", cell, dist0)
 	}
 }
@@ -368,17 +369,17 @@ func TestDistanceTargetMaxEdgeTargetUpdateDistanceToEdgeAntipodal(t *testing.T)
 	targetPts := parsePoints("0:89, 0:91")
 	targetEdge := Edge{targetPts[0], targetPts[1]}
 	target := NewMaxDistanceToEdgeTarget(targetEdge)
-	dist := maxDist.infinity()
+	dist := maxDist.Infinity()
 	pts := parsePoints("1:-90, -1:-90")
 	edge := Edge{pts[0], pts[1]}
 
 	// First call should pass.
-	dist0, ok := target.updateDistanceToEdge(edge, dist)
+	dist0, ok := target.UpdateDistanceToEdge(edge, dist)
 	if !ok {
 		t.Errorf("target.updateDistanceToEdge(%v, %v) should have succeeded", edge, dist)
 	}
 
-	if dist0.chordAngle() != s1.StraightChordAngle {
+	if dist0.ChordAngle() != s1.StraightChordAngle {
 		t.Errorf("target.updateDistanceToPoint(%v, %v) = %v, want %v", edge, dist0, dist0, s1.StraightChordAngle)
 	}
 }
@@ -388,17 +389,17 @@ func TestDistanceTargetMaxEdgeTargetUpdateDistanceToEdgeWhenEqual(t *testing.T)
 
 	targetEdge := parsePoints("1:0, 1:1")
 	target := NewMaxDistanceToEdgeTarget(Edge{targetEdge[0], targetEdge[1]})
-	dist := maxDist.infinity()
+	dist := maxDist.Infinity()
 	pts := parsePoints("0:-1, 0:1")
 	edge := Edge{pts[0], pts[1]}
 
 	// First call should pass.
-	dist0, ok := target.updateDistanceToEdge(edge, dist)
+	dist0, ok := target.UpdateDistanceToEdge(edge, dist)
 	if !ok {
 		t.Errorf("target.updateDistanceToEdge(%v, %v) should have succeeded", edge, dist)
 	}
 	// Second call should fail.
-	if _, ok := target.updateDistanceToEdge(edge, dist0); ok {
+	if _, ok := target.UpdateDistanceToEdge(edge, dist0); ok {
 		t.Errorf("target.updateDistanceToEdge(%v, %v) should have failed", edge, dist0)
 	}
 }
@@ -422,15 +423,15 @@ func TestDistanceTargetMaxEdgeTargetVisitContainingShapes(t *testing.T) {
 
 func TestDistanceTargetMaxShapeIndexTargetCapBound(t *testing.T) {
 	var md maxDistance
-	zero := md.zero()
-	inf := md.infinity()
+	zero := md.Zero()
+	inf := md.Infinity()
 
 	index := NewShapeIndex()
 	index.Add(PolygonFromCell(CellFromCellID(randomCellID())))
 	pv := PointVector([]Point{randomPoint()})
 	index.Add(Shape(&pv))
 	target := NewMaxDistanceToShapeIndexTarget(index)
-	c := target.capBound()
+	c := target.CapBound()
 
 	for j := 0; j < 100; j++ {
 		pTest := randomPoint()
@@ -438,11 +439,11 @@ func TestDistanceTargetMaxShapeIndexTargetCapBound(t *testing.T) {
 		if !c.ContainsPoint(pTest) {
 			var curDist = inf
 			var ok bool
-			if curDist, ok = target.updateDistanceToPoint(pTest, curDist); !ok {
+			if curDist, ok = target.UpdateDistanceToPoint(pTest, curDist); !ok {
 				t.Errorf("updateDistanceToPoint failed, but should have succeeded")
 				continue
 			}
-			if !zero.less(curDist) {
+			if !zero.Less(curDist) {
 				t.Errorf("point %v outside of cap should be less than %v distance, but were %v", pTest, zero, curDist)
 			}
 		}
@@ -454,16 +455,16 @@ func TestDistanceTargetMaxShapeIndexTargetUpdateDistanceToCellWhenEqual(t *testi
 
 	index := makeShapeIndex("1:0 # #")
 	target := NewMaxDistanceToShapeIndexTarget(index)
-	dist := maxDist.infinity()
+	dist := maxDist.Infinity()
 	cell := CellFromCellID(cellIDFromPoint(parsePoint("0:0")))
 
 	// First call should pass.
-	dist0, ok := target.updateDistanceToCell(cell, dist)
+	dist0, ok := target.UpdateDistanceToCell(cell, dist)
 	if !ok {
 		t.Errorf("target.updateDistanceToCell(%v, %v) should have succeeded", cell, dist)
 	}
 	// Second call should fail.
-	if _, ok := target.updateDistanceToCell(cell, dist0); ok {
+	if _, ok := target.UpdateDistanceToCell(cell, dist0); ok {
 		t.Errorf("target.updateDistanceToCell(%v, %v) should have failed", cell, dist0)
 	}
 }
@@ -473,17 +474,17 @@ func TestDistanceTargetMaxShapeIndexTargetUpdateDistanceToEdgeWhenEqual(t *testi
 
 	index := makeShapeIndex("1:0 # #")
 	target := NewMaxDistanceToShapeIndexTarget(index)
-	dist := maxDist.infinity()
+	dist := maxDist.Infinity()
 	pts := parsePoints("0:-1, 0:1")
 	edge := Edge{pts[0], pts[1]}
 
 	// First call should pass.
-	dist0, ok := target.updateDistanceToEdge(edge, dist)
+	dist0, ok := target.UpdateDistanceToEdge(edge, dist)
 	if !ok {
 		t.Errorf("target.updateDistanceToEdge(%v, %v) should have succeeded", edge, dist)
 	}
 	// Second call should fail.
-	if _, ok := target.updateDistanceToEdge(edge, dist0); ok {
+	if _, ok := target.UpdateDistanceToEdge(edge, dist0); ok {
 		t.Errorf("target.updateDistanceToEdge(%v, %v) should have failed", edge, dist0)
 	}
 }
@@ -561,3 +562,102 @@ func TestDistanceTargetMaxShapeIndexTargetVisitContainingShapesEmptyAndFull(t *t
 		t.Errorf("containingShapesForTarget(%v, %q, 5) = %+v, want %+v", emptyTarget, shapeIndexDebugString(index, false), got, want)
 	}
 }
+
+func TestDistanceTargetMaxShapeIndexTargetVisitContainingShapesMatchesPointTargets(t *testing.T) {
+	// The merge-joined implementation must visit the same shapes as testing
+	// each connected component of the target separately.
+	r := rand.New(rand.NewSource(1))
+	index := NewShapeIndex()
+	for i := 0; i < 20; i++ {
+		index.Add(PolygonFromLoops([]*Loop{RegularLoop(randomPoint(r), s1.Degree*20, 10)}))
+	}
+	targetIndex := NewShapeIndex()
+	for i := 0; i < 10; i++ {
+		pts := PointVector{randomPoint(r), randomPoint(r), randomPoint(r)}
+		targetIndex.Add(&pts)
+	}
+
+	target := NewMaxDistanceToShapeIndexTarget(targetIndex)
+	got := containingShapesForTarget(target, index, index.Len()+1)
+
+	seen := map[int]bool{}
+	for _, p := range shapeIndexComponentPoints(targetIndex) {
+		for _, id := range containingShapesForTarget(NewMaxDistanceToPointTarget(p), index, index.Len()+1) {
+			seen[id] = true
+		}
+	}
+	var want []int
+	for id := range seen {
+		want = append(want, id)
+	}
+	sort.Ints(want)
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("containingShapesForTarget(%v) = %v, want %v", target, got, want)
+	}
+}
+
+func TestDistanceTargetMaxCellUnionTargetCapBound(t *testing.T) {
+	cu := CellUnion([]CellID{
+		cellIDFromPoint(parsePoint("0:0")).Parent(8),
+		cellIDFromPoint(parsePoint("10:10")).Parent(8),
+	})
+	target := NewMaxDistanceToCellUnionTarget(cu)
+	c := target.CapBound()
+	for _, id := range cu {
+		cell := CellFromCellID(id)
+		for k := 0; k < 4; k++ {
+			if p := (Point{cell.Vertex(k).Mul(-1)}); !c.ContainsPoint(p) {
+				t.Errorf("%v.CapBound() = %v should contain the antipode %v of a cell vertex", target, c, p)
+			}
+		}
+	}
+}
+
+func TestDistanceTargetMaxCellUnionTargetUpdateDistance(t *testing.T) {
+	cu := CellUnion([]CellID{
+		cellIDFromPoint(parsePoint("0:0")).Parent(10),
+		cellIDFromPoint(parsePoint("0:10")).Parent(10),
+	})
+	cu.Normalize()
+
+	for _, useBruteForce := range []bool{true, false} {
+		target := NewMaxDistanceToCellUnionTarget(cu)
+		target.setUseBruteForce(useBruteForce)
+
+		// The antipode of a point inside one of the cells is at the maximum
+		// possible distance.
+		p := Point{cellIDFromPoint(parsePoint("0:10")).Parent(12).Point().Mul(-1)}
+		if got, ok := target.UpdateDistanceToPoint(p, maxDistance(s1.NegativeChordAngle)); !ok || got.ChordAngle() != s1.StraightChordAngle {
+			t.Errorf("useBruteForce = %v: target.UpdateDistanceToPoint(%v) = %v, %v, want %v, true", useBruteForce, p, got, ok, s1.StraightChordAngle)
+		}
+
+		// Otherwise the distance is the maximum over the cells.
+		q := parsePoint("0:5")
+		want := CellFromCellID(cu[0]).MaxDistance(q)
+		if d := CellFromCellID(cu[1]).MaxDistance(q); d > want {
+			want = d
+		}
+		got, ok := target.UpdateDistanceToPoint(q, maxDistance(s1.NegativeChordAngle))
+		if !ok || !float64Near(float64(got.ChordAngle()), float64(want), 1e-15) {
+			t.Errorf("useBruteForce = %v: target.UpdateDistanceToPoint(%v) = %v, %v, want %v, true", useBruteForce, q, got, ok, want)
+		}
+	}
+}
+
+func TestDistanceTargetMaxCellUnionTargetVisitContainingShapes(t *testing.T) {
+	index := makeShapeIndex("1:1 # 1:1, 2:2 # 0:0, 0:3, 3:0 | 6:6, 6:9, 9:6 | -1:-1, -1:5, 5:-1")
+
+	// Shapes 2 and 4 contain the antipode of the cell near -1:-179, while
+	// shape 3 contains the antipode of the cell near -7:-173.
+	cu := CellUnion([]CellID{
+		cellIDFromPoint(Point{parsePoint("1:1").Mul(-1)}),
+		cellIDFromPoint(Point{parsePoint("7:7").Mul(-1)}),
+	})
+	cu.Normalize()
+	target := NewMaxDistanceToCellUnionTarget(cu)
+
+	if got, want := containingShapesForTarget(target, index, 5), []int{2, 3, 4}; !reflect.DeepEqual(got, want) {
+		t.Errorf("containingShapesForTarget(%v, %q, 5) = %+v, want %+v", target, shapeIndexDebugString(index, false), got, want)
+	}
+}
@@ -20,39 +20,39 @@ import (
 	"github.com/golang/geo/s1"
 )
 
-// minDistance implements distance interface to find closest distance types.
+// minDistance implements the Distance interface to find closest distance types.
 type minDistance s1.ChordAngle
 
-func (m minDistance) chordAngle() s1.ChordAngle { return s1.ChordAngle(m) }
-func (m minDistance) zero() distance            { return minDistance(0) }
-func (m minDistance) negative() distance        { return minDistance(s1.NegativeChordAngle) }
-func (m minDistance) infinity() distance        { return minDistance(s1.InfChordAngle()) }
-func (m minDistance) less(other distance) bool  { return m.chordAngle() < other.chordAngle() }
-func (m minDistance) sub(other distance) distance {
-	return minDistance(m.chordAngle() - other.chordAngle())
+func (m minDistance) ChordAngle() s1.ChordAngle { return s1.ChordAngle(m) }
+func (m minDistance) Zero() Distance            { return minDistance(0) }
+func (m minDistance) Negative() Distance        { return minDistance(s1.NegativeChordAngle) }
+func (m minDistance) Infinity() Distance        { return minDistance(s1.InfChordAngle()) }
+func (m minDistance) Less(other Distance) bool  { return m.ChordAngle() < other.ChordAngle() }
+func (m minDistance) Sub(other Distance) Distance {
+	return minDistance(m.ChordAngle().Sub(other.ChordAngle()))
 }
-func (m minDistance) chordAngleBound() s1.ChordAngle {
-	return m.chordAngle().Expanded(m.chordAngle().MaxAngleError())
+func (m minDistance) ChordAngleBound() s1.ChordAngle {
+	return m.ChordAngle().Expanded(m.ChordAngle().MaxAngleError())
 }
 
-// updateDistance updates its own value if the other value is less() than it is,
+// UpdateDistance updates its own value if the other value is Less() than it is,
 // and reports if it updated.
-func (m minDistance) updateDistance(dist distance) (distance, bool) {
-	if dist.less(m) {
-		m = minDistance(dist.chordAngle())
+func (m minDistance) UpdateDistance(dist Distance) (Distance, bool) {
+	if dist.Less(m) {
+		m = minDistance(dist.ChordAngle())
 		return m, true
 	}
 	return m, false
 }
 
-func (m minDistance) fromChordAngle(o s1.ChordAngle) distance {
+func (m minDistance) FromChordAngle(o s1.ChordAngle) Distance {
 	return minDistance(o)
 }
 
 // MinDistanceToPointTarget is a type for computing the minimum distance to a Point.
 type MinDistanceToPointTarget struct {
 	point Point
-	dist  distance
+	dist  Distance
 }
 
 // NewMinDistanceToPointTarget returns a new target for the given Point.
@@ -61,50 +61,55 @@ func NewMinDistanceToPointTarget(point Point) *MinDistanceToPointTarget {
 	return &MinDistanceToPointTarget{point: point, dist: &m}
 }
 
-func (m *MinDistanceToPointTarget) capBound() Cap {
+func (m *MinDistanceToPointTarget) CapBound() Cap {
 	return CapFromCenterChordAngle(m.point, s1.ChordAngle(0))
 }
 
-func (m *MinDistanceToPointTarget) updateDistanceToPoint(p Point, dist distance) (distance, bool) {
+func (m *MinDistanceToPointTarget) UpdateDistanceToPoint(p Point, dist Distance) (Distance, bool) {
 	var ok bool
-	dist, ok = dist.updateDistance(minDistance(ChordAngleBetweenPoints(p, m.point)))
+	dist, ok = dist.UpdateDistance(minDistance(ChordAngleBetweenPoints(p, m.point)))
 	return dist, ok
 }
 
-func (m *MinDistanceToPointTarget) updateDistanceToEdge(edge Edge, dist distance) (distance, bool) {
-	if d, ok := UpdateMinDistance(m.point, edge.V0, edge.V1, dist.chordAngle()); ok {
-		dist, _ = dist.updateDistance(minDistance(d))
+func (m *MinDistanceToPointTarget) UpdateDistanceToEdge(edge Edge, dist Distance) (Distance, bool) {
+	if d, ok := UpdateMinDistance(m.point, edge.V0, edge.V1, dist.ChordAngle()); ok {
+		dist, _ = dist.UpdateDistance(minDistance(d))
 		return dist, true
 	}
 	return dist, false
 }
 
-func (m *MinDistanceToPointTarget) updateDistanceToCell(cell Cell, dist distance) (distance, bool) {
+func (m *MinDistanceToPointTarget) UpdateDistanceToCell(cell Cell, dist Distance) (Distance, bool) {
 	var ok bool
-	dist, ok = dist.updateDistance(minDistance(cell.Distance(m.point)))
+	dist, ok = dist.UpdateDistance(minDistance(cell.Distance(m.point)))
 	return dist, ok
 }
 
-func (m *MinDistanceToPointTarget) visitContainingShapes(index *ShapeIndex, v shapePointVisitorFunc) bool {
+func (m *MinDistanceToPointTarget) VisitContainingShapes(index *ShapeIndex, v ShapePointVisitorFunc) bool {
 	// For furthest points, we visit the polygons whose interior contains
 	// the antipode of the target point. These are the polygons whose
-	// distance to the target is maxDistance.zero()
+	// distance to the target is maxDistance.Zero()
 	q := NewContainsPointQuery(index, VertexModelSemiOpen)
 	return q.visitContainingShapes(m.point, func(shape Shape) bool {
 		return v(shape, m.point)
 	})
 }
 
-func (m *MinDistanceToPointTarget) setMaxError(maxErr s1.ChordAngle) bool { return false }
-func (m *MinDistanceToPointTarget) maxBruteForceIndexSize() int           { return 30 }
-func (m *MinDistanceToPointTarget) distance() distance                    { return m.dist }
+func (m *MinDistanceToPointTarget) SetMaxError(maxErr s1.ChordAngle) bool { return false }
+func (m *MinDistanceToPointTarget) MaxBruteForceIndexSize() int {
+	// Using BM_FindClosest (which finds the single closest edge), the
+	// break-even points are approximately 20, 65, and 190 edges for point
+	// cloud, fractal, and regular loop geometry respectively.
+	return 120
+}
+func (m *MinDistanceToPointTarget) Distance() Distance { return m.dist }
 
 // ----------------------------------------------------------
 
 // MinDistanceToEdgeTarget is a type for computing the minimum distance to an Edge.
 type MinDistanceToEdgeTarget struct {
 	e    Edge
-	dist distance
+	dist Distance
 }
 
 // NewMinDistanceToEdgeTarget returns a new target for the given Edge.
@@ -113,9 +118,9 @@ func NewMinDistanceToEdgeTarget(e Edge) *MinDistanceToEdgeTarget {
 	return &MinDistanceToEdgeTarget{e: e, dist: m}
 }
 
-// capBound returns a Cap that bounds the antipode of the target. (This
+// CapBound returns a Cap that bounds the antipode of the target. (This
 // is the set of points whose maxDistance to the target is maxDistance.zero)
-func (m *MinDistanceToEdgeTarget) capBound() Cap {
+func (m *MinDistanceToEdgeTarget) CapBound() Cap {
 	// The following computes a radius equal to half the edge length in an
 	// efficient and numerically stable way.
 	d2 := float64(ChordAngleBetweenPoints(m.e.V0, m.e.V1))
@@ -123,45 +128,50 @@ func (m *MinDistanceToEdgeTarget) capBound() Cap {
 	return CapFromCenterChordAngle(Point{m.e.V0.Add(m.e.V1.Vector).Normalize()}, s1.ChordAngleFromSquaredLength(r2))
 }
 
-func (m *MinDistanceToEdgeTarget) updateDistanceToPoint(p Point, dist distance) (distance, bool) {
-	if d, ok := UpdateMinDistance(p, m.e.V0, m.e.V1, dist.chordAngle()); ok {
-		dist, _ = dist.updateDistance(minDistance(d))
+func (m *MinDistanceToEdgeTarget) UpdateDistanceToPoint(p Point, dist Distance) (Distance, bool) {
+	if d, ok := UpdateMinDistance(p, m.e.V0, m.e.V1, dist.ChordAngle()); ok {
+		dist, _ = dist.UpdateDistance(minDistance(d))
 		return dist, true
 	}
 	return dist, false
 }
 
-func (m *MinDistanceToEdgeTarget) updateDistanceToEdge(edge Edge, dist distance) (distance, bool) {
-	if d, ok := updateEdgePairMinDistance(m.e.V0, m.e.V1, edge.V0, edge.V1, dist.chordAngle()); ok {
-		dist, _ = dist.updateDistance(minDistance(d))
+func (m *MinDistanceToEdgeTarget) UpdateDistanceToEdge(edge Edge, dist Distance) (Distance, bool) {
+	if d, ok := updateEdgePairMinDistance(m.e.V0, m.e.V1, edge.V0, edge.V1, dist.ChordAngle()); ok {
+		dist, _ = dist.UpdateDistance(minDistance(d))
 		return dist, true
 	}
 	return dist, false
 }
 
-func (m *MinDistanceToEdgeTarget) updateDistanceToCell(cell Cell, dist distance) (distance, bool) {
-	return dist.updateDistance(minDistance(cell.DistanceToEdge(m.e.V0, m.e.V1)))
+func (m *MinDistanceToEdgeTarget) UpdateDistanceToCell(cell Cell, dist Distance) (Distance, bool) {
+	return dist.UpdateDistance(minDistance(cell.DistanceToEdge(m.e.V0, m.e.V1)))
 }
 
-func (m *MinDistanceToEdgeTarget) visitContainingShapes(index *ShapeIndex, v shapePointVisitorFunc) bool {
+func (m *MinDistanceToEdgeTarget) VisitContainingShapes(index *ShapeIndex, v ShapePointVisitorFunc) bool {
 	// We test the center of the edge in order to ensure that edge targets AB
 	// and BA yield identical results (which is not guaranteed by the API but
 	// users might expect).  Other options would be to test both endpoints, or
 	// return different results for AB and BA in some cases.
 	target := NewMinDistanceToPointTarget(Point{m.e.V0.Add(m.e.V1.Vector).Normalize()})
-	return target.visitContainingShapes(index, v)
+	return target.VisitContainingShapes(index, v)
 }
 
-func (m *MinDistanceToEdgeTarget) setMaxError(maxErr s1.ChordAngle) bool { return false }
-func (m *MinDistanceToEdgeTarget) maxBruteForceIndexSize() int           { return 30 }
-func (m *MinDistanceToEdgeTarget) distance() distance                    { return m.dist }
+func (m *MinDistanceToEdgeTarget) SetMaxError(maxErr s1.ChordAngle) bool { return false }
+func (m *MinDistanceToEdgeTarget) MaxBruteForceIndexSize() int {
+	// Using BM_FindClosest (which finds the single closest edge), the
+	// break-even points are approximately 40, 50, and 100 edges for point
+	// cloud, fractal, and regular loop geometry respectively.
+	return 60
+}
+func (m *MinDistanceToEdgeTarget) Distance() Distance { return m.dist }
 
 // ----------------------------------------------------------
 
 // MinDistanceToCellTarget is a type for computing the minimum distance to a Cell.
 type MinDistanceToCellTarget struct {
 	cell Cell
-	dist distance
+	dist Distance
 }
 
 // NewMinDistanceToCellTarget returns a new target for the given Cell.
@@ -170,23 +180,23 @@ func NewMinDistanceToCellTarget(cell Cell) *MinDistanceToCellTarget {
 	return &MinDistanceToCellTarget{cell: cell, dist: m}
 }
 
-func (m *MinDistanceToCellTarget) capBound() Cap {
+func (m *MinDistanceToCellTarget) CapBound() Cap {
 	return m.cell.CapBound()
 }
 
-func (m *MinDistanceToCellTarget) updateDistanceToPoint(p Point, dist distance) (distance, bool) {
-	return dist.updateDistance(minDistance(m.cell.Distance(p)))
+func (m *MinDistanceToCellTarget) UpdateDistanceToPoint(p Point, dist Distance) (Distance, bool) {
+	return dist.UpdateDistance(minDistance(m.cell.Distance(p)))
 }
 
-func (m *MinDistanceToCellTarget) updateDistanceToEdge(edge Edge, dist distance) (distance, bool) {
-	return dist.updateDistance(minDistance(m.cell.DistanceToEdge(edge.V0, edge.V1)))
+func (m *MinDistanceToCellTarget) UpdateDistanceToEdge(edge Edge, dist Distance) (Distance, bool) {
+	return dist.UpdateDistance(minDistance(m.cell.DistanceToEdge(edge.V0, edge.V1)))
 }
 
-func (m *MinDistanceToCellTarget) updateDistanceToCell(cell Cell, dist distance) (distance, bool) {
-	return dist.updateDistance(minDistance(m.cell.DistanceToCell(cell)))
+func (m *MinDistanceToCellTarget) UpdateDistanceToCell(cell Cell, dist Distance) (Distance, bool) {
+	return dist.UpdateDistance(minDistance(m.cell.DistanceToCell(cell)))
 }
 
-func (m *MinDistanceToCellTarget) visitContainingShapes(index *ShapeIndex, v shapePointVisitorFunc) bool {
+func (m *MinDistanceToCellTarget) VisitContainingShapes(index *ShapeIndex, v ShapePointVisitorFunc) bool {
 	// The simplest approach is simply to return the polygons that contain the
 	// cell center.  Alternatively, if the index cell is smaller than the target
 	// cell then we could return all polygons that are present in the
@@ -196,65 +206,89 @@ func (m *MinDistanceToCellTarget) visitContainingShapes(index *ShapeIndex, v sha
 	// VisitContainingShapes contract so that it only guarantees approximate
 	// intersection, neither of which seems like a good tradeoff.
 	target := NewMinDistanceToPointTarget(m.cell.Center())
-	return target.visitContainingShapes(index, v)
+	return target.VisitContainingShapes(index, v)
 }
-func (m *MinDistanceToCellTarget) setMaxError(maxErr s1.ChordAngle) bool { return false }
-func (m *MinDistanceToCellTarget) maxBruteForceIndexSize() int           { return 30 }
-func (m *MinDistanceToCellTarget) distance() distance                    { return m.dist }
+func (m *MinDistanceToCellTarget) SetMaxError(maxErr s1.ChordAngle) bool { return false }
+func (m *MinDistanceToCellTarget) MaxBruteForceIndexSize() int {
+	// Using BM_FindClosest (which finds the single closest edge), the
+	// break-even points are approximately 20, 25, and 40 edges for point
+	// cloud, fractal, and regular loop geometry respectively.
+	return 30
+}
+func (m *MinDistanceToCellTarget) Distance() Distance { return m.dist }
 
 // ----------------------------------------------------------
 
-/*
-// MinDistanceToCellUnionTarget is a type for computing the minimum distance to a CellUnion.
+// MinDistanceToCellUnionTarget is a type for computing the minimum distance to
+// a CellUnion. The cells are treated as solid regions, so the distance to any
+// point inside the union is zero.
 type MinDistanceToCellUnionTarget struct {
-	cu    CellUnion
-	query *ClosestCellQuery
-	dist  distance
+	cu     CellUnion
+	target *MinDistanceToShapeIndexTarget
+	dist   Distance
 }
 
 // NewMinDistanceToCellUnionTarget returns a new target for the given CellUnion.
 func NewMinDistanceToCellUnionTarget(cu CellUnion) *MinDistanceToCellUnionTarget {
 	m := minDistance(0)
-	return &MinDistanceToCellUnionTarget{cu: cu, dist: m}
+	target := NewMinDistanceToShapeIndexTarget(cellUnionShapeIndex(cu))
+	target.setIncludeInteriors(true)
+	return &MinDistanceToCellUnionTarget{cu: cu, target: target, dist: m}
 }
 
-func (m *MinDistanceToCellUnionTarget) capBound() Cap {
+// CapBound returns a Cap that bounds the cells of the target. (This is the
+// set of points whose minDistance to the target is minDistance.Zero().)
+func (m *MinDistanceToCellUnionTarget) CapBound() Cap {
 	return m.cu.CapBound()
 }
 
-func (m *MinDistanceToCellUnionTarget) updateDistanceToCell(cell Cell, dist distance) (distance, bool) {
-	m.query.opts.DistanceLimit = dist.chordAngle()
-	target := NewMinDistanceToPointTarget(p)
-	r := m.query.findEdge(target)
-	if r.ShapeID < 0 {
-		return dist, false
+func (m *MinDistanceToCellUnionTarget) UpdateDistanceToPoint(p Point, dist Distance) (Distance, bool) {
+	return m.target.UpdateDistanceToPoint(p, dist)
+}
+
+func (m *MinDistanceToCellUnionTarget) UpdateDistanceToEdge(edge Edge, dist Distance) (Distance, bool) {
+	return m.target.UpdateDistanceToEdge(edge, dist)
+}
+
+func (m *MinDistanceToCellUnionTarget) UpdateDistanceToCell(cell Cell, dist Distance) (Distance, bool) {
+	return m.target.UpdateDistanceToCell(cell, dist)
+}
+
+func (m *MinDistanceToCellUnionTarget) VisitContainingShapes(index *ShapeIndex, v ShapePointVisitorFunc) bool {
+	// Each cell is a connected component of the target, so it is sufficient
+	// to test the center of each cell.
+	points := make([]Point, len(m.cu))
+	for i, id := range m.cu {
+		points[i] = id.Point()
 	}
-	return minDistance(r.Distance), true
+	q := NewContainsPointQuery(index, VertexModelSemiOpen)
+	return q.visitContainingShapesOfPoints(points, func(shape Shape, i int) bool {
+		return v(shape, points[i])
+	})
 }
 
-func (m *MinDistanceToCellUnionTarget) visitContainingShapes(index *ShapeIndex, v shapePointVisitorFunc) bool {
-	// We test the center of the edge in order to ensure that edge targets AB
-	// and BA yield identical results (which is not guaranteed by the API but
-	// users might expect).  Other options would be to test both endpoints, or
-	// return different results for AB and BA in some cases.
-	target := NewMinDistanceToPointTarget(Point{m.e.V0.Add(m.e.V1.Vector).Normalize()})
-	return target.visitContainingShapes(index, v)
+func (m *MinDistanceToCellUnionTarget) SetMaxError(maxErr s1.ChordAngle) bool {
+	return m.target.SetMaxError(maxErr)
 }
-func (m *MinDistanceToCellUnionTarget) setMaxError(maxErr s1.ChordAngle) bool {
-	m.query.opts.MaxError = maxErr
-	return true
+func (m *MinDistanceToCellUnionTarget) MaxBruteForceIndexSize() int {
+	// Same as for Cells.
+	return 30
 }
-func (m *MinDistanceToCellUnionTarget) maxBruteForceIndexSize() int           { return 30 }
-func (m *MinDistanceToCellUnionTarget) distance() distance                    { return m.dist }
-*/
+func (m *MinDistanceToCellUnionTarget) Distance() Distance      { return m.dist }
+func (m *MinDistanceToCellUnionTarget) setUseBruteForce(b bool) { m.target.setUseBruteForce(b) }
 
 // ----------------------------------------------------------
 
 // MinDistanceToShapeIndexTarget is a type for computing the minimum distance to a ShapeIndex.
+//
+// When it is the target of an EdgeQuery, the cells of the query index and
+// the target index are merge-joined, so that the work done is roughly
+// proportional to the number of nearby edges rather than to the product of
+// the sizes of the two indexes.
 type MinDistanceToShapeIndexTarget struct {
 	index *ShapeIndex
 	query *EdgeQuery
-	dist  distance
+	dist  Distance
 }
 
 // NewMinDistanceToShapeIndexTarget returns a new target for the given ShapeIndex.
@@ -267,13 +301,12 @@ func NewMinDistanceToShapeIndexTarget(index *ShapeIndex) *MinDistanceToShapeInde
 	}
 }
 
-func (m *MinDistanceToShapeIndexTarget) capBound() Cap {
-	c := m.index.Region().CapBound()
-	return CapFromCenterAngle(Point{c.Center().Mul(-1)}, c.Radius())
+func (m *MinDistanceToShapeIndexTarget) CapBound() Cap {
+	return m.index.Region().CapBound()
 }
 
-func (m *MinDistanceToShapeIndexTarget) updateDistanceToPoint(p Point, dist distance) (distance, bool) {
-	m.query.opts.distanceLimit = dist.chordAngle()
+func (m *MinDistanceToShapeIndexTarget) UpdateDistanceToPoint(p Point, dist Distance) (Distance, bool) {
+	m.query.opts.distanceLimit = dist.ChordAngle()
 	target := NewMinDistanceToPointTarget(p)
 	r := m.query.findEdge(target, m.query.opts)
 	if r.shapeID < 0 {
@@ -282,8 +315,8 @@ func (m *MinDistanceToShapeIndexTarget) updateDistanceToPoint(p Point, dist dist
 	return r.distance, true
 }
 
-func (m *MinDistanceToShapeIndexTarget) updateDistanceToEdge(edge Edge, dist distance) (distance, bool) {
-	m.query.opts.distanceLimit = dist.chordAngle()
+func (m *MinDistanceToShapeIndexTarget) UpdateDistanceToEdge(edge Edge, dist Distance) (Distance, bool) {
+	m.query.opts.distanceLimit = dist.ChordAngle()
 	target := NewMinDistanceToEdgeTarget(edge)
 	r := m.query.findEdge(target, m.query.opts)
 	if r.shapeID < 0 {
@@ -292,8 +325,8 @@ func (m *MinDistanceToShapeIndexTarget) updateDistanceToEdge(edge Edge, dist dis
 	return r.distance, true
 }
 
-func (m *MinDistanceToShapeIndexTarget) updateDistanceToCell(cell Cell, dist distance) (distance, bool) {
-	m.query.opts.distanceLimit = dist.chordAngle()
+func (m *MinDistanceToShapeIndexTarget) UpdateDistanceToCell(cell Cell, dist Distance) (Distance, bool) {
+	m.query.opts.distanceLimit = dist.ChordAngle()
 	target := NewMinDistanceToCellTarget(cell)
 	r := m.query.findEdge(target, m.query.opts)
 	if r.shapeID < 0 {
@@ -306,54 +339,39 @@ func (m *MinDistanceToShapeIndexTarget) updateDistanceToCell(cell Cell, dist dis
 // this method should return the polygons containing the antipodal reflection of
 // *any* connected component. (It is sufficient to test containment of one vertex per
 // connected component, since this allows us to also return any polygon whose
-// boundary has distance.zero() to the target.)
-func (m *MinDistanceToShapeIndexTarget) visitContainingShapes(index *ShapeIndex, v shapePointVisitorFunc) bool {
+// boundary has Distance.Zero() to the target.)
+func (m *MinDistanceToShapeIndexTarget) VisitContainingShapes(index *ShapeIndex, v ShapePointVisitorFunc) bool {
 	// It is sufficient to find the set of chain starts in the target index
 	// (i.e., one vertex per connected component of edges) that are contained by
 	// the query index, except for one special case to handle full polygons.
-	//
-	// TODO(roberts): Do this by merge-joining the two ShapeIndexes.
-	for _, shape := range m.index.shapes {
-		numChains := shape.NumChains()
-		// Shapes that don't have any edges require a special case (below).
-		testedPoint := false
-		for c := 0; c < numChains; c++ {
-			chain := shape.Chain(c)
-			if chain.Length == 0 {
-				continue
-			}
-			testedPoint = true
-			target := NewMinDistanceToPointTarget(shape.ChainEdge(c, 0).V0)
-			if !target.visitContainingShapes(index, v) {
-				return false
-			}
-		}
-		if !testedPoint {
-			// Special case to handle full polygons.
-			ref := shape.ReferencePoint()
-			if !ref.Contained {
-				continue
-			}
-			target := NewMinDistanceToPointTarget(ref.Point)
-			if !target.visitContainingShapes(index, v) {
-				return false
-			}
-		}
-	}
-	return true
+	// These are merge-joined with the query index in a single pass.
+	points := shapeIndexComponentPoints(m.index)
+	q := NewContainsPointQuery(index, VertexModelSemiOpen)
+	return q.visitContainingShapesOfPoints(points, func(shape Shape, i int) bool {
+		return v(shape, points[i])
+	})
 }
 
-func (m *MinDistanceToShapeIndexTarget) setMaxError(maxErr s1.ChordAngle) bool {
+func (m *MinDistanceToShapeIndexTarget) SetMaxError(maxErr s1.ChordAngle) bool {
 	m.query.opts.maxError = maxErr
 	return true
 }
-func (m *MinDistanceToShapeIndexTarget) maxBruteForceIndexSize() int { return 25 }
-func (m *MinDistanceToShapeIndexTarget) distance() distance          { return m.dist }
+func (m *MinDistanceToShapeIndexTarget) MaxBruteForceIndexSize() int {
+	// Using BM_FindClosest (which finds the single closest edge), the
+	// break-even points are approximately 20, 30, and 40 edges for point
+	// cloud, fractal, and regular loop geometry respectively.
+	return 25
+}
+func (m *MinDistanceToShapeIndexTarget) Distance() Distance      { return m.dist }
+func (m *MinDistanceToShapeIndexTarget) targetQuery() *EdgeQuery { return m.query }
+func (m *MinDistanceToShapeIndexTarget) cellTarget(cell Cell) DistanceTarget {
+	return NewMinDistanceToCellTarget(cell)
+}
+func (m *MinDistanceToShapeIndexTarget) edgeTarget(edge Edge) DistanceTarget {
+	return NewMinDistanceToEdgeTarget(edge)
+}
+func (m *MinDistanceToShapeIndexTarget) interiorTestPoint(p Point) Point { return p }
 func (m *MinDistanceToShapeIndexTarget) setIncludeInteriors(b bool) {
 	m.query.opts.includeInteriors = b
 }
 func (m *MinDistanceToShapeIndexTarget) setUseBruteForce(b bool) { m.query.opts.useBruteForce = b }
-
-// TODO(roberts): Remaining methods
-//
-// CellUnionTarget
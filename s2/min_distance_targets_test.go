@@ -15,8 +15,11 @@
 package s2
 
 import (
+	"math/rand"
 	"reflect"
 	"testing"
+
+	"github.com/golang/geo/s1"
 )
 
 func TestDistanceTargetMinCellTargetUpdateDistanceToCellWhenEqual(t *testing.T) {
@@ -24,16 +27,16 @@ func TestDistanceTargetMinCellTargetUpdateDistanceToCellWhenEqual(t *testing.T)
 
 	targetCell := CellFromCellID(cellIDFromPoint(parsePoint("0:1")))
 	target := NewMinDistanceToCellTarget(targetCell)
-	dist := minDist.infinity()
+	dist := minDist.Infinity()
 	cell := CellFromCellID(cellIDFromPoint(parsePoint("0:0")))
 
 	// First call should pass.
-	dist0, ok := target.updateDistanceToCell(cell, dist)
+	dist0, ok := target.UpdateDistanceToCell(cell, dist)
 	if !ok {
 		t.Errorf("target.updateDistanceToCell(%v, %v) should have succeeded", cell, dist)
 	}
 	// Second call should fail.
-	if _, ok := target.updateDistanceToCell(cell, dist0); ok {
+	if _, ok := target.UpdateDistanceToCell(cell, dist0); ok {
 		t.Errorf("target.updateDistanceToCell(%v, %v) should have failed", cell, dist0)
 	}
 }
@@ -43,17 +46,17 @@ func TestDistanceTargetMinCellTargetUpdateDistanceToEdgeWhenEqual(t *testing.T)
 
 	targetCell := CellFromCellID(cellIDFromPoint(parsePoint("0:1")))
 	target := NewMinDistanceToCellTarget(targetCell)
-	dist := minDist.infinity()
+	dist := minDist.Infinity()
 	pts := parsePoints("0:-1, 0:1")
 	edge := Edge{pts[0], pts[1]}
 
 	// First call should pass.
-	dist0, ok := target.updateDistanceToEdge(edge, dist)
+	dist0, ok := target.UpdateDistanceToEdge(edge, dist)
 	if !ok {
 		t.Errorf("target.updateDistanceToEdge(%v, %v) should have succeeded", edge, dist)
 	}
 	// Second call should fail.
-	if _, ok := target.updateDistanceToEdge(edge, dist0); ok {
+	if _, ok := target.UpdateDistanceToEdge(edge, dist0); ok {
 		t.Errorf("target.updateDistanceToEdge(%v, %v) should have failed", edge, dist0)
 	}
 }
@@ -82,70 +85,91 @@ func TestDistanceTargetMinCellTargetVisitContainingShapes(t *testing.T) {
 }
 
 func TestDistanceTargetMinCellUnionTargetUpdateDistanceToCellWhenEqual(t *testing.T) {
-	// TODO(roberts): Uncomment when implemented.
-	/*
-		var minDist minDistance
-
-		targetCellUnion := CellUnion([]CellID{cellIDFromPoint(parsePoint("0:1"))})
-		target := NewMinDistanceToCellUnionTarget(targetCellUnion)
-		dist := minDist.infinity()
-		cell := CellFromCellID(cellIDFromPoint(parsePoint("0:0")))
-
-		// First call should pass.
-		dist0, ok := target.updateDistanceToCell(cell, dist)
-		if !ok {
-			t.Errorf("target.updateDistanceToCell(%v, %v) should have succeeded", cell, dist)
-		}
-		// Second call should fail.
-		if dist1, ok := target.updateDistanceToCell(cell, dist0); ok {
-			t.Errorf("target.updateDistanceToCell(%v, %v) should have failed", cell, dist0)
-		}
-	*/
+	var minDist minDistance
+
+	targetCellUnion := CellUnion([]CellID{cellIDFromPoint(parsePoint("0:1"))})
+	target := NewMinDistanceToCellUnionTarget(targetCellUnion)
+	dist := minDist.Infinity()
+	cell := CellFromCellID(cellIDFromPoint(parsePoint("0:0")))
+
+	// First call should pass.
+	dist0, ok := target.UpdateDistanceToCell(cell, dist)
+	if !ok {
+		t.Errorf("target.updateDistanceToCell(%v, %v) should have succeeded", cell, dist)
+	}
+	// Second call should fail.
+	if _, ok := target.UpdateDistanceToCell(cell, dist0); ok {
+		t.Errorf("target.updateDistanceToCell(%v, %v) should have failed", cell, dist0)
+	}
 }
 
 func TestDistanceTargetMinCellUnionTargetUpdateDistanceToEdgeWhenEqual(t *testing.T) {
-	// TODO(roberts): Uncomment when implemented.
-	/*
-		var minDist minDistance
-
-		targetCellUnion := CellUnion([]CellID{cellIDFromPoint(parsePoint("0:1"))})
-		target := NewMinDistanceToCellUnionTarget(targetCellUnion)
-		dist := minDist.infinity()
-		pts := parsePoints("0:-1, 0:1")
-		edge := Edge{pts[0], pts[1]}
-
-		// First call should pass.
-		dist0, ok := target.updateDistanceToEdge(edge, dist)
-		if !ok {
-			t.Errorf("target.updateDistanceToEdge(%v, %v) should have succeeded", edge, dist)
+	var minDist minDistance
+
+	targetCellUnion := CellUnion([]CellID{cellIDFromPoint(parsePoint("0:1"))})
+	target := NewMinDistanceToCellUnionTarget(targetCellUnion)
+	dist := minDist.Infinity()
+	pts := parsePoints("0:-1, 0:1")
+	edge := Edge{pts[0], pts[1]}
+
+	// First call should pass.
+	dist0, ok := target.UpdateDistanceToEdge(edge, dist)
+	if !ok {
+		t.Errorf("target.updateDistanceToEdge(%v, %v) should have succeeded", edge, dist)
+	}
+	// Second call should fail.
+	if _, ok := target.UpdateDistanceToEdge(edge, dist0); ok {
+		t.Errorf("target.updateDistanceToEdge(%v, %v) should have failed", edge, dist0)
+	}
+}
+
+func TestDistanceTargetMinCellUnionTargetUpdateDistance(t *testing.T) {
+	cu := CellUnion([]CellID{
+		cellIDFromPoint(parsePoint("0:0")).Parent(10),
+		cellIDFromPoint(parsePoint("0:10")).Parent(10),
+	})
+	cu.Normalize()
+
+	for _, useBruteForce := range []bool{true, false} {
+		target := NewMinDistanceToCellUnionTarget(cu)
+		target.setUseBruteForce(useBruteForce)
+
+		// A point inside one of the cells is at distance zero.
+		p := cellIDFromPoint(parsePoint("0:10")).Parent(12).Point()
+		if got, ok := target.UpdateDistanceToPoint(p, minDistance(s1.InfChordAngle())); !ok || got.ChordAngle() != 0 {
+			t.Errorf("useBruteForce = %v: target.UpdateDistanceToPoint(%v) = %v, %v, want 0, true", useBruteForce, p, got, ok)
+		}
+
+		// A point outside is as close as the nearest cell.
+		q := parsePoint("0:5")
+		want := CellFromCellID(cu[0]).Distance(q)
+		if d := CellFromCellID(cu[1]).Distance(q); d < want {
+			want = d
 		}
-		// Second call should fail.
-		if dist1, ok := target.updateDistanceToEdge(edge, dist0); ok {
-			t.Errorf("target.updateDistanceToEdge(%v, %v) should have failed", edge, dist0)
+		got, ok := target.UpdateDistanceToPoint(q, minDistance(s1.InfChordAngle()))
+		if !ok || !float64Near(float64(got.ChordAngle()), float64(want), 1e-15) {
+			t.Errorf("useBruteForce = %v: target.UpdateDistanceToPoint(%v) = %v, %v, want %v, true", useBruteForce, q, got, ok, want)
 		}
-	*/
+	}
 }
 
 func TestDistanceTargetMinCellUnionTargetVisitContainingShapes(t *testing.T) {
-	// TODO(roberts): Uncomment when implemented.
-	/*
-		index := makeShapeIndex("1:1 # 1:1, 2:2 # 0:0, 0:3, 3:0 | 6:6, 6:9, 9:6 | -1:-1, -1:5, 5:-1")
-
-		// Shapes 2 and 4 contain the leaf cell near 1:1, while shape 3 contains the
-		// leaf cell near 7:7.
-		targetCellUnion := CellUnion([]CellID{
-			cellIDFromPoint(parsePoint("1:1")),
-			cellIDFromPoint(parsePoint("7:7")),
-		})
-		target := NewMinDistanceToCellUnionTarget(targetCellUnion)
-
-		if got, want := containingShapesForTarget(target, index, 1), []int{2}; !reflect.DeepEqual(got, want) {
-			t.Errorf("containingShapesForTarget(%v, %q, 1) = %+v, want %+v", targetEdge, shapeIndexDebugString(index, false), got, want)
-		}
-		if got, want := containingShapesForTarget(target, index, 5), []int{2, 3, 4}; !reflect.DeepEqual(got, want) {
-			t.Errorf("containingShapesForTarget(%v, %q, 5) = %+v, want %+v", targetEdge, shapeIndexDebugString(index, false), got, want)
-		}
-	*/
+	index := makeShapeIndex("1:1 # 1:1, 2:2 # 0:0, 0:3, 3:0 | 6:6, 6:9, 9:6 | -1:-1, -1:5, 5:-1")
+
+	// Shapes 2 and 4 contain the leaf cell near 1:1, while shape 3 contains the
+	// leaf cell near 7:7.
+	targetCellUnion := CellUnion([]CellID{
+		cellIDFromPoint(parsePoint("1:1")),
+		cellIDFromPoint(parsePoint("7:7")),
+	})
+	target := NewMinDistanceToCellUnionTarget(targetCellUnion)
+
+	if got, want := containingShapesForTarget(target, index, 1), []int{2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("containingShapesForTarget(%v, %q, 1) = %+v, want %+v", target, shapeIndexDebugString(index, false), got, want)
+	}
+	if got, want := containingShapesForTarget(target, index, 5), []int{2, 3, 4}; !reflect.DeepEqual(got, want) {
+		t.Errorf("containingShapesForTarget(%v, %q, 5) = %+v, want %+v", target, shapeIndexDebugString(index, false), got, want)
+	}
 }
 
 func TestDistanceTargetMinEdgeTargetUpdateDistanceToCellWhenEqual(t *testing.T) {
@@ -153,16 +177,16 @@ func TestDistanceTargetMinEdgeTargetUpdateDistanceToCellWhenEqual(t *testing.T)
 
 	targetEdge := parsePoints("1:0, 1:1")
 	target := NewMinDistanceToEdgeTarget(Edge{targetEdge[0], targetEdge[1]})
-	dist := minDist.infinity()
+	dist := minDist.Infinity()
 	cell := CellFromCellID(cellIDFromPoint(parsePoint("0:0")))
 
 	// First call should pass.
-	dist0, ok := target.updateDistanceToCell(cell, dist)
+	dist0, ok := target.UpdateDistanceToCell(cell, dist)
 	if !ok {
 		t.Errorf("target.updateDistanceToCell(%v, %v) should have succeeded", cell, dist)
 	}
 	// Second call should fail.
-	if _, ok := target.updateDistanceToCell(cell, dist0); ok {
+	if _, ok := target.UpdateDistanceToCell(cell, dist0); ok {
 		t.Errorf("target.updateDistanceToCell(%v, %v) should have failed", cell, dist0)
 	}
 }
@@ -172,17 +196,17 @@ func TestDistanceTargetMinEdgeTargetUpdateDistanceToEdgeWhenEqual(t *testing.T)
 
 	targetEdge := parsePoints("1:0, 1:1")
 	target := NewMinDistanceToEdgeTarget(Edge{targetEdge[0], targetEdge[1]})
-	dist := minDist.infinity()
+	dist := minDist.Infinity()
 	pts := parsePoints("0:-1, 0:1")
 	edge := Edge{pts[0], pts[1]}
 
 	// First call should pass.
-	dist0, ok := target.updateDistanceToEdge(edge, dist)
+	dist0, ok := target.UpdateDistanceToEdge(edge, dist)
 	if !ok {
 		t.Errorf("target.updateDistanceToEdge(%v, %v) should have succeeded", edge, dist)
 	}
 	// Second call should fail.
-	if _, ok := target.updateDistanceToEdge(edge, dist0); ok {
+	if _, ok := target.UpdateDistanceToEdge(edge, dist0); ok {
 		t.Errorf("target.updateDistanceToEdge(%v, %v) should have failed", edge, dist0)
 	}
 }
@@ -205,16 +229,16 @@ func TestDistanceTargetMinEdgeTargetVisitContainingShapes(t *testing.T) {
 func TestDistanceTargetMinPointTargetUpdateDistanceToCellWhenEqual(t *testing.T) {
 	target := NewMinDistanceToPointTarget(parsePoint("1:0"))
 	var minDist minDistance
-	dist := minDist.infinity()
+	dist := minDist.Infinity()
 	cell := CellFromCellID(cellIDFromPoint(parsePoint("0:0")))
 
 	// First call should pass.
-	dist1, ok := target.updateDistanceToCell(cell, dist)
+	dist1, ok := target.UpdateDistanceToCell(cell, dist)
 	if !ok {
 		t.Errorf("target.updateDistanceToCell(%v, %v) should have succeeded", cell, dist)
 	}
 	// Second call should fail.
-	if _, ok := target.updateDistanceToCell(cell, dist1); ok {
+	if _, ok := target.UpdateDistanceToCell(cell, dist1); ok {
 		t.Errorf("target.updateDistanceToCell(%v, %v) should have failed", cell, dist)
 	}
 }
@@ -222,16 +246,16 @@ func TestDistanceTargetMinPointTargetUpdateDistanceToCellWhenEqual(t *testing.T)
 func TestDistanceTargetMinPointTargetUpdateDistanceToEdgeWhenEqual(t *testing.T) {
 	target := NewMinDistanceToPointTarget(parsePoint("1:0"))
 	var minDist minDistance
-	dist := minDist.infinity()
+	dist := minDist.Infinity()
 	edge := parsePoints("0:-1, 0:1")
 
 	// First call should pass.
-	dist1, ok := target.updateDistanceToEdge(Edge{edge[0], edge[1]}, dist)
+	dist1, ok := target.UpdateDistanceToEdge(Edge{edge[0], edge[1]}, dist)
 	if !ok {
 		t.Errorf("target.updateDistanceToEdge(%v, %v) should have succeeded", edge, dist)
 	}
 	// Second call should fail.
-	if _, ok := target.updateDistanceToEdge(Edge{edge[0], edge[1]}, dist1); ok {
+	if _, ok := target.UpdateDistanceToEdge(Edge{edge[0], edge[1]}, dist1); ok {
 		t.Errorf("target.updateDistanceToEdge(%v, %v) should have failed", edge, dist1)
 	}
 }
@@ -250,21 +274,48 @@ func TestDistanceTargetMinPointTargetVisitContainingShapes(t *testing.T) {
 	}
 }
 
+func TestDistanceTargetMinShapeIndexTargetCapBound(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	index := NewShapeIndex()
+	index.Add(PolygonFromCell(CellFromCellID(randomCellIDForLevel(randomUniformInt(MaxLevel+1, r), r))))
+	pv := PointVector([]Point{randomPoint(r)})
+	index.Add(Shape(&pv))
+	target := NewMinDistanceToShapeIndexTarget(index)
+	target.setIncludeInteriors(true)
+	c := target.CapBound()
+
+	for j := 0; j < 100; j++ {
+		pTest := randomPoint(r)
+		// Points at distance zero from the target must be inside the cap.
+		dist, ok := target.UpdateDistanceToPoint(pTest, minDistance(s1.InfChordAngle()))
+		if ok && dist.ChordAngle() == 0 && !c.ContainsPoint(pTest) {
+			t.Errorf("point %v at distance zero should be contained by %v", pTest, c)
+		}
+	}
+	for _, shape := range index.shapes {
+		for e := 0; e < shape.NumEdges(); e++ {
+			if v := shape.Edge(e).V0; !c.ContainsPoint(v) {
+				t.Errorf("vertex %v of the target should be contained by %v", v, c)
+			}
+		}
+	}
+}
+
 func TestDistanceTargetMinShapeIndexTargetUpdateDistanceToCellWhenEqual(t *testing.T) {
 	index := makeShapeIndex("1:0 # #")
 	target := NewMinDistanceToShapeIndexTarget(index)
 	var minDist minDistance
-	dist := minDist.infinity()
+	dist := minDist.Infinity()
 	cell := CellFromCellID(cellIDFromPoint(parsePoint("0:0")))
 
 	// First call should pass.
-	dist1, ok := target.updateDistanceToCell(cell, dist)
+	dist1, ok := target.UpdateDistanceToCell(cell, dist)
 	if !ok {
 		t.Errorf("target.updateDistanceToCell(%v, %v) should have succeeded", cell, dist)
 	}
 
 	// Repeat call should fail.
-	if _, ok := target.updateDistanceToCell(cell, dist1); ok {
+	if _, ok := target.UpdateDistanceToCell(cell, dist1); ok {
 		t.Errorf("target.updateDistanceToCell(%v, %v) should have failed", cell, dist1)
 	}
 }
@@ -273,18 +324,18 @@ func TestDistanceTargetMinShapeIndexTargetUpdateDistanceToEdgeWhenEqual(t *testi
 	index := makeShapeIndex("1:0 # #")
 	target := NewMinDistanceToShapeIndexTarget(index)
 	var minDist minDistance
-	dist := minDist.infinity()
+	dist := minDist.Infinity()
 
 	pts := parsePoints("0:-1, 0:1")
 	edge := Edge{pts[0], pts[1]}
 
 	// First call should pass.
-	dist0, ok := target.updateDistanceToEdge(edge, dist)
+	dist0, ok := target.UpdateDistanceToEdge(edge, dist)
 	if !ok {
 		t.Errorf("target.updateDistanceToEdge(%v, %v) should have succeeded", edge, dist)
 	}
 	// Second call should fail.
-	if _, ok := target.updateDistanceToEdge(edge, dist0); ok {
+	if _, ok := target.UpdateDistanceToEdge(edge, dist0); ok {
 		t.Errorf("target.updateDistanceToEdge(%v, %v) should have failed", edge, dist0)
 	}
 }
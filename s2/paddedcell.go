@@ -50,10 +50,10 @@ func PaddedCellFromCellID(id CellID, padding float64) *PaddedCell {
 		return p
 	}
 
-	_, p.iLo, p.jLo, p.orientation = id.faceIJOrientation()
+	_, p.iLo, p.jLo, p.orientation = id.FaceIJOrientation()
 	p.level = id.Level()
 	p.bound = ijLevelToBoundUV(p.iLo, p.jLo, p.level).ExpandedByMargin(padding)
-	ijSize := sizeIJ(p.level)
+	ijSize := SizeIJ(p.level)
 	p.iLo &= -ijSize
 	p.jLo &= -ijSize
 
@@ -77,7 +77,7 @@ func PaddedCellFromParentIJ(parent *PaddedCell, i, j int) *PaddedCell {
 		middle:      r2.EmptyRect(),
 	}
 
-	ijSize := sizeIJ(p.level)
+	ijSize := SizeIJ(p.level)
 	p.iLo = parent.iLo + i*ijSize
 	p.jLo = parent.jLo + j*ijSize
 
@@ -115,10 +115,10 @@ func (p PaddedCell) Level() int {
 
 // Center returns the center of this cell.
 func (p PaddedCell) Center() Point {
-	ijSize := sizeIJ(p.level)
+	ijSize := SizeIJ(p.level)
 	si := uint32(2*p.iLo + ijSize)
 	ti := uint32(2*p.jLo + ijSize)
-	return Point{faceSiTiToXYZ(p.id.Face(), si, ti).Normalize()}
+	return Point{FaceSiTiToXYZ(p.id.Face(), si, ti).Normalize()}
 }
 
 // Middle returns the rectangle in the middle of this cell that belongs to
@@ -127,9 +127,9 @@ func (p *PaddedCell) Middle() r2.Rect {
 	// We compute this field lazily because it is not needed the majority of the
 	// time (i.e., for cells where the recursion terminates).
 	if p.middle.IsEmpty() {
-		ijSize := sizeIJ(p.level)
-		u := stToUV(siTiToST(uint32(2*p.iLo + ijSize)))
-		v := stToUV(siTiToST(uint32(2*p.jLo + ijSize)))
+		ijSize := SizeIJ(p.level)
+		u := STToUV(SiTiToST(uint32(2*p.iLo + ijSize)))
+		v := STToUV(SiTiToST(uint32(2*p.jLo + ijSize)))
 		p.middle = r2.Rect{
 			X: r1.Interval{Lo: u - p.padding, Hi: u + p.padding},
 			Y: r1.Interval{Lo: v - p.padding, Hi: v + p.padding},
@@ -158,11 +158,11 @@ func (p PaddedCell) EntryVertex() Point {
 	i := p.iLo
 	j := p.jLo
 	if p.orientation&invertMask != 0 {
-		ijSize := sizeIJ(p.level)
+		ijSize := SizeIJ(p.level)
 		i += ijSize
 		j += ijSize
 	}
-	return Point{faceSiTiToXYZ(p.id.Face(), uint32(2*i), uint32(2*j)).Normalize()}
+	return Point{FaceSiTiToXYZ(p.id.Face(), uint32(2*i), uint32(2*j)).Normalize()}
 }
 
 // ExitVertex returns the vertex where the space-filling curve exits this cell.
@@ -171,13 +171,13 @@ func (p PaddedCell) ExitVertex() Point {
 	// inverted but not both, in which case it exits at the (0,1) vertex.
 	i := p.iLo
 	j := p.jLo
-	ijSize := sizeIJ(p.level)
+	ijSize := SizeIJ(p.level)
 	if p.orientation == 0 || p.orientation == swapMask+invertMask {
 		i += ijSize
 	} else {
 		j += ijSize
 	}
-	return Point{faceSiTiToXYZ(p.id.Face(), uint32(2*i), uint32(2*j)).Normalize()}
+	return Point{FaceSiTiToXYZ(p.id.Face(), uint32(2*i), uint32(2*j)).Normalize()}
 }
 
 // ShrinkToFit returns the smallest CellID that contains all descendants of this
@@ -202,9 +202,9 @@ func (p *PaddedCell) ShrinkToFit(rect r2.Rect) CellID {
 		}
 	}
 
-	ijSize := sizeIJ(p.level)
-	if rect.X.Contains(stToUV(siTiToST(uint32(2*p.iLo+ijSize)))) ||
-		rect.Y.Contains(stToUV(siTiToST(uint32(2*p.jLo+ijSize)))) {
+	ijSize := SizeIJ(p.level)
+	if rect.X.Contains(STToUV(SiTiToST(uint32(2*p.iLo+ijSize)))) ||
+		rect.Y.Contains(STToUV(SiTiToST(uint32(2*p.jLo+ijSize)))) {
 		return p.id
 	}
 
@@ -214,25 +214,25 @@ func (p *PaddedCell) ShrinkToFit(rect r2.Rect) CellID {
 	// differ. This corresponds to the first cell level at which at least two
 	// children intersect rect.
 
-	// Increase the padding to compensate for the error in uvToST.
+	// Increase the padding to compensate for the error in UVToST.
 	// (The constant below is a provable upper bound on the additional error.)
 	padded := rect.ExpandedByMargin(p.padding + 1.5*dblEpsilon)
 	iMin, jMin := p.iLo, p.jLo // Min i- or j- coordinate spanned by padded
 	var iXor, jXor int         // XOR of the min and max i- or j-coordinates
 
-	if iMin < stToIJ(uvToST(padded.X.Lo)) {
-		iMin = stToIJ(uvToST(padded.X.Lo))
+	if iMin < STToIJ(UVToST(padded.X.Lo)) {
+		iMin = STToIJ(UVToST(padded.X.Lo))
 	}
-	if a, b := p.iLo+ijSize-1, stToIJ(uvToST(padded.X.Hi)); a <= b {
+	if a, b := p.iLo+ijSize-1, STToIJ(UVToST(padded.X.Hi)); a <= b {
 		iXor = iMin ^ a
 	} else {
 		iXor = iMin ^ b
 	}
 
-	if jMin < stToIJ(uvToST(padded.Y.Lo)) {
-		jMin = stToIJ(uvToST(padded.Y.Lo))
+	if jMin < STToIJ(UVToST(padded.Y.Lo)) {
+		jMin = STToIJ(UVToST(padded.Y.Lo))
 	}
-	if a, b := p.jLo+ijSize-1, stToIJ(uvToST(padded.Y.Hi)); a <= b {
+	if a, b := p.jLo+ijSize-1, STToIJ(UVToST(padded.Y.Hi)); a <= b {
 		jXor = jMin ^ a
 	} else {
 		jXor = jMin ^ b
@@ -248,5 +248,5 @@ func (p *PaddedCell) ShrinkToFit(rect r2.Rect) CellID {
 		return p.id
 	}
 
-	return cellIDFromFaceIJ(p.id.Face(), iMin, jMin).Parent(level)
+	return CellIDFromFaceIJ(p.id.Face(), iMin, jMin).Parent(level)
 }
@@ -45,7 +45,7 @@ func TestPaddedCellMethods(t *testing.T) {
 			t.Errorf("%v.BoundUV() = %v, want %v", pCell, got, want)
 		}
 
-		r := r2.RectFromPoints(cell.id.centerUV()).ExpandedByMargin(padding)
+		r := r2.RectFromPoints(cell.id.CenterUV()).ExpandedByMargin(padding)
 		if r != pCell.Middle() {
 			t.Errorf("%v.Middle() = %v, want %v", pCell, pCell.Middle(), r)
 		}
@@ -82,7 +82,7 @@ func TestPaddedCellMethods(t *testing.T) {
 				t.Errorf("%v.BoundUV() = %v, want %v", pCellChild, got, want)
 			}
 
-			r := r2.RectFromPoints(cellChild.id.centerUV()).ExpandedByMargin(padding)
+			r := r2.RectFromPoints(cellChild.id.CenterUV()).ExpandedByMargin(padding)
 			if got := pCellChild.Middle(); !r.ApproxEqual(got) {
 				t.Errorf("%v.Middle() = %v, want %v", pCellChild, got, r)
 			}
@@ -135,7 +135,7 @@ func TestPaddedCellShrinkToFit(t *testing.T) {
 	for iter := 0; iter < 1000; iter++ {
 		// Start with the desired result and work backwards.
 		result := randomCellID()
-		resultUV := result.boundUV()
+		resultUV := result.BoundUV()
 		sizeUV := resultUV.Size()
 
 		// Find the biggest rectangle that fits in "result" after padding.
@@ -160,9 +160,9 @@ func TestPaddedCellShrinkToFit(t *testing.T) {
 			// by ensuring that rect intersects at least two children of result
 			// (after padding).
 			useY := oneIn(2)
-			center := result.centerUV().X
+			center := result.CenterUV().X
 			if useY {
-				center = result.centerUV().Y
+				center = result.CenterUV().Y
 			}
 
 			// Find the range of coordinates that are shared between child cells
@@ -34,12 +34,12 @@ func TestOriginPoint(t *testing.T) {
 	}
 
 	// The point chosen below is about 66km from the north pole towards the East
-	// Siberian Sea. The purpose of the stToUV(2/3) calculation is to keep the
+	// Siberian Sea. The purpose of the STToUV(2/3) calculation is to keep the
 	// origin as far away as possible from the longitudinal edges of large
 	// Cells. (The line of longitude through the chosen point is always 1/3
 	// or 2/3 of the way across any Cell with longitudinal edges that it
 	// passes through.)
-	p := Point{r3.Vector{X: -0.01, Y: 0.01 * stToUV(2.0/3), Z: 1}}
+	p := Point{r3.Vector{X: -0.01, Y: 0.01 * STToUV(2.0/3), Z: 1}}
 	if !p.ApproxEqual(OriginPoint()) {
 		t.Errorf("Origin point should fall in the Siberian Sea, but does not.")
 	}
@@ -303,11 +303,11 @@ func stToPiQi(s float64, level uint) uint32 {
 // siTitoPiQi returns the value transformed into the PiQi coordinate spade.
 // encodeFirstPointFixedLength encodes the return value using level bits,
 // so we clamp si to the range [0, 2**level - 1] before trying to encode
-// it. This is okay because if si == maxSiTi, then it is not a cell center
+// it. This is okay because if si == MaxSiTi, then it is not a cell center
 // anyway and will be encoded separately as an off-center point.
 func siTitoPiQi(siTi uint32, level int) uint32 {
 	s := uint(siTi)
-	const max = maxSiTi - 1
+	const max = MaxSiTi - 1
 	if s > max {
 		s = max
 	}
@@ -325,5 +325,5 @@ func piQiToST(pi uint32, level int) float64 {
 }
 
 func facePiQitoXYZ(face int, pi, qi uint32, level int) r3.Vector {
-	return faceUVToXYZ(face, stToUV(piQiToST(pi, level)), stToUV(piQiToST(qi, level))).Normalize()
+	return FaceUVToXYZ(face, STToUV(piQiToST(pi, level)), STToUV(piQiToST(qi, level))).Normalize()
 }
@@ -18,6 +18,8 @@ import (
 	"fmt"
 	"io"
 	"math"
+
+	"github.com/golang/geo/s1"
 )
 
 // Polygon represents a sequence of zero or more loops; recall that the
@@ -590,7 +592,7 @@ func (p *Polygon) RectBound() Rect { return p.bound }
 func (p *Polygon) ContainsPoint(point Point) bool {
 	// NOTE: A bounds check slows down this function by about 50%. It is
 	// worthwhile only when it might allow us to delay building the index.
-	if !p.index.IsFresh() && !p.bound.ContainsPoint(point) {
+	if (p.index == nil || !p.index.IsFresh()) && !p.bound.ContainsPoint(point) {
 		return false
 	}
 
@@ -1205,12 +1207,56 @@ func (p *Polygon) decodeCompressed(d *decoder) {
 	p.initLoopProperties()
 }
 
+// DistanceToPoint returns the distance from the given point to the polygon
+// interior. If the polygon contains the point, the distance is zero. If the
+// polygon is empty, the distance is infinite.
+func (p *Polygon) DistanceToPoint(x Point) s1.Angle {
+	// Polygon.ContainsPoint is slightly more efficient than the generic
+	// version used by EdgeQuery.
+	if p.ContainsPoint(x) {
+		return 0
+	}
+	return p.DistanceToBoundary(x)
+}
+
+// DistanceToBoundary returns the distance from the given point to the
+// polygon boundary. If the polygon is empty or full, the distance is
+// infinite (since such polygons have no boundary).
+func (p *Polygon) DistanceToBoundary(x Point) s1.Angle {
+	if p.IsEmpty() || p.IsFull() {
+		return s1.InfAngle()
+	}
+	query := NewClosestEdgeQuery(p.index, NewClosestEdgeQueryOptions().IncludeInteriors(false))
+	return query.Distance(NewMinDistanceToPointTarget(x)).Angle()
+}
+
+// Project returns the closest point in the polygon to the given point. If
+// the polygon contains the point, the point itself is returned. If the
+// polygon is empty, the point itself is also returned.
+func (p *Polygon) Project(x Point) Point {
+	if p.ContainsPoint(x) {
+		return x
+	}
+	return p.ProjectToBoundary(x)
+}
+
+// ProjectToBoundary returns the closest point on the polygon boundary to the
+// given point. If the polygon is empty or full (and so has no boundary), the
+// point itself is returned.
+func (p *Polygon) ProjectToBoundary(x Point) Point {
+	if p.IsEmpty() || p.IsFull() {
+		return x
+	}
+	query := NewClosestEdgeQuery(p.index, NewClosestEdgeQueryOptions().IncludeInteriors(false).MaxResults(1))
+	results := query.FindEdges(NewMinDistanceToPointTarget(x))
+	if len(results) == 0 {
+		return x
+	}
+	return query.Project(x, results[0])
+}
+
 // TODO(roberts): Differences from C++
 // SnapLevel
-// DistanceToPoint
-// DistanceToBoundary
-// Project
-// ProjectToBoundary
 // ApproxContains/ApproxDisjoint for Polygons
 // InitTo{Intersection/ApproxIntersection/Union/ApproxUnion/Diff/ApproxDiff}
 // InitToSimplified
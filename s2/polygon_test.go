@@ -1205,3 +1205,99 @@ func TestPolygonInvert(t *testing.T) {
 //   TestNarrowGapRemoved
 //   TestCloselySpacedEdgeVerticesKept
 //   TestPolylineAssemblyBug
+
+func TestPolygonDistanceMethods(t *testing.T) {
+	// A square shell with a square hole, both centered on the LatLng point
+	// (0,0). Points are chosen along the equator, which is the only line of
+	// latitude that is also a geodesic, so projections are easy to compute.
+	p := makePolygon("-3:-3, -3:3, 3:3, 3:-3; -1:-1, -1:1, 1:1, 1:-1", true)
+
+	tests := []struct {
+		desc      string
+		x         Point
+		boundary  Point
+		contained bool
+	}{
+		{
+			desc:     "a point in the hole",
+			x:        parsePoint("0:0.5"),
+			boundary: parsePoint("0:1"),
+		},
+		{
+			desc:      "a point inside the polygon near the hole",
+			x:         parsePoint("0:1.5"),
+			boundary:  parsePoint("0:1"),
+			contained: true,
+		},
+		{
+			desc:      "a point inside the polygon near the shell",
+			x:         parsePoint("0:2.5"),
+			boundary:  parsePoint("0:3"),
+			contained: true,
+		},
+		{
+			desc:     "a point outside the polygon",
+			x:        parsePoint("0:5"),
+			boundary: parsePoint("0:3"),
+		},
+	}
+
+	for _, test := range tests {
+		if got := p.ContainsPoint(test.x); got != test.contained {
+			t.Fatalf("%s: ContainsPoint(%v) = %v, want %v", test.desc, test.x, got, test.contained)
+		}
+
+		want := test.x.Distance(test.boundary)
+		if got := p.DistanceToBoundary(test.x); !float64Near(got.Radians(), want.Radians(), epsilon) {
+			t.Errorf("%s: DistanceToBoundary(%v) = %v, want %v", test.desc, test.x, got, want)
+		}
+		if got := p.ProjectToBoundary(test.x); !got.ApproxEqual(test.boundary) {
+			t.Errorf("%s: ProjectToBoundary(%v) = %v, want %v", test.desc, test.x, got, test.boundary)
+		}
+
+		wantDist, wantProj := want, test.boundary
+		if test.contained {
+			wantDist, wantProj = 0, test.x
+		}
+		if got := p.DistanceToPoint(test.x); !float64Near(got.Radians(), wantDist.Radians(), epsilon) {
+			t.Errorf("%s: DistanceToPoint(%v) = %v, want %v", test.desc, test.x, got, wantDist)
+		}
+		if got := p.Project(test.x); !got.ApproxEqual(wantProj) {
+			t.Errorf("%s: Project(%v) = %v, want %v", test.desc, test.x, got, wantProj)
+		}
+	}
+}
+
+func TestPolygonDistanceMethodsEmptyAndFull(t *testing.T) {
+	x := PointFromCoords(0, 1, 0)
+	empty := PolygonFromLoops([]*Loop{EmptyLoop()})
+	for _, p := range []*Polygon{empty, fullPolygon} {
+		if got := p.DistanceToBoundary(x); got != s1.InfAngle() {
+			t.Errorf("DistanceToBoundary(%v) = %v, want %v", x, got, s1.InfAngle())
+		}
+		if got := p.ProjectToBoundary(x); got != x {
+			t.Errorf("ProjectToBoundary(%v) = %v, want %v", x, got, x)
+		}
+		if got := p.Project(x); got != x {
+			t.Errorf("Project(%v) = %v, want %v", x, got, x)
+		}
+	}
+	if got := empty.DistanceToPoint(x); got != s1.InfAngle() {
+		t.Errorf("empty.DistanceToPoint(%v) = %v, want %v", x, got, s1.InfAngle())
+	}
+	if got := fullPolygon.DistanceToPoint(x); got != 0 {
+		t.Errorf("fullPolygon.DistanceToPoint(%v) = %v, want 0", x, got)
+	}
+}
+
+func TestPolygonContainsPointWithoutIndex(t *testing.T) {
+	// The full polygon is not indexed.
+	if fullPolygon.index != nil {
+		t.Fatalf("fullPolygon.index = %v, want nil", fullPolygon.index)
+	}
+	for _, x := range []Point{PointFromCoords(0, 1, 0), PointFromCoords(0, 0, -1)} {
+		if !fullPolygon.ContainsPoint(x) {
+			t.Errorf("fullPolygon.ContainsPoint(%v) = false, want true", x)
+		}
+	}
+}
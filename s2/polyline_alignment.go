@@ -19,6 +19,8 @@ import (
 	"bytes"
 	"fmt"
 	"math"
+
+	"github.com/golang/geo/r3"
 )
 
 // This library provides code to compute vertex alignments between Polylines.
@@ -77,11 +79,11 @@ import (
 // methods return a single, representative polyline from a non-empty collection
 // of polylines, for various definitions of "representative."
 //
-// GetMedoidPolyline() returns a new polyline (point-for-point-equal to some
-// existing polyline from the collection) that minimizes the summed vertex
-// alignment cost to all other polylines in the collection.
+// MedoidPolyline returns the index of the polyline in the collection that
+// minimizes the summed vertex alignment cost to all other polylines in the
+// collection.
 //
-// GetConsensusPolyline() returns a new polyline (unlikely to be present in the
+// ConsensusPolyline returns a new polyline (unlikely to be present in the
 // input collection) that represents a "weighted consensus" polyline. This
 // polyline is constructed iteratively using the Dynamic Timewarp Barycenter
 // Averaging algorithm of F. Petitjean, A. Ketterlin, and P. Gancarski, which
@@ -193,7 +195,35 @@ func windowFromStrides(strides []columnStride) *window {
 	}
 }
 
-// TODO(rsned): Add windowFromWarpPath
+// windowFromWarpPath creates a window from the given WarpPath. The window
+// consists of the cells visited by the path, so each row's stride covers the
+// columns the path passes through in that row.
+//
+// The WarpPath must be non-empty and in forward order.
+func windowFromWarpPath(path WarpPath) *window {
+	rows := path[len(path)-1].A + 1
+	cols := path[len(path)-1].B + 1
+	strides := make([]columnStride, rows)
+
+	prevRow := 0
+	strideStart := 0
+	strideStop := 0
+	for _, pair := range path {
+		if pair.A > prevRow {
+			strides[prevRow] = columnStride{strideStart, strideStop}
+			strideStart = pair.B
+			prevRow = pair.A
+		}
+		strideStop = pair.B + 1
+	}
+	strides[rows-1] = columnStride{strideStart, strideStop}
+
+	return &window{
+		rows:    rows,
+		cols:    cols,
+		strides: strides,
+	}
+}
 
 // isValid reports if this windows data represents a valid window.
 //
@@ -312,19 +342,30 @@ func halfResolution(p *Polyline) *Polyline {
 	return &p2
 }
 
-// warpPath represents a pairing between vertex
+// WarpPath is a sequence of pairings between vertex
 // a.vertex(i) and vertex b.vertex(j) in the optimal alignment.
-// The warpPath is defined in forward order, such that the result of
-// aligning polylines `a` and `b` is always a warpPath with warpPath[0] = {0,0}
-// and warp_path[n] = {len(a) - 1, len(b)- 1}
+// The WarpPath is defined in forward order, such that the result of
+// aligning polylines `a` and `b` is always a WarpPath with WarpPath[0] = {0,0}
+// and WarpPath[n] = {len(a) - 1, len(b)- 1}
 //
 // Note that this DOES NOT define an alignment from a point sequence to an
 // edge sequence. That functionality may come at a later date.
-type warpPath []warpPair
-type warpPair struct{ a, b int }
+type WarpPath []WarpPair
 
-type vertexAlignment struct {
-	// alignmentCost represents the sum of the squared chordal distances
+// WarpPair is a pair of indices (A, B) of the vertices a.vertex(A) and
+// b.vertex(B) that are linked together in an alignment.
+type WarpPair struct{ A, B int }
+
+// reverse reverses the order of the pairs in this path in place.
+func (w WarpPath) reverse() {
+	for i, j := 0, len(w)-1; i < j; i, j = i+1, j-1 {
+		w[i], w[j] = w[j], w[i]
+	}
+}
+
+// VertexAlignment is the result of aligning two polylines.
+type VertexAlignment struct {
+	// AlignmentCost represents the sum of the squared chordal distances
 	// between each pair of vertices in the warp path. Specifically,
 	// cost = sum_{(i, j) \in path} (a.vertex(i) - b.vertex(j)).Norm();
 	// This means that the units of alignment_cost are distance. This is
@@ -333,16 +374,33 @@ type vertexAlignment struct {
 	// vertex alignment is a metric that satisfies the triangle inequality, and
 	// chordal distance works as well as spherical s1.Angle distance for
 	// this purpose.
-	alignmentCost float64
-	warpPath      warpPath
+	AlignmentCost float64
+	// WarpPath is the sequence of vertex pairs linked together in the
+	// alignment.
+	WarpPath WarpPath
 }
 
+// costTable holds the dynamic timewarp costs for the cells inside a window.
+// Each row only stores the columns in that row's stride, so the memory used
+// is proportional to the number of cells in the window rather than to the
+// full rows x cols table. Row i holds the costs for the columns
+// [strides[i].start, strides[i].end), i.e. c[row][col-strides[row].start].
 type costTable [][]float64
 
-func newCostTable(rows, cols int) costTable {
-	c := make([][]float64, rows)
-	for i := 0; i < rows; i++ {
-		c[i] = make([]float64, cols)
+// newCostTable returns a costTable sized to hold the cells of the given window.
+func newCostTable(w *window) costTable {
+	size := 0
+	for _, stride := range w.strides {
+		size += stride.end - stride.start
+	}
+
+	// Carve all the rows out of a single allocation.
+	backing := make([]float64, size)
+	c := make([][]float64, w.rows)
+	for i, stride := range w.strides {
+		n := stride.end - stride.start
+		c[i] = backing[:n:n]
+		backing = backing[n:]
 	}
 	return c
 }
@@ -359,13 +417,16 @@ func (c costTable) String() string {
 	return buf.String()
 }
 
+// boundsCheckedTableCost returns the cost at the given cell, whose row has
+// the given stride. Cells outside the table or the stride have infinite cost,
+// except for the virtual cell before the start of the table.
 func (c costTable) boundsCheckedTableCost(row, col int, stride columnStride) float64 {
 	if row < 0 && col < 0 {
 		return 0.0
 	} else if row < 0 || col < 0 || !stride.InRange(col) {
 		return math.MaxFloat64
 	} else {
-		return c[row][col]
+		return c[row][col-stride.start]
 	}
 }
 
@@ -405,7 +466,7 @@ func ExactVertexAlignmentCost(a, b *Polyline) float64 {
 // ExactVertexAlignment takes two non-empty polylines as input, and returns
 // the VertexAlignment corresponding to the optimal alignment between them. This
 // method is quadratic O(A*B) in both space and time complexity.
-func ExactVertexAlignment(a, b *Polyline) *vertexAlignment {
+func ExactVertexAlignment(a, b *Polyline) *VertexAlignment {
 	aN := len(*a)
 	bN := len(*b)
 	strides := make([]columnStride, aN)
@@ -437,12 +498,12 @@ func ExactVertexAlignment(a, b *Polyline) *vertexAlignment {
 // This is the hottest routine in the whole package, please be careful to
 // profile any future changes made here.
 //
-// This method takes time proportional to the number of cells in the window,
-// which can range from O(max(a, b)) cells (best) to O(a*b) cells (worst)
-func dynamicTimewarp(a, b *Polyline, w *window) *vertexAlignment {
+// This method takes time and space proportional to the number of cells in the
+// window, which can range from O(max(a, b)) cells (best) to O(a*b) cells (worst)
+func dynamicTimewarp(a, b *Polyline, w *window) *VertexAlignment {
 	rows := len(*a)
 	cols := len(*b)
-	costs := newCostTable(rows, cols)
+	costs := newCostTable(w)
 
 	var curr columnStride
 	prev := allColumnStride()
@@ -458,7 +519,7 @@ func dynamicTimewarp(a, b *Polyline, w *window) *vertexAlignment {
 			uCost := costs.boundsCheckedTableCost(row-1, col-0, prev)
 			lCost := costs.boundsCheckedTableCost(row-0, col-1, curr)
 
-			costs[row][col] = minFloat64(dCost, uCost, lCost) +
+			costs[row][col-curr.start] = minFloat64(dCost, uCost, lCost) +
 				(*a)[row].Sub((*b)[col].Vector).Norm()
 		}
 		prev = curr
@@ -473,13 +534,13 @@ func dynamicTimewarp(a, b *Polyline, w *window) *vertexAlignment {
 	// this incurs is larger than the cost to simply redo the comparisons.
 	// It's probably worth revisiting this assumption in the future.
 	// As it turns out, the following code ends up effectively free.
-	warpPath := make([]warpPair, 0, maxInt(rows, cols))
+	path := make(WarpPath, 0, maxInt(rows, cols))
 	row := rows - 1
 	col := cols - 1
 	curr = w.checkedColumnStride(row)
 	prev = w.checkedColumnStride(row - 1)
 	for row >= 0 && col >= 0 {
-		warpPath = append(warpPath, warpPair{row, col})
+		path = append(path, WarpPair{row, col})
 		dCost := costs.boundsCheckedTableCost(row-1, col-1, prev)
 		uCost := costs.boundsCheckedTableCost(row-1, col-0, prev)
 		lCost := costs.boundsCheckedTableCost(row-0, col-1, curr)
@@ -498,11 +559,211 @@ func dynamicTimewarp(a, b *Polyline, w *window) *vertexAlignment {
 		}
 	}
 
-	// TODO(rsned): warpPath.reverse
-	return &vertexAlignment{alignmentCost: costs.cost(), warpPath: warpPath}
+	path.reverse()
+	return &VertexAlignment{AlignmentCost: costs.cost(), WarpPath: path}
+}
+
+// ApproxVertexAlignment takes two non-empty polylines as input, and returns
+// the VertexAlignment corresponding to the approximately optimal alignment
+// between them.
+//
+// This method uses the FastDTW approach: the alignment is first computed
+// recursively at half resolution, and the resulting warp path is then
+// projected up to the full resolution and dilated by radius in each direction
+// to form a search window for a windowed dynamic timewarp. Larger values of
+// radius give a more accurate result at the expense of speed. When either
+// polyline is short enough that the approximation would not save anything,
+// the exact alignment is computed instead.
+//
+// This method takes O(max(A,B)*radius) time and space, so unlike the exact
+// alignment it can be used on polylines with many thousands of vertices.
+func ApproxVertexAlignment(a, b *Polyline, radius int) *VertexAlignment {
+	// These bounds were determined experimentally in the C++ library, through
+	// benchmarking, as about the points at which ExactAlignment is faster than
+	// ApproximateAlignment, so we use these as our switchover points to exact
+	// computation mode.
+	aN := len(*a)
+	bN := len(*b)
+	if aN < radius+32 || bN < radius+32 {
+		return ExactVertexAlignment(a, b)
+	}
+
+	// Recursive call to compute the alignment at half resolution.
+	proj := ApproxVertexAlignment(halfResolution(a), halfResolution(b), radius)
+
+	// Project the warp path from the half resolution alignment to the full
+	// resolution, and then dilate it to search nearby.
+	w := windowFromWarpPath(proj.WarpPath).upsample(aN, bN).dilate(radius)
+	return dynamicTimewarp(a, b, w)
+}
+
+// approxRadius returns the default search radius used by the approximate
+// alignment methods for polylines of the given lengths.
+func approxRadius(a, b *Polyline) int {
+	return int(math.Pow(float64(maxInt(len(*a), len(*b))), 0.25))
+}
+
+// ApproxVertexAlignmentCost takes two non-empty polylines as input and
+// returns the cost of their approximately optimal alignment, computed with
+// the given search radius as in ApproxVertexAlignment.
+func ApproxVertexAlignmentCost(a, b *Polyline, radius int) float64 {
+	return ApproxVertexAlignment(a, b, radius).AlignmentCost
+}
+
+// alignmentCost returns the exact or approximate alignment cost of the two
+// polylines.
+func alignmentCost(a, b *Polyline, approx bool) float64 {
+	if approx {
+		return ApproxVertexAlignmentCost(a, b, approxRadius(a, b))
+	}
+	return ExactVertexAlignmentCost(a, b)
+}
+
+// alignment returns the exact or approximate alignment of the two polylines.
+func alignment(a, b *Polyline, approx bool) *VertexAlignment {
+	if approx {
+		return ApproxVertexAlignment(a, b, approxRadius(a, b))
+	}
+	return ExactVertexAlignment(a, b)
+}
+
+// MedoidOptions holds the options used by MedoidPolyline.
+type MedoidOptions struct {
+	approx bool
+}
+
+// NewMedoidOptions returns the default MedoidOptions, which uses approximate
+// alignments.
+func NewMedoidOptions() *MedoidOptions {
+	return &MedoidOptions{approx: true}
+}
+
+// Approx sets whether approximate alignments are used in place of exact
+// alignments when computing the costs between polylines.
+func (m *MedoidOptions) Approx(x bool) *MedoidOptions {
+	m.approx = x
+	return m
+}
+
+// MedoidPolyline returns the index p of a "medoid" polyline from a non-empty
+// collection of polylines. The medoid is the polyline that minimizes the sum
+// of the vertex alignment costs from it to all of the other polylines in the
+// collection.
+//
+// If opts is nil, the default options are used.
+//
+// This method panics if polylines is empty.
+func MedoidPolyline(polylines []*Polyline, opts *MedoidOptions) int {
+	if len(polylines) == 0 {
+		panic("MedoidPolyline called with no polylines")
+	}
+	if opts == nil {
+		opts = NewMedoidOptions()
+	}
+
+	// costs[i] is the total cost of aligning polyline i with all others.
+	costs := make([]float64, len(polylines))
+	for i := range polylines {
+		for j := i + 1; j < len(polylines); j++ {
+			cost := alignmentCost(polylines[i], polylines[j], opts.approx)
+			costs[i] += cost
+			costs[j] += cost
+		}
+	}
+
+	medoid := 0
+	for i, cost := range costs {
+		if cost < costs[medoid] {
+			medoid = i
+		}
+	}
+	return medoid
+}
+
+// ConsensusOptions holds the options used by ConsensusPolyline.
+type ConsensusOptions struct {
+	approx       bool
+	seedMedoid   bool
+	iterationCap int
+}
+
+// NewConsensusOptions returns the default ConsensusOptions, which uses
+// approximate alignments, seeds the consensus with the first polyline, and
+// performs at most 5 iterations.
+func NewConsensusOptions() *ConsensusOptions {
+	return &ConsensusOptions{
+		approx:       true,
+		seedMedoid:   false,
+		iterationCap: 5,
+	}
+}
+
+// Approx sets whether approximate alignments are used in place of exact
+// alignments when aligning the polylines to the consensus.
+func (c *ConsensusOptions) Approx(x bool) *ConsensusOptions {
+	c.approx = x
+	return c
+}
+
+// SeedMedoid sets whether the consensus is seeded with the medoid of the
+// polylines rather than the first polyline. Computing the medoid requires
+// aligning every pair of polylines, so this is quadratic in the number of
+// polylines, but it may reduce the number of iterations needed.
+func (c *ConsensusOptions) SeedMedoid(x bool) *ConsensusOptions {
+	c.seedMedoid = x
+	return c
+}
+
+// IterationCap sets the maximum number of iterations of the barycenter
+// averaging that are performed. Iteration stops early if the consensus
+// converges.
+func (c *ConsensusOptions) IterationCap(n int) *ConsensusOptions {
+	c.iterationCap = n
+	return c
 }
 
-// TODO(rsned): Differences from C++
-// ApproxVertexAlignment/Cost
-// MedoidPolyline / Options
-// ConsensusPolyline / Options
+// ConsensusPolyline returns a new polyline representing the "consensus" of a
+// non-empty collection of polylines, computed using Dynamic Timewarp
+// Barycenter Averaging. The consensus starts out as a copy of a seed polyline
+// and is refined iteratively: each vertex of the consensus is replaced by the
+// normalized sum of all the vertices aligned with it in the other polylines,
+// until the result stops changing or the iteration cap is reached. The result
+// has the same number of vertices as the seed polyline.
+//
+// If opts is nil, the default options are used.
+//
+// This method panics if polylines is empty.
+func ConsensusPolyline(polylines []*Polyline, opts *ConsensusOptions) *Polyline {
+	if len(polylines) == 0 {
+		panic("ConsensusPolyline called with no polylines")
+	}
+	if opts == nil {
+		opts = NewConsensusOptions()
+	}
+
+	// Seed a consensus polyline, either by medoid or by picking the first.
+	seed := 0
+	if opts.seedMedoid {
+		seed = MedoidPolyline(polylines, NewMedoidOptions().Approx(opts.approx))
+	}
+	consensus := make(Polyline, len(*polylines[seed]))
+	copy(consensus, *polylines[seed])
+
+	converged := false
+	for iterations := 0; !converged && iterations < opts.iterationCap; iterations++ {
+		points := make([]r3.Vector, len(consensus))
+		for _, polyline := range polylines {
+			for _, pair := range alignment(&consensus, polyline, opts.approx).WarpPath {
+				points[pair.A] = points[pair.A].Add((*polyline)[pair.B].Vector)
+			}
+		}
+
+		next := make(Polyline, len(points))
+		for i, p := range points {
+			next[i] = Point{p.Normalize()}
+		}
+		converged = next.ApproxEqual(&consensus)
+		consensus = next
+	}
+	return &consensus
+}
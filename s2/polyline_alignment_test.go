@@ -16,6 +16,8 @@
 package s2
 
 import (
+	"math"
+	"math/rand"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -356,7 +358,7 @@ func TestPolylineAlignmentExactAlignmentCost(t *testing.T) {
 	tests := []struct {
 		label    string
 		a, b     string
-		wantPath warpPath
+		wantPath WarpPath
 	}{
 		// Test cases that should cause panic and crash
 		/*
@@ -364,44 +366,44 @@ func TestPolylineAlignmentExactAlignmentCost(t *testing.T) {
 				label:    "ExactLengthZeroInputs",
 				a:        "",
 				b:        "",
-				wantPath: warpPath{},
+				wantPath: WarpPath{},
 			},
 			{
 				label:    "ExactLengthZeroInputA",
 				a:        "",
 				b:        "0:0, 1:1, 2:2",
-				wantPath: warpPath{},
+				wantPath: WarpPath{},
 			},
 			{
 				label:    "ExactLengthZeroInputB",
 				a:        "0:0, 1:1, 2:2",
 				b:        "",
-				wantPath: warpPath{},
+				wantPath: WarpPath{},
 			},
 		*/
 		{
 			label:    "ExactLengthOneInputs",
 			a:        "1:1",
 			b:        "2:2",
-			wantPath: warpPath{{0, 0}},
+			wantPath: WarpPath{{0, 0}},
 		},
 		{
 			label:    "ExactLengthOneInputA",
 			a:        "0:0",
 			b:        "0:0, 1:1, 2:2",
-			wantPath: warpPath{{0, 0}, {0, 1}, {0, 2}},
+			wantPath: WarpPath{{0, 0}, {0, 1}, {0, 2}},
 		},
 		{
 			label:    "ExactLengthOneInputB",
 			a:        "0:0, 1:1, 2:2",
 			b:        "0:0",
-			wantPath: warpPath{{0, 0}, {1, 0}, {2, 0}},
+			wantPath: WarpPath{{0, 0}, {1, 0}, {2, 0}},
 		},
 		{
 			label:    "ExactHeaderFileExample",
 			a:        "1:0, 5:0, 6:0, 9:0",
 			b:        "2:0, 7:0, 8:0",
-			wantPath: warpPath{{0, 0}, {1, 1}, {2, 1}, {3, 2}},
+			wantPath: WarpPath{{0, 0}, {1, 1}, {2, 1}, {3, 2}},
 		},
 		{
 			// Tests that we get the correct path in the case where we have polylines at
@@ -418,7 +420,7 @@ func TestPolylineAlignmentExactAlignmentCost(t *testing.T) {
 			label:    "DifferentPathForDistanceVersusSquaredDistance",
 			a:        "0.1:-0.1, 0.1:0, 0.1:0.1, -0.1:0.1",
 			b:        "0.1:-0.1, -0.1:-0.1, -0.1:0.1",
-			wantPath: warpPath{{0, 0}, {1, 0}, {2, 1}, {3, 2}},
+			wantPath: WarpPath{{0, 0}, {1, 0}, {2, 1}, {3, 2}},
 		},
 	}
 
@@ -437,15 +439,317 @@ func TestPolylineAlignmentExactAlignmentCost(t *testing.T) {
 		}
 
 		exactAlignment := ExactVertexAlignment(a, b)
-		if !float64Eq(bruteCost, exactAlignment.alignmentCost) {
+		if !float64Eq(bruteCost, exactAlignment.AlignmentCost) {
 			t.Errorf("%s: ExactVertexAlignment(%v, %v) = %f, want %f",
-				test.label, a, b, exactAlignment.alignmentCost, bruteCost)
+				test.label, a, b, exactAlignment.AlignmentCost, bruteCost)
+		}
+		if diff := cmp.Diff(exactAlignment.WarpPath, test.wantPath); diff != "" {
+			t.Errorf("%s: ExactVertexAlignment(%v, %v).WarpPath = %v, want %v\ndiff: %s",
+				test.label, a, b, exactAlignment.WarpPath, test.wantPath, diff)
+		}
+	}
+}
+
+func TestPolylineAlignmentFuzzWithBruteForce(t *testing.T) {
+	// Compare the exact alignment against the brute force solver for many
+	// small random polylines. The brute force solver is exponential in the
+	// number of vertices, so the polylines are kept short.
+	r := rand.New(rand.NewSource(1))
+	for iter := 0; iter < 200; iter++ {
+		a := make(Polyline, 1+r.Intn(6))
+		b := make(Polyline, 1+r.Intn(6))
+		center := randomPoint(r)
+		for i := range a {
+			a[i] = samplePointFromCap(CapFromCenterAngle(center, 0.1), r)
+		}
+		for i := range b {
+			b[i] = samplePointFromCap(CapFromCenterAngle(center, 0.1), r)
+		}
+
+		bruteCost := bruteForceCost(distanceMatrix(&a, &b), len(a)-1, len(b)-1)
+		if got := ExactVertexAlignmentCost(&a, &b); !float64Near(got, bruteCost, 1e-14) {
+			t.Errorf("ExactVertexAlignmentCost(%v, %v) = %v, want %v", a, b, got, bruteCost)
+		}
+
+		// The cost of the warp path must also match.
+		alignment := ExactVertexAlignment(&a, &b)
+		var pathCost float64
+		for _, pair := range alignment.WarpPath {
+			pathCost += a[pair.A].Sub(b[pair.B].Vector).Norm()
+		}
+		if !float64Near(pathCost, bruteCost, 1e-14) {
+			t.Errorf("ExactVertexAlignment(%v, %v).WarpPath cost = %v, want %v", a, b, pathCost, bruteCost)
+		}
+	}
+}
+
+func TestPolylineAlignmentWindowFromWarpPath(t *testing.T) {
+	//    0 1 2 3 4 5
+	//  0 * . . . . .
+	//  1 * * . . . .
+	//  2 . * . . . .
+	//  3 . * * . . .
+	//  4 . . . * * *
+	path := WarpPath{{0, 0}, {1, 0}, {1, 1}, {2, 1}, {3, 1}, {3, 2}, {4, 3}, {4, 4}, {4, 5}}
+	w := windowFromWarpPath(path)
+	want := windowFromStrides([]columnStride{
+		{0, 1},
+		{0, 2},
+		{1, 2},
+		{1, 3},
+		{3, 6},
+	})
+	if !w.isValid() {
+		t.Errorf("windowFromWarpPath(%v) is not valid:\n%s", path, w.debugString())
+	}
+	if got, want := w.debugString(), want.debugString(); got != want {
+		t.Errorf("windowFromWarpPath(%v) = \n%s\nwant\n%s", path, got, want)
+	}
+}
+
+// makeSinusoidPolyline returns a polyline with n vertices tracing a sine wave
+// along the equator with the given amplitude and phase, in degrees.
+func makeSinusoidPolyline(n int, amplitude, phase float64) *Polyline {
+	p := make(Polyline, n)
+	for i := 0; i < n; i++ {
+		lng := 10 * float64(i) / float64(n)
+		lat := amplitude * math.Sin(lng+phase)
+		p[i] = PointFromLatLng(LatLngFromDegrees(lat, lng))
+	}
+	return &p
+}
+
+func TestPolylineAlignmentApproxAlignment(t *testing.T) {
+	tests := []struct {
+		aN, bN int
+		radius int
+	}{
+		// Short enough that the exact alignment is used.
+		{10, 12, 0},
+		{40, 100, 4},
+		// Long enough to be approximated.
+		{100, 120, 1},
+		{200, 150, 1},
+		{256, 512, 2},
+		{500, 500, 4},
+	}
+
+	for _, test := range tests {
+		a := makeSinusoidPolyline(test.aN, 1, 0)
+		b := makeSinusoidPolyline(test.bN, 1.1, 0.2)
+
+		exact := ExactVertexAlignment(a, b)
+		approx := ApproxVertexAlignment(a, b, test.radius)
+
+		// The approximate cost can never be better than the optimal cost, and
+		// for well behaved inputs should be very close to it.
+		if approx.AlignmentCost < exact.AlignmentCost-1e-14 {
+			t.Errorf("ApproxVertexAlignment(%d, %d, %d) cost = %v, less than exact cost %v",
+				test.aN, test.bN, test.radius, approx.AlignmentCost, exact.AlignmentCost)
+		}
+		if approx.AlignmentCost > 1.1*exact.AlignmentCost {
+			t.Errorf("ApproxVertexAlignment(%d, %d, %d) cost = %v, want close to exact cost %v",
+				test.aN, test.bN, test.radius, approx.AlignmentCost, exact.AlignmentCost)
+		}
+
+		// The warp path must start and end at the corners and be monotone.
+		path := approx.WarpPath
+		if got, want := path[0], (WarpPair{0, 0}); got != want {
+			t.Errorf("ApproxVertexAlignment(%d, %d, %d).WarpPath[0] = %v, want %v",
+				test.aN, test.bN, test.radius, got, want)
+		}
+		if got, want := path[len(path)-1], (WarpPair{test.aN - 1, test.bN - 1}); got != want {
+			t.Errorf("ApproxVertexAlignment(%d, %d, %d).WarpPath[last] = %v, want %v",
+				test.aN, test.bN, test.radius, got, want)
+		}
+		for i := 1; i < len(path); i++ {
+			da, db := path[i].A-path[i-1].A, path[i].B-path[i-1].B
+			if da < 0 || da > 1 || db < 0 || db > 1 || da+db == 0 {
+				t.Errorf("ApproxVertexAlignment(%d, %d, %d).WarpPath has invalid step %v -> %v",
+					test.aN, test.bN, test.radius, path[i-1], path[i])
+				break
+			}
+		}
+
+		if got, want := ApproxVertexAlignmentCost(a, b, test.radius), approx.AlignmentCost; got != want {
+			t.Errorf("ApproxVertexAlignmentCost(%d, %d, %d) = %v, want %v", test.aN, test.bN, test.radius, got, want)
+		}
+	}
+}
+
+func TestPolylineAlignmentCostTableWindowed(t *testing.T) {
+	w := windowFromStrides([]columnStride{
+		{0, 3},
+		{1, 4},
+		{2, 4},
+		{3, 6},
+		{4, 6},
+	})
+	c := newCostTable(w)
+	if got, want := len(c), w.rows; got != want {
+		t.Errorf("len(newCostTable(w)) = %d, want %d", got, want)
+	}
+	for row, stride := range w.strides {
+		if got, want := len(c[row]), stride.end-stride.start; got != want {
+			t.Errorf("len(newCostTable(w)[%d]) = %d, want %d", row, got, want)
+		}
+	}
+
+	// Writing each row should not spill over into the next.
+	for row := range c {
+		for col := range c[row] {
+			c[row][col] = float64(row)
+		}
+	}
+	for row, stride := range w.strides {
+		for col := stride.start; col < stride.end; col++ {
+			if got := c.boundsCheckedTableCost(row, col, stride); got != float64(row) {
+				t.Errorf("boundsCheckedTableCost(%d, %d) = %v, want %v", row, col, got, row)
+			}
 		}
 	}
+}
+
+func TestPolylineAlignmentApproxAlignmentLongPolylines(t *testing.T) {
+	// The exact alignment of polylines this long would need several
+	// gigabytes for its cost table.
+	const aN, bN = 30000, 40000
+	a := makeSinusoidPolyline(aN, 1, 0)
+	b := makeSinusoidPolyline(bN, 1, 0.01)
+
+	alignment := ApproxVertexAlignment(a, b, approxRadius(a, b))
+	path := alignment.WarpPath
+	if got, want := path[0], (WarpPair{0, 0}); got != want {
+		t.Errorf("ApproxVertexAlignment(%d, %d).WarpPath[0] = %v, want %v", aN, bN, got, want)
+	}
+	if got, want := path[len(path)-1], (WarpPair{aN - 1, bN - 1}); got != want {
+		t.Errorf("ApproxVertexAlignment(%d, %d).WarpPath[last] = %v, want %v", aN, bN, got, want)
+	}
+
+	// The cost of the path must match the sum of the distances of its pairs.
+	var cost float64
+	for _, pair := range path {
+		cost += (*a)[pair.A].Sub((*b)[pair.B].Vector).Norm()
+	}
+	if !float64Near(cost, alignment.AlignmentCost, 1e-9) {
+		t.Errorf("ApproxVertexAlignment(%d, %d).AlignmentCost = %v, want %v", aN, bN, alignment.AlignmentCost, cost)
+	}
+}
 
-	// TODO(rsned): Add FuzzWithBruteForce to this.
+func makePolylines(inputs []string) []*Polyline {
+	var polylines []*Polyline
+	for _, s := range inputs {
+		polylines = append(polylines, makePolyline(s))
+	}
+	return polylines
 }
 
-// TODO()rsned): Differences from C++
-// Medoid tests
-// Consensus tests
+func TestPolylineAlignmentMedoidPolyline(t *testing.T) {
+	tests := []struct {
+		label  string
+		inputs []string
+		want   int
+	}{
+		{
+			label:  "SinglePolyline",
+			inputs: []string{"5:0, 5:1, 5:2"},
+			want:   0,
+		},
+		{
+			label:  "HeaderExample",
+			inputs: []string{"5:0, 5:1, 5:2", "3:0, 3:1, 3:2", "1:0, 1:1, 1:2"},
+			want:   1,
+		},
+		{
+			label:  "DifferentLengths",
+			inputs: []string{"5:0, 5:1, 5:2, 5:3", "3:0, 3:1, 3:2", "1:0, 1:1"},
+			want:   1,
+		},
+		{
+			label:  "LastIsMedoid",
+			inputs: []string{"0:0, 0:1", "9:0, 9:1", "5:0, 5:1"},
+			want:   2,
+		},
+	}
+
+	for _, test := range tests {
+		polylines := makePolylines(test.inputs)
+		for _, approx := range []bool{true, false} {
+			if got := MedoidPolyline(polylines, NewMedoidOptions().Approx(approx)); got != test.want {
+				t.Errorf("%s: MedoidPolyline(%v, approx=%v) = %d, want %d",
+					test.label, test.inputs, approx, got, test.want)
+			}
+		}
+	}
+	if got := MedoidPolyline(makePolylines(tests[1].inputs), nil); got != tests[1].want {
+		t.Errorf("MedoidPolyline(%v, nil) = %d, want %d", tests[1].inputs, got, tests[1].want)
+	}
+}
+
+func TestPolylineAlignmentConsensusPolyline(t *testing.T) {
+	tests := []struct {
+		label  string
+		inputs []string
+		want   string
+	}{
+		{
+			label:  "SinglePolyline",
+			inputs: []string{"3:0, 5:1, 5:2"},
+			want:   "3:0, 5:1, 5:2",
+		},
+		{
+			label:  "HeaderExample",
+			inputs: []string{"5:0, 5:1, 5:2", "3:0, 3:1, 3:2", "1:0, 1:1, 1:2"},
+			want:   "3:0, 3:1, 3:2",
+		},
+		{
+			label:  "FourChevrons",
+			inputs: []string{"3:0, 0:1, 3:2", "2:0, 0:1, 2:2", "1:0, 0:1, 1:2", "0:0, 0:1, 0:2"},
+			want:   "1.5:0, 0:1, 1.5:2",
+		},
+	}
+
+	for _, test := range tests {
+		polylines := makePolylines(test.inputs)
+		want := makePolyline(test.want)
+		for _, approx := range []bool{true, false} {
+			for _, seedMedoid := range []bool{true, false} {
+				opts := NewConsensusOptions().Approx(approx).SeedMedoid(seedMedoid)
+				if got := ConsensusPolyline(polylines, opts); !got.ApproxEqual(want) {
+					t.Errorf("%s: ConsensusPolyline(%v, approx=%v, seedMedoid=%v) = %v, want %v",
+						test.label, test.inputs, approx, seedMedoid, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestPolylineAlignmentConsensusPolylineIterationCap(t *testing.T) {
+	polylines := makePolylines([]string{"5:0, 5:1, 5:2", "3:0, 3:1, 3:2", "1:0, 1:1, 1:2"})
+	seed := *polylines[0]
+
+	// With no iterations, the result is a copy of the seed polyline.
+	got := ConsensusPolyline(polylines, NewConsensusOptions().IterationCap(0))
+	if !got.Equal(&seed) {
+		t.Errorf("ConsensusPolyline with IterationCap(0) = %v, want %v", got, seed)
+	}
+	if got == polylines[0] {
+		t.Errorf("ConsensusPolyline returned the input polyline, want a copy")
+	}
+}
+
+func BenchmarkPolylineAlignmentExact(b *testing.B) {
+	p := makeSinusoidPolyline(2048, 1, 0)
+	q := makeSinusoidPolyline(2048, 1.1, 0.2)
+	for i := 0; i < b.N; i++ {
+		ExactVertexAlignment(p, q)
+	}
+}
+
+func BenchmarkPolylineAlignmentApprox(b *testing.B) {
+	p := makeSinusoidPolyline(2048, 1, 0)
+	q := makeSinusoidPolyline(2048, 1.1, 0.2)
+	for i := 0; i < b.N; i++ {
+		ApproxVertexAlignment(p, q, approxRadius(p, q))
+	}
+}
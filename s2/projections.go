@@ -18,6 +18,7 @@ import (
 	"math"
 
 	"github.com/golang/geo/r2"
+	"github.com/golang/geo/r3"
 	"github.com/golang/geo/s1"
 )
 
@@ -66,9 +67,6 @@ type Projection interface {
 	// Then this function would return [190, 20] for point B (reducing the edge
 	// length in the "x" direction from 340 to 20).
 	WrapDestination(a, b r2.Point) r2.Point
-
-	// We do not support implementations of this interface outside this package.
-	privateInterface()
 }
 
 // PlateCarreeProjection defines the "plate carree" (square plate) projection,
@@ -142,8 +140,6 @@ func (p *PlateCarreeProjection) WrapDestination(a, b r2.Point) r2.Point {
 	return wrapDestination(a, b, p.WrapDistance)
 }
 
-func (p *PlateCarreeProjection) privateInterface() {}
-
 // MercatorProjection defines the spherical Mercator projection. Google Maps
 // uses this projection together with WGS84 coordinates, in which case it is
 // known as the "Web Mercator" projection (see Wikipedia). This class makes
@@ -224,7 +220,287 @@ func (p *MercatorProjection) WrapDestination(a, b r2.Point) r2.Point {
 	return wrapDestination(a, b, p.WrapDistance)
 }
 
-func (p *MercatorProjection) privateInterface() {}
+// WebMercatorProjection defines the Web Mercator projection in the tile and
+// pixel coordinates used by web maps. At zoom level z the map is a square of
+// 2**z by 2**z tiles, and coordinates are measured from its top left corner
+// (longitude -180 degrees, latitude about 85.05 degrees) with "x" increasing
+// eastward and "y" increasing southward. Each tile is tileSize units across,
+// so a tileSize of 1 yields tile coordinates (whose integer parts are the
+// indices of the tile containing the point) and a tileSize of 256 yields pixel
+// coordinates for the usual 256x256 pixel tiles.
+//
+// Like MercatorProjection, "x" wraps around the map while "y" spans an
+// infinite range, although web maps only show the square part of the map.
+type WebMercatorProjection struct {
+	worldSize float64 // The width and height of the map.
+}
+
+// NewWebMercatorProjection constructs a Web Mercator projection for the given
+// zoom level, where each tile is tileSize units across.
+func NewWebMercatorProjection(zoom int, tileSize float64) Projection {
+	return &WebMercatorProjection{worldSize: math.Ldexp(tileSize, zoom)}
+}
+
+// Project converts a point on the sphere to a projected 2D point.
+func (p *WebMercatorProjection) Project(pt Point) r2.Point {
+	return p.FromLatLng(LatLngFromPoint(pt))
+}
+
+// Unproject converts a projected 2D point to a point on the sphere.
+func (p *WebMercatorProjection) Unproject(pt r2.Point) Point {
+	return PointFromLatLng(p.ToLatLng(pt))
+}
+
+// FromLatLng returns the LatLng projected into an R2 Point.
+func (p *WebMercatorProjection) FromLatLng(ll LatLng) r2.Point {
+	sinPhi := math.Sin(float64(ll.Lat))
+	y := 0.5 * math.Log((1+sinPhi)/(1-sinPhi))
+	return r2.Point{
+		X: p.worldSize * (0.5 + float64(ll.Lng)/(2*math.Pi)),
+		Y: p.worldSize * (0.5 - y/(2*math.Pi)),
+	}
+}
+
+// ToLatLng returns the LatLng projected from the given R2 Point.
+func (p *WebMercatorProjection) ToLatLng(pt r2.Point) LatLng {
+	x := 2 * math.Pi * math.Remainder(pt.X/p.worldSize-0.5, 1)
+	k := math.Exp(4 * math.Pi * (0.5 - pt.Y/p.worldSize))
+	var y float64
+	if math.IsInf(k, 0) {
+		y = math.Pi / 2
+	} else {
+		y = math.Asin((k - 1) / (k + 1))
+	}
+	return LatLng{s1.Angle(y), s1.Angle(x)}
+}
+
+// Interpolate returns the point obtained by interpolating the given
+// fraction of the distance along the line from A to B.
+func (p *WebMercatorProjection) Interpolate(f float64, a, b r2.Point) r2.Point {
+	return a.Mul(1 - f).Add(b.Mul(f))
+}
+
+// WrapDistance reports the coordinate wrapping distance along each axis.
+func (p *WebMercatorProjection) WrapDistance() r2.Point {
+	return r2.Point{X: p.worldSize, Y: 0}
+}
+
+// WrapDestination wraps the points if needed to get the shortest edge.
+func (p *WebMercatorProjection) WrapDestination(a, b r2.Point) r2.Point {
+	return wrapDestination(a, b, p.WrapDistance)
+}
+
+// GnomonicProjection defines the gnomonic projection onto the plane tangent
+// to the sphere at the center of a cube face, which maps points to the (u,v)
+// coordinates of that face. It maps geodesics to straight lines, so edges
+// never need to be tessellated, and it is what the cube faces of CellIDs
+// are based on.
+//
+// Only points in the open hemisphere centered on the face can be projected.
+// Other points are projected to infinite coordinates.
+type GnomonicProjection struct {
+	face int
+}
+
+// NewGnomonicProjection constructs a gnomonic projection onto the given cube
+// face, which must be in the range [0, 5].
+func NewGnomonicProjection(face int) Projection {
+	return &GnomonicProjection{face: face}
+}
+
+// Project converts a point on the sphere to a projected 2D point.
+func (p *GnomonicProjection) Project(pt Point) r2.Point {
+	u, v, ok := FaceXYZToUV(p.face, pt)
+	if !ok {
+		return r2.Point{X: math.Inf(1), Y: math.Inf(1)}
+	}
+	return r2.Point{X: u, Y: v}
+}
+
+// Unproject converts a projected 2D point to a point on the sphere.
+func (p *GnomonicProjection) Unproject(pt r2.Point) Point {
+	return Point{FaceUVToXYZ(p.face, pt.X, pt.Y).Normalize()}
+}
+
+// FromLatLng returns the LatLng projected into an R2 Point.
+func (p *GnomonicProjection) FromLatLng(ll LatLng) r2.Point {
+	return p.Project(PointFromLatLng(ll))
+}
+
+// ToLatLng returns the LatLng projected from the given R2 Point.
+func (p *GnomonicProjection) ToLatLng(pt r2.Point) LatLng {
+	return LatLngFromPoint(p.Unproject(pt))
+}
+
+// Interpolate returns the point obtained by interpolating the given
+// fraction of the distance along the line from A to B.
+func (p *GnomonicProjection) Interpolate(f float64, a, b r2.Point) r2.Point {
+	return a.Mul(1 - f).Add(b.Mul(f))
+}
+
+// WrapDistance reports the coordinate wrapping distance along each axis.
+func (p *GnomonicProjection) WrapDistance() r2.Point {
+	return r2.Point{}
+}
+
+// WrapDestination wraps the points if needed to get the shortest edge.
+func (p *GnomonicProjection) WrapDestination(a, b r2.Point) r2.Point {
+	return wrapDestination(a, b, p.WrapDistance)
+}
+
+// azimuthalFrame returns the frame used by the azimuthal projections centered
+// at the given point: its "z" axis is the center, its "y" axis points north
+// along the meridian through the center and its "x" axis points east.
+//
+// If the center is a pole, the "y" axis points along the meridian at
+// longitude 180 degrees for the north pole and 0 degrees for the south pole,
+// as is usual for polar maps.
+func azimuthalFrame(center Point) matrix3x3 {
+	east := r3.Vector{X: 0, Y: 0, Z: 1}.Cross(center.Vector)
+	if east.Norm2() == 0 {
+		east = r3.Vector{X: 0, Y: 1, Z: 0}
+	}
+	east = east.Normalize()
+	m := matrix3x3{}
+	m.setCol(0, Point{east})
+	m.setCol(1, Point{center.Cross(east)})
+	m.setCol(2, center)
+	return m
+}
+
+// StereographicProjection defines the stereographic projection centered at a
+// given point, e.g. the polar stereographic projection when the center is a
+// pole. It is conformal, i.e. it preserves angles and shapes locally. Circles
+// on the sphere, including geodesics, are mapped to circles or lines.
+//
+// Coordinates are expressed in units of the sphere's radius, so that lengths
+// near the center are preserved, with "y" pointing north (see
+// NewStereographicProjection). The point opposite the center is projected to
+// infinite coordinates.
+type StereographicProjection struct {
+	frame matrix3x3
+}
+
+// NewStereographicProjection constructs a stereographic projection centered
+// at the given point. The "y" axis points north along the meridian through
+// the center, or if the center is the north (south) pole, along the meridian
+// at longitude 180 (0) degrees.
+func NewStereographicProjection(center Point) Projection {
+	return &StereographicProjection{frame: azimuthalFrame(center)}
+}
+
+// Project converts a point on the sphere to a projected 2D point.
+func (p *StereographicProjection) Project(pt Point) r2.Point {
+	q := toFrame(p.frame, pt)
+	if q.Z <= -1 {
+		return r2.Point{X: math.Inf(1), Y: math.Inf(1)}
+	}
+	k := 2 / (1 + q.Z)
+	return r2.Point{X: k * q.X, Y: k * q.Y}
+}
+
+// Unproject converts a projected 2D point to a point on the sphere.
+func (p *StereographicProjection) Unproject(pt r2.Point) Point {
+	d2 := pt.X*pt.X + pt.Y*pt.Y
+	q := r3.Vector{X: 4 * pt.X, Y: 4 * pt.Y, Z: 4 - d2}
+	return Point{fromFrame(p.frame, Point{q}).Normalize()}
+}
+
+// FromLatLng returns the LatLng projected into an R2 Point.
+func (p *StereographicProjection) FromLatLng(ll LatLng) r2.Point {
+	return p.Project(PointFromLatLng(ll))
+}
+
+// ToLatLng returns the LatLng projected from the given R2 Point.
+func (p *StereographicProjection) ToLatLng(pt r2.Point) LatLng {
+	return LatLngFromPoint(p.Unproject(pt))
+}
+
+// Interpolate returns the point obtained by interpolating the given
+// fraction of the distance along the line from A to B.
+func (p *StereographicProjection) Interpolate(f float64, a, b r2.Point) r2.Point {
+	return a.Mul(1 - f).Add(b.Mul(f))
+}
+
+// WrapDistance reports the coordinate wrapping distance along each axis.
+func (p *StereographicProjection) WrapDistance() r2.Point {
+	return r2.Point{}
+}
+
+// WrapDestination wraps the points if needed to get the shortest edge.
+func (p *StereographicProjection) WrapDestination(a, b r2.Point) r2.Point {
+	return wrapDestination(a, b, p.WrapDistance)
+}
+
+// LambertAzimuthalProjection defines the Lambert azimuthal equal-area
+// projection centered at a given point. It preserves areas: the area of a
+// region on the unit sphere equals the area of its projection.
+//
+// Coordinates are expressed in units of the sphere's radius, with "y" pointing
+// north as for StereographicProjection. The whole sphere is projected to a
+// disc of radius 2, whose boundary is the point opposite the center.
+type LambertAzimuthalProjection struct {
+	frame matrix3x3
+}
+
+// NewLambertAzimuthalProjection constructs a Lambert azimuthal equal-area
+// projection centered at the given point. The axes are oriented as for
+// NewStereographicProjection.
+func NewLambertAzimuthalProjection(center Point) Projection {
+	return &LambertAzimuthalProjection{frame: azimuthalFrame(center)}
+}
+
+// Project converts a point on the sphere to a projected 2D point. The point
+// opposite the center, which maps to the whole boundary of the disc, is
+// projected to (2, 0).
+func (p *LambertAzimuthalProjection) Project(pt Point) r2.Point {
+	q := toFrame(p.frame, pt)
+	// Near the antipode 1+q.Z loses all precision (and rounding errors can
+	// even make it negative), so there it is computed as (x²+y²)/(1-z).
+	d := 1 + q.Z
+	if q.Z < 0 {
+		d = (q.X*q.X + q.Y*q.Y) / (1 - q.Z)
+	}
+	if d <= 0 {
+		return r2.Point{X: 2, Y: 0}
+	}
+	k := math.Sqrt(2 / d)
+	return r2.Point{X: k * q.X, Y: k * q.Y}
+}
+
+// Unproject converts a projected 2D point to a point on the sphere.
+func (p *LambertAzimuthalProjection) Unproject(pt r2.Point) Point {
+	d2 := math.Min(4, pt.X*pt.X+pt.Y*pt.Y)
+	k := math.Sqrt(1 - 0.25*d2)
+	q := r3.Vector{X: k * pt.X, Y: k * pt.Y, Z: 1 - 0.5*d2}
+	return Point{fromFrame(p.frame, Point{q}).Normalize()}
+}
+
+// FromLatLng returns the LatLng projected into an R2 Point.
+func (p *LambertAzimuthalProjection) FromLatLng(ll LatLng) r2.Point {
+	return p.Project(PointFromLatLng(ll))
+}
+
+// ToLatLng returns the LatLng projected from the given R2 Point.
+func (p *LambertAzimuthalProjection) ToLatLng(pt r2.Point) LatLng {
+	return LatLngFromPoint(p.Unproject(pt))
+}
+
+// Interpolate returns the point obtained by interpolating the given
+// fraction of the distance along the line from A to B.
+func (p *LambertAzimuthalProjection) Interpolate(f float64, a, b r2.Point) r2.Point {
+	return a.Mul(1 - f).Add(b.Mul(f))
+}
+
+// WrapDistance reports the coordinate wrapping distance along each axis.
+func (p *LambertAzimuthalProjection) WrapDistance() r2.Point {
+	return r2.Point{}
+}
+
+// WrapDestination wraps the points if needed to get the shortest edge.
+func (p *LambertAzimuthalProjection) WrapDestination(a, b r2.Point) r2.Point {
+	return wrapDestination(a, b, p.WrapDistance)
+}
 
 func wrapDestination(a, b r2.Point, wrapDistance func() r2.Point) r2.Point {
 	wrap := wrapDistance()
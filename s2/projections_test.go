@@ -16,10 +16,12 @@ package s2
 
 import (
 	"math"
+	"math/rand"
 	"testing"
 
 	"github.com/golang/geo/r2"
 	"github.com/golang/geo/r3"
+	"github.com/golang/geo/s1"
 )
 
 func TestPlateCarreeProjectionInterpolate(t *testing.T) {
@@ -125,3 +127,198 @@ func TestMercatorProjectionProjectUnproject(t *testing.T) {
 		}
 	}
 }
+
+func TestWebMercatorProjectionProjectUnproject(t *testing.T) {
+	// The latitude of the top and bottom edges of the map.
+	maxLat := math.Atan(math.Sinh(math.Pi)) * 180 / math.Pi
+
+	tests := []struct {
+		zoom     int
+		tileSize float64
+		have     LatLng
+		want     r2.Point
+	}{
+		{0, 256, LatLngFromDegrees(0, 0), r2.Point{X: 128, Y: 128}},
+		{0, 256, LatLngFromDegrees(0, -180), r2.Point{X: 0, Y: 128}},
+		{0, 256, LatLngFromDegrees(maxLat, -90), r2.Point{X: 64, Y: 0}},
+		{0, 256, LatLngFromDegrees(-maxLat, 90), r2.Point{X: 192, Y: 256}},
+		{2, 1, LatLngFromDegrees(0, 90), r2.Point{X: 3, Y: 2}},
+		{2, 1, LatLngFromDegrees(maxLat, 0), r2.Point{X: 2, Y: 0}},
+		{10, 1, LatLngFromDegrees(-maxLat, 180), r2.Point{X: 1024, Y: 1024}},
+	}
+
+	for _, test := range tests {
+		proj := NewWebMercatorProjection(test.zoom, test.tileSize)
+		if got := proj.FromLatLng(test.have); !r2PointsApproxEqual(test.want, got, 1e-12*test.want.Norm()) {
+			t.Errorf("NewWebMercatorProjection(%d, %v).FromLatLng(%v) = %v, want %v", test.zoom, test.tileSize, test.have, got, test.want)
+		}
+		if got := proj.Unproject(test.want); !got.ApproxEqual(PointFromLatLng(test.have)) {
+			t.Errorf("NewWebMercatorProjection(%d, %v).Unproject(%v) = %v, want %v", test.zoom, test.tileSize, test.want, LatLngFromPoint(got), test.have)
+		}
+	}
+
+	proj := NewWebMercatorProjection(1, 256)
+	if got, want := proj.WrapDistance(), (r2.Point{X: 512, Y: 0}); got != want {
+		t.Errorf("NewWebMercatorProjection(1, 256).WrapDistance() = %v, want %v", got, want)
+	}
+}
+
+func TestGnomonicProjectionProjectUnproject(t *testing.T) {
+	tests := []struct {
+		face int
+		have Point
+		want r2.Point
+	}{
+		{0, PointFromCoords(1, 0, 0), r2.Point{X: 0, Y: 0}},
+		{0, PointFromCoords(1, 1, 0), r2.Point{X: 1, Y: 0}},
+		{0, PointFromCoords(1, -2, 3), r2.Point{X: -2, Y: 3}},
+		{2, PointFromCoords(0, 0, 1), r2.Point{X: 0, Y: 0}},
+		{4, PointFromCoords(1, -1, 1), r2.Point{X: -1, Y: 1}},
+	}
+	for _, test := range tests {
+		proj := NewGnomonicProjection(test.face)
+		if got := proj.Project(test.have); !r2PointsApproxEqual(test.want, got, epsilon) {
+			t.Errorf("NewGnomonicProjection(%d).Project(%v) = %v, want %v", test.face, test.have, got, test.want)
+		}
+		if got := proj.Unproject(test.want); !got.ApproxEqual(test.have) {
+			t.Errorf("NewGnomonicProjection(%d).Unproject(%v) = %v, want %v", test.face, test.want, got, test.have)
+		}
+	}
+
+	// Points outside the hemisphere centered on the face cannot be projected.
+	for _, p := range []Point{PointFromCoords(-1, 0, 0), PointFromCoords(0, 1, 0)} {
+		if got := NewGnomonicProjection(0).Project(p); !math.IsInf(got.X, 1) || !math.IsInf(got.Y, 1) {
+			t.Errorf("NewGnomonicProjection(0).Project(%v) = %v, want infinite", p, got)
+		}
+	}
+
+	// Geodesics are projected to straight lines.
+	proj := NewGnomonicProjection(1)
+	a, b := PointFromCoords(0.3, 1, -0.2), PointFromCoords(-0.5, 1, 0.7)
+	for f := 0.0; f <= 1; f += 0.125 {
+		got := proj.Unproject(proj.Interpolate(f, proj.Project(a), proj.Project(b)))
+		if DistanceFromSegment(got, a, b) > 1e-15 {
+			t.Errorf("point %v of the projected edge is %v from the geodesic edge, want 0",
+				got, DistanceFromSegment(got, a, b))
+		}
+	}
+}
+
+func TestStereographicProjectionProjectUnproject(t *testing.T) {
+	north, south := PointFromCoords(0, 0, 1), PointFromCoords(0, 0, -1)
+	tests := []struct {
+		center Point
+		have   Point
+		want   r2.Point
+	}{
+		// Polar maps have longitude 0 at the bottom (top) for the north (south) pole.
+		{north, north, r2.Point{X: 0, Y: 0}},
+		{north, PointFromCoords(1, 0, 0), r2.Point{X: 0, Y: -2}},
+		{north, PointFromCoords(0, 1, 0), r2.Point{X: 2, Y: 0}},
+		{north, PointFromLatLng(LatLngFromDegrees(0, 180)), r2.Point{X: 0, Y: 2}},
+		{south, PointFromCoords(1, 0, 0), r2.Point{X: 0, Y: 2}},
+		{south, PointFromCoords(0, 1, 0), r2.Point{X: 2, Y: 0}},
+		// Otherwise "y" points north and "x" points east.
+		{PointFromCoords(1, 0, 0), PointFromCoords(0, 0, 1), r2.Point{X: 0, Y: 2}},
+		{PointFromCoords(1, 0, 0), PointFromCoords(0, 1, 0), r2.Point{X: 2, Y: 0}},
+		{PointFromCoords(1, 0, 0), PointFromCoords(1, 0, 1), r2.Point{X: 0, Y: 2 * (math.Sqrt2 - 1)}},
+	}
+	for _, test := range tests {
+		proj := NewStereographicProjection(test.center)
+		if got := proj.Project(test.have); !r2PointsApproxEqual(test.want, got, epsilon) {
+			t.Errorf("NewStereographicProjection(%v).Project(%v) = %v, want %v", test.center, test.have, got, test.want)
+		}
+		if got := proj.Unproject(test.want); !got.ApproxEqual(test.have) {
+			t.Errorf("NewStereographicProjection(%v).Unproject(%v) = %v, want %v", test.center, test.want, got, test.have)
+		}
+	}
+
+	// The point opposite the center is projected to infinity.
+	if got := NewStereographicProjection(north).Project(south); !math.IsInf(got.Y, 0) {
+		t.Errorf("NewStereographicProjection(%v).Project(%v) = %v, want infinite", north, south, got)
+	}
+}
+
+func TestLambertAzimuthalProjectionProjectUnproject(t *testing.T) {
+	center := PointFromCoords(1, 0, 0)
+	tests := []struct {
+		have Point
+		want r2.Point
+	}{
+		{center, r2.Point{X: 0, Y: 0}},
+		{PointFromCoords(0, 0, 1), r2.Point{X: 0, Y: math.Sqrt2}},
+		{PointFromCoords(0, 1, 0), r2.Point{X: math.Sqrt2, Y: 0}},
+		{PointFromCoords(0, -1, 0), r2.Point{X: -math.Sqrt2, Y: 0}},
+	}
+	proj := NewLambertAzimuthalProjection(center)
+	for _, test := range tests {
+		if got := proj.Project(test.have); !r2PointsApproxEqual(test.want, got, epsilon) {
+			t.Errorf("proj.Project(%v) = %v, want %v", test.have, got, test.want)
+		}
+		if got := proj.Unproject(test.want); !got.ApproxEqual(test.have) {
+			t.Errorf("proj.Unproject(%v) = %v, want %v", test.want, got, test.have)
+		}
+	}
+
+	// The boundary of the disc of radius 2 is the point opposite the center.
+	if got := proj.Unproject(r2.Point{X: 1.2, Y: -1.6}); !got.ApproxEqual(Point{center.Mul(-1)}) {
+		t.Errorf("proj.Unproject(1.2, -1.6) = %v, want %v", got, Point{center.Mul(-1)})
+	}
+
+	// The point opposite the center is projected to the boundary, and points
+	// near it are projected close to the boundary rather than to NaN.
+	if got, want := proj.Project(Point{center.Mul(-1)}), (r2.Point{X: 2, Y: 0}); got != want {
+		t.Errorf("proj.Project(%v) = %v, want %v", Point{center.Mul(-1)}, got, want)
+	}
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		c := randomPoint(r)
+		proj := NewLambertAzimuthalProjection(c)
+		anti := Point{c.Mul(-1)}
+		for _, p := range []Point{anti, Point{anti.Add(randomPoint(r).Mul(1e-15)).Normalize()}} {
+			got := proj.Project(p)
+			if math.IsNaN(got.X) || math.IsNaN(got.Y) || math.Abs(got.Norm()-2) > 1e-6 {
+				t.Errorf("NewLambertAzimuthalProjection(%v).Project(%v) = %v, want a point with norm 2", c, p, got)
+			}
+		}
+	}
+
+	// Areas are preserved, so the planar area of a projected loop equals the
+	// area of the loop on the sphere.
+	for i := 0; i < 10; i++ {
+		loop := RegularLoop(randomPoint(r), s1.Angle(randomFloat64(r))*s1.Radian, 1000)
+		proj := NewLambertAzimuthalProjection(loop.Vertex(0))
+		area := 0.0
+		for j := 0; j < loop.NumVertices(); j++ {
+			a, b := proj.Project(loop.Vertex(j)), proj.Project(loop.Vertex(j+1))
+			area += 0.5 * a.Cross(b)
+		}
+		if got, want := math.Abs(area), loop.Area(); !float64Near(got, want, 1e-4*want) {
+			t.Errorf("area of projected %v = %v, want %v", loop, got, want)
+		}
+	}
+}
+
+func TestProjectionsRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	center := randomPoint(r)
+	projections := []Projection{
+		NewPlateCarreeProjection(180),
+		NewMercatorProjection(180),
+		NewWebMercatorProjection(5, 256),
+		NewGnomonicProjection(XYZToFace(center.Vector)),
+		NewStereographicProjection(center),
+		NewLambertAzimuthalProjection(center),
+	}
+	for _, proj := range projections {
+		for i := 0; i < 100; i++ {
+			p := samplePointFromCap(CapFromCenterAngle(center, 0.5), r)
+			if got := proj.Unproject(proj.Project(p)); !got.ApproxEqual(p) {
+				t.Errorf("%T: Unproject(Project(%v)) = %v", proj, p, got)
+			}
+			if got := proj.ToLatLng(proj.FromLatLng(LatLngFromPoint(p))); !PointFromLatLng(got).ApproxEqual(p) {
+				t.Errorf("%T: ToLatLng(FromLatLng(%v)) = %v", proj, LatLngFromPoint(p), got)
+			}
+		}
+	}
+}
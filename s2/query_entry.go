@@ -22,7 +22,7 @@ import "container/heap"
 type queryQueueEntry struct {
 	// A lower bound on the distance from the target to ID. This is the key
 	// of the priority queue.
-	distance distance
+	distance Distance
 
 	// The cell being queued.
 	id CellID
@@ -71,7 +71,7 @@ type queryPQ []*queryQueueEntry
 
 func (q queryPQ) Len() int { return len(q) }
 func (q queryPQ) Less(i, j int) bool {
-	return q[i].distance.less(q[j].distance)
+	return q[i].distance.Less(q[j].distance)
 }
 
 // Swap swaps the two entries.
@@ -91,3 +91,45 @@ func (q *queryPQ) Pop() any {
 	*q = (*q)[:len(*q)-1]
 	return item
 }
+
+// A cellPairQueueEntry stores a pair of cells, one from each of two
+// ShapeIndexes, and a lower bound on the distance between them. It is used by
+// EdgeQuery to merge-join its index with the index of a ShapeIndex target.
+type cellPairQueueEntry struct {
+	// A lower bound on the distance between the two cells. This is the key
+	// of the priority queue.
+	distance Distance
+
+	// The cells being queued, from the query index and the target index
+	// respectively. As in queryQueueEntry, each index cell field stores the
+	// corresponding ShapeIndexCell, or nil if the CellID is a proper ancestor
+	// of one or more ShapeIndexCells.
+	a, b         CellID
+	aCell, bCell *ShapeIndexCell
+}
+
+// cellPairPQ is a priority queue of cell pairs that implements the heap
+// interface, sorted in increasing order of distance.
+type cellPairPQ []*cellPairQueueEntry
+
+func (q cellPairPQ) Len() int { return len(q) }
+func (q cellPairPQ) Less(i, j int) bool {
+	return q[i].distance.Less(q[j].distance)
+}
+
+// Swap swaps the two entries.
+func (q cellPairPQ) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+}
+
+// Push adds the given entry to the queue.
+func (q *cellPairPQ) Push(x any) {
+	*q = append(*q, x.(*cellPairQueueEntry))
+}
+
+// Pop returns the top element of the queue.
+func (q *cellPairPQ) Pop() any {
+	item := (*q)[len(*q)-1]
+	*q = (*q)[:len(*q)-1]
+	return item
+}
@@ -25,7 +25,7 @@ func TestQueryQueueEntry(t *testing.T) {
 	// that queued up a number of cells in order to verify that this
 	// priority queue implementation matches.
 	var cells = []struct {
-		dist distance
+		dist Distance
 		cell string
 	}{
 		{dist: minDistance(s1.ChordAngleFromAngle(s1.Angle(0.220708))), cell: "1/3022"},
@@ -85,11 +85,16 @@ type queryOptions struct {
 	// The default value is true.
 	includeInteriors bool
 
-	// specifies that distances should be computed by examining every edge
-	// rather than using the ShapeIndex.
+	// useBruteForce specifies that distances should be computed by examining
+	// every edge rather than using the ShapeIndex. This is useful for testing,
+	// benchmarking, and debugging.
 	//
-	// TODO(roberts): When optimized is implemented, update the default to false.
-	// The default value is true.
+	// Even when this is false, the brute force algorithm is used for small
+	// indexes, where it is faster: namely when the index has at most
+	// MaxBruteForceIndexSize edges for the target type (e.g. 120 edges for
+	// a point target, or 30 for a cell target).
+	//
+	// The default value is false.
 	useBruteForce bool
 
 	// region specifies that results must intersect the given Region.
@@ -99,10 +104,23 @@ type queryOptions struct {
 	// instead. You can also set a distance limit and also require that results
 	// lie within a given rectangle.
 	//
+	// Since a Region can only be tested against points and cells, edges are
+	// tested conservatively: an edge that does not intersect the region may
+	// still be returned if it passes within a small fraction of its own length
+	// of the region. Interior results (see includeInteriors) are not affected
+	// by the region.
+	//
 	// The default is nil (no region limits).
 	region Region
 }
 
+// Region specifies that results must intersect the given Region. A nil
+// Region removes the restriction.
+func (q *queryOptions) Region(r Region) *queryOptions {
+	q.region = r
+	return q
+}
+
 // UseBruteForce sets or disables the use of brute force in a query.
 func (q *queryOptions) UseBruteForce(x bool) *queryOptions {
 	q.useBruteForce = x
@@ -184,10 +202,10 @@ func (q *queryOptions) FurthestConservativeDistanceLimit(limit s1.ChordAngle) *q
 
 // newQueryOptions returns a set of options using the given distance type
 // with the proper default values.
-func newQueryOptions(d distance) *queryOptions {
+func newQueryOptions(d Distance) *queryOptions {
 	return &queryOptions{
 		maxResults:       maxQueryResults,
-		distanceLimit:    d.infinity().chordAngle(),
+		distanceLimit:    d.Infinity().ChordAngle(),
 		maxError:         0,
 		includeInteriors: true,
 		useBruteForce:    false,
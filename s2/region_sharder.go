@@ -0,0 +1,73 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package s2
+
+// RegionSharder assigns regions to shards, where each shard is defined by a
+// CellUnion covering the part of the sphere that it is responsible for. The
+// shards are typically disjoint and together cover the whole sphere, but
+// neither is required.
+//
+// Regions are approximated by a covering computed with the default
+// RegionCoverer, and the shards are stored in a CellIndex, so the cost of a
+// lookup is logarithmic in the total number of cells in the shards.
+type RegionSharder struct {
+	index CellIndex
+}
+
+// NewRegionSharder returns a RegionSharder for the given shards. Shard i is
+// the region covered by shards[i].
+func NewRegionSharder(shards []CellUnion) *RegionSharder {
+	s := &RegionSharder{}
+	for i, shard := range shards {
+		s.index.AddCellUnion(shard, int32(i))
+	}
+	s.index.Build()
+	return s
+}
+
+// MostIntersectingShard returns the shard whose covering has the largest
+// intersection with the covering of the given region, measured in leaf
+// cells. Ties are broken in favor of the lowest numbered shard. If the region
+// does not intersect any shard, defaultShard is returned.
+func (s *RegionSharder) MostIntersectingShard(region Region, defaultShard int) int {
+	covering := NewRegionCoverer().CellUnion(region)
+
+	intersection := make(map[int32]int64)
+	s.index.VisitIntersectingCells(covering, func(id CellID, label int32) bool {
+		cu := CellUnionFromIntersectionWithCellID(covering, id)
+		intersection[label] += cu.LeafCellsCovered()
+		return true
+	})
+
+	best, bestSize := defaultShard, int64(0)
+	for label, size := range intersection {
+		if size > bestSize || (size == bestSize && int(label) < best) {
+			best, bestSize = int(label), size
+		}
+	}
+	return best
+}
+
+// IntersectingShards returns the sorted list of shards whose coverings
+// intersect the covering of the given region.
+func (s *RegionSharder) IntersectingShards(region Region) []int {
+	covering := NewRegionCoverer().CellUnion(region)
+
+	var shards []int
+	for _, label := range s.index.IntersectingLabels(covering) {
+		shards = append(shards, int(label))
+	}
+	return shards
+}
@@ -0,0 +1,80 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package s2
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRegionSharder(t *testing.T) {
+	face0 := CellIDFromFace(0).Children()
+	sharder := NewRegionSharder([]CellUnion{
+		{face0[0], face0[1]},
+		{face0[2]},
+		{face0[3], CellIDFromFace(1)},
+		{},
+	})
+
+	tests := []struct {
+		region          Region
+		mostIntersected int
+		intersecting    []int
+	}{
+		{
+			// A small region within a single shard.
+			region:          CellFromCellID(face0[0].ChildBeginAtLevel(10)),
+			mostIntersected: 0,
+			intersecting:    []int{0},
+		},
+		{
+			// Half of face 0 is in shard 0.
+			region:          CellFromCellID(CellIDFromFace(0)),
+			mostIntersected: 0,
+			intersecting:    []int{0, 1, 2},
+		},
+		{
+			region:          CellFromCellID(CellIDFromFace(1).ChildBeginAtLevel(3)),
+			mostIntersected: 2,
+			intersecting:    []int{2},
+		},
+		{
+			// A region that is not in any shard.
+			region:          CellFromCellID(CellIDFromFace(4)),
+			mostIntersected: 7,
+			intersecting:    nil,
+		},
+	}
+	for _, test := range tests {
+		if got := sharder.MostIntersectingShard(test.region, 7); got != test.mostIntersected {
+			t.Errorf("MostIntersectingShard(%v, 7) = %d, want %d", test.region, got, test.mostIntersected)
+		}
+		if got := sharder.IntersectingShards(test.region); !reflect.DeepEqual(got, test.intersecting) {
+			t.Errorf("IntersectingShards(%v) = %v, want %v", test.region, got, test.intersecting)
+		}
+	}
+}
+
+func TestRegionSharderTies(t *testing.T) {
+	face0 := CellIDFromFace(0).Children()
+	sharder := NewRegionSharder([]CellUnion{
+		{face0[0], face0[1]},
+		{face0[2], face0[3]},
+	})
+	// Both shards cover exactly half of the region.
+	if got := sharder.MostIntersectingShard(CellFromCellID(CellIDFromFace(0)), -1); got != 0 {
+		t.Errorf("MostIntersectingShard(face 0) = %d, want 0", got)
+	}
+}
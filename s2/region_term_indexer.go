@@ -0,0 +1,303 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package s2
+
+import (
+	"strings"
+)
+
+// RegionTermIndexerOptions holds the options used by a RegionTermIndexer.
+//
+// The options are set using builder style methods, e.g.
+//
+//	indexer := NewRegionTermIndexer(NewRegionTermIndexerOptions().
+//		MaxCells(12).
+//		LevelMod(2))
+type RegionTermIndexerOptions struct {
+	minLevel         int
+	maxLevel         int
+	levelMod         int
+	maxCells         int
+	pointsOnly       bool
+	optimizeForSpace bool
+	marker           byte
+}
+
+// NewRegionTermIndexerOptions returns the default RegionTermIndexerOptions,
+// which use at most 8 cells per covering at levels 4 through 16 (from about
+// 600km down to about 150m), and mark covering terms with '$'.
+func NewRegionTermIndexerOptions() *RegionTermIndexerOptions {
+	return &RegionTermIndexerOptions{
+		minLevel: 4,
+		maxLevel: 16,
+		levelMod: 1,
+		maxCells: 8,
+		marker:   '$',
+	}
+}
+
+// MinLevel sets the minimum cell level used for index and query terms.
+// Values are clamped to the range [0, MaxLevel].
+func (o *RegionTermIndexerOptions) MinLevel(level int) *RegionTermIndexerOptions {
+	o.minLevel = maxInt(0, minInt(MaxLevel, level))
+	return o
+}
+
+// MaxLevel sets the maximum cell level used for index and query terms. This
+// bounds the precision of the index: the smallest cells used have size on the
+// order of the cells at this level. Values are clamped to the range
+// [0, MaxLevel].
+func (o *RegionTermIndexerOptions) MaxLevel(level int) *RegionTermIndexerOptions {
+	o.maxLevel = maxInt(0, minInt(MaxLevel, level))
+	return o
+}
+
+// LevelMod sets the level modulus; only cells where (level - MinLevel) is a
+// multiple of LevelMod are used. Larger values reduce the number of index
+// terms per region at the expense of less precise coverings (see
+// RegionCoverer). Values are clamped to the range [1, 3].
+func (o *RegionTermIndexerOptions) LevelMod(n int) *RegionTermIndexerOptions {
+	o.levelMod = maxInt(1, minInt(3, n))
+	return o
+}
+
+// MaxCells sets the desired maximum number of cells used to approximate each
+// region. Larger values give more precise results, at the cost of more terms.
+// The default is 8.
+func (o *RegionTermIndexerOptions) MaxCells(n int) *RegionTermIndexerOptions {
+	o.maxCells = n
+	return o
+}
+
+// IndexContainsPointsOnly sets whether the index will contain only points
+// (rather than regions). If so, query terms are smaller and faster to match:
+// only one term is generated per query cell.
+func (o *RegionTermIndexerOptions) IndexContainsPointsOnly(x bool) *RegionTermIndexerOptions {
+	o.pointsOnly = x
+	return o
+}
+
+// OptimizeForSpace sets whether to reduce the number of index terms at the
+// expense of more query terms. Since the index typically holds far more
+// regions than are queried at once, this is usually a good trade-off when the
+// index size matters more than query speed. It has no effect on points, or if
+// IndexContainsPointsOnly is set.
+func (o *RegionTermIndexerOptions) OptimizeForSpace(x bool) *RegionTermIndexerOptions {
+	o.optimizeForSpace = x
+	return o
+}
+
+// MarkerCharacter sets the character that is prepended to covering terms to
+// distinguish them from ancestor terms. It must not be a character that can
+// appear in a CellID token, i.e. a hexadecimal digit or 'X'. The default is
+// '$'.
+func (o *RegionTermIndexerOptions) MarkerCharacter(c byte) *RegionTermIndexerOptions {
+	if strings.IndexByte("0123456789abcdefX", c) >= 0 {
+		panic("s2: RegionTermIndexer marker character must not appear in CellID tokens")
+	}
+	o.marker = c
+	return o
+}
+
+// trueMaxLevel returns the maximum level that satisfies both MaxLevel and
+// LevelMod.
+func (o *RegionTermIndexerOptions) trueMaxLevel() int {
+	if o.maxLevel < o.minLevel {
+		return o.maxLevel
+	}
+	return o.maxLevel - (o.maxLevel-o.minLevel)%o.levelMod
+}
+
+// RegionTermIndexer converts points and regions into index and query terms
+// for use with a general purpose information retrieval system, such as an
+// inverted index or full text search engine. A document is indexed under the
+// index terms of its geometry; a query for the documents whose geometry
+// intersects a given region is the disjunction (OR) of the query terms of that
+// region. For example:
+//
+//	indexer := NewRegionTermIndexer(nil)
+//	for _, term := range indexer.IndexTermsForRegion(docRegion, "") {
+//		index.Add(term, docID)
+//	}
+//	...
+//	var docs []DocID
+//	for _, term := range indexer.QueryTermsForRegion(queryRegion, "") {
+//		docs = append(docs, index.Lookup(term)...)
+//	}
+//
+// Matching is based on cell coverings, so a document is returned if its
+// covering intersects the covering of the query. The results are therefore a
+// superset of the documents whose geometry actually intersects the query
+// region; clients that need exact results should filter the candidates.
+// Documents and queries must use indexers with the same options.
+//
+// Each region is approximated by a covering of cells. Every cell of a
+// document's covering is indexed as a "covering" term, which matches queries
+// for regions that are contained by the cell, and every ancestor of the cell
+// (and the cell itself) is indexed as an "ancestor" term, which matches queries
+// for regions that contain the cell. Covering terms are distinguished from
+// ancestor terms by a marker character.
+//
+// Terms may be given a prefix, which allows several geometry fields to share
+// the same index. Prefixes should be chosen so that no prefix is a prefix of
+// another.
+type RegionTermIndexer struct {
+	opts    RegionTermIndexerOptions
+	coverer *RegionCoverer
+}
+
+// NewRegionTermIndexer returns a RegionTermIndexer with the given options. If
+// opts is nil, the default options are used.
+func NewRegionTermIndexer(opts *RegionTermIndexerOptions) *RegionTermIndexer {
+	if opts == nil {
+		opts = NewRegionTermIndexerOptions()
+	}
+	return &RegionTermIndexer{
+		opts: *opts,
+		coverer: &RegionCoverer{
+			MinLevel: opts.minLevel,
+			MaxLevel: opts.maxLevel,
+			LevelMod: opts.levelMod,
+			MaxCells: opts.maxCells,
+		},
+	}
+}
+
+// Coverer returns a copy of the RegionCoverer used to compute coverings of
+// regions. It can be used to compute a canonical covering once and pass it to
+// both IndexTermsForCanonicalCovering and QueryTermsForCanonicalCovering.
+func (t *RegionTermIndexer) Coverer() *RegionCoverer {
+	c := *t.coverer
+	return &c
+}
+
+// term returns the index or query term for the given cell.
+func (t *RegionTermIndexer) term(covering bool, id CellID, prefix string) string {
+	// There are generally more ancestor terms than covering terms, so the
+	// marker is added to the covering terms to keep the index small.
+	if covering {
+		return prefix + string(t.opts.marker) + id.ToToken()
+	}
+	return prefix + id.ToToken()
+}
+
+// IndexTermsForPoint returns the terms under which a document containing the
+// given point should be indexed.
+func (t *RegionTermIndexer) IndexTermsForPoint(p Point, prefix string) []string {
+	// A point is indexed like a region whose covering is the single cell at
+	// the maximum level, which needs only ancestor terms.
+	id := cellIDFromPoint(p)
+	var terms []string
+	for level := t.opts.minLevel; level <= t.opts.trueMaxLevel(); level += t.opts.levelMod {
+		terms = append(terms, t.term(false, id.Parent(level), prefix))
+	}
+	return terms
+}
+
+// IndexTermsForRegion returns the terms under which a document containing the
+// given region should be indexed.
+func (t *RegionTermIndexer) IndexTermsForRegion(region Region, prefix string) []string {
+	return t.IndexTermsForCanonicalCovering(t.coverer.Covering(region), prefix)
+}
+
+// IndexTermsForCanonicalCovering returns the index terms for a region with the
+// given covering, which must be canonical with respect to the indexer's
+// options (see RegionCoverer.IsCanonical). This is useful when the covering
+// has already been computed, or to index a CellUnion exactly.
+func (t *RegionTermIndexer) IndexTermsForCanonicalCovering(covering CellUnion, prefix string) []string {
+	// Cells in the covering are indexed as covering terms, and also as
+	// ancestor terms unless optimizing for space. Their ancestors are
+	// indexed as ancestor terms.
+	trueMaxLevel := t.opts.trueMaxLevel()
+	var terms []string
+	prevID := CellID(0)
+	for _, id := range covering {
+		level := id.Level()
+		if level < trueMaxLevel {
+			// Cells at the maximum level cannot contain any query cells, so
+			// they do not need covering terms.
+			terms = append(terms, t.term(true, id, prefix))
+		}
+		if level == trueMaxLevel || !t.opts.optimizeForSpace {
+			terms = append(terms, t.term(false, id, prefix))
+		}
+		for level -= t.opts.levelMod; level >= t.opts.minLevel; level -= t.opts.levelMod {
+			ancestor := id.Parent(level)
+			if prevID != 0 && prevID.Level() > level && prevID.Parent(level) == ancestor {
+				// This ancestor and all of its ancestors were already added.
+				break
+			}
+			terms = append(terms, t.term(false, ancestor, prefix))
+		}
+		prevID = id
+	}
+	return terms
+}
+
+// QueryTermsForPoint returns the terms to query for documents whose geometry
+// contains the given point.
+func (t *RegionTermIndexer) QueryTermsForPoint(p Point, prefix string) []string {
+	id := cellIDFromPoint(p)
+	// Cells at the maximum level are indexed only as ancestor terms.
+	level := t.opts.trueMaxLevel()
+	terms := []string{t.term(false, id.Parent(level), prefix)}
+	if t.opts.pointsOnly {
+		return terms
+	}
+	// Match the documents whose coverings contain an ancestor of the point.
+	for level -= t.opts.levelMod; level >= t.opts.minLevel; level -= t.opts.levelMod {
+		terms = append(terms, t.term(true, id.Parent(level), prefix))
+	}
+	return terms
+}
+
+// QueryTermsForRegion returns the terms to query for documents whose geometry
+// intersects the given region.
+func (t *RegionTermIndexer) QueryTermsForRegion(region Region, prefix string) []string {
+	return t.QueryTermsForCanonicalCovering(t.coverer.Covering(region), prefix)
+}
+
+// QueryTermsForCanonicalCovering returns the query terms for a region with the
+// given covering, which must be canonical with respect to the indexer's
+// options (see RegionCoverer.IsCanonical).
+func (t *RegionTermIndexer) QueryTermsForCanonicalCovering(covering CellUnion, prefix string) []string {
+	trueMaxLevel := t.opts.trueMaxLevel()
+	var terms []string
+	prevID := CellID(0)
+	for _, id := range covering {
+		// Match the documents whose coverings contain a descendant of this
+		// cell (or the cell itself, unless optimizing for space).
+		level := id.Level()
+		terms = append(terms, t.term(false, id, prefix))
+		if t.opts.pointsOnly {
+			continue
+		}
+		if t.opts.optimizeForSpace && level < trueMaxLevel {
+			// The cell itself is indexed only as a covering term.
+			terms = append(terms, t.term(true, id, prefix))
+		}
+		// Match the documents whose coverings contain an ancestor of this cell.
+		for level -= t.opts.levelMod; level >= t.opts.minLevel; level -= t.opts.levelMod {
+			ancestor := id.Parent(level)
+			if prevID != 0 && prevID.Level() > level && prevID.Parent(level) == ancestor {
+				// This ancestor and all of its ancestors were already added.
+				break
+			}
+			terms = append(terms, t.term(true, ancestor, prefix))
+		}
+		prevID = id
+	}
+	return terms
+}
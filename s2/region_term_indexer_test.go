@@ -0,0 +1,151 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package s2
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/golang/geo/s1"
+)
+
+// termsIntersect reports whether the two lists of terms have a term in common.
+func termsIntersect(a, b []string) bool {
+	set := make(map[string]bool)
+	for _, term := range a {
+		set[term] = true
+	}
+	for _, term := range b {
+		if set[term] {
+			return true
+		}
+	}
+	return false
+}
+
+// checkRegionTermIndexer indexes random caps (or points) and checks that
+// the query terms for random query caps match exactly the documents whose
+// coverings intersect the query covering.
+func checkRegionTermIndexer(t *testing.T, opts *RegionTermIndexerOptions, r *rand.Rand) {
+	t.Helper()
+	indexer := NewRegionTermIndexer(opts)
+	coverer := indexer.Coverer()
+
+	// Keep all of the geometry within a small area so that many documents
+	// match each query.
+	area := CapFromCenterAngle(randomPoint(r), 10*s1.Degree)
+	const numDocs = 50
+	var docCoverings []CellUnion
+	var docTerms [][]string
+	for i := 0; i < numDocs; i++ {
+		if opts.pointsOnly || oneIn(3, r) {
+			p := samplePointFromCap(area, r)
+			id := cellIDFromPoint(p).Parent(opts.trueMaxLevel())
+			docCoverings = append(docCoverings, CellUnion{id})
+			docTerms = append(docTerms, indexer.IndexTermsForPoint(p, "p:"))
+			continue
+		}
+		c := CapFromCenterAngle(samplePointFromCap(area, r), s1.Angle(randomFloat64(r))*area.Radius())
+		covering := coverer.Covering(c)
+		if !coverer.IsCanonical(covering) {
+			t.Fatalf("coverer.Covering(%v) = %v is not canonical", c, covering)
+		}
+		docCoverings = append(docCoverings, covering)
+		docTerms = append(docTerms, indexer.IndexTermsForRegion(c, "p:"))
+	}
+
+	for i := 0; i < 20; i++ {
+		var queryCovering CellUnion
+		var queryTerms []string
+		if oneIn(3, r) {
+			p := samplePointFromCap(area, r)
+			queryCovering = CellUnion{cellIDFromPoint(p)}
+			queryTerms = indexer.QueryTermsForPoint(p, "p:")
+		} else {
+			c := CapFromCenterAngle(samplePointFromCap(area, r), s1.Angle(randomFloat64(r))*area.Radius())
+			queryCovering = coverer.Covering(c)
+			queryTerms = indexer.QueryTermsForRegion(c, "p:")
+		}
+		for j, docCovering := range docCoverings {
+			want := docCovering.Intersects(queryCovering)
+			if got := termsIntersect(docTerms[j], queryTerms); got != want {
+				t.Errorf("%+v: doc %v and query %v: terms intersect = %v, want %v", *opts, docCovering, queryCovering, got, want)
+			}
+		}
+	}
+}
+
+func TestRegionTermIndexerRandomCaps(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	tests := []*RegionTermIndexerOptions{
+		NewRegionTermIndexerOptions(),
+		NewRegionTermIndexerOptions().OptimizeForSpace(true),
+		NewRegionTermIndexerOptions().IndexContainsPointsOnly(true),
+		NewRegionTermIndexerOptions().MinLevel(0).MaxLevel(16).LevelMod(2).MaxCells(20),
+		NewRegionTermIndexerOptions().MinLevel(3).MaxLevel(13).LevelMod(3).OptimizeForSpace(true),
+		NewRegionTermIndexerOptions().MinLevel(6).MaxLevel(12).MaxCells(4).MarkerCharacter('#'),
+	}
+	for _, opts := range tests {
+		for iter := 0; iter < 5; iter++ {
+			checkRegionTermIndexer(t, opts, r)
+		}
+	}
+}
+
+func TestRegionTermIndexerTerms(t *testing.T) {
+	indexer := NewRegionTermIndexer(NewRegionTermIndexerOptions().MinLevel(2).MaxLevel(4))
+	p := parsePoint("10:20")
+	id := cellIDFromPoint(p)
+
+	got := indexer.IndexTermsForPoint(p, "loc:")
+	want := []string{
+		"loc:" + id.Parent(2).ToToken(),
+		"loc:" + id.Parent(3).ToToken(),
+		"loc:" + id.Parent(4).ToToken(),
+	}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("IndexTermsForPoint(%v) = %v, want %v", p, got, want)
+	}
+
+	got = indexer.QueryTermsForPoint(p, "loc:")
+	want = []string{
+		"loc:" + id.Parent(4).ToToken(),
+		"loc:$" + id.Parent(3).ToToken(),
+		"loc:$" + id.Parent(2).ToToken(),
+	}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("QueryTermsForPoint(%v) = %v, want %v", p, got, want)
+	}
+
+	// A covering of a single cell below the maximum level gets a covering
+	// term and an ancestor term for itself, and ancestor terms for its
+	// ancestors.
+	cell := id.Parent(3)
+	got = indexer.IndexTermsForCanonicalCovering(CellUnion{cell}, "")
+	want = []string{"$" + cell.ToToken(), cell.ToToken(), id.Parent(2).ToToken()}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("IndexTermsForCanonicalCovering(%v) = %v, want %v", cell, got, want)
+	}
+}
+
+func TestRegionTermIndexerMarkerCharacterPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("MarkerCharacter('a') did not panic")
+		}
+	}()
+	NewRegionTermIndexerOptions().MarkerCharacter('a')
+}
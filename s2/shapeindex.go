@@ -15,10 +15,12 @@
 package s2
 
 import (
+	"maps"
 	"math"
 	"sort"
 	"sync"
 	"sync/atomic"
+	"unsafe"
 
 	"github.com/golang/geo/r1"
 	"github.com/golang/geo/r2"
@@ -49,16 +51,20 @@ const (
 	// and not at query time.
 	cellPadding = 2.0 * (faceClipErrorUVCoord + edgeClipErrorUVCoord)
 
-	// cellSizeToLongEdgeRatio defines the cell size relative to the length of an
-	// edge at which it is first considered to be long. Long edges do not
-	// contribute toward the decision to subdivide a cell further. For example,
-	// a value of 2.0 means that the cell must be at least twice the size of the
-	// edge in order for that edge to be counted. There are two reasons for not
-	// counting long edges: (1) such edges typically need to be propagated to
-	// several children, which increases time and memory costs without much benefit,
-	// and (2) in pathological cases, many long edges close together could force
-	// subdivision to continue all the way to the leaf cell level.
-	cellSizeToLongEdgeRatio = 1.0
+	// defaultMaxEdgesPerCell is the default maximum number of short edges
+	// per index cell. See ShapeIndexOptions.MaxEdgesPerCell.
+	defaultMaxEdgesPerCell = 10
+
+	// defaultCellSizeToLongEdgeRatio is the default cell size relative to
+	// the length of an edge at which it is first considered to be long. See
+	// ShapeIndexOptions.CellSizeToLongEdgeRatio.
+	defaultCellSizeToLongEdgeRatio = 1.0
+
+	// defaultMinShortEdgeFraction is the default minimum fraction of short
+	// edges that a cell must have in order to be subdivided. The default of 0
+	// disables the check, so cells are subdivided based on MaxEdgesPerCell
+	// alone. See ShapeIndexOptions.MinShortEdgeFraction.
+	defaultMinShortEdgeFraction = 0
 )
 
 // clippedShape represents the part of a shape that intersects a Cell.
@@ -113,6 +119,15 @@ type ShapeIndexCell struct {
 	shapes []*clippedShape
 }
 
+// spaceUsed returns an estimate of the number of bytes of memory used by this cell.
+func (s *ShapeIndexCell) spaceUsed() int64 {
+	size := int64(unsafe.Sizeof(*s)) + int64(cap(s.shapes))*int64(unsafe.Sizeof(s))
+	for _, c := range s.shapes {
+		size += int64(unsafe.Sizeof(*c)) + int64(cap(c.edges))*int64(unsafe.Sizeof(int(0)))
+	}
+	return size
+}
+
 // NewShapeIndexCell creates a new cell that is sized to hold the given number of shapes.
 func NewShapeIndexCell(numShapes int) *ShapeIndexCell {
 	return &ShapeIndexCell{
@@ -208,8 +223,22 @@ const (
 //	for it := index.Iterator(); !it.Done(); it.Next() {
 //	  fmt.Print(it.CellID())
 //	}
+//
+// An iterator is tied to the version of the index it was positioned in. If
+// the index is modified afterwards (that is, pending updates are applied, or
+// the index is Reset), the iterator becomes stale, and the methods that read
+// or move from the current position (Done, Next, Prev, CellID, IndexCell,
+// and Center) panic. The methods that position the iterator from scratch
+// (Begin, End, Seek, LocatePoint, and LocateCellID) can still be used, and bring
+// the iterator up to date with the index; Begin and End also apply any
+// pending updates first. Use IsStale to check whether an iterator is stale.
 type ShapeIndexIterator struct {
-	index    *ShapeIndex
+	index *ShapeIndex
+	// The version of the index when the iterator was last positioned.
+	version uint64
+	// The leaf of the index's cell tree holding the current cell and the
+	// position of the cell within it. leaf is nil past the end of the index.
+	leaf     *cellTreeNode
 	position int
 	id       CellID
 	cell     *ShapeIndexCell
@@ -219,7 +248,8 @@ type ShapeIndexIterator struct {
 // position is specified, the iterator is positioned at the given spot.
 func NewShapeIndexIterator(index *ShapeIndex, pos ...ShapeIndexIteratorPos) *ShapeIndexIterator {
 	s := &ShapeIndexIterator{
-		index: index,
+		index:   index,
+		version: index.version,
 	}
 
 	if len(pos) > 0 {
@@ -242,6 +272,8 @@ func NewShapeIndexIterator(index *ShapeIndex, pos ...ShapeIndexIteratorPos) *Sha
 func (s *ShapeIndexIterator) clone() *ShapeIndexIterator {
 	return &ShapeIndexIterator{
 		index:    s.index,
+		version:  s.version,
+		leaf:     s.leaf,
 		position: s.position,
 		id:       s.id,
 		cell:     s.cell,
@@ -251,6 +283,7 @@ func (s *ShapeIndexIterator) clone() *ShapeIndexIterator {
 // CellID returns the CellID of the current index cell.
 // If s.Done() is true, a value larger than any valid CellID is returned.
 func (s *ShapeIndexIterator) CellID() CellID {
+	s.checkNotStale()
 	return s.id
 }
 
@@ -259,6 +292,7 @@ func (s *ShapeIndexIterator) IndexCell() *ShapeIndexCell {
 	// TODO(roberts): C++ has this call a virtual method to allow subclasses
 	// of ShapeIndexIterator to do other work before returning the cell. Do
 	// we need such a thing?
+	s.checkNotStale()
 	return s.cell
 }
 
@@ -267,18 +301,39 @@ func (s *ShapeIndexIterator) Center() Point {
 	return s.CellID().Point()
 }
 
+// IsStale reports whether the index has been modified since the iterator was
+// positioned, in which case only the methods that position the iterator from
+// scratch may be used.
+func (s *ShapeIndexIterator) IsStale() bool {
+	return s.version != s.index.version
+}
+
+// checkNotStale panics if the iterator is stale.
+func (s *ShapeIndexIterator) checkNotStale() {
+	if s.version != s.index.version {
+		panic("s2: ShapeIndexIterator used after its ShapeIndex was modified")
+	}
+}
+
 // Begin positions the iterator at the beginning of the index.
 func (s *ShapeIndexIterator) Begin() {
 	if !s.index.IsFresh() {
 		s.index.maybeApplyUpdates()
 	}
-	s.position = 0
+	s.version = s.index.version
+	s.leaf, s.position = s.index.cells.first(), 0
 	s.refresh()
 }
 
 // Next positions the iterator at the next index cell.
 func (s *ShapeIndexIterator) Next() {
-	s.position++
+	s.checkNotStale()
+	if s.leaf != nil {
+		s.position++
+		if s.position == len(s.leaf.ids) {
+			s.leaf, s.position = s.leaf.next, 0
+		}
+	}
 	s.refresh()
 }
 
@@ -286,67 +341,107 @@ func (s *ShapeIndexIterator) Next() {
 // indicate it was not yet at the beginning of the index. If the iterator is at the
 // first cell the call does nothing and returns false.
 func (s *ShapeIndexIterator) Prev() bool {
-	if s.position <= 0 {
+	s.checkNotStale()
+	switch {
+	case s.leaf == nil:
+		last := s.index.cells.last()
+		if last == nil {
+			return false
+		}
+		s.leaf, s.position = last, len(last.ids)-1
+	case s.position > 0:
+		s.position--
+	case s.leaf.prev != nil:
+		s.leaf = s.leaf.prev
+		s.position = len(s.leaf.ids) - 1
+	default:
 		return false
 	}
-
-	s.position--
 	s.refresh()
 	return true
 }
 
 // End positions the iterator at the end of the index.
 func (s *ShapeIndexIterator) End() {
-	s.position = len(s.index.cells)
+	if !s.index.IsFresh() {
+		s.index.maybeApplyUpdates()
+	}
+	s.version = s.index.version
+	s.leaf, s.position = nil, 0
 	s.refresh()
 }
 
 // Done reports if the iterator is positioned at or after the last index cell.
 func (s *ShapeIndexIterator) Done() bool {
+	s.checkNotStale()
 	return s.id == SentinelCellID
 }
 
 // refresh updates the stored internal iterator values.
 func (s *ShapeIndexIterator) refresh() {
-	if s.position < len(s.index.cells) {
-		s.id = s.index.cells[s.position]
-		s.cell = s.index.cellMap[s.CellID()]
+	if s.leaf != nil {
+		s.id = s.leaf.ids[s.position]
+		s.cell = s.leaf.cells[s.position]
 	} else {
 		s.id = SentinelCellID
 		s.cell = nil
 	}
 }
 
-// seek positions the iterator at the first cell whose ID >= target, or at the
+// Seek positions the iterator at the first cell whose ID >= target, or at the
 // end of the index if no such cell exists.
-func (s *ShapeIndexIterator) seek(target CellID) {
-	s.position = sort.Search(len(s.index.cells), func(i int) bool {
-		return s.index.cells[i] >= target
-	})
+func (s *ShapeIndexIterator) Seek(target CellID) {
+	s.version = s.index.version
+	s.leaf, s.position = s.index.cells.lowerBound(target)
 	s.refresh()
 }
 
-// LocatePoint positions the iterator at the cell that contains the given Point.
-// If no such cell exists, the iterator position is unspecified, and false is returned.
-// The cell at the matched position is guaranteed to contain all edges that might
-// intersect the line segment between target and the cell's center.
-func (s *ShapeIndexIterator) LocatePoint(p Point) bool {
-	// Let I = cellMap.LowerBound(T), where T is the leaf cell containing
-	// point P. Then if T is contained by an index cell, then the
-	// containing cell is either I or I'. We test for containment by comparing
-	// the ranges of leaf cells spanned by T, I, and I'.
+// seekForward is like Seek, except that it assumes the target is at or after
+// the current position. Targets that fall within the current or the next leaf
+// of the cell tree are found without descending the tree, so locating a
+// sequence of increasing, nearby targets this way is cheaper than seeking to
+// each one from scratch.
+func (s *ShapeIndexIterator) seekForward(target CellID) {
+	if s.leaf == nil || s.id >= target {
+		return
+	}
+	for _, leaf := range []*cellTreeNode{s.leaf, s.leaf.next} {
+		if leaf != nil && leaf.ids[len(leaf.ids)-1] >= target {
+			s.leaf, s.position = leaf, leaf.search(target)
+			s.refresh()
+			return
+		}
+	}
+	s.Seek(target)
+}
+
+// locatePointForward is like LocatePoint, but it only moves the iterator
+// forward (apart from a single step back to check the preceding cell). It
+// is intended for locating points that have been sorted by CellID, which
+// lets a whole batch of points be merge-joined with the index cells.
+func (s *ShapeIndexIterator) locatePointForward(p Point) bool {
 	target := cellIDFromPoint(p)
-	s.seek(target)
+	if !s.Done() && s.CellID().RangeMin() <= target && s.CellID().RangeMax() >= target {
+		return true
+	}
+	s.seekForward(target)
 	if !s.Done() && s.CellID().RangeMin() <= target {
 		return true
 	}
-
 	if s.Prev() && s.CellID().RangeMax() >= target {
 		return true
 	}
 	return false
 }
 
+// LocatePoint positions the iterator at the cell that contains the given Point.
+// If no such cell exists, the iterator position is unspecified, and false is returned.
+// The cell at the matched position is guaranteed to contain all edges that might
+// intersect the line segment between target and the cell's center.
+func (s *ShapeIndexIterator) LocatePoint(p Point) bool {
+	return locatePoint(s, p)
+}
+
 // LocateCellID attempts to position the iterator at the first matching index cell
 // in the index that has some relation to the given CellID. Let T be the target CellID.
 // If T is contained by (or equal to) some index cell I, then the iterator is positioned
@@ -354,24 +449,7 @@ func (s *ShapeIndexIterator) LocatePoint(p Point) bool {
 // then the iterator is positioned at the first such cell I and return Subdivided.
 // Otherwise Disjoint is returned and the iterator position is undefined.
 func (s *ShapeIndexIterator) LocateCellID(target CellID) CellRelation {
-	// Let T be the target, let I = cellMap.LowerBound(T.RangeMin()), and
-	// let I' be the predecessor of I. If T contains any index cells, then T
-	// contains I. Similarly, if T is contained by an index cell, then the
-	// containing cell is either I or I'. We test for containment by comparing
-	// the ranges of leaf cells spanned by T, I, and I'.
-	s.seek(target.RangeMin())
-	if !s.Done() {
-		if s.CellID() >= target && s.CellID().RangeMin() <= target {
-			return Indexed
-		}
-		if s.CellID() <= target.RangeMax() {
-			return Subdivided
-		}
-	}
-	if s.Prev() && s.CellID().RangeMax() >= target {
-		return Indexed
-	}
-	return Disjoint
+	return locateCellID(s, target)
 }
 
 // tracker keeps track of which shapes in a given set contain a particular point
@@ -416,7 +494,7 @@ func newTracker() *tracker {
 		b:          trackerOrigin(),
 		nextCellID: CellIDFromFace(0).ChildBeginAtLevel(MaxLevel),
 	}
-	t.drawTo(Point{faceUVToXYZ(0, -1, -1).Normalize()}) // CellID curve start
+	t.drawTo(Point{FaceUVToXYZ(0, -1, -1).Normalize()}) // CellID curve start
 
 	return t
 }
@@ -425,7 +503,18 @@ func newTracker() *tracker {
 // (corresponding to the start of the CellID space-filling curve).
 func trackerOrigin() Point {
 	// The start of the S2CellId space-filling curve.
-	return Point{faceUVToXYZ(0, -1, -1).Normalize()}
+	return Point{FaceUVToXYZ(0, -1, -1).Normalize()}
+}
+
+// clone returns a copy of this tracker that can be used independently of it.
+func (t *tracker) clone() *tracker {
+	c := *t
+	c.shapeIDs = append([]int32(nil), t.shapeIDs...)
+	c.savedIDs = append([]int32(nil), t.savedIDs...)
+	if t.crosser != nil {
+		c.crosser = NewEdgeCrosser(t.a, t.b)
+	}
+	return &c
 }
 
 // focus returns the current focus point of the tracker.
@@ -541,9 +630,9 @@ func (t *tracker) restoreStateBefore(limitShapeID int32) {
 	t.savedIDs = nil
 }
 
-// lowerBound returns the shapeID of the first entry x where x >= shapeID.
-func (t *tracker) lowerBound(shapeID int32) int32 {
-	panic("not implemented")
+// lowerBound returns the index of the first entry x in shapeIDs where x >= shapeID.
+func (t *tracker) lowerBound(shapeID int32) int {
+	return sort.Search(len(t.shapeIDs), func(i int) bool { return t.shapeIDs[i] >= shapeID })
 }
 
 // removedShape represents a set of edges from the given shape that is queued for removal.
@@ -554,6 +643,136 @@ type removedShape struct {
 	edges                 []Edge
 }
 
+// tmpBytesPerEdge is the approximate amount of temporary memory used per
+// edge while the index is being updated. Each edge being added is clipped to
+// the faces it intersects (a faceEdge) and then repeatedly to the cells it
+// passes through while subdividing (clippedEdges, plus the slices of pointers
+// to them), which adds up to a few hundred bytes.
+const tmpBytesPerEdge = 200
+
+// ShapeIndexOptions holds the options used when building a ShapeIndex.
+//
+// The options are set using builder style methods, e.g.
+//
+//	index := NewShapeIndexWithOptions(NewShapeIndexOptions().MemoryBudget(1 << 30))
+type ShapeIndexOptions struct {
+	maxEdgesPerCell         int
+	cellSizeToLongEdgeRatio float64
+	minShortEdgeFraction    float64
+	memoryBudget            int64
+	workers                 int
+	changeCallback          func(version uint64, changed CellUnion)
+}
+
+// NewShapeIndexOptions returns the default ShapeIndexOptions, which allow 10
+// short edges per cell, place no limit on the temporary memory used while
+// building the index, and build the index in a single goroutine.
+func NewShapeIndexOptions() *ShapeIndexOptions {
+	return &ShapeIndexOptions{
+		maxEdgesPerCell:         defaultMaxEdgesPerCell,
+		cellSizeToLongEdgeRatio: defaultCellSizeToLongEdgeRatio,
+		minShortEdgeFraction:    defaultMinShortEdgeFraction,
+		workers:                 1,
+	}
+}
+
+// MaxEdgesPerCell sets the maximum number of short edges an index cell may
+// have before it is subdivided. (Long edges are not counted; see
+// CellSizeToLongEdgeRatio.) Smaller values give larger indexes that are
+// faster to query, since fewer edges need to be tested in each cell, while
+// larger values give smaller indexes that are faster to build. For example,
+// an index of points answers point location queries faster with a small
+// value. Values < 1 are treated as 1. The default is 10.
+func (o *ShapeIndexOptions) MaxEdgesPerCell(n int) *ShapeIndexOptions {
+	o.maxEdgesPerCell = max(n, 1)
+	return o
+}
+
+// CellSizeToLongEdgeRatio sets the cell size relative to the length of an
+// edge at which the edge is first considered to be long. Long edges do not
+// contribute toward the decision to subdivide a cell further. For example, a
+// value of 2.0 means that the cell must be at least twice the size of the
+// edge in order for that edge to be counted. There are two reasons for not
+// counting long edges: (1) such edges typically need to be propagated to
+// several children, which increases time and memory costs without much
+// benefit, and (2) in pathological cases, many long edges close together
+// could force subdivision to continue all the way to the leaf cell level.
+//
+// Smaller values make cells keep subdividing around longer edges, which
+// speeds up queries on data made of long edges at the cost of a larger
+// index, while larger values give a smaller index. Values that are not
+// positive and finite (including NaN) are treated as the default, which is
+// 1.0.
+func (o *ShapeIndexOptions) CellSizeToLongEdgeRatio(ratio float64) *ShapeIndexOptions {
+	if !(ratio > 0) || math.IsInf(ratio, 1) {
+		ratio = defaultCellSizeToLongEdgeRatio
+	}
+	o.cellSizeToLongEdgeRatio = ratio
+	return o
+}
+
+// MinShortEdgeFraction sets the minimum fraction of short edges that must be
+// present in a cell in order for it to be subdivided. A cell is subdivided
+// only if its number of short edges exceeds both MaxEdgesPerCell and this
+// fraction of the number of edges and containing shapes in the cell.
+//
+// If this is positive then the total index size and construction time are
+// guaranteed to be linear in the number of input edges, which prevents the
+// quadratic worst case that certain configurations of long edges can
+// otherwise cause. Reasonable values range from 0.01 to 0.3 or more; a
+// value of 0 disables this check. The default is 0, so only MaxEdgesPerCell
+// decides whether a cell is subdivided unless this is set.
+func (o *ShapeIndexOptions) MinShortEdgeFraction(fraction float64) *ShapeIndexOptions {
+	o.minShortEdgeFraction = fraction
+	return o
+}
+
+// Workers sets the maximum number of goroutines used to apply updates to the
+// index. When this is greater than 1, the six cube faces are updated
+// concurrently, and when an index is first built, cells with many edges are
+// also subdivided concurrently. The resulting index is identical to the one
+// built by a single goroutine. Values < 1 are treated as 1.
+func (o *ShapeIndexOptions) Workers(n int) *ShapeIndexOptions {
+	o.workers = n
+	return o
+}
+
+// MemoryBudget sets the approximate number of bytes of temporary memory that
+// may be used while applying updates to the index. Building the index uses
+// much more memory per edge than the finished index, so when the pending
+// additions would exceed this budget they are split into batches that are
+// added one after another. This bounds the peak memory used when building
+// very large indexes, at the cost of a somewhat slower build.
+//
+// The budget does not include the memory used by the shapes themselves or
+// by the finished index (see ShapeIndex.SpaceUsed). A value <= 0 means that
+// all updates are applied in a single batch.
+func (o *ShapeIndexOptions) MemoryBudget(bytes int64) *ShapeIndexOptions {
+	o.memoryBudget = bytes
+	return o
+}
+
+// ChangeCallback sets a function that is called every time the cells of the
+// index change, that is, whenever pending updates are applied and when the
+// index is Reset. It is passed the new version of the index (see
+// ShapeIndex.Version) and a normalized CellUnion covering the cells that
+// were removed, added, or replaced, which can be used to invalidate data
+// derived from the affected parts of the index. The union may also cover
+// some cells whose contents did not change.
+//
+// The callback is made by the goroutine that applied the updates, after the
+// index has been brought up to date, so it may query the index. It must not
+// modify the index.
+func (o *ShapeIndexOptions) ChangeCallback(f func(version uint64, changed CellUnion)) *ShapeIndexOptions {
+	o.changeCallback = f
+	return o
+}
+
+// parallelUpdateMinEdges is the minimum number of edges a cell must have for
+// its children to be updated concurrently when building an index with more
+// than one worker. Smaller cells are not worth the overhead.
+const parallelUpdateMinEdges = 4096
+
 // There are three basic states the index can be in.
 const (
 	stale    int32 = iota // There are pending updates.
@@ -583,28 +802,63 @@ const (
 //	    index.Add(polyline);
 //	}
 //	// Now you can use a CrossingEdgeQuery or ClosestEdgeQuery here.
+//
+// The index is built lazily by the first query after shapes are added or
+// removed, or explicitly by calling Build. Methods that only read the index
+// (Iterator, Shape, Len, NumEdges, the query types, and so on) are safe to
+// call from any number of goroutines at once, including on an index that
+// still has pending updates: the first caller applies the updates while
+// holding a mutex and the others wait for it. Once the index is built, these
+// methods do not acquire the mutex at all, and only check the status of the
+// index with a single atomic load. Note that query objects such as
+// ContainsPointQuery and iterators are not themselves safe for concurrent
+// use, so each goroutine should create its own.
+//
+// Add, Remove, and Reset must not be called concurrently with any other
+// method. To keep serving queries while the index is being modified, use
+// Freeze to take an immutable snapshot, share the snapshot with the readers,
+// and modify the original.
 type ShapeIndex struct {
 	// shapes is a map of shape ID to shape.
 	shapes map[int32]Shape
 
-	// The maximum number of edges per cell.
-	// TODO(roberts): Update the comments when the usage of this is implemented.
-	maxEdgesPerCell int
+	// maxEdgesPerCell, cellSizeToLongEdgeRatio, and minShortEdgeFraction
+	// control when cells are subdivided; see ShapeIndexOptions.
+	maxEdgesPerCell         int
+	cellSizeToLongEdgeRatio float64
+	minShortEdgeFraction    float64
+
+	// memoryBudget is the approximate number of bytes of temporary memory
+	// each batch of updates may use, or <= 0 if there is no limit.
+	memoryBudget int64
+
+	// workerTokens limits the number of extra goroutines used while applying
+	// updates. It is nil when updates are applied by a single goroutine.
+	workerTokens chan struct{}
 
 	// nextID tracks the next ID to hand out. IDs are not reused when shapes
 	// are removed from the index.
 	nextID int32
 
-	// cellMap is a map from CellID to the set of clipped shapes that intersect that
-	// cell. The cell IDs cover a set of non-overlapping regions on the sphere.
-	// In C++, this is a BTree, so the cells are ordered naturally by the data structure.
-	cellMap map[CellID]*ShapeIndexCell
-	// Track the ordered list of cell IDs.
-	cells []CellID
+	// cells maps each CellID to the set of clipped shapes that intersect that
+	// cell. The cell IDs cover a set of non-overlapping regions on the sphere,
+	// and are kept in increasing order by the tree.
+	cells cellTree
 
 	// The current status of the index; accessed atomically.
 	status int32
 
+	// version is incremented every time the index cells change.
+	version uint64
+
+	// changeCallback, if non-nil, is called with the region of the index
+	// that changed every time the cells change.
+	changeCallback func(version uint64, changed CellUnion)
+
+	// frozen is set on the snapshots returned by Freeze, which cannot be
+	// modified.
+	frozen bool
+
 	// Additions and removals are queued and processed on the first subsequent
 	// query. There are several reasons to do this:
 	//
@@ -636,13 +890,28 @@ type ShapeIndex struct {
 
 // NewShapeIndex creates a new ShapeIndex.
 func NewShapeIndex() *ShapeIndex {
-	return &ShapeIndex{
-		maxEdgesPerCell: 10,
-		shapes:          make(map[int32]Shape),
-		cellMap:         make(map[CellID]*ShapeIndexCell),
-		cells:           nil,
-		status:          fresh,
+	return NewShapeIndexWithOptions(nil)
+}
+
+// NewShapeIndexWithOptions creates a new ShapeIndex that is built using the
+// given options. If opts is nil, the default options are used.
+func NewShapeIndexWithOptions(opts *ShapeIndexOptions) *ShapeIndex {
+	if opts == nil {
+		opts = NewShapeIndexOptions()
+	}
+	s := &ShapeIndex{
+		maxEdgesPerCell:         opts.maxEdgesPerCell,
+		cellSizeToLongEdgeRatio: opts.cellSizeToLongEdgeRatio,
+		minShortEdgeFraction:    opts.minShortEdgeFraction,
+		memoryBudget:            opts.memoryBudget,
+		changeCallback:          opts.changeCallback,
+		shapes:                  make(map[int32]Shape),
+		status:                  fresh,
 	}
+	if opts.workers > 1 {
+		s.workerTokens = make(chan struct{}, opts.workers-1)
+	}
+	return s
 }
 
 // Iterator returns an iterator for this index.
@@ -659,10 +928,6 @@ func (s *ShapeIndex) Begin() *ShapeIndexIterator {
 
 // End positions the iterator at the last cell in the index.
 func (s *ShapeIndex) End() *ShapeIndexIterator {
-	// TODO(roberts): It's possible that updates could happen to the index between
-	// the time this is called and the time the iterators position is used and this
-	// will be invalid or not the end. For now, things will be undefined if this
-	// happens. See about referencing the IsFresh to guard for this in the future.
 	s.maybeApplyUpdates()
 	return NewShapeIndexIterator(s, IteratorEnd)
 }
@@ -683,11 +948,72 @@ func (s *ShapeIndex) Len() int {
 
 // Reset resets the index to its original state.
 func (s *ShapeIndex) Reset() {
+	s.checkNotFrozen("Reset")
+	var changed CellUnion
+	if s.changeCallback != nil {
+		for n := s.cells.first(); n != nil; n = n.next {
+			changed = append(changed, n.ids...)
+		}
+	}
 	s.shapes = make(map[int32]Shape)
 	s.nextID = 0
-	s.cellMap = make(map[CellID]*ShapeIndexCell)
-	s.cells = nil
+	s.cells = cellTree{}
+	s.pendingAdditionsPos = 0
+	s.pendingRemovals = nil
+	s.version++
 	atomic.StoreInt32(&s.status, fresh)
+	if s.changeCallback != nil {
+		changed.Normalize()
+		s.changeCallback(s.version, changed)
+	}
+}
+
+// Version returns the version of the contents of the index. The version
+// starts at 0 and is incremented every time the index cells change, which
+// happens when pending updates are applied (by Build, or lazily by the first
+// query after the index is modified) and when the index is Reset. Adding or
+// removing shapes does not change the version until the updates are applied.
+// Iterators positioned in an older version of the index are stale.
+//
+// Version does not apply pending updates; call Build first to get the
+// version that includes all the shapes that have been added and removed.
+func (s *ShapeIndex) Version() uint64 {
+	return s.version
+}
+
+// SpaceUsed returns an estimate of the number of bytes of memory used by the
+// index, including any pending updates but excluding the shapes themselves.
+// If the index has pending updates, the memory needed to apply them (see
+// ShapeIndexOptions.MemoryBudget) is not included.
+//
+// In C++, this is MutableS2ShapeIndex::SpaceUsed.
+func (s *ShapeIndex) SpaceUsed() int64 {
+	size := int64(unsafe.Sizeof(*s))
+
+	// Each map entry holds a key and an interface value, plus per-entry
+	// overhead for the hash table.
+	size += int64(len(s.shapes)) * int64(unsafe.Sizeof(int32(0))+unsafe.Sizeof(Shape(nil))+8)
+
+	var sizeNode func(n *cellTreeNode)
+	sizeNode = func(n *cellTreeNode) {
+		size += int64(unsafe.Sizeof(*n))
+		size += int64(cap(n.ids)) * int64(unsafe.Sizeof(CellID(0)))
+		size += int64(cap(n.cells)+cap(n.children)) * int64(unsafe.Sizeof(n))
+		for _, cell := range n.cells {
+			size += cell.spaceUsed()
+		}
+		for _, child := range n.children {
+			sizeNode(child)
+		}
+	}
+	if s.cells.root != nil {
+		sizeNode(s.cells.root)
+	}
+
+	for _, r := range s.pendingRemovals {
+		size += int64(unsafe.Sizeof(*r)) + int64(cap(r.edges))*int64(unsafe.Sizeof(Edge{}))
+	}
+	return size
 }
 
 // NumEdges returns the number of edges in this index.
@@ -742,6 +1068,7 @@ func (s *ShapeIndex) idForShape(shape Shape) int32 {
 
 // Add adds the given shape to the index and returns the assigned ID..
 func (s *ShapeIndex) Add(shape Shape) int32 {
+	s.checkNotFrozen("Add")
 	s.shapes[s.nextID] = shape
 	s.nextID++
 	atomic.StoreInt32(&s.status, stale)
@@ -750,6 +1077,7 @@ func (s *ShapeIndex) Add(shape Shape) int32 {
 
 // Remove removes the given shape from the index.
 func (s *ShapeIndex) Remove(shape Shape) {
+	s.checkNotFrozen("Remove")
 	// The index updates itself lazily because it is much more efficient to
 	// process additions and removals in batches.
 	id := s.idForShape(shape)
@@ -772,7 +1100,7 @@ func (s *ShapeIndex) Remove(shape Shape) {
 	removed := &removedShape{
 		shapeID:               id,
 		hasInterior:           shape.Dimension() == 2,
-		containsTrackerOrigin: shape.ReferencePoint().Contained,
+		containsTrackerOrigin: containsBruteForce(shape, trackerOrigin()),
 		edges:                 make([]Edge, numEdges),
 	}
 
@@ -794,6 +1122,47 @@ func (s *ShapeIndex) Build() {
 	s.maybeApplyUpdates()
 }
 
+// Freeze applies any pending updates and returns an immutable snapshot of
+// the index. The snapshot supports every method of ShapeIndex except Add,
+// Remove, and Reset, which panic. Since it never changes, the snapshot can be
+// shared by any number of goroutines while the original index continues to
+// be modified (by a single goroutine) to build the next version.
+//
+// The snapshot shares the shapes and the index cells with the original,
+// which are never modified once built, so taking a snapshot only copies the
+// structure that maps CellIDs to cells. Freezing a snapshot returns it as is.
+func (s *ShapeIndex) Freeze() *ShapeIndex {
+	if s.frozen {
+		return s
+	}
+	s.maybeApplyUpdates()
+	return &ShapeIndex{
+		shapes:                  maps.Clone(s.shapes),
+		maxEdgesPerCell:         s.maxEdgesPerCell,
+		cellSizeToLongEdgeRatio: s.cellSizeToLongEdgeRatio,
+		minShortEdgeFraction:    s.minShortEdgeFraction,
+		nextID:                  s.nextID,
+		cells:                   s.cells.clone(),
+		version:                 s.version,
+		status:                  fresh,
+		frozen:                  true,
+		pendingAdditionsPos:     s.pendingAdditionsPos,
+	}
+}
+
+// IsFrozen reports whether this index is a snapshot returned by Freeze.
+func (s *ShapeIndex) IsFrozen() bool {
+	return s.frozen
+}
+
+// checkNotFrozen panics if the index is frozen, naming the method that was
+// called to modify it.
+func (s *ShapeIndex) checkNotFrozen(method string) {
+	if s.frozen {
+		panic("s2: ShapeIndex." + method + " called on a frozen index")
+	}
+}
+
 // IsFresh reports if there are no pending updates that need to be applied.
 // This can be useful to avoid building the index unnecessarily, or for
 // choosing between two different algorithms depending on whether the index
@@ -821,46 +1190,297 @@ func (s *ShapeIndex) isShapeBeingRemoved(shapeID int32) bool {
 
 // maybeApplyUpdates checks if the index pieces have changed, and if so, applies pending updates.
 func (s *ShapeIndex) maybeApplyUpdates() {
-	// TODO(roberts): To avoid acquiring and releasing the mutex on every
-	// query, we should use atomic operations when testing whether the status
-	// is fresh and when updating the status to be fresh. This guarantees
-	// that any thread that sees a status of fresh will also see the
-	// corresponding index updates.
-	if atomic.LoadInt32(&s.status) != fresh {
-		s.mu.Lock()
-		s.applyUpdatesInternal()
-		atomic.StoreInt32(&s.status, fresh)
-		s.mu.Unlock()
+	// To avoid acquiring and releasing the mutex on every query, we use
+	// atomic operations when testing whether the status is fresh and when
+	// updating the status to be fresh. This guarantees that any goroutine
+	// that sees a status of fresh will also see the corresponding index
+	// updates.
+	if atomic.LoadInt32(&s.status) == fresh {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// Another goroutine may have applied the updates while this one was
+	// waiting for the mutex.
+	if atomic.LoadInt32(&s.status) == fresh {
+		return
+	}
+	atomic.StoreInt32(&s.status, updating)
+	changed := s.applyUpdatesInternal()
+	s.version++
+	atomic.StoreInt32(&s.status, fresh)
+	// The callback is run while holding the mutex, so that callbacks are
+	// made in version order. Since the index is already fresh, the callback
+	// can query the index without deadlocking.
+	if s.changeCallback != nil {
+		s.changeCallback(s.version, changed)
 	}
 }
 
 // applyUpdatesInternal does the actual work of updating the index by applying all
 // pending additions and removals. It does *not* update the indexes status.
-func (s *ShapeIndex) applyUpdatesInternal() {
-	// TODO(roberts): Building the index can use up to 20x as much memory per
-	// edge as the final index memory size. If this causes issues, add in
-	// batched updating to limit the amount of items per batch to a
-	// configurable memory footprint overhead.
+// If the index has a change callback, the region of the index that changed
+// is returned, and otherwise the result is empty.
+func (s *ShapeIndex) applyUpdatesInternal() CellUnion {
+	// Building the index can use many times as much memory per edge as the
+	// final index, so the pending additions are split into batches that
+	// each fit within the memory budget, and the batches are applied one
+	// after another. All removals are applied with the first batch.
+	var changed CellUnion
+	for _, end := range s.updateBatches() {
+		changed = s.applyUpdateBatch(end, changed)
+	}
+	changed.Normalize()
+	// It is the caller's responsibility to update the index status.
+	return changed
+}
+
+// updateBatches splits the pending updates into batches, and returns the
+// shape ID just past the last shape added by each batch. Each batch adds a
+// consecutive range of shape IDs whose edges, together with those of the
+// pending removals for the first batch, are expected to fit within the
+// memory budget. A shape with more edges than the budget allows is given a
+// batch of its own.
+func (s *ShapeIndex) updateBatches() []int32 {
+	if s.memoryBudget <= 0 {
+		return []int32{s.nextID}
+	}
+	maxBatchEdges := int(s.memoryBudget / tmpBytesPerEdge)
+
+	batchEdges := 0
+	for _, r := range s.pendingRemovals {
+		batchEdges += len(r.edges)
+	}
+
+	var batches []int32
+	for id := s.pendingAdditionsPos; id < s.nextID; id++ {
+		shape := s.shapes[id]
+		if shape == nil {
+			continue // This shape has already been removed.
+		}
+		numEdges := shape.NumEdges()
+		if batchEdges > 0 && batchEdges+numEdges > maxBatchEdges {
+			batches = append(batches, id)
+			batchEdges = 0
+		}
+		batchEdges += numEdges
+	}
+	return append(batches, s.nextID)
+}
+
+// applyUpdateBatch applies any pending removals and adds the pending shapes
+// with IDs below end to the index. If the index has a change callback, the
+// ids of the cells that were deleted or set are appended to changed, which
+// is returned.
+func (s *ShapeIndex) applyUpdateBatch(end int32, changed CellUnion) CellUnion {
 	t := newTracker()
 
 	// allEdges maps a Face to a collection of faceEdges.
 	allEdges := make([][]faceEdge, 6)
 
+	// Edges are kept sorted by shape ID, and all shapes being removed come
+	// before the shapes being added.
+	sort.Slice(s.pendingRemovals, func(i, j int) bool {
+		return s.pendingRemovals[i].shapeID < s.pendingRemovals[j].shapeID
+	})
 	for _, p := range s.pendingRemovals {
 		s.removeShapeInternal(p, allEdges, t)
 	}
 
-	for id := s.pendingAdditionsPos; id < int32(len(s.shapes)); id++ {
+	for id := s.pendingAdditionsPos; id < end; id++ {
 		s.addShapeInternal(id, allEdges, t)
 	}
 
-	for face := 0; face < 6; face++ {
-		s.updateFaceEdges(face, allEdges[face], t)
+	var buffers [6]cellBuffer
+	if s.workerTokens == nil {
+		for face := 0; face < 6; face++ {
+			s.updateFaceEdges(face, allEdges[face], t, &buffers[face])
+		}
+	} else {
+		// The faces are independent of each other once each one has a
+		// tracker positioned at its start, so they are updated concurrently.
+		trackers := s.faceTrackers(t, end)
+		var wg sync.WaitGroup
+		for face := 0; face < 6; face++ {
+			face := face
+			s.spawn(&wg, func() {
+				s.updateFaceEdges(face, allEdges[face], trackers[face], &buffers[face])
+			})
+		}
+		wg.Wait()
+	}
+
+	// Apply all of the deletions before any of the additions, since a cell
+	// that is absorbed may be replaced by a new cell with the same CellID.
+	for i := range buffers {
+		for _, id := range buffers[i].deleted {
+			s.cells.delete(id)
+		}
+	}
+	for i := range buffers {
+		for k, id := range buffers[i].ids {
+			s.cells.set(id, buffers[i].cells[k])
+		}
+	}
+	if s.changeCallback != nil {
+		for i := range buffers {
+			changed = append(changed, buffers[i].deleted...)
+			changed = append(changed, buffers[i].ids...)
+		}
 	}
 
 	s.pendingRemovals = s.pendingRemovals[:0]
-	s.pendingAdditionsPos = int32(len(s.shapes))
-	// It is the caller's responsibility to update the index status.
+	s.pendingAdditionsPos = end
+	return changed
+}
+
+// cellBuffer records the changes made to the index cells while applying a
+// batch of updates. The changes are applied to the cell tree once the whole
+// batch has been processed, so that during the update the tree is only read,
+// which allows the faces (and the parts of large cells) to be updated
+// concurrently. New cells are recorded in increasing CellID order.
+type cellBuffer struct {
+	ids     []CellID
+	cells   []*ShapeIndexCell
+	deleted []CellID
+}
+
+// spawn runs f in a new goroutine tracked by wg if a worker is available, and
+// otherwise runs f in the calling goroutine.
+func (s *ShapeIndex) spawn(wg *sync.WaitGroup, f func()) {
+	select {
+	case s.workerTokens <- struct{}{}:
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-s.workerTokens }()
+			f()
+		}()
+	default:
+		f()
+	}
+}
+
+// faceTrackers returns a tracker for each face, positioned at the entry
+// vertex of that face, given the tracker t which is positioned at the start
+// of the CellID space-filling curve. The shapes whose interiors are tracked
+// are the pending removals and the pending additions with IDs below end.
+//
+// When the faces are processed sequentially, the tracker state at the start
+// of each face is whatever it was at the end of the previous one. Here the
+// state is computed instead by drawing a line from the entry vertex of each
+// face to the next and counting edge crossings.
+func (s *ShapeIndex) faceTrackers(t *tracker, end int32) [6]*tracker {
+	var entries [6]Point
+	for face := 0; face < 6; face++ {
+		entries[face] = PaddedCellFromCellID(CellIDFromFace(face), 0).EntryVertex()
+	}
+	entries[0] = t.focus()
+
+	// toggles[face] lists the shapes whose state changes between the entry
+	// vertex of the previous face and the entry vertex of this face.
+	var toggles [6][]int32
+	if t.isActive {
+		var wg sync.WaitGroup
+		for face := 1; face < 6; face++ {
+			face := face
+			s.spawn(&wg, func() {
+				crosser := NewEdgeCrosser(entries[face-1], entries[face])
+				crossed := func(shapeID int32, edge Edge) {
+					if crosser.EdgeOrVertexCrossing(edge.V0, edge.V1) {
+						toggles[face] = append(toggles[face], shapeID)
+					}
+				}
+				for _, r := range s.pendingRemovals {
+					if r.hasInterior {
+						for _, edge := range r.edges {
+							crossed(r.shapeID, edge)
+						}
+					}
+				}
+				for id := s.pendingAdditionsPos; id < end; id++ {
+					shape := s.shapes[id]
+					if shape == nil || shape.Dimension() != 2 {
+						continue
+					}
+					for e := 0; e < shape.NumEdges(); e++ {
+						crossed(id, shape.Edge(e))
+					}
+				}
+			})
+		}
+		wg.Wait()
+	}
+
+	var trackers [6]*tracker
+	trackers[0] = t.clone()
+	for face := 1; face < 6; face++ {
+		trackers[face] = trackers[face-1].clone()
+		for _, shapeID := range toggles[face] {
+			trackers[face].toggleShape(shapeID)
+		}
+		trackers[face].moveTo(entries[face])
+	}
+	for face := 0; face < 6; face++ {
+		trackers[face].setNextCellID(CellIDFromFace(face))
+	}
+	return trackers
+}
+
+// updateChildrenParallel updates the four children of pcell concurrently,
+// given the edges of pcell and their division among the children. Each child
+// is processed with its own tracker, positioned at the entry vertex of the
+// child, and its own cellBuffer; the buffers are then appended to b in CellID
+// order. Finally t is advanced to the exit vertex of pcell, just as if the
+// children had been processed one after another.
+//
+// This is only used when building an index for the first time, when there
+// are no existing index cells to absorb.
+func (s *ShapeIndex) updateChildrenParallel(pcell *PaddedCell, edges []*clippedEdge, childEdges [2][2][]*clippedEdge, t *tracker, b *cellBuffer) {
+	var children [4]*PaddedCell
+	var trackers [4]*tracker
+
+	// There are no edge crossings between the current focus of the tracker
+	// and the entry vertex of pcell, otherwise there would be index cells
+	// between them that have not been created yet.
+	cur := t.clone()
+	for pos := 0; pos < 4; pos++ {
+		i, j := pcell.ChildIJ(pos)
+		children[pos] = PaddedCellFromParentIJ(pcell, i, j)
+		if pos == 0 {
+			cur.moveTo(children[pos].EntryVertex())
+		} else if cur.isActive {
+			cur.drawTo(children[pos].EntryVertex())
+			s.testAllEdges(edges, cur)
+		}
+		trackers[pos] = cur.clone()
+		trackers[pos].setNextCellID(children[pos].id)
+	}
+
+	var buffers [4]cellBuffer
+	var wg sync.WaitGroup
+	for pos := 0; pos < 4; pos++ {
+		pos := pos
+		i, j := pcell.ChildIJ(pos)
+		if len(childEdges[i][j]) == 0 && len(trackers[pos].shapeIDs) == 0 {
+			continue
+		}
+		s.spawn(&wg, func() {
+			s.updateEdges(children[pos], childEdges[i][j], trackers[pos], true, &buffers[pos])
+		})
+	}
+	wg.Wait()
+	for pos := 0; pos < 4; pos++ {
+		b.ids = append(b.ids, buffers[pos].ids...)
+		b.cells = append(b.cells, buffers[pos].cells...)
+	}
+
+	if cur.isActive {
+		cur.drawTo(pcell.ExitVertex())
+		s.testAllEdges(edges, cur)
+	}
+	cur.setNextCellID(pcell.id.Next())
+	*t = *cur
 }
 
 // addShapeInternal clips all edges of the given shape to the six cube faces,
@@ -888,20 +1508,20 @@ func (s *ShapeIndex) addShapeInternal(shapeID int32, allEdges [][]faceEdge, t *t
 
 		faceEdge.edgeID = e
 		faceEdge.edge = edge
-		faceEdge.MaxLevel = maxLevelForEdge(edge)
+		faceEdge.MaxLevel = s.maxLevelForEdge(edge)
 		s.addFaceEdge(faceEdge, allEdges)
 	}
 }
 
 // addFaceEdge adds the given faceEdge into the collection of all edges.
 func (s *ShapeIndex) addFaceEdge(fe faceEdge, allEdges [][]faceEdge) {
-	aFace := face(fe.edge.V0.Vector)
+	aFace := XYZToFace(fe.edge.V0.Vector)
 	// See if both endpoints are on the same face, and are far enough from
 	// the edge of the face that they don't intersect any (padded) adjacent face.
-	if aFace == face(fe.edge.V1.Vector) {
-		x, y := validFaceXYZToUV(aFace, fe.edge.V0.Vector)
+	if aFace == XYZToFace(fe.edge.V1.Vector) {
+		x, y := ValidFaceXYZToUV(aFace, fe.edge.V0.Vector)
 		fe.a = r2.Point{X: x, Y: y}
-		x, y = validFaceXYZToUV(aFace, fe.edge.V1.Vector)
+		x, y = ValidFaceXYZToUV(aFace, fe.edge.V1.Vector)
 		fe.b = r2.Point{X: x, Y: y}
 
 		maxUV := 1 - cellPadding
@@ -924,7 +1544,7 @@ func (s *ShapeIndex) addFaceEdge(fe faceEdge, allEdges [][]faceEdge) {
 
 // updateFaceEdges adds or removes the various edges from the index.
 // An edge is added if shapes[id] is not nil, and removed otherwise.
-func (s *ShapeIndex) updateFaceEdges(face int, faceEdges []faceEdge, t *tracker) {
+func (s *ShapeIndex) updateFaceEdges(face int, faceEdges []faceEdge, t *tracker, b *cellBuffer) {
 	numEdges := len(faceEdges)
 	if numEdges == 0 && len(t.shapeIDs) == 0 {
 		return
@@ -959,16 +1579,16 @@ func (s *ShapeIndex) updateFaceEdges(face int, faceEdges []faceEdge, t *tracker)
 			// can save a lot of work by starting directly with that cell, but if we
 			// are in the interior of at least one shape then we need to create
 			// index entries for the cells we are skipping over.
-			s.skipCellRange(faceID.RangeMin(), shrunkID.RangeMin(), t, disjointFromIndex)
+			s.skipCellRange(faceID.RangeMin(), shrunkID.RangeMin(), t, disjointFromIndex, b)
 			pcell = PaddedCellFromCellID(shrunkID, cellPadding)
-			s.updateEdges(pcell, clippedEdges, t, disjointFromIndex)
-			s.skipCellRange(shrunkID.RangeMax().Next(), faceID.RangeMax().Next(), t, disjointFromIndex)
+			s.updateEdges(pcell, clippedEdges, t, disjointFromIndex, b)
+			s.skipCellRange(shrunkID.RangeMax().Next(), faceID.RangeMax().Next(), t, disjointFromIndex, b)
 			return
 		}
 	}
 
 	// Otherwise (no edges, or no shrinking is possible), subdivide normally.
-	s.updateEdges(pcell, clippedEdges, t, disjointFromIndex)
+	s.updateEdges(pcell, clippedEdges, t, disjointFromIndex, b)
 }
 
 // shrinkToFit shrinks the PaddedCell to fit within the given bounds.
@@ -977,8 +1597,9 @@ func (s *ShapeIndex) shrinkToFit(pcell *PaddedCell, bound r2.Rect) CellID {
 
 	if !s.isFirstUpdate() && shrunkID != pcell.CellID() {
 		// Don't shrink any smaller than the existing index cells, since we need
-		// to combine the new edges with those cells.
-		iter := s.Iterator()
+		// to combine the new edges with those cells. (The iterator must not
+		// try to apply pending updates, since we are in the middle of doing so.)
+		iter := NewShapeIndexIterator(s)
 		if iter.LocateCellID(shrunkID) == Indexed {
 			shrunkID = iter.CellID()
 		}
@@ -988,7 +1609,7 @@ func (s *ShapeIndex) shrinkToFit(pcell *PaddedCell, bound r2.Rect) CellID {
 
 // skipCellRange skips over the cells in the given range, creating index cells if we are
 // currently in the interior of at least one shape.
-func (s *ShapeIndex) skipCellRange(begin, end CellID, t *tracker, disjointFromIndex bool) {
+func (s *ShapeIndex) skipCellRange(begin, end CellID, t *tracker, disjointFromIndex bool, b *cellBuffer) {
 	// If we aren't in the interior of a shape, then skipping over cells is easy.
 	if len(t.shapeIDs) == 0 {
 		return
@@ -999,14 +1620,15 @@ func (s *ShapeIndex) skipCellRange(begin, end CellID, t *tracker, disjointFromIn
 	skipped := CellUnionFromRange(begin, end)
 	for _, cell := range skipped {
 		var clippedEdges []*clippedEdge
-		s.updateEdges(PaddedCellFromCellID(cell, cellPadding), clippedEdges, t, disjointFromIndex)
+		s.updateEdges(PaddedCellFromCellID(cell, cellPadding), clippedEdges, t, disjointFromIndex, b)
 	}
 }
 
 // updateEdges adds or removes the given edges whose bounding boxes intersect a
-// given cell. disjointFromIndex is an optimization hint indicating that cellMap
-// does not contain any entries that overlap the given cell.
-func (s *ShapeIndex) updateEdges(pcell *PaddedCell, edges []*clippedEdge, t *tracker, disjointFromIndex bool) {
+// given cell. disjointFromIndex is an optimization hint indicating that the index
+// does not contain any cells that overlap the given cell. The resulting changes
+// to the index cells are recorded in b.
+func (s *ShapeIndex) updateEdges(pcell *PaddedCell, edges []*clippedEdge, t *tracker, disjointFromIndex bool, b *cellBuffer) {
 	// This function is recursive with a maximum recursion depth of 30 (MaxLevel).
 
 	// Incremental updates are handled as follows. All edges being added or
@@ -1032,7 +1654,7 @@ func (s *ShapeIndex) updateEdges(pcell *PaddedCell, edges []*clippedEdge, t *tra
 		// There may be existing index cells contained inside pcell. If we
 		// encounter such a cell, we need to combine the edges being updated with
 		// the existing cell contents by absorbing the cell.
-		iter := s.Iterator()
+		iter := NewShapeIndexIterator(s)
 		r := iter.LocateCellID(pcell.id)
 		switch r {
 		case Disjoint:
@@ -1040,7 +1662,7 @@ func (s *ShapeIndex) updateEdges(pcell *PaddedCell, edges []*clippedEdge, t *tra
 		case Indexed:
 			// Absorb the index cell by transferring its contents to edges and
 			// deleting it. We also start tracking the interior of any new shapes.
-			s.absorbIndexCell(pcell, iter, edges, t)
+			edges = s.absorbIndexCell(pcell, iter, edges, t, b)
 			indexCellAbsorbed = true
 			disjointFromIndex = true
 		case Subdivided:
@@ -1054,7 +1676,7 @@ func (s *ShapeIndex) updateEdges(pcell *PaddedCell, edges []*clippedEdge, t *tra
 	// subdividing so that we can merge with those cells. Otherwise,
 	// makeIndexCell checks if the number of edges is small enough, and creates
 	// an index cell if possible (returning true when it does so).
-	if !disjointFromIndex || !s.makeIndexCell(pcell, edges, t) {
+	if !disjointFromIndex || !s.makeIndexCell(pcell, edges, t, b) {
 		// TODO(roberts): If it turns out to have memory problems when there
 		// are 10M+ edges in the index, look into pre-allocating space so we
 		// are not always appending.
@@ -1128,12 +1750,16 @@ func (s *ShapeIndex) updateEdges(pcell *PaddedCell, edges []*clippedEdge, t *tra
 
 		// Now recursively update the edges in each child. We call the children in
 		// increasing order of CellID so that when the index is first constructed,
-		// all insertions into cellMap are at the end (which is much faster).
-		for pos := 0; pos < 4; pos++ {
-			i, j := pcell.ChildIJ(pos)
-			if len(childEdges[i][j]) > 0 || len(t.shapeIDs) > 0 {
-				s.updateEdges(PaddedCellFromParentIJ(pcell, i, j), childEdges[i][j],
-					t, disjointFromIndex)
+		// all insertions into the cell tree are at the end (which is much faster).
+		if s.workerTokens != nil && s.isFirstUpdate() && len(edges) >= parallelUpdateMinEdges {
+			s.updateChildrenParallel(pcell, edges, childEdges, t, b)
+		} else {
+			for pos := 0; pos < 4; pos++ {
+				i, j := pcell.ChildIJ(pos)
+				if len(childEdges[i][j]) > 0 || len(t.shapeIDs) > 0 {
+					s.updateEdges(PaddedCellFromParentIJ(pcell, i, j), childEdges[i][j],
+						t, disjointFromIndex, b)
+				}
 			}
 		}
 	}
@@ -1145,8 +1771,8 @@ func (s *ShapeIndex) updateEdges(pcell *PaddedCell, edges []*clippedEdge, t *tra
 }
 
 // makeIndexCell builds an indexCell from the given padded cell and set of edges and adds
-// it to the index. If the cell or edges are empty, no cell is added.
-func (s *ShapeIndex) makeIndexCell(p *PaddedCell, edges []*clippedEdge, t *tracker) bool {
+// it to the index (by way of b). If the cell or edges are empty, no cell is added.
+func (s *ShapeIndex) makeIndexCell(p *PaddedCell, edges []*clippedEdge, t *tracker, b *cellBuffer) bool {
 	// If the cell is empty, no index cell is needed. (In most cases this
 	// situation is detected before we get to this point, but this can happen
 	// when all shapes in a cell are removed.)
@@ -1157,12 +1783,13 @@ func (s *ShapeIndex) makeIndexCell(p *PaddedCell, edges []*clippedEdge, t *track
 	// Count the number of edges that have not reached their maximum level yet.
 	// Return false if there are too many such edges.
 	count := 0
+	maxShortEdges := s.maxShortEdges(len(edges) + len(t.shapeIDs))
 	for _, ce := range edges {
 		if p.Level() < ce.faceEdge.MaxLevel {
 			count++
 		}
 
-		if count > s.maxEdgesPerCell {
+		if count > maxShortEdges {
 			return false
 		}
 	}
@@ -1215,7 +1842,7 @@ func (s *ShapeIndex) makeIndexCell(p *PaddedCell, edges []*clippedEdge, t *track
 	for i := 0; i < numShapes; i++ {
 		var clipped *clippedShape
 		// advance to next value base + i
-		eshapeID := int32(s.Len())
+		eshapeID := s.nextID
 		cshapeID := eshapeID // Sentinels
 
 		if eNext != len(edges) {
@@ -1247,9 +1874,9 @@ func (s *ShapeIndex) makeIndexCell(p *PaddedCell, edges []*clippedEdge, t *track
 		cell.shapes[i] = clipped
 	}
 
-	// Add this cell to the map.
-	s.cellMap[p.id] = cell
-	s.cells = append(s.cells, p.id)
+	// Add this cell to the index.
+	b.ids = append(b.ids, p.id)
+	b.cells = append(b.cells, cell)
 
 	// Shift the tracker focus point to the exit vertex of this cell.
 	if t.isActive && len(edges) != 0 {
@@ -1367,7 +1994,8 @@ func (s *ShapeIndex) clipVAxis(edge *clippedEdge, middle r1.Interval) (a, b *cli
 // and/or "tracker", and then delete this cell from the index. If edges includes
 // any edges that are being removed, this method also updates their
 // InteriorTracker state to correspond to the exit vertex of this cell.
-func (s *ShapeIndex) absorbIndexCell(p *PaddedCell, iter *ShapeIndexIterator, edges []*clippedEdge, t *tracker) {
+// The updated set of edges is returned.
+func (s *ShapeIndex) absorbIndexCell(p *PaddedCell, iter *ShapeIndexIterator, edges []*clippedEdge, t *tracker, b *cellBuffer) []*clippedEdge {
 	// When we absorb a cell, we erase all the edges that are being removed.
 	// However when we are finished with this cell, we want to restore the state
 	// of those edges (since that is how we find all the index cells that need
@@ -1445,7 +2073,7 @@ func (s *ShapeIndex) absorbIndexCell(p *PaddedCell, iter *ShapeIndexIterator, ed
 			edgeID := clipped.edges[i]
 			edge.edgeID = edgeID
 			edge.edge = shape.Edge(edgeID)
-			edge.MaxLevel = maxLevelForEdge(edge.edge)
+			edge.MaxLevel = s.maxLevelForEdge(edge.edge)
 			if edge.hasInterior {
 				t.testEdge(shapeID, edge.edge)
 			}
@@ -1454,7 +2082,9 @@ func (s *ShapeIndex) absorbIndexCell(p *PaddedCell, iter *ShapeIndexIterator, ed
 			if !ok {
 				panic("invariant failure in ShapeIndex")
 			}
-			faceEdges = append(faceEdges, edge)
+			// Each clipped edge refers to its own copy of the faceEdge.
+			fe := *edge
+			faceEdges = append(faceEdges, &fe)
 		}
 	}
 	// Now create a clippedEdge for each faceEdge, and put them in "new_edges".
@@ -1476,12 +2106,9 @@ func (s *ShapeIndex) absorbIndexCell(p *PaddedCell, iter *ShapeIndexIterator, ed
 		}
 	}
 
-	// Update the edge list and delete this cell from the index.
-	// TODO(rsned): Figure out best fix for this. Linters are
-	// flagging the swap because newEdges is no longer used after
-	// this.
-	edges, newEdges = newEdges, edges // nolint
-	delete(s.cellMap, p.id)
+	// Delete this cell from the index and return the updated edge list.
+	b.deleted = append(b.deleted, p.id)
+	return newEdges
 }
 
 // testAllEdges calls the trackers testEdge on all edges from shapes that have interiors.
@@ -1529,12 +2156,19 @@ func (s *ShapeIndex) countShapes(edges []*clippedEdge, shapeIDs []int32) int {
 	return count
 }
 
+// maxShortEdges returns the maximum number of short edges that a cell with
+// the given number of edges and containing shapes may have before it must
+// be subdivided.
+func (s *ShapeIndex) maxShortEdges(numEdgesAndShapes int) int {
+	return max(s.maxEdgesPerCell, int(s.minShortEdgeFraction*float64(numEdgesAndShapes)))
+}
+
 // maxLevelForEdge reports the maximum level for a given edge.
-func maxLevelForEdge(edge Edge) int {
+func (s *ShapeIndex) maxLevelForEdge(edge Edge) int {
 	// Compute the maximum cell size for which this edge is considered long.
 	// The calculation does not need to be perfectly accurate, so we use Norm
 	// rather than Angle for speed.
-	cellSize := edge.V0.Sub(edge.V1.Vector).Norm() * cellSizeToLongEdgeRatio
+	cellSize := edge.V0.Sub(edge.V1.Vector).Norm() * s.cellSizeToLongEdgeRatio
 	// Now return the first level encountered during subdivision where the
 	// average cell size is at most cellSize.
 	return AvgEdgeMetric.MinLevel(cellSize)
@@ -1542,5 +2176,17 @@ func maxLevelForEdge(edge Edge) int {
 
 // removeShapeInternal does the actual work for removing a given shape from the index.
 func (s *ShapeIndex) removeShapeInternal(removed *removedShape, allEdges [][]faceEdge, t *tracker) {
-	// TODO(roberts): finish the implementation of this.
+	faceEdge := faceEdge{
+		edgeID:      -1, // Not used or needed for removed edges.
+		shapeID:     removed.shapeID,
+		hasInterior: removed.hasInterior,
+	}
+	if faceEdge.hasInterior {
+		t.addShape(removed.shapeID, removed.containsTrackerOrigin)
+	}
+	for _, edge := range removed.edges {
+		faceEdge.edge = edge
+		faceEdge.MaxLevel = s.maxLevelForEdge(edge)
+		s.addFaceEdge(faceEdge, allEdges)
+	}
 }
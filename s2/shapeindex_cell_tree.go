@@ -0,0 +1,315 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package s2
+
+import (
+	"slices"
+	"sort"
+)
+
+const (
+	// cellTreeMaxEntries is the maximum number of entries (for leaves) or
+	// children (for interior nodes) in a cellTree node.
+	cellTreeMaxEntries = 64
+
+	// cellTreeMinEntries is the size below which a node is merged with one of
+	// its siblings when entries are deleted, provided the result fits in a
+	// single node.
+	cellTreeMinEntries = cellTreeMaxEntries / 4
+)
+
+// cellTree is an in-memory B+ tree that maps CellIDs to ShapeIndexCells and
+// keeps them in increasing order of CellID. It is the cell store used by
+// ShapeIndex.
+//
+// All entries are kept in the leaves, and the leaves are linked together so
+// that an iterator can step to the next or previous cell in constant time.
+// Inserting, deleting, and locating a cell all take time logarithmic in the
+// number of cells, so an incremental update to the index costs time
+// proportional to the number of index cells it touches rather than to the
+// size of the whole index.
+//
+// In C++, this is the absl::btree_map used by MutableS2ShapeIndex.
+type cellTree struct {
+	root *cellTreeNode
+	len  int
+}
+
+// cellTreeNode is a node of a cellTree. Leaf nodes hold the entries, while
+// interior nodes hold their children along with the smallest CellID in each
+// child's subtree. Apart from an empty tree (which has no root), every node
+// is non-empty.
+type cellTreeNode struct {
+	ids      []CellID
+	cells    []*ShapeIndexCell // Leaf nodes only.
+	children []*cellTreeNode   // Interior nodes only.
+
+	// The adjacent leaves in CellID order. Leaf nodes only.
+	prev, next *cellTreeNode
+}
+
+func (n *cellTreeNode) isLeaf() bool { return n.children == nil }
+
+// childIndex returns the index of the child of the interior node n whose
+// subtree should contain the given id.
+func (n *cellTreeNode) childIndex(id CellID) int {
+	i := sort.Search(len(n.ids), func(i int) bool { return n.ids[i] > id }) - 1
+	if i < 0 {
+		return 0
+	}
+	return i
+}
+
+// search returns the index of the first entry of the leaf n whose id is >= id.
+func (n *cellTreeNode) search(id CellID) int {
+	return sort.Search(len(n.ids), func(i int) bool { return n.ids[i] >= id })
+}
+
+// first returns the leaf containing the smallest entry, or nil if the tree is empty.
+func (t *cellTree) first() *cellTreeNode {
+	n := t.root
+	for n != nil && !n.isLeaf() {
+		n = n.children[0]
+	}
+	return n
+}
+
+// last returns the leaf containing the largest entry, or nil if the tree is empty.
+func (t *cellTree) last() *cellTreeNode {
+	n := t.root
+	for n != nil && !n.isLeaf() {
+		n = n.children[len(n.children)-1]
+	}
+	return n
+}
+
+// lowerBound returns the leaf and position within that leaf of the first entry
+// whose id is >= target. If there is no such entry, the returned leaf is nil.
+func (t *cellTree) lowerBound(target CellID) (*cellTreeNode, int) {
+	n := t.root
+	if n == nil {
+		return nil, 0
+	}
+	for !n.isLeaf() {
+		n = n.children[n.childIndex(target)]
+	}
+	i := n.search(target)
+	if i == len(n.ids) {
+		// All entries of the next leaf are larger than target.
+		return n.next, 0
+	}
+	return n, i
+}
+
+// get returns the cell stored for the given id, or nil if there is none.
+func (t *cellTree) get(id CellID) *ShapeIndexCell {
+	if n, i := t.lowerBound(id); n != nil && n.ids[i] == id {
+		return n.cells[i]
+	}
+	return nil
+}
+
+// set stores the cell for the given id, replacing any existing entry.
+func (t *cellTree) set(id CellID, cell *ShapeIndexCell) {
+	if t.root == nil {
+		t.root = &cellTreeNode{ids: []CellID{id}, cells: []*ShapeIndexCell{cell}}
+		t.len = 1
+		return
+	}
+	if split := t.insert(t.root, id, cell); split != nil {
+		t.root = &cellTreeNode{
+			ids:      []CellID{t.root.ids[0], split.ids[0]},
+			children: []*cellTreeNode{t.root, split},
+		}
+	}
+}
+
+// insert adds or replaces the entry in the subtree rooted at n. If this makes
+// n overflow, n is split in two and the new right half is returned.
+func (t *cellTree) insert(n *cellTreeNode, id CellID, cell *ShapeIndexCell) *cellTreeNode {
+	if n.isLeaf() {
+		i := n.search(id)
+		if i < len(n.ids) && n.ids[i] == id {
+			n.cells[i] = cell
+			return nil
+		}
+		n.ids = slices.Insert(n.ids, i, id)
+		n.cells = slices.Insert(n.cells, i, cell)
+		t.len++
+		if len(n.ids) <= cellTreeMaxEntries {
+			return nil
+		}
+
+		right := &cellTreeNode{prev: n, next: n.next}
+		mid := splitPoint(i, len(n.ids))
+		right.ids = append([]CellID(nil), n.ids[mid:]...)
+		right.cells = append([]*ShapeIndexCell(nil), n.cells[mid:]...)
+		clear(n.cells[mid:])
+		n.ids = n.ids[:mid:mid]
+		n.cells = n.cells[:mid:mid]
+		if n.next != nil {
+			n.next.prev = right
+		}
+		n.next = right
+		return right
+	}
+
+	i := n.childIndex(id)
+	split := t.insert(n.children[i], id, cell)
+	n.ids[i] = n.children[i].ids[0]
+	if split == nil {
+		return nil
+	}
+	n.ids = slices.Insert(n.ids, i+1, split.ids[0])
+	n.children = slices.Insert(n.children, i+1, split)
+	if len(n.children) <= cellTreeMaxEntries {
+		return nil
+	}
+
+	right := &cellTreeNode{}
+	mid := splitPoint(i+1, len(n.children))
+	right.ids = append([]CellID(nil), n.ids[mid:]...)
+	right.children = append([]*cellTreeNode(nil), n.children[mid:]...)
+	clear(n.children[mid:])
+	n.ids = n.ids[:mid:mid]
+	n.children = n.children[:mid:mid]
+	return right
+}
+
+// splitPoint returns where to split an overflowing node of size n whose
+// entry at position i was just inserted. Nodes are normally split in half,
+// but when the insertion was at the end of the node (as happens for every
+// node when an index is first built, since cells are created in increasing
+// CellID order) the left node is left full so that the tree stays compact.
+func splitPoint(i, n int) int {
+	if i == n-1 {
+		return n - 1
+	}
+	return n / 2
+}
+
+// delete removes the entry for the given id and reports whether it was present.
+func (t *cellTree) delete(id CellID) bool {
+	if t.root == nil || !t.remove(t.root, id) {
+		return false
+	}
+	t.len--
+	for !t.root.isLeaf() && len(t.root.children) == 1 {
+		t.root = t.root.children[0]
+	}
+	if len(t.root.ids) == 0 {
+		t.root = nil
+	}
+	return true
+}
+
+// remove deletes the entry for the given id from the subtree rooted at n,
+// and reports whether it was present.
+func (t *cellTree) remove(n *cellTreeNode, id CellID) bool {
+	if n.isLeaf() {
+		i := n.search(id)
+		if i == len(n.ids) || n.ids[i] != id {
+			return false
+		}
+		n.ids = slices.Delete(n.ids, i, i+1)
+		n.cells = slices.Delete(n.cells, i, i+1)
+		return true
+	}
+
+	i := n.childIndex(id)
+	if !t.remove(n.children[i], id) {
+		return false
+	}
+	n.rebalance(i)
+	return true
+}
+
+// rebalance restores the invariants of the interior node n after an entry was
+// removed from the subtree of its i-th child. Empty children are dropped, and
+// small children are merged with an adjacent sibling when they fit in one node.
+func (n *cellTreeNode) rebalance(i int) {
+	child := n.children[i]
+	if len(child.ids) == 0 {
+		if child.isLeaf() {
+			unlinkLeaf(child)
+		}
+		n.ids = slices.Delete(n.ids, i, i+1)
+		n.children = slices.Delete(n.children, i, i+1)
+		return
+	}
+	n.ids[i] = child.ids[0]
+	if len(child.ids) >= cellTreeMinEntries || len(n.children) == 1 {
+		return
+	}
+
+	// Merge the child with its left sibling if there is one, otherwise
+	// with its right sibling.
+	left := i - 1
+	if left < 0 {
+		left = 0
+	}
+	a, b := n.children[left], n.children[left+1]
+	if len(a.ids)+len(b.ids) > cellTreeMaxEntries {
+		return
+	}
+	a.ids = append(a.ids, b.ids...)
+	if a.isLeaf() {
+		a.cells = append(a.cells, b.cells...)
+		unlinkLeaf(b)
+	} else {
+		a.children = append(a.children, b.children...)
+	}
+	n.ids = slices.Delete(n.ids, left+1, left+2)
+	n.children = slices.Delete(n.children, left+1, left+2)
+}
+
+// unlinkLeaf removes the given leaf from the list of leaves.
+func unlinkLeaf(n *cellTreeNode) {
+	if n.prev != nil {
+		n.prev.next = n.next
+	}
+	if n.next != nil {
+		n.next.prev = n.prev
+	}
+	n.prev, n.next = nil, nil
+}
+
+// clone returns a copy of the tree that shares the cells with t but none of
+// the nodes, so that either tree can be modified without affecting the other.
+func (t *cellTree) clone() cellTree {
+	var prev *cellTreeNode
+	var cloneNode func(n *cellTreeNode) *cellTreeNode
+	cloneNode = func(n *cellTreeNode) *cellTreeNode {
+		c := &cellTreeNode{ids: slices.Clone(n.ids)}
+		if n.isLeaf() {
+			c.cells = slices.Clone(n.cells)
+			c.prev = prev
+			if prev != nil {
+				prev.next = c
+			}
+			prev = c
+			return c
+		}
+		c.children = make([]*cellTreeNode, len(n.children))
+		for i, child := range n.children {
+			c.children[i] = cloneNode(child)
+		}
+		return c
+	}
+	if t.root == nil {
+		return cellTree{}
+	}
+	return cellTree{root: cloneNode(t.root), len: t.len}
+}
@@ -0,0 +1,307 @@
+// Copyright 2026 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package s2
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/golang/geo/s1"
+)
+
+// checkCellTree verifies that the tree holds exactly the entries of want, in
+// order, and that the leaf links and interior keys are consistent.
+func checkCellTree(t *testing.T, tree *cellTree, want map[CellID]*ShapeIndexCell) {
+	t.Helper()
+	var ids []CellID
+	for id := range want {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	if tree.len != len(ids) {
+		t.Fatalf("tree.len = %d, want %d", tree.len, len(ids))
+	}
+
+	// Walk the leaves forwards.
+	i := 0
+	var prev *cellTreeNode
+	for n := tree.first(); n != nil; n = n.next {
+		if n.prev != prev {
+			t.Fatalf("leaf.prev link is inconsistent")
+		}
+		if len(n.ids) == 0 {
+			t.Fatalf("tree has an empty leaf")
+		}
+		for j, id := range n.ids {
+			if i >= len(ids) || id != ids[i] || n.cells[j] != want[id] {
+				t.Fatalf("entry %d of tree = %v, want %v", i, id, ids[i])
+			}
+			i++
+		}
+		prev = n
+	}
+	if i != len(ids) {
+		t.Fatalf("tree has %d entries, want %d", i, len(ids))
+	}
+	if prev != tree.last() {
+		t.Fatalf("tree.last() is not the last leaf")
+	}
+
+	// Check that every interior key is the minimum of its subtree.
+	var checkNode func(n *cellTreeNode) CellID
+	checkNode = func(n *cellTreeNode) CellID {
+		if n.isLeaf() {
+			return n.ids[0]
+		}
+		if len(n.ids) != len(n.children) {
+			t.Fatalf("interior node has %d keys and %d children", len(n.ids), len(n.children))
+		}
+		for k, child := range n.children {
+			if got := checkNode(child); got != n.ids[k] {
+				t.Fatalf("interior key %v, want subtree minimum %v", n.ids[k], got)
+			}
+		}
+		return n.ids[0]
+	}
+	if tree.root != nil {
+		checkNode(tree.root)
+	}
+}
+
+func TestCellTreeEmpty(t *testing.T) {
+	var tree cellTree
+	if n, _ := tree.lowerBound(CellIDFromFace(0)); n != nil {
+		t.Errorf("lowerBound on an empty tree should return nil")
+	}
+	if tree.first() != nil || tree.last() != nil {
+		t.Errorf("first and last of an empty tree should be nil")
+	}
+	if tree.delete(CellIDFromFace(0)) {
+		t.Errorf("delete on an empty tree should return false")
+	}
+	if got := tree.get(CellIDFromFace(0)); got != nil {
+		t.Errorf("get on an empty tree = %v, want nil", got)
+	}
+}
+
+func TestCellTreeRandomOperations(t *testing.T) {
+	var tree cellTree
+	want := make(map[CellID]*ShapeIndexCell)
+	var ids []CellID
+
+	for i := 0; i < 20000; i++ {
+		if len(ids) > 0 && oneIn(3) {
+			k := randomUniformInt(len(ids))
+			id := ids[k]
+			ids[k] = ids[len(ids)-1]
+			ids = ids[:len(ids)-1]
+			if !tree.delete(id) {
+				t.Fatalf("tree.delete(%v) = false, want true", id)
+			}
+			delete(want, id)
+			if tree.delete(id) {
+				t.Fatalf("deleting %v twice should fail", id)
+			}
+			continue
+		}
+		// Use a small range of levels so that ids are sometimes replaced.
+		id := randomCellIDForLevel(5)
+		cell := NewShapeIndexCell(0)
+		if _, ok := want[id]; !ok {
+			ids = append(ids, id)
+		}
+		tree.set(id, cell)
+		want[id] = cell
+	}
+	checkCellTree(t, &tree, want)
+
+	for _, id := range ids {
+		if got := tree.get(id); got != want[id] {
+			t.Errorf("tree.get(%v) = %p, want %p", id, got, want[id])
+		}
+		// The lower bound of an entry is the entry itself.
+		n, i := tree.lowerBound(id)
+		if n == nil || n.ids[i] != id {
+			t.Errorf("tree.lowerBound(%v) did not find the entry", id)
+		}
+	}
+
+	// Delete everything, checking the structure along the way.
+	for k, id := range ids {
+		tree.delete(id)
+		delete(want, id)
+		if k%997 == 0 {
+			checkCellTree(t, &tree, want)
+		}
+	}
+	checkCellTree(t, &tree, want)
+	if tree.root != nil {
+		t.Errorf("tree.root should be nil once all entries are deleted")
+	}
+}
+
+func TestCellTreeClone(t *testing.T) {
+	var tree cellTree
+	want := make(map[CellID]*ShapeIndexCell)
+	id := CellIDFromFace(2).ChildBeginAtLevel(8)
+	for i := 0; i < 5*cellTreeMaxEntries; i++ {
+		cell := NewShapeIndexCell(0)
+		tree.set(id, cell)
+		want[id] = cell
+		id = id.Next()
+	}
+
+	clone := tree.clone()
+	checkCellTree(t, &clone, want)
+
+	// Modifying either tree leaves the other unchanged.
+	cloneWant := make(map[CellID]*ShapeIndexCell)
+	for id, cell := range want {
+		cloneWant[id] = cell
+	}
+	for id := range want {
+		if id.Pos()%3 == 0 {
+			tree.delete(id)
+			delete(want, id)
+		}
+	}
+	extra := CellIDFromFace(4)
+	clone.set(extra, nil)
+	cloneWant[extra] = nil
+
+	checkCellTree(t, &tree, want)
+	checkCellTree(t, &clone, cloneWant)
+}
+
+func TestCellTreeSequentialInsertIsCompact(t *testing.T) {
+	// Cells are added in increasing order when an index is first built, so
+	// every leaf but the last should be full.
+	var tree cellTree
+	const n = 10 * cellTreeMaxEntries
+	id := CellIDFromFace(0).ChildBeginAtLevel(10)
+	for i := 0; i < n; i++ {
+		tree.set(id, nil)
+		id = id.Next()
+	}
+	leaves := 0
+	for l := tree.first(); l != nil; l = l.next {
+		leaves++
+	}
+	if want := (n + cellTreeMaxEntries - 1) / cellTreeMaxEntries; leaves != want {
+		t.Errorf("tree has %d leaves after sequential inserts, want %d", leaves, want)
+	}
+}
+
+// sortedSliceCellStore is the cell store the ShapeIndex used before the
+// cellTree: a map from CellID to cell plus a separately maintained sorted
+// slice of CellIDs. It is kept here to compare against in the benchmarks.
+type sortedSliceCellStore struct {
+	cellMap map[CellID]*ShapeIndexCell
+	cells   []CellID
+}
+
+func (s *sortedSliceCellStore) set(id CellID, cell *ShapeIndexCell) {
+	if _, ok := s.cellMap[id]; !ok {
+		i := sort.Search(len(s.cells), func(i int) bool { return s.cells[i] >= id })
+		s.cells = append(s.cells, 0)
+		copy(s.cells[i+1:], s.cells[i:])
+		s.cells[i] = id
+	}
+	s.cellMap[id] = cell
+}
+
+func (s *sortedSliceCellStore) delete(id CellID) {
+	if _, ok := s.cellMap[id]; !ok {
+		return
+	}
+	delete(s.cellMap, id)
+	i := sort.Search(len(s.cells), func(i int) bool { return s.cells[i] >= id })
+	s.cells = append(s.cells[:i], s.cells[i+1:]...)
+}
+
+// cellStore is the set of operations used by the benchmarks below.
+type cellStore interface {
+	set(id CellID, cell *ShapeIndexCell)
+	delete(id CellID)
+}
+
+// cellTreeStore adapts cellTree to the cellStore interface.
+type cellTreeStore struct{ cellTree }
+
+func (s *cellTreeStore) delete(id CellID) { s.cellTree.delete(id) }
+
+// benchmarkLocalizedUpdates measures the cost of replacing one index cell by
+// its four children and back again, which is the typical shape of an
+// incremental index update, in a store holding numCells cells.
+func benchmarkLocalizedUpdates(b *testing.B, store cellStore, numCells int) {
+	level := 1
+	for 6*(1<<(2*level)) < numCells {
+		level++
+	}
+	var ids []CellID
+	for id := CellIDFromFace(0).ChildBeginAtLevel(level); len(ids) < numCells; id = id.Next() {
+		store.set(id, nil)
+		ids = append(ids, id)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		id := ids[randomUniformInt(len(ids))]
+		store.delete(id)
+		for _, child := range id.Children() {
+			store.set(child, nil)
+		}
+		for _, child := range id.Children() {
+			store.delete(child)
+		}
+		store.set(id, nil)
+	}
+}
+
+func BenchmarkCellStoreLocalizedUpdates(b *testing.B) {
+	for _, n := range []int{1e4, 1e5, 1e6} {
+		b.Run(fmt.Sprintf("cellTree/%d", n), func(b *testing.B) {
+			benchmarkLocalizedUpdates(b, &cellTreeStore{}, n)
+		})
+		b.Run(fmt.Sprintf("mapAndSortedSlice/%d", n), func(b *testing.B) {
+			benchmarkLocalizedUpdates(b, &sortedSliceCellStore{cellMap: make(map[CellID]*ShapeIndexCell)}, n)
+		})
+	}
+}
+
+func BenchmarkShapeIndexIncrementalUpdate(b *testing.B) {
+	// An index with many cells, to which a small shape is repeatedly added and
+	// then removed again.
+	for _, numShapes := range []int{100, 1000} {
+		b.Run(fmt.Sprintf("shapes=%d", numShapes), func(b *testing.B) {
+			index := NewShapeIndex()
+			for i := 0; i < numShapes; i++ {
+				index.Add(RegularLoop(randomPoint(), s1.Degree, 50))
+			}
+			index.Build()
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				loop := RegularLoop(randomPoint(), s1.Degree/10, 10)
+				index.Add(loop)
+				index.Build()
+				index.Remove(loop)
+				index.Build()
+			}
+		})
+	}
+}
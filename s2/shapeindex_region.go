@@ -95,7 +95,7 @@ func (s *ShapeIndexRegion) CellUnionBound() []CellID {
 			// Find the range of index cells contained by C and then shrink C so
 			// that it just covers those cells.
 			first := s.iter.CellID()
-			s.iter.seek(id.RangeMax().Next())
+			s.iter.Seek(id.RangeMax().Next())
 			s.iter.Prev()
 			cellIDs = s.coverRange(first, s.iter.CellID(), cellIDs)
 			s.iter.Next()
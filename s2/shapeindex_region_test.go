@@ -22,13 +22,13 @@ import (
 const shapeIndexCellPadding = 2 * (faceClipErrorUVCoord + intersectsRectErrorUVDist)
 
 func padCell(id CellID, paddingUV float64) Shape {
-	face, i, j, _ := id.faceIJOrientation()
+	face, i, j, _ := id.FaceIJOrientation()
 
 	uv := ijLevelToBoundUV(i, j, id.Level()).ExpandedByMargin(paddingUV)
 
 	vertices := make([]Point, 4)
 	for i, v := range uv.Vertices() {
-		vertices[i] = Point{faceUVToXYZ(face, v.X, v.Y).Normalize()}
+		vertices[i] = Point{FaceUVToXYZ(face, v.X, v.Y).Normalize()}
 	}
 
 	return LaxLoopFromPoints(vertices)
@@ -15,6 +15,11 @@
 package s2
 
 import (
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+	"sync"
 	"testing"
 
 	"github.com/golang/geo/r3"
@@ -129,7 +134,7 @@ func validateEdge(t *testing.T, a, b Point, ci CellID, hasEdge bool) {
 		sign = -1
 	}
 	padding += sign * intersectsRectErrorUVDist
-	bound := ci.boundUV().ExpandedByMargin(padding)
+	bound := ci.BoundUV().ExpandedByMargin(padding)
 	aUV, bUV, ok := ClipToPaddedFace(a, b, ci.Face(), padding)
 
 	if got := ok && edgeIntersectsRect(aUV, bUV, bound); got != hasEdge {
@@ -180,7 +185,8 @@ func quadraticValidate(t *testing.T, index *ShapeIndex) {
 		// Iterate through all the shapes, simultaneously validating the current
 		// index cell and all the skipped cells.
 		shortEdges := 0 // number of edges counted toward subdivision
-		for id, shape := range index.shapes {
+		for id := int32(0); id < index.nextID; id++ {
+			shape := index.Shape(id)
 			for j := 0; j < len(skipped); j++ {
 				validateInterior(t, shape, skipped[j], false)
 			}
@@ -209,15 +215,25 @@ func quadraticValidate(t *testing.T, index *ShapeIndex) {
 				if !it.Done() {
 					hasEdge := clipped != nil && clipped.containsEdge(e)
 					validateEdge(t, edge.V0, edge.V1, it.CellID(), hasEdge)
-					if hasEdge && it.CellID().Level() < maxLevelForEdge(edge) {
+					if hasEdge && it.CellID().Level() < index.maxLevelForEdge(edge) {
 						shortEdges++
 					}
 				}
 			}
 		}
 
-		if shortEdges > index.maxEdgesPerCell {
-			t.Errorf("too many edges")
+		// The number of short edges allowed depends on the number of edges
+		// and containing shapes in the cell. Every shape that contains the
+		// cell's entry vertex is present in the cell, so the number of edges
+		// plus the number of shapes is an upper bound for it.
+		if !it.Done() {
+			cellSize := 0
+			for _, clipped := range it.IndexCell().shapes {
+				cellSize += 1 + clipped.numEdges()
+			}
+			if limit := index.maxShortEdges(cellSize); shortEdges > limit {
+				t.Errorf("cell %v has %d short edges, want <= %d", it.CellID(), shortEdges, limit)
+			}
 		}
 
 		if it.Done() {
@@ -230,6 +246,8 @@ func quadraticValidate(t *testing.T, index *ShapeIndex) {
 func copyIterator(i *ShapeIndexIterator) *ShapeIndexIterator {
 	s := &ShapeIndexIterator{
 		index:    i.index,
+		version:  i.version,
+		leaf:     i.leaf,
 		position: i.position,
 		id:       i.id,
 		cell:     i.cell,
@@ -267,7 +285,7 @@ func testIteratorMethods(t *testing.T, index *ShapeIndex) {
 				t.Errorf("CellID location should be Disjoint for non-existent entry, got %v", got)
 			}
 			it2.Begin()
-			it2.seek(skipped[i])
+			it2.Seek(skipped[i])
 			if ci != it2.CellID() {
 				t.Errorf("seeking the current cell in the skipped list should match the current cellid. got %v, want %v", it2.CellID(), ci)
 			}
@@ -291,7 +309,7 @@ func testIteratorMethods(t *testing.T, index *ShapeIndex) {
 				t.Errorf("advancing back one spot should give us the current cell")
 			}
 
-			it2.seek(prevCell)
+			it2.Seek(prevCell)
 			if prevCell != it2.CellID() {
 				t.Errorf("seek from beginning for the first previous cell %v should not give us the current cell %v", prevCell, it.CellID())
 			}
@@ -546,9 +564,623 @@ func TestShapeIndexNumEdgesUpTo(t *testing.T) {
 	}
 }
 
+func TestShapeIndexSimpleUpdates(t *testing.T) {
+	// Add 5 loops one at a time, then remove them one at a time, validating
+	// the index at each step.
+	index := NewShapeIndex()
+	var loops []*Loop
+	for i := 0; i < 5; i++ {
+		loop := RegularLoop(randomPoint(), s1.Degree*5, 20)
+		loops = append(loops, loop)
+		index.Add(loop)
+		quadraticValidate(t, index)
+	}
+	for _, loop := range loops {
+		index.Remove(loop)
+		quadraticValidate(t, index)
+	}
+	if !index.Begin().Done() {
+		t.Errorf("index should have no cells after removing every shape")
+	}
+}
+
+func TestShapeIndexRandomUpdates(t *testing.T) {
+	// Add a mixture of polygons, polylines, and points, then repeatedly add
+	// and remove random subsets of them, validating the index after each
+	// batch of updates.
+	index := NewShapeIndex()
+	var shapes []Shape
+	for i := 0; i < 8; i++ {
+		center := randomPoint()
+		switch i % 3 {
+		case 0:
+			shapes = append(shapes, PolygonFromLoops([]*Loop{RegularLoop(center, s1.Degree*10, 30)}))
+		case 1:
+			line := Polyline(RegularLoop(center, s1.Degree*3, 15).Vertices())
+			shapes = append(shapes, &line)
+		default:
+			pts := PointVector{center, randomPoint(), randomPoint()}
+			shapes = append(shapes, &pts)
+		}
+	}
+
+	inIndex := make([]bool, len(shapes))
+	for iter := 0; iter < 10; iter++ {
+		for i, shape := range shapes {
+			if !oneIn(3) {
+				continue
+			}
+			if inIndex[i] {
+				index.Remove(shape)
+			} else {
+				index.Add(shape)
+			}
+			inIndex[i] = !inIndex[i]
+		}
+		quadraticValidate(t, index)
+		testIteratorMethods(t, index)
+	}
+}
+
+// checkUpdateBatches checks that each batch of the pending updates of the
+// given index is expected to fit within its memory budget. Only a batch that
+// consists of a single shape or of the pending removals alone may exceed it.
+func checkUpdateBatches(t *testing.T, index *ShapeIndex) {
+	t.Helper()
+	removalEdges := 0
+	for _, r := range index.pendingRemovals {
+		removalEdges += len(r.edges)
+	}
+
+	begin := index.pendingAdditionsPos
+	for i, end := range index.updateBatches() {
+		edges, parts := 0, 0
+		if i == 0 && removalEdges > 0 {
+			edges, parts = removalEdges, 1
+		}
+		for id := begin; id < end; id++ {
+			if shape := index.shapes[id]; shape != nil {
+				edges += shape.NumEdges()
+				parts++
+			}
+		}
+		if tmpBytes := int64(edges) * tmpBytesPerEdge; tmpBytes > index.memoryBudget && parts > 1 {
+			t.Errorf("batch %d uses about %d temporary bytes, want <= %d", i, tmpBytes, index.memoryBudget)
+		}
+		begin = end
+	}
+}
+
+// checkIndexesEquivalent checks that the two indexes, which must contain the
+// same shapes with the same IDs, give the same query results for random
+// points and edges. Indexes built in different batches need not have
+// identical cells, since cells subdivided by an earlier batch are not merged
+// again by later ones.
+func checkIndexesEquivalent(t *testing.T, r *rand.Rand, want, got *ShapeIndex) {
+	t.Helper()
+	wantContains := NewContainsPointQuery(want, VertexModelSemiOpen)
+	gotContains := NewContainsPointQuery(got, VertexModelSemiOpen)
+	wantCrossings := NewCrossingEdgeQuery(want)
+	gotCrossings := NewCrossingEdgeQuery(got)
+	for i := 0; i < 1000; i++ {
+		p := randomPoint(r)
+		if w, g := wantContains.ContainingShapes(p), gotContains.ContainingShapes(p); !reflect.DeepEqual(w, g) {
+			t.Errorf("ContainingShapes(%v) = %v, want %v", p, g, w)
+		}
+
+		q := InterpolateAtDistance(s1.Degree*20*s1.Angle(randomFloat64(r)), p, randomPoint(r))
+		w := wantCrossings.CrossingsEdgeMap(p, q, CrossingTypeAll)
+		g := gotCrossings.CrossingsEdgeMap(p, q, CrossingTypeAll)
+		if len(w) != len(g) || (len(w) > 0 && !reflect.DeepEqual(w, g)) {
+			t.Errorf("CrossingsEdgeMap(%v, %v) = %v, want %v", p, q, g, w)
+		}
+	}
+}
+
+// numClippedEdges returns the total number of edges stored in the index
+// cells, which dominates the size of the index.
+func numClippedEdges(index *ShapeIndex) int {
+	n := 0
+	for it := index.Iterator(); !it.Done(); it.Next() {
+		for _, clipped := range it.IndexCell().shapes {
+			n += clipped.numEdges()
+		}
+	}
+	return n
+}
+
+func TestShapeIndexOptionsMaxEdgesPerCell(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	points := make([]Point, 1000)
+	for i := range points {
+		points[i] = randomPoint(r)
+	}
+
+	prevCells := 0
+	for _, maxEdges := range []int{100, 10, 1} {
+		index := NewShapeIndexWithOptions(NewShapeIndexOptions().MaxEdgesPerCell(maxEdges))
+		pv := PointVector(points)
+		index.Add(&pv)
+		quadraticValidate(t, index)
+		for it := index.Iterator(); !it.Done(); it.Next() {
+			if n := it.IndexCell().numEdges(); n > maxEdges {
+				t.Errorf("with MaxEdgesPerCell(%d), cell %v has %d points", maxEdges, it.CellID(), n)
+			}
+		}
+		if numCells := index.cells.len; numCells <= prevCells {
+			t.Errorf("with MaxEdgesPerCell(%d), index has %d cells, want more than %d", maxEdges, numCells, prevCells)
+		} else {
+			prevCells = numCells
+		}
+	}
+}
+
+func TestShapeIndexOptionsCellSizeToLongEdgeRatio(t *testing.T) {
+	// Edges crossing a small area are subdivided further when the ratio is
+	// smaller, since they then count as short edges in smaller cells.
+	r := rand.New(rand.NewSource(1))
+	center := randomPoint(r)
+	var edges []Edge
+	for i := 0; i < 100; i++ {
+		a := samplePointFromCap(CapFromCenterAngle(center, s1.Degree), r)
+		edges = append(edges, Edge{a, Point{a.Mul(-1).Add(center.Mul(1.9)).Normalize()}})
+	}
+
+	small := NewShapeIndexWithOptions(NewShapeIndexOptions().CellSizeToLongEdgeRatio(0.25))
+	large := NewShapeIndexWithOptions(NewShapeIndexOptions().CellSizeToLongEdgeRatio(1))
+	for _, index := range []*ShapeIndex{small, large} {
+		index.Add(&edgeVectorShape{edges: edges})
+		quadraticValidate(t, index)
+	}
+	if small.cells.len <= large.cells.len {
+		t.Errorf("index with ratio 0.25 has %d cells, want more than the %d of ratio 1", small.cells.len, large.cells.len)
+	}
+
+	// Ratios that are not positive and finite are treated as the default.
+	for _, ratio := range []float64{0, -1, math.NaN(), math.Inf(1)} {
+		opts := NewShapeIndexOptions().CellSizeToLongEdgeRatio(ratio)
+		if got, want := opts.cellSizeToLongEdgeRatio, defaultCellSizeToLongEdgeRatio; got != want {
+			t.Errorf("CellSizeToLongEdgeRatio(%v) set the ratio to %v, want %v", ratio, got, want)
+		}
+	}
+}
+
+func TestShapeIndexOptionsMinShortEdgeFraction(t *testing.T) {
+	// A cluster of tiny edges surrounded by many long edges that pass close
+	// to it. Without the short edge fraction, the cells around the cluster
+	// are subdivided until the tiny edges are separated, and every level of
+	// subdivision copies all of the long edges.
+	r := rand.New(rand.NewSource(1))
+	center := randomPoint(r)
+	var edges []Edge
+	for i := 0; i < 20; i++ {
+		a := samplePointFromCap(CapFromCenterAngle(center, 1e-9), r)
+		b := samplePointFromCap(CapFromCenterAngle(a, 1e-10), r)
+		edges = append(edges, Edge{a, b})
+	}
+	for i := 0; i < 200; i++ {
+		a := samplePointFromCap(CapFromCenterAngle(center, 1e-8), r)
+		d := randomPoint(r)
+		dir := Point{center.Cross(d.Vector).Normalize()}
+		edges = append(edges, Edge{
+			Point{a.Add(dir.Mul(0.5)).Normalize()},
+			Point{a.Sub(dir.Mul(0.5)).Normalize()},
+		})
+	}
+
+	var sizes []int
+	for _, fraction := range []float64{0, 0.2} {
+		index := NewShapeIndexWithOptions(NewShapeIndexOptions().MinShortEdgeFraction(fraction))
+		index.Add(&edgeVectorShape{edges: edges})
+		quadraticValidate(t, index)
+		sizes = append(sizes, numClippedEdges(index))
+	}
+	if sizes[1] >= sizes[0] {
+		t.Errorf("index with MinShortEdgeFraction(0.2) has %d clipped edges, want fewer than the %d with 0", sizes[1], sizes[0])
+	}
+
+	// The check is disabled by default, so the default index is subdivided
+	// around the cluster just as with a fraction of 0.
+	index := NewShapeIndex()
+	index.Add(&edgeVectorShape{edges: edges})
+	zero := NewShapeIndexWithOptions(NewShapeIndexOptions().MinShortEdgeFraction(0))
+	zero.Add(&edgeVectorShape{edges: edges})
+	if !indexCellsEqual(index, zero) {
+		t.Errorf("default index differs from the index with MinShortEdgeFraction(0)")
+	}
+	if got := numClippedEdges(index); got != sizes[0] {
+		t.Errorf("default index has %d clipped edges, want %d", got, sizes[0])
+	}
+}
+
+func TestShapeIndexMemoryBudget(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	var shapes []Shape
+	for i := 0; i < 20; i++ {
+		shapes = append(shapes, RegularLoop(randomPoint(r), s1.Degree*10, 50))
+	}
+
+	index := NewShapeIndex()
+	// Budget enough temporary memory for about 120 edges per batch.
+	opts := NewShapeIndexOptions().MemoryBudget(120 * tmpBytesPerEdge)
+	batched := NewShapeIndexWithOptions(opts)
+	for _, shape := range shapes {
+		index.Add(shape)
+		batched.Add(shape)
+	}
+
+	if got, want := len(batched.updateBatches()), 10; got != want {
+		t.Errorf("len(updateBatches()) = %d, want %d", got, want)
+	}
+	if got, want := len(index.updateBatches()), 1; got != want {
+		t.Errorf("len(updateBatches()) without a budget = %d, want %d", got, want)
+	}
+	checkUpdateBatches(t, batched)
+
+	quadraticValidate(t, index)
+	quadraticValidate(t, batched)
+	checkIndexesEquivalent(t, r, index, batched)
+
+	// Removals are applied together with the first batch of additions.
+	batched.Remove(shapes[0])
+	index.Remove(shapes[0])
+	for i := 0; i < 3; i++ {
+		shape := RegularLoop(randomPoint(r), s1.Degree*10, 50)
+		batched.Add(shape)
+		index.Add(shape)
+	}
+	if got, want := len(batched.updateBatches()), 2; got != want {
+		t.Errorf("len(updateBatches()) after removing a shape = %d, want %d", got, want)
+	}
+	checkUpdateBatches(t, batched)
+
+	quadraticValidate(t, batched)
+	checkIndexesEquivalent(t, r, index, batched)
+}
+
+func TestShapeIndexSpaceUsed(t *testing.T) {
+	index := NewShapeIndex()
+	empty := index.SpaceUsed()
+	if empty <= 0 {
+		t.Errorf("SpaceUsed() of an empty index = %d, want > 0", empty)
+	}
+
+	var loops []*Loop
+	prev := empty
+	for i := 0; i < 5; i++ {
+		loop := RegularLoop(randomPoint(), s1.Degree*10, 100)
+		loops = append(loops, loop)
+		index.Add(loop)
+		index.Build()
+		if got := index.SpaceUsed(); got <= prev {
+			t.Errorf("SpaceUsed() after adding shape %d = %d, want > %d", i, got, prev)
+		}
+		prev = index.SpaceUsed()
+	}
+
+	for _, loop := range loops {
+		index.Remove(loop)
+	}
+	index.Build()
+	if got := index.SpaceUsed(); got >= prev {
+		t.Errorf("SpaceUsed() after removing all shapes = %d, want < %d", got, prev)
+	}
+
+	index.Reset()
+	if got := index.SpaceUsed(); got != empty {
+		t.Errorf("SpaceUsed() after Reset = %d, want %d", got, empty)
+	}
+}
+
+// indexCellsEqual reports whether the two indexes have identical cells.
+func indexCellsEqual(a, b *ShapeIndex) bool {
+	itA, itB := a.Iterator(), b.Iterator()
+	for ; !itA.Done() && !itB.Done(); itA.Next() {
+		if itA.CellID() != itB.CellID() || !reflect.DeepEqual(itA.IndexCell(), itB.IndexCell()) {
+			return false
+		}
+		itB.Next()
+	}
+	return itA.Done() && itB.Done()
+}
+
+func TestShapeIndexParallelBuild(t *testing.T) {
+	// A mixture of large polygons that span several faces, polygons with
+	// enough edges that their cells are subdivided concurrently, polylines,
+	// and points.
+	var shapes []Shape
+	shapes = append(shapes, PolygonFromLoops([]*Loop{RegularLoop(randomPoint(), s1.Degree*100, 200)}))
+	for i := 0; i < 3; i++ {
+		shapes = append(shapes, PolygonFromLoops([]*Loop{RegularLoop(randomPoint(), s1.Degree*5, 3*parallelUpdateMinEdges)}))
+	}
+	for i := 0; i < 10; i++ {
+		line := Polyline(RegularLoop(randomPoint(), s1.Degree*20, 50).Vertices())
+		shapes = append(shapes, &line)
+		pts := PointVector{randomPoint(), randomPoint()}
+		shapes = append(shapes, &pts)
+	}
+
+	index := NewShapeIndex()
+	parallel := NewShapeIndexWithOptions(NewShapeIndexOptions().Workers(8))
+	for _, shape := range shapes {
+		index.Add(shape)
+		parallel.Add(shape)
+	}
+	if !indexCellsEqual(index, parallel) {
+		t.Errorf("index built in parallel differs from the index built sequentially")
+	}
+
+	// Incremental updates are also applied to the faces in parallel.
+	for i := 0; i < len(shapes); i += 3 {
+		index.Remove(shapes[i])
+		parallel.Remove(shapes[i])
+	}
+	for i := 0; i < 3; i++ {
+		loop := RegularLoop(randomPoint(), s1.Degree*30, 100)
+		index.Add(loop)
+		parallel.Add(loop)
+	}
+	if !indexCellsEqual(index, parallel) {
+		t.Errorf("index updated in parallel differs from the index updated sequentially")
+	}
+}
+
+func TestShapeIndexConcurrentReads(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	index := NewShapeIndex()
+	for i := 0; i < 20; i++ {
+		index.Add(PolygonFromLoops([]*Loop{RegularLoop(randomPoint(r), s1.Degree*10, 100)}))
+	}
+	points := make([]Point, 500)
+	for i := range points {
+		points[i] = randomPoint(r)
+	}
+
+	// The index has not been built yet, so the goroutines race to apply the
+	// pending updates, and then query the index concurrently.
+	const numReaders = 8
+	results := make([][]bool, numReaders)
+	var wg sync.WaitGroup
+	for g := 0; g < numReaders; g++ {
+		g := g
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			query := NewContainsPointQuery(index, VertexModelSemiOpen)
+			for _, p := range points {
+				results[g] = append(results[g], query.Contains(p))
+			}
+		}()
+	}
+	wg.Wait()
+
+	if !index.IsFresh() {
+		t.Errorf("index.IsFresh() = false after concurrent queries, want true")
+	}
+	query := NewContainsPointQuery(index, VertexModelSemiOpen)
+	for i, p := range points {
+		want := query.Contains(p)
+		for g := range results {
+			if results[g][i] != want {
+				t.Errorf("goroutine %d: Contains(%v) = %v, want %v", g, p, results[g][i], want)
+			}
+		}
+	}
+}
+
+func TestShapeIndexFreeze(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	var shapes []Shape
+	for i := 0; i < 10; i++ {
+		shapes = append(shapes, RegularLoop(randomPoint(r), s1.Degree*10, 50))
+	}
+	index := NewShapeIndex()
+	want := NewShapeIndex()
+	for _, shape := range shapes {
+		index.Add(shape)
+		want.Add(shape)
+	}
+
+	snapshot := index.Freeze()
+	if !snapshot.IsFrozen() || index.IsFrozen() {
+		t.Errorf("only the snapshot should be frozen")
+	}
+	if got := snapshot.Freeze(); got != snapshot {
+		t.Errorf("freezing a snapshot should return the snapshot")
+	}
+
+	// Modify the original while readers query the snapshot.
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 10; i++ {
+				if !indexCellsEqual(snapshot, want) {
+					t.Errorf("snapshot changed while the original index was modified")
+					return
+				}
+			}
+		}()
+	}
+	for i := 0; i < 5; i++ {
+		index.Remove(shapes[i])
+		index.Add(RegularLoop(randomPoint(r), s1.Degree*10, 50))
+		index.Build()
+	}
+	wg.Wait()
+
+	quadraticValidate(t, snapshot)
+	if got, want := snapshot.Len(), len(shapes); got != want {
+		t.Errorf("snapshot.Len() = %d, want %d", got, want)
+	}
+	for i, shape := range shapes {
+		if got := snapshot.Shape(int32(i)); got != shape {
+			t.Errorf("snapshot.Shape(%d) = %v, want %v", i, got, shape)
+		}
+	}
+	quadraticValidate(t, index)
+
+	for name, f := range map[string]func(){
+		"Add":    func() { snapshot.Add(shapes[0]) },
+		"Remove": func() { snapshot.Remove(shapes[0]) },
+		"Reset":  func() { snapshot.Reset() },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("snapshot.%s did not panic", name)
+				}
+			}()
+			f()
+		}()
+	}
+}
+
+func TestShapeIndexVersionAndStaleIterators(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	index := NewShapeIndex()
+	if got := index.Version(); got != 0 {
+		t.Errorf("Version() of a new index = %d, want 0", got)
+	}
+	loop := RegularLoop(randomPoint(r), s1.Degree*10, 20)
+	index.Add(loop)
+	if got := index.Version(); got != 0 {
+		t.Errorf("Version() with pending updates = %d, want 0", got)
+	}
+	index.Build()
+	if got := index.Version(); got != 1 {
+		t.Errorf("Version() after Build = %d, want 1", got)
+	}
+	index.Build()
+	if got := index.Version(); got != 1 {
+		t.Errorf("Version() after Build with no pending updates = %d, want 1", got)
+	}
+
+	it := index.Iterator()
+	if it.IsStale() {
+		t.Errorf("new iterator should not be stale")
+	}
+	index.Add(RegularLoop(randomPoint(r), s1.Degree*10, 20))
+	if it.IsStale() {
+		t.Errorf("iterator should not be stale while updates are pending")
+	}
+	index.Build()
+	if got := index.Version(); got != 2 {
+		t.Errorf("Version() after the second Build = %d, want 2", got)
+	}
+	if !it.IsStale() {
+		t.Errorf("iterator should be stale once updates are applied")
+	}
+
+	for name, f := range map[string]func(){
+		"Done":      func() { it.Done() },
+		"Next":      func() { it.Next() },
+		"Prev":      func() { it.Prev() },
+		"CellID":    func() { it.CellID() },
+		"IndexCell": func() { it.IndexCell() },
+		"Center":    func() { it.Center() },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s on a stale iterator did not panic", name)
+				}
+			}()
+			f()
+		}()
+	}
+
+	// Positioning the iterator from scratch brings it up to date.
+	if !it.LocatePoint(loop.Vertex(0)) || it.IsStale() {
+		t.Errorf("LocatePoint should position a stale iterator in the current index")
+	}
+	index.Remove(loop)
+	it.Begin()
+	if it.IsStale() || index.Version() != 3 {
+		t.Errorf("Begin should apply pending updates and position the iterator in the current index")
+	}
+
+	index.Reset()
+	if got := index.Version(); got != 4 {
+		t.Errorf("Version() after Reset = %d, want 4", got)
+	}
+	if !it.IsStale() {
+		t.Errorf("iterator should be stale after Reset")
+	}
+}
+
+func TestShapeIndexChangeCallback(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	var versions []uint64
+	var changed CellUnion
+	opts := NewShapeIndexOptions().ChangeCallback(func(version uint64, c CellUnion) {
+		versions = append(versions, version)
+		changed = c
+	})
+	index := NewShapeIndexWithOptions(opts)
+	var shapes []Shape
+	for i := 0; i < 10; i++ {
+		shape := RegularLoop(randomPoint(r), s1.Degree*10, 50)
+		shapes = append(shapes, shape)
+		index.Add(shape)
+	}
+	index.Build()
+	if !reflect.DeepEqual(versions, []uint64{1}) {
+		t.Fatalf("callback versions = %v, want [1]", versions)
+	}
+
+	// The changed region covers exactly the cells that differ between two
+	// versions of the index; every other cell is unchanged.
+	checkChanged := func(before, after *ShapeIndex) {
+		t.Helper()
+		cells := func(index *ShapeIndex) map[CellID]*ShapeIndexCell {
+			m := make(map[CellID]*ShapeIndexCell)
+			for it := index.Iterator(); !it.Done(); it.Next() {
+				m[it.CellID()] = it.IndexCell()
+			}
+			return m
+		}
+		a, b := cells(before), cells(after)
+		for _, m := range []map[CellID]*ShapeIndexCell{a, b} {
+			for id := range m {
+				if a[id] != b[id] && !changed.ContainsCellID(id) {
+					t.Errorf("cell %v changed but is not in the changed region", id)
+				}
+				if a[id] == b[id] && changed.IntersectsCellID(id) {
+					t.Errorf("cell %v is unchanged but intersects the changed region", id)
+				}
+			}
+		}
+	}
+
+	before := index.Freeze()
+	index.Remove(shapes[3])
+	index.Add(RegularLoop(randomPoint(r), s1.Degree, 10))
+	index.Build()
+	if !reflect.DeepEqual(versions, []uint64{1, 2}) {
+		t.Fatalf("callback versions = %v, want [1 2]", versions)
+	}
+	if len(changed) == 0 {
+		t.Errorf("changed region is empty after an update")
+	}
+	checkChanged(before, index)
+
+	// The callback is also called when updates are applied lazily.
+	before = index.Freeze()
+	index.Add(RegularLoop(randomPoint(r), s1.Degree, 10))
+	NewContainsPointQuery(index, VertexModelSemiOpen).Contains(randomPoint(r))
+	if got := index.Version(); versions[len(versions)-1] != got {
+		t.Errorf("callback version = %d, want %d", versions[len(versions)-1], got)
+	}
+	checkChanged(before, index)
+
+	before = index.Freeze()
+	index.Reset()
+	checkChanged(before, index)
+}
+
 // TODO(roberts): Differences from C++:
-// TestShapeIndexSimpleUpdates(t *testing.T) {}
-// TestShapeIndexRandomUpdates(t *testing.T) {}
 // TestShapeIndexHasCrossing(t *testing.T) {}
 
 func BenchmarkShapeIndexIteratorLocatePoint(b *testing.B) {
@@ -568,3 +1200,21 @@ func BenchmarkShapeIndexIteratorLocatePoint(b *testing.B) {
 		it.LocatePoint(randomPoint())
 	}
 }
+
+func BenchmarkShapeIndexBuild(b *testing.B) {
+	var shapes []Shape
+	for i := 0; i < 100; i++ {
+		shapes = append(shapes, RegularLoop(randomPoint(), s1.Degree*5, 2000))
+	}
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				index := NewShapeIndexWithOptions(NewShapeIndexOptions().Workers(workers))
+				for _, shape := range shapes {
+					index.Add(shape)
+				}
+				index.Build()
+			}
+		})
+	}
+}
@@ -56,31 +56,31 @@ func TestShapeutilRangeIteratorNext(t *testing.T) {
 	itCount := 0
 	next := func() {
 		itCount++
-		it.next()
-		if it.done() {
+		it.Next()
+		if it.Done() {
 			t.Errorf("There should be 3 items in the index but there were only %d", itCount)
 		}
 	}
 
-	if got, want := it.cellID().Face(), 0; got != want {
+	if got, want := it.CellID().Face(), 0; got != want {
 		t.Errorf("it.CellID().Face() = %v, want %v", got, want)
 	}
 	next()
 
-	if got, want := it.cellID().Face(), 1; got != want {
+	if got, want := it.CellID().Face(), 1; got != want {
 		t.Errorf("it.CellID().Face() = %v, want %v", got, want)
 	}
 	next()
 
-	if got, want := it.cellID().Face(), 2; got != want {
+	if got, want := it.CellID().Face(), 2; got != want {
 		t.Errorf("it.CellID().Face() = %v, want %v", got, want)
 	}
 
-	it.next()
-	if !it.done() {
+	it.Next()
+	if !it.Done() {
 		t.Errorf("iterator over index of three items should be done after 3 calls to next")
 	}
-	if got, want := it.cellID(), SentinelCellID; got != want {
+	if got, want := it.CellID(), SentinelCellID; got != want {
 		t.Errorf("it.CellID() = %v, want %v", got, want)
 	}
 }
@@ -92,27 +92,27 @@ func TestShapeutilRangeIteratorEmptyIndex(t *testing.T) {
 	emptyIter := newRangeIterator(empty)
 	nonEmptyIter := newRangeIterator(nonEmpty)
 
-	if !emptyIter.done() {
+	if !emptyIter.Done() {
 		t.Errorf("the rangeIterator on an empty ShapeIndex should be done at creation")
 	}
 
-	emptyIter.seekTo(nonEmptyIter)
-	if !emptyIter.done() {
+	emptyIter.SeekTo(nonEmptyIter)
+	if !emptyIter.Done() {
 		t.Errorf("seeking in the range iterator on an empty index to a cell should hit the end")
 	}
 
-	emptyIter.seekBeyond(nonEmptyIter)
-	if !emptyIter.done() {
+	emptyIter.SeekBeyond(nonEmptyIter)
+	if !emptyIter.Done() {
 		t.Errorf("seeking in the range iterator on an empty index beyond a cell should hit the end")
 	}
 
-	emptyIter.seekTo(emptyIter)
-	if !emptyIter.done() {
+	emptyIter.SeekTo(emptyIter)
+	if !emptyIter.Done() {
 		t.Errorf("seeking in the range iterator on an empty index to a its current position should hit the end")
 	}
 
-	emptyIter.seekBeyond(emptyIter)
-	if !emptyIter.done() {
+	emptyIter.SeekBeyond(emptyIter)
+	if !emptyIter.Done() {
 		t.Errorf("seeking in the range iterator on an empty index beyond itself should hit the end")
 	}
 }